@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/app"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/imports"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidArguments возникает при некорректных аргументах командной строки.
+var ErrInvalidArguments = errors.New("invalid arguments")
+
+// runImport выполняет подкоманду import: импортирует персон из файла CSV или NDJSON,
+// печатает итоги импорта в формате JSON и при необходимости сохраняет отчет об ошибках.
+//
+//	service import [-tenant ID] [-format csv|ndjson] [-mapping JSON] [-delimiter ;] [-dry-run] [-enrich] [-report errors.csv] <file>
+func runImport(ctx context.Context, cfg *setup.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "file format: csv or ndjson (detected from the file extension by default)")
+	mapping := flags.String("mapping", "", `JSON object mapping file columns to person fields, e.g. {"Фамилия":"surname"}`)
+	delimiter := flags.String("delimiter", ",", "CSV field delimiter")
+	dryRun := flags.Bool("dry-run", false, "validate rows without saving persons")
+	enrich := flags.Bool("enrich", false, "enrich imported persons and wait for completion")
+	reportPath := flags.String("report", "", "path of the CSV error report to write")
+	actor := flags.String("actor", "cli", "actor recorded in the person history")
+	tenantID := flags.String("tenant", "", "tenant the persons are imported for (required when tenants are configured)")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArguments, err)
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%w: exactly one file is required", ErrInvalidArguments)
+	}
+	if utf8.RuneCountInString(*delimiter) != 1 {
+		return fmt.Errorf("%w: delimiter must be a single character", ErrInvalidArguments)
+	}
+
+	path := flags.Arg(0)
+	opts := imports.Options{
+		Format:   *format,
+		FileName: filepath.Base(path),
+		DryRun:   *dryRun,
+		Enrich:   *enrich,
+	}
+	if opts.Format == "" {
+		opts.Format = imports.DetectFormat(path, "")
+	}
+	opts.Delimiter, _ = utf8.DecodeRuneInString(*delimiter)
+
+	var err error
+	if opts.Mapping, err = imports.ParseMapping(*mapping); err != nil {
+		return fmt.Errorf("failed to parse mapping: %w", err)
+	}
+
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Warn(ctx, "failed to close import file", zap.Error(err))
+		}
+	}()
+
+	application, err := app.NewApplication(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer func() {
+		if err := application.Stop(ctx); err != nil {
+			logger.Error(ctx, "error stopping application", zap.Error(err))
+		}
+	}()
+
+	// Арендатор по умолчанию подставляется, только если арендаторы не настроены: иначе импорт
+	// без -tenant записал бы персоны арендатору, которого нет в настройках.
+	if *tenantID == "" {
+		if application.Tenants().Configured() {
+			return fmt.Errorf("%w: -tenant is required when tenants are configured", ErrInvalidArguments)
+		}
+		*tenantID = tenant.Default
+	}
+	if _, ok := application.Tenants().Get(*tenantID); !ok {
+		return fmt.Errorf("%w: unknown tenant %q", ErrInvalidArguments, *tenantID)
+	}
+	importCtx := historyrepo.WithActor(tenant.WithID(ctx, *tenantID), *actor)
+
+	service := imports.NewService(application.API(), application.Repositories())
+	result, err := service.Import(importCtx, file, opts)
+	if err != nil {
+		return fmt.Errorf("failed to import persons: %w", err)
+	}
+	service.Wait()
+
+	if *reportPath != "" {
+		if err := writeReport(*reportPath, result); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to print import result: %w", err)
+	}
+	return nil
+}
+
+// writeReport сохраняет построчный отчет об ошибках импорта в файл.
+func writeReport(path string, result *entities.PersonImport) error {
+	report, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := imports.WriteReport(report, result); err != nil {
+		_ = report.Close()
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	if err := report.Close(); err != nil {
+		return fmt.Errorf("failed to close report file: %w", err)
+	}
+	return nil
+}
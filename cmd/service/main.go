@@ -13,8 +13,6 @@ import (
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/app"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/config"
-	"github.com/flexer2006/case-person-enrichment-go/pkg/database"
-	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -71,47 +69,30 @@ func main() {
 
 		logger.SetGlobal(finalLogger)
 
-		shutdownTimeout, err := time.ParseDuration(cfg.Graceful.ShutdownTimeout)
-		if err != nil {
-			logger.Error(ctx, "invalid graceful shutdown timeout", zap.Error(err))
-			shutdownTimeout = 5 * time.Second
-		}
-
-		dbConfig := database.Config{
-			Postgres: cfg.Postgres.ToConfig(),
-			Migrate: migrate.Config{
-				Path: cfg.Migrations.Path,
-			},
-			ApplyMigrations: true, // Автоматически применяем миграции при запуске
-		}
-
-		logger.Info(ctx, "initializing database")
-		data, err := database.New(ctx, dbConfig)
-		if err != nil {
-			logger.Error(ctx, "failed to initialize database", zap.Error(err))
-			exitCode = 1
+		// Подкоманда import выполняет импорт из файла без запуска HTTP-сервера.
+		if len(os.Args) > 1 && os.Args[1] == "import" {
+			if err := runImport(ctx, cfg, os.Args[2:]); err != nil {
+				logger.Error(ctx, "import failed", zap.Error(err))
+				exitCode = 1
+			}
 			return
 		}
 
-		if err := data.Ping(ctx); err != nil {
-			logger.Error(ctx, "database ping failed", zap.Error(err))
-			exitCode = 1
+		// Подкоманда migrate управляет версией схемы без запуска приложения.
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			if err := runMigrate(ctx, cfg, os.Args[2:]); err != nil {
+				logger.Error(ctx, "migrate failed", zap.Error(err))
+				exitCode = 1
+			}
 			return
 		}
 
-		version, dirty, err := data.GetMigrationVersion(ctx)
+		shutdownTimeout, err := time.ParseDuration(cfg.Graceful.ShutdownTimeout)
 		if err != nil {
-			logger.Warn(ctx, "failed to get migration version", zap.Error(err))
-		} else {
-			if dirty {
-				logger.Warn(ctx, "database has dirty migration", zap.Uint("version", version))
-			} else {
-				logger.Info(ctx, "current migration version", zap.Uint("version", version))
-			}
+			logger.Error(ctx, "invalid graceful shutdown timeout", zap.Error(err))
+			shutdownTimeout = 5 * time.Second
 		}
 
-		logger.Info(ctx, "database initialized successfully")
-
 		application, err := app.NewApplication(ctx, cfg)
 		if err != nil {
 			logger.Error(ctx, "failed to initialize application", zap.Error(err))
@@ -134,12 +115,25 @@ func main() {
 			zap.String("environment", cfg.Logger.Model),
 			zap.String("log_level", cfg.Logger.Level),
 			zap.String("startup_time", time.Now().Format(time.RFC3339)),
+			zap.String("storage_driver", cfg.Storage.Driver),
 			zap.Object("server_config", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
 				for _, field := range cfg.Server.LogFields() {
 					field.AddTo(enc)
 				}
 				return nil
 			})),
+			zap.Object("retention_config", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				for _, field := range cfg.Retention.LogFields() {
+					field.AddTo(enc)
+				}
+				return nil
+			})),
+			zap.Object("tenants_config", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				for _, field := range cfg.Tenants.LogFields() {
+					field.AddTo(enc)
+				}
+				return nil
+			})),
 		)
 
 		var sig os.Signal
@@ -163,9 +157,6 @@ func main() {
 			exitCode = 1
 		}
 
-		logger.Info(ctx, "closing database connection")
-		data.Close(shutdownCtx)
-
 		logger.Info(ctx, "service shutdown complete")
 	}()
 
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/storage"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+)
+
+// runMigrate выполняет подкоманду migrate: управляет версией схемы хранилища, выбранного в конфигурации.
+// Без N команда up применяет все новые миграции, а down откатывает одну. Команда verify проверяет
+// обратимость миграций и запускается только на пустой базе.
+//
+//	service migrate up [N] | down [N] | goto V | force V | status | verify
+func runMigrate(ctx context.Context, cfg *setup.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: migrate command is required (up, down, goto, force, status, verify)", ErrInvalidArguments)
+	}
+
+	dsn, source, err := migrationTarget(cfg)
+	if err != nil {
+		return err
+	}
+	migrator := migrate.NewAdapter(source)
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "up":
+		if len(args) == 0 {
+			return migrator.Up(ctx, dsn)
+		}
+		n, err := parseCount(args)
+		if err != nil {
+			return err
+		}
+		return migrator.Steps(ctx, dsn, n)
+	case "down":
+		n := 1
+		if len(args) > 0 {
+			if n, err = parseCount(args); err != nil {
+				return err
+			}
+		}
+		return migrator.Steps(ctx, dsn, -n)
+	case "goto":
+		version, err := parseVersion(args)
+		if err != nil {
+			return err
+		}
+		return migrator.Goto(ctx, dsn, version)
+	case "force":
+		// Версия -1 означает, что ни одна миграция не применена.
+		if len(args) != 1 {
+			return fmt.Errorf("%w: force requires exactly one version", ErrInvalidArguments)
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return fmt.Errorf("%w: invalid version %q", ErrInvalidArguments, args[0])
+		}
+		return migrator.Force(ctx, dsn, version)
+	case "status":
+		if len(args) != 0 {
+			return fmt.Errorf("%w: status takes no arguments", ErrInvalidArguments)
+		}
+		status, err := migrator.Status(ctx, dsn)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(status); err != nil {
+			return fmt.Errorf("failed to write migration status: %w", err)
+		}
+		return nil
+	case "verify":
+		if len(args) != 0 {
+			return fmt.Errorf("%w: verify takes no arguments", ErrInvalidArguments)
+		}
+		schema, closeDatabase, err := openSchema(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer closeDatabase()
+		return migrator.Verify(ctx, dsn, schema)
+	default:
+		return fmt.Errorf("%w: unknown migrate command %q", ErrInvalidArguments, command)
+	}
+}
+
+// openSchema подключается к базе данных хранилища и возвращает функцию чтения ее схемы
+// и функцию закрытия подключения.
+func openSchema(ctx context.Context, cfg *setup.Config) (migrate.SchemaFunc, func(), error) {
+	if cfg.Storage.Driver == storage.DriverSQLite {
+		database, err := sqlite.New(ctx, cfg.SQLite.ToConfig())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		return database.Schema, func() { database.Close(ctx) }, nil
+	}
+
+	database, err := postgres.New(ctx, cfg.Postgres.ToConfig())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return database.Schema, func() { database.Close(ctx) }, nil
+}
+
+// migrationTarget возвращает строку подключения и источник миграций для хранилища из конфигурации.
+func migrationTarget(cfg *setup.Config) (string, migrate.Config, error) {
+	switch cfg.Storage.Driver {
+	case storage.DriverPostgres:
+		return cfg.Postgres.ToConfig().DSN(), cfg.Migrations.Postgres(), nil
+	case storage.DriverSQLite:
+		config := cfg.SQLite.ToConfig()
+		if err := config.Validate(); err != nil {
+			return "", migrate.Config{}, fmt.Errorf("%w: %w", ErrInvalidArguments, err)
+		}
+		// SQLite создает файл базы, но не каталог, в котором он лежит.
+		if err := os.MkdirAll(filepath.Dir(config.Path), 0o750); err != nil {
+			return "", migrate.Config{}, fmt.Errorf("failed to create database directory: %w", err)
+		}
+		source, err := cfg.Migrations.SQLite()
+		if err != nil {
+			return "", migrate.Config{}, err
+		}
+		return config.MigrateURL(), source, nil
+	default:
+		return "", migrate.Config{}, fmt.Errorf("%w: storage driver %q has no migrations", ErrInvalidArguments, cfg.Storage.Driver)
+	}
+}
+
+// parseCount разбирает единственный аргумент с положительным количеством миграций.
+func parseCount(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%w: expected a single migration count", ErrInvalidArguments)
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: invalid migration count %q", ErrInvalidArguments, args[0])
+	}
+	return n, nil
+}
+
+// parseVersion разбирает единственный аргумент с версией миграции.
+func parseVersion(args []string) (uint, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%w: expected a single version", ErrInvalidArguments)
+	}
+	version, err := strconv.ParseUint(args[0], 10, strconv.IntSize)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid version %q", ErrInvalidArguments, args[0])
+	}
+	return uint(version), nil
+}
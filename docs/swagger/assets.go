@@ -0,0 +1,14 @@
+package swagger
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed swagger.html swagger.json
+var assets embed.FS
+
+// Assets возвращает встроенные страницу Swagger UI и спецификацию API.
+func Assets() fs.FS {
+	return assets
+}
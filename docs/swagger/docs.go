@@ -9,15 +9,232 @@ const docTemplate = `{
     "info": {
         "description": "{{escape .Description}}",
         "title": "{{.Title}}",
-        "contact": {},
+        "contact": {
+            "name": "API Support",
+            "email": "andrewgo1133official@gmail.com"
+        },
+        "license": {
+            "name": "MIT",
+            "url": "https://opensource.org/licenses/MIT"
+        },
         "version": "{{.Version}}"
     },
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/imports": {
+            "post": {
+                "description": "Import persons from an uploaded CSV (with a header row) or NDJSON file. Columns are copied to the person fields\nwith the same name unless mapping assigns them explicitly, e.g. {\"Фамилия\": \"surname\", \"Имя\": \"name\"}.\nInvalid rows are skipped and reported; with dry_run nothing is saved. The per-row error report is available at /imports/{id}/report",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Import persons from a file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV or NDJSON file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "File format, detected from the file name or content type by default",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object mapping file columns to person fields",
+                        "name": "mapping",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": ",",
+                        "description": "CSV field delimiter",
+                        "name": "delimiter",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Validate rows without saving persons",
+                        "name": "dry_run",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Enrich imported persons in the background",
+                        "name": "enrich",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Import result",
+                        "schema": {
+                            "$ref": "#/definitions/entities.PersonImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Missing file, malformed file or invalid options",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Too many records",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}": {
+            "get": {
+                "description": "Get the summary of a previous import",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Get import result",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import result",
+                        "schema": {
+                            "$ref": "#/definitions/entities.PersonImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid UUID format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Import not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}/report": {
+            "get": {
+                "description": "Download the per-row error report of an import as CSV with the columns row, field and error",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Download import error report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV error report",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid UUID format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Import not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/persons": {
             "get": {
-                "description": "Get a list of persons with filtering and pagination",
+                "description": "Get a list of persons with filtering, sorting and pagination. By default persons are ordered by creation time (newest first).\nPass next_cursor from the previous page as cursor (with the same sort) for stable keyset pagination.\nText filters (name, surname, patronymic, gender, nationality) accept the suffixes _exact, _prefix, _contains and _not to choose the match mode",
                 "consumes": [
                     "application/json"
                 ],
@@ -41,45 +258,170 @@ const docTemplate = `{
                         "minimum": 0,
                         "type": "integer",
                         "default": 0,
-                        "description": "Page offset",
+                        "description": "Page offset, ignored when cursor is set",
                         "name": "offset",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by name",
+                        "example": "surname,-age",
+                        "description": "Comma-separated sort columns, prefix - for descending: name, surname, age, gender_probability, nationality_probability, created_at, updated_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Skip counting the total number of persons",
+                        "name": "skip_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by name substring, comma-separated values are combined with OR",
                         "name": "name",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by surname",
+                        "description": "Filter by surname substring, comma-separated values are combined with OR",
                         "name": "surname",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by patronymic",
+                        "description": "Comma-separated exact names (case-insensitive)",
+                        "name": "name_exact",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated name prefixes",
+                        "name": "name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated name substrings",
+                        "name": "name_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Match name and surname by phonetic keys instead of substring",
+                        "name": "phonetic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by patronymic substring, comma-separated values are combined with OR",
                         "name": "patronymic",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by gender",
+                        "example": "male,female",
+                        "description": "Comma-separated genders (exact match)",
                         "name": "gender",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by nationality",
+                        "example": "RU,UA,BY",
+                        "description": "Comma-separated nationality codes (exact match)",
                         "name": "nationality",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated nationality codes to exclude",
+                        "name": "nationality_not",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Filter by age",
                         "name": "age",
                         "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Minimum age (inclusive)",
+                        "name": "age_min",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Maximum age (inclusive)",
+                        "name": "age_max",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1,
+                        "minimum": 0,
+                        "type": "number",
+                        "description": "Minimum gender probability (inclusive)",
+                        "name": "gender_probability_min",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1,
+                        "minimum": 0,
+                        "type": "number",
+                        "description": "Minimum nationality probability (inclusive)",
+                        "name": "nationality_probability_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated columns that must be empty: patronymic, age, gender, gender_probability, nationality, nationality_probability",
+                        "name": "missing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated columns that must be filled",
+                        "name": "present",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted persons (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -90,6 +432,24 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "Bad request - Invalid filter, include_deleted, sort, cursor, skip_count or phonetic",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Admin privileges required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -104,7 +464,1171 @@ const docTemplate = `{
             "post": {
                 "description": "Create a new person with the input data",
                 "consumes": [
-                    "application/json"
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Create new person",
+                "parameters": [
+                    {
+                        "description": "Person object to be created",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created person",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/bulk": {
+            "post": {
+                "description": "Create many persons from a JSON array (application/json) or NDJSON stream (application/x-ndjson).\nEvery record is validated; in atomic mode nothing is created if any record is invalid or the insert fails,\nin partial mode valid records are inserted in chunks and each record reports its own id or error",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Create persons in bulk",
+                "parameters": [
+                    {
+                        "description": "Persons to create",
+                        "name": "persons",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Person"
+                            }
+                        }
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "default": "atomic",
+                        "description": "Insert mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "Records per transaction in partial mode",
+                        "name": "chunk_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "All persons created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "207": {
+                        "description": "Some persons were not created (partial mode)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Malformed body, mode or chunk_size",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "A person with the same ID already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Too many records",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed (atomic mode)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/by-external/{source}/{externalId}": {
+            "get": {
+                "description": "Get the person linked to the external id of an upstream system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Get person by external reference",
+                "parameters": [
+                    {
+                        "maxLength": 50,
+                        "type": "string",
+                        "description": "Upstream system name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maxLength": 255,
+                        "type": "string",
+                        "description": "Person id in the upstream system (URL-encoded)",
+                        "name": "externalId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved person",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current person version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid source or external id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create a person linked to the external id of an upstream system, or replace the person already linked to it.\nRe-sending the same record is idempotent, so repeated imports do not create duplicates. If-Match is checked only when the person exists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Create or update person by external reference",
+                "parameters": [
+                    {
+                        "maxLength": 50,
+                        "type": "string",
+                        "description": "Upstream system name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maxLength": 255,
+                        "type": "string",
+                        "description": "Person id in the upstream system (URL-encoded)",
+                        "name": "externalId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Person data",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected person version (ETag)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Person updated",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Person version"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Person created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Person version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid source, external id or body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Linked person is deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Person version does not match If-Match or the person does not exist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/duplicates": {
+            "get": {
+                "description": "Group persons that likely describe the same human: the same name, surname and patronymic in a different case,\ntransliteration (matching phonetic keys) or with typos (trigram similarity of the full name).\nPersons linked by a chain of pairs with score \u003e= min_score form one cluster; the cluster score is its best pair score",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Find likely duplicate persons",
+                "parameters": [
+                    {
+                        "maximum": 1,
+                        "minimum": 0,
+                        "type": "number",
+                        "default": 0.7,
+                        "description": "Minimum pair similarity score",
+                        "name": "min_score",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Maximum number of clusters",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Duplicate clusters by descending score",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid min_score",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/export": {
+            "get": {
+                "description": "Stream persons matching the list filters as CSV, NDJSON or Parquet. Rows are read from a database cursor\nand written as they arrive, so the export is not limited by memory. The file is not paginated.\nErrors that occur after streaming has started terminate the response early.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet",
+                    "application/gzip"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Export persons to a file",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,surname,age",
+                        "description": "Comma-separated columns in output order: id, name, surname, patronymic, age, gender, gender_probability, nationality, nationality_probability, created_at, updated_at, version, deleted_at",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Compress CSV and NDJSON with gzip, Parquet pages with the GZIP codec",
+                        "name": "gzip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "surname,-age",
+                        "description": "Comma-separated sort columns, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by name substring, comma-separated values are combined with OR",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by surname substring, comma-separated values are combined with OR",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Match name and surname by phonetic keys instead of substring",
+                        "name": "phonetic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by patronymic substring, comma-separated values are combined with OR",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "male,female",
+                        "description": "Comma-separated genders (exact match)",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "RU,UA,BY",
+                        "description": "Comma-separated nationality codes (exact match)",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by age",
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Minimum age (inclusive)",
+                        "name": "age_min",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Maximum age (inclusive)",
+                        "name": "age_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated columns that must be empty",
+                        "name": "missing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated columns that must be filled",
+                        "name": "present",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted persons (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported persons",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid format, columns, gzip, filter or sort",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Admin privileges required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/merge": {
+            "post": {
+                "description": "Merge persons into the survivor. Empty survivor fields are filled from the merged persons; a value entered manually\nwins over an enriched one, among enriched gender and nationality the higher probability wins, otherwise the survivor\nand then the most recently updated person win. Merged persons are soft deleted, lookups of their ids redirect to the survivor,\nand the merge is recorded in the history of every person involved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Merge persons",
+                "parameters": [
+                    {
+                        "description": "Survivor and persons to merge into it",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MergeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected survivor version (ETag)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merged survivor",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New survivor version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid body or set of persons",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Survivor version does not match If-Match",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/search": {
+            "get": {
+                "description": "Typo-tolerant search by name, surname and patronymic using trigram similarity.\nResults are ranked by the similarity score (0..1), which is returned with every person.\nWith phonetic=true name and surname are matched by phonetic keys (Soundex, Double Metaphone, Russian metaphone) and the score is the share of matched query keys",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Fuzzy search persons",
+                "parameters": [
+                    {
+                        "maxLength": 100,
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Maximum number of results",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Match by phonetic keys instead of trigram similarity",
+                        "name": "phonetic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted persons (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully found persons",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Empty or too long query, invalid phonetic",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Admin privileges required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/stats": {
+            "get": {
+                "description": "Aggregate persons matching the list filters: counts by gender, the most frequent nationalities,\nan age histogram, the number and share of persons with each enrichable field filled and average probabilities.\nAll aggregates are computed by the database on one snapshot",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Get person statistics",
+                "parameters": [
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of most frequent nationalities",
+                        "name": "top",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Age histogram bucket width in years",
+                        "name": "age_bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by name substring, comma-separated values are combined with OR",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by surname substring, comma-separated values are combined with OR",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Match name and surname by phonetic keys instead of substring",
+                        "name": "phonetic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by patronymic substring, comma-separated values are combined with OR",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "male,female",
+                        "description": "Comma-separated genders (exact match)",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "RU,UA,BY",
+                        "description": "Comma-separated nationality codes (exact match)",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by age",
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Minimum age (inclusive)",
+                        "name": "age_min",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Maximum age (inclusive)",
+                        "name": "age_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated columns that must be empty",
+                        "name": "missing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated columns that must be filled",
+                        "name": "present",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted persons (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Person statistics",
+                        "schema": {
+                            "$ref": "#/definitions/entities.PersonStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid top, age_bucket or filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Admin privileges required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/{id}": {
+            "get": {
+                "description": "Get person details by UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Get person by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Person UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include soft-deleted persons (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved person",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current person version"
+                            }
+                        }
+                    },
+                    "308": {
+                        "description": "Person has been merged, Location points to the survivor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid UUID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Admin privileges required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing person",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Update person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Person UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Person data to update",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected person version (ETag)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated person",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New person version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Person version does not match If-Match or the person does not exist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft delete a person by UUID. The person can be restored until it is purged by the retention job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Delete person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Person UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected person version (ETag)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted"
+                    },
+                    "400": {
+                        "description": "Bad request - Invalid UUID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Person version does not match If-Match or the person does not exist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially update a person with JSON Merge Patch (RFC 7396, application/merge-patch+json or application/json)\nor JSON Patch (RFC 6902, application/json-patch+json). Only supplied fields are changed, explicit null clears a field.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
                 ],
                 "produces": [
                     "application/json"
@@ -112,27 +1636,83 @@ const docTemplate = `{
                 "tags": [
                     "persons"
                 ],
-                "summary": "Create new person",
+                "summary": "Partially update person",
                 "parameters": [
                     {
-                        "description": "Person object to be created",
-                        "name": "person",
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Person UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch object or array of JSON Patch operations",
+                        "name": "patch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entities.Person"
+                            "type": "object"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected person version (ETag)",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created person",
+                    "200": {
+                        "description": "Successfully patched person",
                         "schema": {
                             "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New person version"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad request - Invalid input",
+                        "description": "Bad request - Invalid patch or field value",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "JSON Patch test operation failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Person version does not match If-Match or the person does not exist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported patch media type",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -152,9 +1732,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/persons/{id}": {
-            "get": {
-                "description": "Get person details by UUID",
+        "/persons/{id}/enrich": {
+            "post": {
+                "description": "Enrich person with age, gender, and nationality data from external APIs",
                 "consumes": [
                     "application/json"
                 ],
@@ -164,7 +1744,7 @@ const docTemplate = `{
                 "tags": [
                     "persons"
                 ],
-                "summary": "Get person by ID",
+                "summary": "Enrich person data",
                 "parameters": [
                     {
                         "type": "string",
@@ -173,13 +1753,25 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected person version (ETag)",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved person",
+                        "description": "Successfully enriched person",
                         "schema": {
                             "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New person version"
+                            }
                         }
                     },
                     "400": {
@@ -200,6 +1792,30 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "412": {
+                        "description": "Person version does not match If-Match or the person does not exist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Tenant enrichment quota exceeded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "string",
+                                "description": "Seconds until the quota resets"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -210,9 +1826,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update an existing person",
+            }
+        },
+        "/persons/{id}/history": {
+            "get": {
+                "description": "Get the change history of a person, newest version first. Each entry contains before/after snapshots,\nthe list of changed fields, the actor (X-Actor header) and the request id (X-Request-ID header)",
                 "consumes": [
                     "application/json"
                 ],
@@ -222,7 +1840,7 @@ const docTemplate = `{
                 "tags": [
                     "persons"
                 ],
-                "summary": "Update person",
+                "summary": "Get person change history",
                 "parameters": [
                     {
                         "type": "string",
@@ -233,24 +1851,32 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Person data to update",
-                        "name": "person",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.Person"
-                        }
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully updated person",
+                        "description": "Successfully retrieved person history",
                         "schema": {
-                            "$ref": "#/definitions/entities.Person"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request - Invalid input",
+                        "description": "Bad request - Invalid UUID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -277,9 +1903,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a person by UUID",
+            }
+        },
+        "/persons/{id}/history/{version}/revert": {
+            "post": {
+                "description": "Restore the person data recorded in the history entry of the given version.\nThe change goes through the regular update path and is recorded in the history as a new version",
                 "consumes": [
                     "application/json"
                 ],
@@ -289,7 +1917,7 @@ const docTemplate = `{
                 "tags": [
                     "persons"
                 ],
-                "summary": "Delete person",
+                "summary": "Revert person to a previous version",
                 "parameters": [
                     {
                         "type": "string",
@@ -298,14 +1926,37 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Person version to revert to",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected current person version (ETag)",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Successfully deleted"
+                    "200": {
+                        "description": "Successfully reverted person",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New person version"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Bad request - Invalid UUID",
+                        "description": "Bad request - Invalid UUID or version",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -314,7 +1965,25 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Person not found",
+                        "description": "Person or history entry not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Person was modified concurrently during revert",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Person version does not match If-Match or the person does not exist",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -334,9 +2003,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/persons/{id}/enrich": {
+        "/persons/{id}/restore": {
             "post": {
-                "description": "Enrich person with age, gender, and nationality data from external APIs",
+                "description": "Restore a soft-deleted person by UUID",
                 "consumes": [
                     "application/json"
                 ],
@@ -346,7 +2015,7 @@ const docTemplate = `{
                 "tags": [
                     "persons"
                 ],
-                "summary": "Enrich person data",
+                "summary": "Restore person",
                 "parameters": [
                     {
                         "type": "string",
@@ -359,9 +2028,15 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully enriched person",
+                        "description": "Successfully restored person",
                         "schema": {
                             "$ref": "#/definitions/entities.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New person version"
+                            }
                         }
                     },
                     "400": {
@@ -382,6 +2057,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "409": {
+                        "description": "Person is not deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -405,6 +2089,9 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "type": "string"
+                },
                 "gender": {
                     "type": "string"
                 },
@@ -431,6 +2118,153 @@ const docTemplate = `{
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.PersonImport": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "enrich": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total - количество строк данных в файле, Valid - прошедших проверку, Imported - сохраненных.",
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.PersonStats": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "Age содержит непустые интервалы гистограммы возраста по возрастанию.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/person.AgeBucket"
+                    }
+                },
+                "age_bucket_width": {
+                    "description": "AgeBucketWidth - ширина интервала гистограммы возраста в годах.",
+                    "type": "integer"
+                },
+                "average_gender_probability": {
+                    "type": "number"
+                },
+                "average_nationality_probability": {
+                    "type": "number"
+                },
+                "coverage": {
+                    "description": "Coverage показывает, у скольких персон заполнена каждая колонка, заполняемая обогащением.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/person.FieldCoverage"
+                    }
+                },
+                "gender": {
+                    "description": "Gender содержит количество персон по полу по убыванию количества.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/person.ValueCount"
+                    }
+                },
+                "nationality": {
+                    "description": "Nationality содержит самые частые национальности по убыванию количества.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/person.ValueCount"
+                    }
+                },
+                "nationality_other": {
+                    "description": "NationalityOther - количество персон с национальностью, не вошедшей в Nationality.",
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.MergeRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "IDs - персоны, поглощаемые survivor.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "survivor_id": {
+                    "description": "SurvivorID - персона, которая останется после объединения.",
+                    "type": "string"
+                }
+            }
+        },
+        "person.AgeBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "person.FieldCoverage": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "filled": {
+                    "type": "integer"
+                },
+                "ratio": {
+                    "type": "number"
+                }
+            }
+        },
+        "person.ValueCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
                 }
             }
         }
@@ -439,12 +2273,12 @@ const docTemplate = `{
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
+	Version:          "1.0",
 	Host:             "",
-	BasePath:         "",
+	BasePath:         "/api/v1",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "Person Enrichment API",
+	Description:      "API for managing and enriching person data with external services",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -2,6 +2,8 @@
 package api
 
 import (
+	"context"
+
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/enrichment/api/people"
 	apiports "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
 	peopleapi "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people"
@@ -33,3 +35,8 @@ func NewDefaultAPI() *API {
 func (a *API) People() peopleapi.Services {
 	return a.peopleServices
 }
+
+// ReserveEnrichment ничего не учитывает: внешние API не ограничены квотой арендатора.
+func (a *API) ReserveEnrichment(_ context.Context) error {
+	return nil
+}
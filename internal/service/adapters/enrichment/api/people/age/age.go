@@ -35,14 +35,22 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// DefaultBaseURL адрес API agify.io.
+const DefaultBaseURL = "https://api.agify.io"
+
 // NewAgeAPIClient создает новый экземпляр APIClient.
 func NewAgeAPIClient(client HTTPClient) *APIClient {
+	return NewAgeAPIClientWithBaseURL(client, DefaultBaseURL)
+}
+
+// NewAgeAPIClientWithBaseURL создает новый экземпляр APIClient, обращающийся к API по адресу baseURL.
+func NewAgeAPIClientWithBaseURL(client HTTPClient, baseURL string) *APIClient {
 	if client == nil {
 		client = &http.Client{}
 	}
 
 	return &APIClient{
-		baseURL:    "https://api.agify.io",
+		baseURL:    baseURL,
 		httpClient: client,
 	}
 }
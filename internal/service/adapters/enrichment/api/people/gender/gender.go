@@ -35,14 +35,22 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// DefaultBaseURL адрес API genderize.io.
+const DefaultBaseURL = "https://api.genderize.io"
+
 // NewGenderAPIClient создает новый экземпляр APIClient.
 func NewGenderAPIClient(client HTTPClient) *APIClient {
+	return NewGenderAPIClientWithBaseURL(client, DefaultBaseURL)
+}
+
+// NewGenderAPIClientWithBaseURL создает новый экземпляр APIClient, обращающийся к API по адресу baseURL.
+func NewGenderAPIClientWithBaseURL(client HTTPClient, baseURL string) *APIClient {
 	if client == nil {
 		client = &http.Client{}
 	}
 
 	return &APIClient{
-		baseURL:    "https://api.genderize.io",
+		baseURL:    baseURL,
 		httpClient: client,
 	}
 }
@@ -35,14 +35,22 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// DefaultBaseURL адрес API nationalize.io.
+const DefaultBaseURL = "https://api.nationalize.io"
+
 // NewNationalityAPIClient создает новый экземпляр APIClient.
 func NewNationalityAPIClient(client HTTPClient) *APIClient {
+	return NewNationalityAPIClientWithBaseURL(client, DefaultBaseURL)
+}
+
+// NewNationalityAPIClientWithBaseURL создает новый экземпляр APIClient, обращающийся к API по адресу baseURL.
+func NewNationalityAPIClientWithBaseURL(client HTTPClient, baseURL string) *APIClient {
 	if client == nil {
 		client = &http.Client{}
 	}
 
 	return &APIClient{
-		baseURL:    "https://api.nationalize.io",
+		baseURL:    baseURL,
 		httpClient: client,
 	}
 }
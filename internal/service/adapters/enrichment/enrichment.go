@@ -2,6 +2,8 @@
 package enrichment
 
 import (
+	"context"
+
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/enrichment/api"
 	apiports "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
 	peopleapi "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people"
@@ -33,3 +35,8 @@ func NewDefaultEnrichment() *Enrichment {
 func (e *Enrichment) People() peopleapi.Services {
 	return e.api.People()
 }
+
+// ReserveEnrichment учитывает обогащение персоны в квоте API сервисов.
+func (e *Enrichment) ReserveEnrichment(ctx context.Context) error {
+	return e.api.ReserveEnrichment(ctx)
+}
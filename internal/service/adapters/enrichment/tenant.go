@@ -0,0 +1,196 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/enrichment/api/people/age"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/enrichment/api/people/gender"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/enrichment/api/people/nationality"
+	apiports "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
+	peopleapi "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people"
+	ageservice "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/age"
+	genderservice "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/gender"
+	nationalityservice "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/nationality"
+	personservice "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/person"
+	tenantsetup "github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/quota"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+)
+
+// Ошибки обогащения с учетом настроек арендатора.
+var (
+	ErrProviderDisabled   = apiports.ErrProviderDisabled
+	ErrTenantQuotaReached = apiports.ErrTenantQuotaReached
+)
+
+// Проверка, что TenantEnrichment реализует интерфейс apiports.API.
+var _ apiports.API = (*TenantEnrichment)(nil)
+
+// TenantEnrichment реализует интерфейс apiports.API, обращаясь к поставщикам обогащения
+// арендатора из контекста. Суточная квота арендатора учитывает обогащения персон, а не отдельные
+// обращения к поставщикам. Арендаторы без собственных настроек используют общих поставщиков без ограничений.
+type TenantEnrichment struct {
+	services *tenantServices
+}
+
+// tenantServices реализует peopleapi.Services, выбирая поставщиков по арендатору из контекста.
+type tenantServices struct {
+	defaults peopleapi.Services
+	tenants  map[string]*tenantProviders
+}
+
+// tenantProviders содержит поставщиков обогащения арендатора; nil означает отключенного поставщика.
+type tenantProviders struct {
+	age         ageservice.Service
+	gender      genderservice.Service
+	nationality nationalityservice.Service
+	limiter     *quota.Limiter
+}
+
+// NewTenantEnrichment создает TenantEnrichment с общими поставщиками defaults и настройками
+// поставщиков и квот арендаторов из registry.
+func NewTenantEnrichment(defaults peopleapi.Services, registry *tenantsetup.Registry) *TenantEnrichment {
+	services := &tenantServices{
+		defaults: defaults,
+		tenants:  make(map[string]*tenantProviders),
+	}
+
+	for _, t := range registry.Tenants() {
+		providers := &tenantProviders{
+			age:         defaults.Age(),
+			gender:      defaults.Gender(),
+			nationality: defaults.Nationality(),
+			limiter:     quota.NewLimiter(t.Quota.EnrichmentsPerDay, 24*time.Hour),
+		}
+
+		switch settings := t.Enrichment.Age; {
+		case settings.Disabled:
+			providers.age = nil
+		case settings.BaseURL != "":
+			providers.age = age.NewAgeAPIClientWithBaseURL(nil, settings.BaseURL)
+		}
+		switch settings := t.Enrichment.Gender; {
+		case settings.Disabled:
+			providers.gender = nil
+		case settings.BaseURL != "":
+			providers.gender = gender.NewGenderAPIClientWithBaseURL(nil, settings.BaseURL)
+		}
+		switch settings := t.Enrichment.Nationality; {
+		case settings.Disabled:
+			providers.nationality = nil
+		case settings.BaseURL != "":
+			providers.nationality = nationality.NewNationalityAPIClientWithBaseURL(nil, settings.BaseURL)
+		}
+
+		services.tenants[t.ID] = providers
+	}
+
+	return &TenantEnrichment{services: services}
+}
+
+// People возвращает интерфейсы для работы с данными о людях.
+func (e *TenantEnrichment) People() peopleapi.Services {
+	return e.services
+}
+
+// ReserveEnrichment учитывает одно обогащение персоны в суточной квоте арендатора из контекста.
+func (e *TenantEnrichment) ReserveEnrichment(ctx context.Context) error {
+	providers, err := e.services.providers(ctx)
+	if err != nil {
+		return err
+	}
+	return providers.allow(ctx)
+}
+
+// providers возвращает поставщиков арендатора из контекста. Без арендатора в контексте обращение
+// отклоняется, чтобы оно не прошло мимо настроек и квоты арендатора.
+func (s *tenantServices) providers(ctx context.Context) (*tenantProviders, error) {
+	id, ok := tenant.ID(ctx)
+	if !ok {
+		return nil, tenant.ErrMissing
+	}
+	if providers, ok := s.tenants[id]; ok {
+		return providers, nil
+	}
+	return &tenantProviders{
+		age:         s.defaults.Age(),
+		gender:      s.defaults.Gender(),
+		nationality: s.defaults.Nationality(),
+	}, nil
+}
+
+// allow учитывает обогащение персоны в квоте арендатора из контекста.
+func (p *tenantProviders) allow(ctx context.Context) error {
+	id, _ := tenant.ID(ctx)
+	if allowed, retryAfter := p.limiter.Allow(id); !allowed {
+		return &apiports.QuotaError{Tenant: id, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Person возвращает интерфейс для работы с персонами.
+func (s *tenantServices) Person() personservice.Service {
+	return s.defaults.Person()
+}
+
+// Age возвращает интерфейс для определения возраста.
+func (s *tenantServices) Age() ageservice.Service {
+	return tenantAge{s}
+}
+
+// Gender возвращает интерфейс для определения пола.
+func (s *tenantServices) Gender() genderservice.Service {
+	return tenantGender{s}
+}
+
+// Nationality возвращает интерфейс для определения национальности.
+func (s *tenantServices) Nationality() nationalityservice.Service {
+	return tenantNationality{s}
+}
+
+// tenantAge определяет возраст поставщиком арендатора из контекста.
+type tenantAge struct{ services *tenantServices }
+
+// GetAgeByName возвращает вероятный возраст и вероятность по имени.
+func (a tenantAge) GetAgeByName(ctx context.Context, name string) (int, float64, error) {
+	providers, err := a.services.providers(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if providers.age == nil {
+		return 0, 0, fmt.Errorf("%w: age", ErrProviderDisabled)
+	}
+	return providers.age.GetAgeByName(ctx, name)
+}
+
+// tenantGender определяет пол поставщиком арендатора из контекста.
+type tenantGender struct{ services *tenantServices }
+
+// GetGenderByName возвращает вероятный пол и вероятность по имени.
+func (g tenantGender) GetGenderByName(ctx context.Context, name string) (string, float64, error) {
+	providers, err := g.services.providers(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	if providers.gender == nil {
+		return "", 0, fmt.Errorf("%w: gender", ErrProviderDisabled)
+	}
+	return providers.gender.GetGenderByName(ctx, name)
+}
+
+// tenantNationality определяет национальность поставщиком арендатора из контекста.
+type tenantNationality struct{ services *tenantServices }
+
+// GetNationalityByName возвращает вероятную национальность и вероятность по имени.
+func (n tenantNationality) GetNationalityByName(ctx context.Context, name string) (string, float64, error) {
+	providers, err := n.services.providers(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	if providers.nationality == nil {
+		return "", 0, fmt.Errorf("%w: nationality", ErrProviderDisabled)
+	}
+	return providers.nationality.GetNationalityByName(ctx, name)
+}
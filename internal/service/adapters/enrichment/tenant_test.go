@@ -0,0 +1,103 @@
+package enrichment_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/enrichment"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/enrichment/api/people"
+	apiports "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
+	tenantsetup "github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAge struct{ age int }
+
+func (s stubAge) GetAgeByName(_ context.Context, _ string) (int, float64, error) {
+	return s.age, 1, nil
+}
+
+type stubGender struct{}
+
+func (stubGender) GetGenderByName(_ context.Context, _ string) (string, float64, error) {
+	return "female", 1, nil
+}
+
+type stubNationality struct{}
+
+func (stubNationality) GetNationalityByName(_ context.Context, _ string) (string, float64, error) {
+	return "RU", 1, nil
+}
+
+func TestTenantEnrichment(t *testing.T) {
+	agify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"anna","age":42,"count":1000}`))
+	}))
+	t.Cleanup(agify.Close)
+
+	registry, err := tenantsetup.NewRegistry([]tenantsetup.Tenant{
+		{
+			ID: "acme",
+			Enrichment: tenantsetup.Enrichment{
+				Age:    tenantsetup.Provider{BaseURL: agify.URL},
+				Gender: tenantsetup.Provider{Disabled: true},
+			},
+			Quota: tenantsetup.Quota{EnrichmentsPerDay: 2},
+		},
+		{ID: "globex"},
+	}, false)
+	require.NoError(t, err)
+
+	defaults := people.NewServices(nil, stubAge{age: 30}, stubGender{}, stubNationality{})
+	tenantAPI := enrichment.NewTenantEnrichment(defaults, registry)
+	services := tenantAPI.People()
+	acme := tenant.WithID(context.Background(), "acme")
+	globex := tenant.WithID(context.Background(), "globex")
+
+	t.Run("uses tenant provider address", func(t *testing.T) {
+		age, _, err := services.Age().GetAgeByName(acme, "Anna")
+		require.NoError(t, err)
+		assert.Equal(t, 42, age)
+
+		age, _, err = services.Age().GetAgeByName(globex, "Anna")
+		require.NoError(t, err)
+		assert.Equal(t, 30, age, "tenants without overrides use default providers")
+	})
+
+	t.Run("rejects disabled provider", func(t *testing.T) {
+		_, _, err := services.Gender().GetGenderByName(acme, "Anna")
+		require.ErrorIs(t, err, enrichment.ErrProviderDisabled)
+
+		gender, _, err := services.Gender().GetGenderByName(globex, "Anna")
+		require.NoError(t, err)
+		assert.Equal(t, "female", gender)
+	})
+
+	t.Run("rejects context without tenant", func(t *testing.T) {
+		_, _, err := services.Age().GetAgeByName(context.Background(), "Anna")
+		require.ErrorIs(t, err, tenant.ErrMissing)
+	})
+
+	t.Run("charges tenant quota once per person enrichment", func(t *testing.T) {
+		require.NoError(t, tenantAPI.ReserveEnrichment(acme))
+		// Обращения к поставщикам в рамках обогащения квоту не расходуют.
+		for range 3 {
+			_, _, err := services.Nationality().GetNationalityByName(acme, "Anna")
+			require.NoError(t, err)
+		}
+		require.NoError(t, tenantAPI.ReserveEnrichment(acme))
+
+		err := tenantAPI.ReserveEnrichment(acme)
+		require.ErrorIs(t, err, enrichment.ErrTenantQuotaReached)
+		var quotaErr *apiports.QuotaError
+		require.ErrorAs(t, err, &quotaErr)
+		assert.Positive(t, quotaErr.RetryAfter)
+
+		require.NoError(t, tenantAPI.ReserveEnrichment(globex))
+		require.ErrorIs(t, tenantAPI.ReserveEnrichment(context.Background()), tenant.ErrMissing)
+	})
+}
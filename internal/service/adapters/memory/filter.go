@@ -0,0 +1,377 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/phonetic"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// condition проверяет, удовлетворяет ли запись условию фильтра.
+type condition func(rec *record) bool
+
+// selection описывает выборку персон, разобранную из фильтра GetPersons.
+type selection struct {
+	conditions []condition
+	cursor     *person.Cursor
+	sortFields []person.SortField
+	skipCount  bool
+}
+
+// parseFilter разбирает фильтр GetPersons в условия отбора, сортировку и курсор
+// по тем же правилам, что и PostgreSQL-реализация.
+func parseFilter(ctx context.Context, filter map[string]any) (*selection, error) {
+	result := &selection{sortFields: person.DefaultSort}
+	usePhonetic, _ := filter[person.FilterPhonetic].(bool)
+
+	if !person.IncludeDeleted(ctx) {
+		result.conditions = append(result.conditions, func(rec *record) bool {
+			return rec.person.DeletedAt == nil
+		})
+	}
+
+	for field, value := range filter {
+		var match condition
+		var err error
+
+		switch field {
+		case "age":
+			match, err = intCondition(field, value, func(age, bound int) bool { return age == bound })
+		case person.FilterAgeMin:
+			match, err = intCondition(field, value, func(age, bound int) bool { return age >= bound })
+		case person.FilterAgeMax:
+			match, err = intCondition(field, value, func(age, bound int) bool { return age <= bound })
+		case person.FilterGenderProbabilityMin, person.FilterNationalityProbabilityMin:
+			match, err = probabilityCondition(field, value)
+		case person.FilterCreatedAfter, person.FilterCreatedBefore, person.FilterUpdatedAfter:
+			match, err = timeCondition(field, value)
+		case person.FilterMissing, person.FilterPresent:
+			columns, ok := value.([]string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a list of columns", person.ErrInvalidFilter, field)
+			}
+			for _, column := range columns {
+				if !person.NullableColumns[column] {
+					return nil, fmt.Errorf("%w: %s: unknown column %q", person.ErrInvalidFilter, field, column)
+				}
+				filled := field == person.FilterPresent
+				result.conditions = append(result.conditions, func(rec *record) bool {
+					return columnFilled(&rec.person, column) == filled
+				})
+			}
+			continue
+		case person.FilterIDs:
+			ids, ok := value.([]uuid.UUID)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a list of ids", person.ErrInvalidFilter, field)
+			}
+			match = func(rec *record) bool { return slices.Contains(ids, rec.person.ID) }
+		case person.FilterCursor:
+			position, ok := value.(person.Cursor)
+			if !ok {
+				return nil, fmt.Errorf("%w: unexpected type %T", person.ErrInvalidCursor, value)
+			}
+			result.cursor = &position
+			continue
+		case person.FilterSort:
+			fields, ok := value.([]person.SortField)
+			if !ok || len(fields) == 0 {
+				return nil, fmt.Errorf("%w: unexpected value %v", person.ErrInvalidSort, value)
+			}
+			for _, field := range fields {
+				if !person.SortableColumns[field.Column] {
+					return nil, fmt.Errorf("%w: unknown column %q", person.ErrInvalidSort, field.Column)
+				}
+			}
+			result.sortFields = fields
+			continue
+		case person.FilterSkipCount:
+			result.skipCount, _ = value.(bool)
+			continue
+		case person.FilterPhonetic:
+			continue
+		default:
+			textFilter, ok := person.ParseTextFilterKey(field)
+			if !ok {
+				logger.Warn(ctx, "ignoring unknown filter field", zap.String("field", field))
+				continue
+			}
+			values, valuesErr := filterValues(value)
+			if valuesErr != nil {
+				return nil, fmt.Errorf("%w: %s: %w", person.ErrInvalidFilter, field, valuesErr)
+			}
+			if usePhonetic && field == textFilter.Column && (field == "name" || field == "surname") {
+				match = phoneticCondition(field, values)
+			} else {
+				match = textCondition(textFilter, values)
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		result.conditions = append(result.conditions, match)
+	}
+
+	return result, nil
+}
+
+// matches сообщает, удовлетворяет ли запись всем условиям выборки.
+func (s *selection) matches(rec *record) bool {
+	for _, match := range s.conditions {
+		if !match(rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// ordered возвращает записи, удовлетворяющие условиям, в порядке сортировки выборки
+// и следующие за курсором, если он задан.
+func (s *selection) ordered(data *state) ([]*record, error) {
+	if s.cursor != nil && len(s.cursor.Values) != len(s.sortFields) {
+		return nil, fmt.Errorf("%w: cursor does not match sort", person.ErrInvalidCursor)
+	}
+
+	records := make([]*record, 0, len(data.persons))
+	for _, rec := range data.persons {
+		if !s.matches(rec) {
+			continue
+		}
+		// Курсор задает позицию по тому же ключу, что и сортировка.
+		if s.cursor != nil && compareKeys(s.sortFields, sortKey(rec, s.sortFields), rec.person.ID, s.cursor.Values, s.cursor.ID) <= 0 {
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	slices.SortFunc(records, func(a, b *record) int {
+		return compareKeys(s.sortFields, sortKey(a, s.sortFields), a.person.ID, sortKey(b, s.sortFields), b.person.ID)
+	})
+	return records, nil
+}
+
+// count возвращает количество записей, удовлетворяющих условиям выборки, без учета курсора.
+func (s *selection) count(data *state) int {
+	total := 0
+	for _, rec := range data.persons {
+		if s.matches(rec) {
+			total++
+		}
+	}
+	return total
+}
+
+// sortKey возвращает значения колонок сортировки записи.
+func sortKey(rec *record, sortFields []person.SortField) []any {
+	values := make([]any, len(sortFields))
+	for i, field := range sortFields {
+		values[i] = person.SortValue(&rec.person, field.Column)
+	}
+	return values
+}
+
+// compareKeys сравнивает позиции двух записей в порядке сортировки. Идентификатор служит
+// последним ключом с направлением первой колонки, как в ORDER BY PostgreSQL-реализации.
+func compareKeys(sortFields []person.SortField, a []any, aID uuid.UUID, b []any, bID uuid.UUID) int {
+	for i, field := range sortFields {
+		result := compareValues(a[i], b[i])
+		if field.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+
+	result := bytes.Compare(aID[:], bID[:])
+	if sortFields[0].Desc {
+		result = -result
+	}
+	return result
+}
+
+// compareValues сравнивает значения колонки сортировки. Как и в PostgreSQL, NULL (nil)
+// считается больше любого значения: он оказывается в конце при ASC и в начале при DESC.
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	switch a := a.(type) {
+	case string:
+		b, _ := b.(string)
+		return strings.Compare(a, b)
+	case int:
+		b, _ := b.(int)
+		return cmp.Compare(a, b)
+	case float64:
+		b, _ := b.(float64)
+		return cmp.Compare(a, b)
+	case time.Time:
+		b, _ := b.(time.Time)
+		return a.Compare(b)
+	default:
+		return 0
+	}
+}
+
+// intCondition строит условие по возрасту; значение фильтра должно иметь тип int.
+func intCondition(field string, value any, compare func(age, bound int) bool) (condition, error) {
+	bound, ok := value.(int)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be an integer", person.ErrInvalidFilter, field)
+	}
+	return func(rec *record) bool {
+		return rec.person.Age != nil && compare(*rec.person.Age, bound)
+	}, nil
+}
+
+// probabilityCondition строит условие минимальной вероятности; значение фильтра должно иметь тип float64.
+func probabilityCondition(field string, value any) (condition, error) {
+	bound, ok := value.(float64)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a number", person.ErrInvalidFilter, field)
+	}
+	column := strings.TrimSuffix(field, "_min")
+	return func(rec *record) bool {
+		probability, ok := person.SortValue(&rec.person, column).(float64)
+		return ok && probability >= bound
+	}, nil
+}
+
+// timeCondition строит условие по времени создания или изменения; значение фильтра должно иметь тип time.Time.
+// Нижняя граница включается, верхняя нет.
+func timeCondition(field string, value any) (condition, error) {
+	bound, ok := value.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a time", person.ErrInvalidFilter, field)
+	}
+	return func(rec *record) bool {
+		switch field {
+		case person.FilterCreatedAfter:
+			return !rec.person.CreatedAt.Before(bound)
+		case person.FilterCreatedBefore:
+			return rec.person.CreatedAt.Before(bound)
+		default:
+			return !rec.person.UpdatedAt.Before(bound)
+		}
+	}, nil
+}
+
+// textCondition строит условие текстового фильтра без учета регистра. Несколько значений
+// объединяются через ИЛИ; исключение также пропускает пустые значения.
+func textCondition(filter person.TextFilter, values []string) condition {
+	patterns := make([]string, len(values))
+	for i, value := range values {
+		patterns[i] = strings.ToLower(value)
+	}
+
+	return func(rec *record) bool {
+		value, ok := textValue(rec, filter.Column)
+		if !ok {
+			return filter.Negate
+		}
+		value = strings.ToLower(value)
+
+		matched := slices.ContainsFunc(patterns, func(pattern string) bool {
+			switch filter.Mode {
+			case person.MatchPrefix:
+				return strings.HasPrefix(value, pattern)
+			case person.MatchContains:
+				return strings.Contains(value, pattern)
+			default:
+				return value == pattern
+			}
+		})
+		return matched != filter.Negate
+	}
+}
+
+// phoneticCondition строит условие совпадения хотя бы одного фонетического ключа имени или фамилии.
+func phoneticCondition(column string, values []string) condition {
+	var keys []string
+	for _, value := range values {
+		keys = append(keys, phonetic.Keys(value)...)
+	}
+
+	return func(rec *record) bool {
+		stored := rec.namePhonetic
+		if column == "surname" {
+			stored = rec.surnamePhonetic
+		}
+		return slices.ContainsFunc(stored, func(key string) bool { return slices.Contains(keys, key) })
+	}
+}
+
+// textValue возвращает значение текстовой колонки записи; false соответствует NULL.
+func textValue(rec *record, column string) (string, bool) {
+	var value *string
+	switch column {
+	case "name":
+		return rec.person.Name, true
+	case "surname":
+		return rec.person.Surname, true
+	case "patronymic":
+		value = rec.person.Patronymic
+	case "gender":
+		value = rec.person.Gender
+	case "nationality":
+		value = rec.person.Nationality
+	}
+	if value == nil {
+		return "", false
+	}
+	return *value, true
+}
+
+// columnFilled сообщает, заполнена ли колонка персоны из NullableColumns.
+func columnFilled(p *entities.Person, column string) bool {
+	switch column {
+	case "patronymic":
+		return p.Patronymic != nil
+	case "age":
+		return p.Age != nil
+	case "gender":
+		return p.Gender != nil
+	case "gender_probability":
+		return p.GenderProbability != nil
+	case "nationality":
+		return p.Nationality != nil
+	case "nationality_probability":
+		return p.NationalityProbability != nil
+	default:
+		return true
+	}
+}
+
+// filterValues приводит значение текстового фильтра (string или []string) к непустому списку.
+func filterValues(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		if len(v) == 0 {
+			return nil, errors.New("empty list of values")
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unexpected type %T", value)
+	}
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrHistoryNotFound возникает, когда запись истории не найдена.
+var ErrHistoryNotFound = errors.New("person history not found")
+
+// Проверка реализации интерфейса.
+var _ history.Repository = (*HistoryRepository)(nil)
+
+// HistoryRepository реализует интерфейс history.Repository с хранением данных в памяти.
+type HistoryRepository struct {
+	session session
+}
+
+// GetHistory получает историю изменений персоны, начиная с последней версии.
+func (r *HistoryRepository) GetHistory(ctx context.Context, personID uuid.UUID, offset, limit int) ([]*entities.PersonHistory, int, error) {
+	logger.Debug(ctx, "getting person history",
+		zap.String("id", personID.String()),
+		zap.Int("offset", offset),
+		zap.Int("limit", limit))
+
+	var entries []*entities.PersonHistory
+	r.session.read(func(data *state) {
+		entries = slices.Clone(data.history[personID])
+	})
+
+	total := len(entries)
+	slices.Reverse(entries)
+	entries = entries[min(offset, total):min(offset+limit, total)]
+
+	result := make([]*entities.PersonHistory, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, cloneHistory(entry))
+	}
+	return result, total, nil
+}
+
+// GetByVersion получает запись истории, в результате которой персона получила указанную версию.
+func (r *HistoryRepository) GetByVersion(ctx context.Context, personID uuid.UUID, version int) (*entities.PersonHistory, error) {
+	logger.Debug(ctx, "getting person history entry",
+		zap.String("id", personID.String()),
+		zap.Int("version", version))
+
+	var found *entities.PersonHistory
+	r.session.read(func(data *state) {
+		for _, entry := range data.history[personID] {
+			if entry.Version == version {
+				found = cloneHistory(entry)
+				return
+			}
+		}
+	})
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: id %s, version %d", ErrHistoryNotFound, personID, version)
+	}
+	return found, nil
+}
+
+// cloneHistory возвращает копию записи истории, изменение которой не затрагивает хранилище.
+func cloneHistory(entry *entities.PersonHistory) *entities.PersonHistory {
+	result := *entry
+	if entry.Before != nil {
+		result.Before = clonePerson(entry.Before)
+	}
+	if entry.After != nil {
+		result.After = clonePerson(entry.After)
+	}
+	result.ChangedFields = slices.Clone(entry.ChangedFields)
+	result.RelatedIDs = slices.Clone(entry.RelatedIDs)
+	return &result
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Ошибки, связанные с хранением результатов импорта.
+var (
+	ErrImportNotFound      = errors.New("person import not found")
+	ErrImportAlreadyExists = errors.New("person import already exists")
+)
+
+// Проверка реализации интерфейса.
+var _ imports.Repository = (*ImportsRepository)(nil)
+
+// ImportsRepository реализует интерфейс imports.Repository с хранением данных в памяти.
+type ImportsRepository struct {
+	session session
+}
+
+// CreateImport сохраняет результат импорта.
+func (r *ImportsRepository) CreateImport(ctx context.Context, result *entities.PersonImport) error {
+	logger.Debug(ctx, "saving person import",
+		zap.String("id", result.ID.String()),
+		zap.Int("total", result.Total),
+		zap.Int("failed", result.Failed))
+
+	if result.ID == uuid.Nil {
+		result.ID = uuid.New()
+	}
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = now()
+	}
+
+	stored := *result
+	stored.Errors = slices.Clone(result.Errors)
+	if stored.Errors == nil {
+		stored.Errors = []entities.PersonImportError{}
+	}
+
+	return r.session.write(func(data *state) error {
+		if _, ok := data.imports[stored.ID]; ok {
+			return fmt.Errorf("%w: id %s", ErrImportAlreadyExists, stored.ID)
+		}
+		data.imports[stored.ID] = &stored
+		return nil
+	})
+}
+
+// GetImport получает результат импорта по идентификатору вместе с построчными ошибками.
+func (r *ImportsRepository) GetImport(ctx context.Context, id uuid.UUID) (*entities.PersonImport, error) {
+	logger.Debug(ctx, "getting person import", zap.String("id", id.String()))
+
+	var found *entities.PersonImport
+	r.session.read(func(data *state) {
+		if stored, ok := data.imports[id]; ok {
+			result := *stored
+			result.Errors = slices.Clone(stored.Errors)
+			found = &result
+		}
+	})
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: id %s", ErrImportNotFound, id)
+	}
+	return found, nil
+}
@@ -0,0 +1,177 @@
+// Package memory содержит реализацию репозиториев, хранящую данные в памяти процесса.
+// Хранилище предназначено для локального запуска без PostgreSQL и для тестов:
+// его поведение совпадает с PostgreSQL-реализацией, но данные теряются при остановке.
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	importsrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/google/uuid"
+)
+
+// Проверка реализации интерфейсов.
+var (
+	_ repo.Repositories   = (*Repositories)(nil)
+	_ people.Repositories = (*peopleRepositories)(nil)
+)
+
+// record хранит персону вместе с производными данными, которые в PostgreSQL лежат в отдельных колонках.
+// Записи не изменяются после сохранения: изменение персоны заменяет запись целиком.
+type record struct {
+	person          entities.Person
+	namePhonetic    []string
+	surnamePhonetic []string
+	mergedInto      uuid.UUID
+}
+
+// externalKey идентифицирует внешний идентификатор персоны.
+type externalKey struct {
+	source     string
+	externalID string
+}
+
+// state содержит все данные хранилища. Значения в картах не изменяются после сохранения,
+// поэтому копия карт является снимком данных.
+type state struct {
+	persons     map[uuid.UUID]*record
+	externalIDs map[externalKey]uuid.UUID
+	history     map[uuid.UUID][]*entities.PersonHistory
+	historySeq  int64
+	imports     map[uuid.UUID]*entities.PersonImport
+}
+
+// clone возвращает снимок данных, к которому можно вернуться при откате транзакции.
+func (s *state) clone() *state {
+	history := make(map[uuid.UUID][]*entities.PersonHistory, len(s.history))
+	for id, entries := range s.history {
+		// Ограничение емкости не дает записям, добавленным в транзакции, попасть в снимок.
+		history[id] = entries[:len(entries):len(entries)]
+	}
+	return &state{
+		persons:     maps.Clone(s.persons),
+		externalIDs: maps.Clone(s.externalIDs),
+		history:     history,
+		historySeq:  s.historySeq,
+		imports:     maps.Clone(s.imports),
+	}
+}
+
+// store разделяет состояние между репозиториями и защищает его от одновременного изменения.
+type store struct {
+	mu    sync.RWMutex
+	state *state
+}
+
+// session предоставляет репозиториям доступ к хранилищу. Сессия транзакции WithTx
+// выполняется под блокировкой, которую уже удерживает WithTx.
+type session struct {
+	store *store
+	inTx  bool
+}
+
+// read выполняет fn с блокировкой хранилища на чтение.
+func (s session) read(fn func(data *state)) {
+	if !s.inTx {
+		s.store.mu.RLock()
+		defer s.store.mu.RUnlock()
+	}
+	fn(s.store.state)
+}
+
+// write выполняет fn с исключительной блокировкой хранилища. fn проверяет все условия
+// до первого изменения, поэтому ошибка не оставляет частично примененных изменений.
+func (s session) write(fn func(data *state) error) error {
+	if !s.inTx {
+		s.store.mu.Lock()
+		defer s.store.mu.Unlock()
+	}
+	return fn(s.store.state)
+}
+
+// Repositories реализует интерфейс repo.Repositories с хранением данных в памяти.
+type Repositories struct {
+	session    session
+	peopleRepo *peopleRepositories
+}
+
+// NewRepositories создает пустое хранилище в памяти.
+func NewRepositories() *Repositories {
+	return newRepositories(session{store: &store{state: &state{
+		persons:     make(map[uuid.UUID]*record),
+		externalIDs: make(map[externalKey]uuid.UUID),
+		history:     make(map[uuid.UUID][]*entities.PersonHistory),
+		imports:     make(map[uuid.UUID]*entities.PersonImport),
+	}}})
+}
+
+// newRepositories создает репозитории, работающие в сессии s.
+func newRepositories(s session) *Repositories {
+	return &Repositories{
+		session: s,
+		peopleRepo: &peopleRepositories{
+			personRepo:  &PersonRepository{session: s},
+			historyRepo: &HistoryRepository{session: s},
+			importsRepo: &ImportsRepository{session: s},
+		},
+	}
+}
+
+// People возвращает репозитории для работы с данными о людях.
+func (r *Repositories) People() people.Repositories {
+	return r.peopleRepo
+}
+
+// WithTx выполняет fn под исключительной блокировкой хранилища, поэтому транзакции выполняются
+// последовательно и не требуют повторов. Если fn возвращает ошибку, данные возвращаются к снимку,
+// сделанному до вызова.
+func (r *Repositories) WithTx(_ context.Context, fn func(tx repo.Repositories) error) error {
+	if r.session.inTx {
+		return fn(r)
+	}
+
+	r.session.store.mu.Lock()
+	defer r.session.store.mu.Unlock()
+
+	snapshot := r.session.store.state.clone()
+	if err := fn(newRepositories(session{store: r.session.store, inTx: true})); err != nil {
+		r.session.store.state = snapshot
+		return err
+	}
+	return nil
+}
+
+// peopleRepositories реализует интерфейс people.Repositories с хранением данных в памяти.
+type peopleRepositories struct {
+	personRepo  *PersonRepository
+	historyRepo *HistoryRepository
+	importsRepo *ImportsRepository
+}
+
+// Person возвращает репозиторий для работы с персонами.
+func (r *peopleRepositories) Person() personrepo.Repository {
+	return r.personRepo
+}
+
+// History возвращает репозиторий истории изменений персон.
+func (r *peopleRepositories) History() historyrepo.Repository {
+	return r.historyRepo
+}
+
+// Imports возвращает репозиторий результатов импорта персон.
+func (r *peopleRepositories) Imports() importsrepo.Repository {
+	return r.importsRepo
+}
+
+// now возвращает текущее время с точностью PostgreSQL (микросекунды).
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
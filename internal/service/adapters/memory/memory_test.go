@@ -0,0 +1,71 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/memory"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person/persontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonRepository(t *testing.T) {
+	persontest.RunRepositoryTests(t, func(*testing.T) person.Repository {
+		return memory.NewRepositories().People().Person()
+	})
+}
+
+func TestWithTxRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repositories := memory.NewRepositories()
+	errAbort := errors.New("abort")
+
+	var created *entities.Person
+	err := repositories.WithTx(ctx, func(tx repo.Repositories) error {
+		created = &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+		if err := tx.People().Person().CreatePerson(ctx, created); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	exists, err := repositories.People().Person().ExistsByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = repositories.WithTx(ctx, func(tx repo.Repositories) error {
+		return tx.People().Person().CreatePerson(ctx, created)
+	})
+	require.NoError(t, err)
+
+	exists, err = repositories.People().Person().ExistsByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestHistoryRecordsChanges(t *testing.T) {
+	ctx := context.Background()
+	repositories := memory.NewRepositories()
+
+	p := &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+	require.NoError(t, repositories.People().Person().CreatePerson(ctx, p))
+	_, err := repositories.People().Person().PatchPerson(ctx, p.ID, map[string]any{"age": 30}, 0)
+	require.NoError(t, err)
+
+	history, total, err := repositories.People().History().GetHistory(ctx, p.ID, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	assert.Equal(t, 2, history[0].Version)
+	assert.Equal(t, []string{"age"}, history[0].ChangedFields)
+
+	first, err := repositories.People().History().GetByVersion(ctx, p.ID, 1)
+	require.NoError(t, err)
+	require.NotNil(t, first.After)
+	assert.Nil(t, first.After.Age)
+}
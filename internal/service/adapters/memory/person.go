@@ -0,0 +1,715 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/phonetic"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Ошибки, связанные с работой с персоны.
+var (
+	ErrPersonNotFound      = errors.New("person not found")
+	ErrPersonAlreadyExists = errors.New("person already exists")
+	ErrUnknownColumn       = errors.New("unknown person column")
+	ErrInvalidColumnValue  = errors.New("invalid person column value")
+)
+
+// Проверка реализации интерфейса.
+var _ person.Repository = (*PersonRepository)(nil)
+
+// PersonRepository реализует интерфейс person.Repository с хранением данных в памяти.
+// Фильтрация, сортировка, курсоры и оценки сходства повторяют PostgreSQL-реализацию.
+type PersonRepository struct {
+	session session
+}
+
+// GetByID получает персону по идентификатору.
+func (r *PersonRepository) GetByID(ctx context.Context, personID uuid.UUID) (*entities.Person, error) {
+	logger.Debug(ctx, "getting person by ID", zap.String("id", personID.String()))
+
+	var found *entities.Person
+	r.session.read(func(data *state) {
+		if rec, ok := data.persons[personID]; ok && (rec.person.DeletedAt == nil || person.IncludeDeleted(ctx)) {
+			found = clonePerson(&rec.person)
+		}
+	})
+
+	if found == nil {
+		logger.Debug(ctx, "person not found", zap.String("id", personID.String()))
+		return nil, fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+	}
+	return found, nil
+}
+
+// GetPersons получает список персон с фильтрацией и пагинацией.
+func (r *PersonRepository) GetPersons(ctx context.Context, filter map[string]any, offset, limit int) ([]*entities.Person, int, error) {
+	logger.Debug(ctx, "getting persons with filter",
+		zap.Any("filter", filter),
+		zap.Int("offset", offset),
+		zap.Int("limit", limit))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if selection.cursor != nil {
+		offset = 0
+	}
+
+	total := -1
+	var records []*record
+	r.session.read(func(data *state) {
+		if !selection.skipCount {
+			total = selection.count(data)
+		}
+		records, err = selection.ordered(data)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	records = records[min(offset, len(records)):min(offset+limit, len(records))]
+	persons := make([]*entities.Person, 0, len(records))
+	for _, rec := range records {
+		persons = append(persons, clonePerson(&rec.person))
+	}
+	return persons, total, nil
+}
+
+// StreamPersons передает в fn персоны, отобранные фильтром GetPersons. Выборка определяется
+// по снимку данных на момент вызова, а fn вызывается без блокировки хранилища.
+func (r *PersonRepository) StreamPersons(ctx context.Context, filter map[string]any, fn func(*entities.Person) error) error {
+	logger.Debug(ctx, "streaming persons with filter", zap.Any("filter", filter))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	var records []*record
+	r.session.read(func(data *state) {
+		records, err = selection.ordered(data)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("failed to stream persons: %w", err)
+		}
+		if err := fn(clonePerson(&rec.person)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpsertByExternalID создает или обновляет персону, связанную с внешним идентификатором.
+func (r *PersonRepository) UpsertByExternalID(ctx context.Context, source, externalID string, input *entities.Person) (bool, error) {
+	logger.Debug(ctx, "upserting person by external id",
+		zap.String("source", source),
+		zap.String("external_id", externalID))
+
+	created := false
+	err := r.session.write(func(data *state) error {
+		key := externalKey{source: source, externalID: externalID}
+		personID, linked := data.externalIDs[key]
+		if !linked {
+			if input.Version != 0 {
+				return fmt.Errorf("%w: external id %s/%s is not linked, expected version %d",
+					person.ErrVersionConflict, source, externalID, input.Version)
+			}
+			candidate := *input
+			candidate.ID = uuid.New()
+			candidate.CreatedAt = now()
+			candidate.UpdatedAt = candidate.CreatedAt
+			candidate.Version = 1
+			candidate.DeletedAt = nil
+
+			inserted := data.insert(&candidate)
+			data.externalIDs[key] = inserted.ID
+			data.addHistory(ctx, historyrepo.Operation(ctx, historyrepo.OperationCreate), nil, inserted)
+			*input = *clonePerson(inserted)
+			created = true
+			return nil
+		}
+
+		before, err := data.lock(ctx, personID, input.Version)
+		if err != nil {
+			return err
+		}
+		candidate := *input
+		candidate.ID = personID
+		updated := data.write(&candidate)
+		data.addHistory(ctx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, updated)
+		*input = *clonePerson(updated)
+		return nil
+	})
+
+	return created, err
+}
+
+// GetByExternalID получает неудаленную персону по внешнему идентификатору.
+func (r *PersonRepository) GetByExternalID(ctx context.Context, source, externalID string) (*entities.Person, error) {
+	logger.Debug(ctx, "getting person by external id",
+		zap.String("source", source),
+		zap.String("external_id", externalID))
+
+	var found *entities.Person
+	r.session.read(func(data *state) {
+		personID, ok := data.externalIDs[externalKey{source: source, externalID: externalID}]
+		if !ok {
+			return
+		}
+		if rec, ok := data.persons[personID]; ok && rec.person.DeletedAt == nil {
+			found = clonePerson(&rec.person)
+		}
+	})
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: external id %s/%s", ErrPersonNotFound, source, externalID)
+	}
+	return found, nil
+}
+
+// CreatePerson создает новую персону.
+func (r *PersonRepository) CreatePerson(ctx context.Context, input *entities.Person) error {
+	logger.Debug(ctx, "creating new person", zap.String("name", input.Name), zap.String("surname", input.Surname))
+
+	if input.ID == uuid.Nil {
+		input.ID = uuid.New()
+	}
+
+	return r.session.write(func(data *state) error {
+		if _, ok := data.persons[input.ID]; ok {
+			logger.Error(ctx, "person with this ID already exists", zap.String("id", input.ID.String()))
+			return fmt.Errorf("%w: ID %s", ErrPersonAlreadyExists, input.ID)
+		}
+
+		input.CreatedAt = now()
+		input.UpdatedAt = input.CreatedAt
+		input.Version = 1
+		input.DeletedAt = nil
+
+		created := data.insert(input)
+		data.addHistory(ctx, historyrepo.OperationCreate, nil, created)
+		*input = *clonePerson(created)
+		return nil
+	})
+}
+
+// CreatePersons создает несколько персон: если хотя бы одна не может быть создана, не создается ни одна.
+func (r *PersonRepository) CreatePersons(ctx context.Context, persons []*entities.Person) error {
+	logger.Debug(ctx, "creating persons in bulk", zap.Int("count", len(persons)))
+
+	if len(persons) == 0 {
+		return nil
+	}
+
+	createdAt := now()
+	for _, input := range persons {
+		if input.ID == uuid.Nil {
+			input.ID = uuid.New()
+		}
+		input.CreatedAt = createdAt
+		input.UpdatedAt = createdAt
+		input.Version = 1
+		input.DeletedAt = nil
+	}
+
+	return r.session.write(func(data *state) error {
+		seen := make(map[uuid.UUID]bool, len(persons))
+		for _, input := range persons {
+			if _, ok := data.persons[input.ID]; ok || seen[input.ID] {
+				logger.Error(ctx, "person with this ID already exists", zap.String("id", input.ID.String()))
+				return fmt.Errorf("%w: ID %s", ErrPersonAlreadyExists, input.ID)
+			}
+			seen[input.ID] = true
+		}
+
+		operation := historyrepo.Operation(ctx, historyrepo.OperationCreate)
+		for _, input := range persons {
+			data.addHistory(ctx, operation, nil, data.insert(input))
+		}
+		return nil
+	})
+}
+
+// UpdatePerson обновляет существующую персону.
+func (r *PersonRepository) UpdatePerson(ctx context.Context, input *entities.Person) error {
+	logger.Debug(ctx, "updating person", zap.String("id", input.ID.String()))
+
+	return r.session.write(func(data *state) error {
+		before, err := data.lock(ctx, input.ID, input.Version)
+		if err != nil {
+			return err
+		}
+
+		updated := data.write(input)
+		data.addHistory(ctx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, updated)
+		*input = *clonePerson(updated)
+		return nil
+	})
+}
+
+// PatchPerson частично обновляет персону, изменяя только переданные колонки.
+func (r *PersonRepository) PatchPerson(ctx context.Context, personID uuid.UUID, fields map[string]any, version int) (*entities.Person, error) {
+	logger.Debug(ctx, "patching person",
+		zap.String("id", personID.String()),
+		zap.Any("fields", fields),
+		zap.Int("version", version))
+
+	for column := range fields {
+		if !patchableColumns[column] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, column)
+		}
+	}
+
+	var patched *entities.Person
+	err := r.session.write(func(data *state) error {
+		before, err := data.lock(ctx, personID, version)
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			patched = clonePerson(before)
+			return nil
+		}
+
+		candidate := clonePerson(before)
+		for column, value := range fields {
+			if err := setColumn(candidate, column, value); err != nil {
+				return err
+			}
+		}
+
+		updated := data.write(candidate)
+		data.addHistory(ctx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, updated)
+		patched = clonePerson(updated)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
+// DeletePerson мягко удаляет персону по идентификатору, устанавливая deleted_at.
+func (r *PersonRepository) DeletePerson(ctx context.Context, personID uuid.UUID, version int) error {
+	logger.Debug(ctx, "deleting person", zap.String("id", personID.String()), zap.Int("version", version))
+
+	return r.session.write(func(data *state) error {
+		before, err := data.lock(ctx, personID, version)
+		if err != nil {
+			return err
+		}
+
+		deleted := data.replace(personID, func(p *entities.Person) {
+			deletedAt := now()
+			p.DeletedAt = &deletedAt
+			p.UpdatedAt = deletedAt
+		})
+		data.addHistory(ctx, historyrepo.OperationDelete, before, deleted)
+		return nil
+	})
+}
+
+// RestorePerson восстанавливает мягко удаленную персону.
+func (r *PersonRepository) RestorePerson(ctx context.Context, personID uuid.UUID) (*entities.Person, error) {
+	logger.Debug(ctx, "restoring person", zap.String("id", personID.String()))
+
+	var restored *entities.Person
+	err := r.session.write(func(data *state) error {
+		rec, ok := data.persons[personID]
+		if !ok {
+			return fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+		}
+		if rec.person.DeletedAt == nil {
+			return fmt.Errorf("%w: id %s", person.ErrPersonNotDeleted, personID)
+		}
+
+		before := clonePerson(&rec.person)
+		after := data.replace(personID, func(p *entities.Person) {
+			p.DeletedAt = nil
+			p.UpdatedAt = now()
+		})
+		data.persons[personID].mergedInto = uuid.Nil
+		data.addHistory(ctx, historyrepo.OperationRestore, before, after)
+		restored = clonePerson(after)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// MergePersons объединяет персоны mergedIDs с персоной survivorID как одну операцию.
+func (r *PersonRepository) MergePersons(ctx context.Context, survivorID uuid.UUID, mergedIDs []uuid.UUID, version int, resolve person.MergeResolver) (*entities.Person, error) {
+	logger.Debug(ctx, "merging persons",
+		zap.String("survivor", survivorID.String()),
+		zap.Int("merged", len(mergedIDs)),
+		zap.Int("version", version))
+
+	if len(mergedIDs) == 0 || slices.Contains(mergedIDs, survivorID) {
+		return nil, fmt.Errorf("%w: merged persons must not be empty or contain the survivor", person.ErrInvalidMerge)
+	}
+
+	var survivor *entities.Person
+	err := r.session.write(func(data *state) error {
+		ids := append([]uuid.UUID{survivorID}, mergedIDs...)
+		for _, id := range ids {
+			if rec, ok := data.persons[id]; !ok || rec.person.DeletedAt != nil {
+				logger.Debug(ctx, "person not found for modification", zap.String("id", id.String()))
+				return fmt.Errorf("%w: id %s", ErrPersonNotFound, id)
+			}
+		}
+		before, err := data.lock(ctx, survivorID, version)
+		if err != nil {
+			return err
+		}
+
+		merged := make([]*entities.Person, 0, len(mergedIDs))
+		for _, id := range mergedIDs {
+			merged = append(merged, clonePerson(&data.persons[id].person))
+		}
+
+		resolved := resolve(clonePerson(before), merged, data.enrichedFields(ids))
+		resolved.ID = survivorID
+		updated := data.write(resolved)
+		data.addHistory(ctx, historyrepo.OperationMerge, before, updated, mergedIDs...)
+
+		deletedAt := now()
+		for _, previous := range merged {
+			deleted := data.replace(previous.ID, func(p *entities.Person) {
+				p.DeletedAt = &deletedAt
+				p.UpdatedAt = deletedAt
+			})
+			data.persons[previous.ID].mergedInto = survivorID
+			data.addHistory(ctx, historyrepo.OperationMerge, previous, deleted, survivorID)
+		}
+
+		// Ссылки на поглощенные персоны и их внешние идентификаторы перенаправляются на survivor.
+		for id, rec := range data.persons {
+			if slices.Contains(mergedIDs, rec.mergedInto) {
+				redirected := *rec
+				redirected.mergedInto = survivorID
+				data.persons[id] = &redirected
+			}
+		}
+		for key, personID := range data.externalIDs {
+			if slices.Contains(mergedIDs, personID) {
+				data.externalIDs[key] = survivorID
+			}
+		}
+
+		survivor = clonePerson(updated)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return survivor, nil
+}
+
+// MergedInto возвращает персону, с которой была объединена персона, или uuid.Nil.
+func (r *PersonRepository) MergedInto(ctx context.Context, personID uuid.UUID) (uuid.UUID, error) {
+	logger.Debug(ctx, "getting merge target", zap.String("id", personID.String()))
+
+	target := uuid.Nil
+	r.session.read(func(data *state) {
+		if rec, ok := data.persons[personID]; ok {
+			target = rec.mergedInto
+		}
+	})
+	return target, nil
+}
+
+// PurgeDeleted безвозвратно удаляет персоны, мягко удаленные раньше deletedBefore, вместе с их историей
+// и внешними идентификаторами; ссылки объединенных персон на удаленные очищаются.
+func (r *PersonRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	logger.Debug(ctx, "purging deleted persons", zap.Time("deleted_before", deletedBefore))
+
+	var purged int64
+	err := r.session.write(func(data *state) error {
+		removed := make(map[uuid.UUID]bool)
+		for id, rec := range data.persons {
+			if rec.person.DeletedAt != nil && rec.person.DeletedAt.Before(deletedBefore) {
+				removed[id] = true
+			}
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+
+		for id := range removed {
+			delete(data.persons, id)
+			delete(data.history, id)
+		}
+		for id, rec := range data.persons {
+			if removed[rec.mergedInto] {
+				unlinked := *rec
+				unlinked.mergedInto = uuid.Nil
+				data.persons[id] = &unlinked
+			}
+		}
+		for key, personID := range data.externalIDs {
+			if removed[personID] {
+				delete(data.externalIDs, key)
+			}
+		}
+
+		purged = int64(len(removed))
+		return nil
+	})
+
+	return purged, err
+}
+
+// ExistsByID проверяет существование персоны по идентификатору.
+func (r *PersonRepository) ExistsByID(ctx context.Context, personID uuid.UUID) (bool, error) {
+	logger.Debug(ctx, "checking if person exists", zap.String("id", personID.String()))
+
+	exists := false
+	r.session.read(func(data *state) {
+		rec, ok := data.persons[personID]
+		exists = ok && (rec.person.DeletedAt == nil || person.IncludeDeleted(ctx))
+	})
+	return exists, nil
+}
+
+// patchableColumns содержит колонки, которые допускается изменять частичным обновлением.
+var patchableColumns = map[string]bool{
+	"name":                    true,
+	"surname":                 true,
+	"patronymic":              true,
+	"age":                     true,
+	"gender":                  true,
+	"gender_probability":      true,
+	"nationality":             true,
+	"nationality_probability": true,
+}
+
+// setColumn записывает значение частичного обновления в колонку персоны; nil очищает колонку.
+func setColumn(p *entities.Person, column string, value any) error {
+	var ok bool
+	switch column {
+	case "name":
+		p.Name, ok = value.(string)
+	case "surname":
+		p.Surname, ok = value.(string)
+	case "patronymic":
+		p.Patronymic, ok = optional[string](value)
+	case "age":
+		p.Age, ok = optional[int](value)
+	case "gender":
+		p.Gender, ok = optional[string](value)
+	case "gender_probability":
+		p.GenderProbability, ok = optional[float64](value)
+	case "nationality":
+		p.Nationality, ok = optional[string](value)
+	case "nationality_probability":
+		p.NationalityProbability, ok = optional[float64](value)
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %s: unexpected type %T", ErrInvalidColumnValue, column, value)
+	}
+	return nil
+}
+
+// optional приводит значение колонки к указателю; nil соответствует NULL.
+func optional[T any](value any) (*T, bool) {
+	if value == nil {
+		return nil, true
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return nil, false
+	}
+	return &typed, true
+}
+
+// lock возвращает неудаленную персону для изменения и проверяет ожидаемую версию.
+// Версия 0 отключает проверку.
+func (s *state) lock(ctx context.Context, personID uuid.UUID, version int) (*entities.Person, error) {
+	rec, ok := s.persons[personID]
+	if !ok || rec.person.DeletedAt != nil {
+		logger.Debug(ctx, "person not found for modification", zap.String("id", personID.String()))
+		return nil, fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+	}
+
+	if version != 0 && rec.person.Version != version {
+		logger.Debug(ctx, "person version conflict",
+			zap.String("id", personID.String()),
+			zap.Int("expected", version),
+			zap.Int("actual", rec.person.Version))
+		return nil, fmt.Errorf("%w: id %s, expected version %d, actual %d",
+			person.ErrVersionConflict, personID, version, rec.person.Version)
+	}
+
+	return clonePerson(&rec.person), nil
+}
+
+// insert сохраняет новую персону со всеми колонками, включая время создания и версию.
+// Возвращает: сохраненное состояние персоны.
+func (s *state) insert(p *entities.Person) *entities.Person {
+	rec := &record{person: *clonePerson(p)}
+	rec.namePhonetic = phonetic.Keys(rec.person.Name)
+	rec.surnamePhonetic = phonetic.Keys(rec.person.Surname)
+	s.persons[rec.person.ID] = rec
+	return &rec.person
+}
+
+// write записывает все изменяемые колонки персоны, увеличивая версию.
+// Возвращает: новое состояние персоны.
+func (s *state) write(p *entities.Person) *entities.Person {
+	return s.replace(p.ID, func(current *entities.Person) {
+		current.Name = p.Name
+		current.Surname = p.Surname
+		current.Patronymic = p.Patronymic
+		current.Age = p.Age
+		current.Gender = p.Gender
+		current.GenderProbability = p.GenderProbability
+		current.Nationality = p.Nationality
+		current.NationalityProbability = p.NationalityProbability
+		current.UpdatedAt = now()
+	})
+}
+
+// replace заменяет запись персоны копией, измененной fn, и увеличивает версию.
+// Возвращает: новое состояние персоны.
+func (s *state) replace(personID uuid.UUID, fn func(p *entities.Person)) *entities.Person {
+	rec := *s.persons[personID]
+	fn(&rec.person)
+	// Копия отделяет сохраненную запись от значений, переданных вызывающим.
+	rec.person = *clonePerson(&rec.person)
+	rec.person.Version++
+	rec.namePhonetic = phonetic.Keys(rec.person.Name)
+	rec.surnamePhonetic = phonetic.Keys(rec.person.Surname)
+	s.persons[personID] = &rec
+	return &rec.person
+}
+
+// enrichedFields возвращает для каждой персоны колонки, последнее изменение которых
+// по истории было сделано обогащением.
+func (s *state) enrichedFields(ids []uuid.UUID) map[uuid.UUID][]string {
+	enriched := make(map[uuid.UUID][]string, len(ids))
+	for _, id := range ids {
+		latest := make(map[string]string)
+		for _, entry := range s.history[id] {
+			for _, field := range entry.ChangedFields {
+				latest[field] = entry.Operation
+			}
+		}
+		for field, operation := range latest {
+			if operation == historyrepo.OperationEnrich {
+				enriched[id] = append(enriched[id], field)
+			}
+		}
+		sort.Strings(enriched[id])
+	}
+	return enriched
+}
+
+// addHistory записывает изменение персоны в историю.
+// Инициатор и идентификатор запроса берутся из контекста; related перечисляет
+// другие персоны, участвовавшие в операции.
+func (s *state) addHistory(ctx context.Context, operation string, before, after *entities.Person, related ...uuid.UUID) {
+	requestID, _ := logger.RequestID(ctx)
+
+	entry := &entities.PersonHistory{
+		PersonID:      after.ID,
+		Version:       after.Version,
+		Operation:     operation,
+		After:         clonePerson(after),
+		ChangedFields: changedFields(before, after),
+		RelatedIDs:    slices.Clone(related),
+		Actor:         historyrepo.Actor(ctx),
+		RequestID:     requestID,
+		CreatedAt:     now(),
+	}
+	if before != nil {
+		entry.Before = clonePerson(before)
+	}
+
+	s.historySeq++
+	entry.ID = s.historySeq
+	s.history[after.ID] = append(s.history[after.ID], entry)
+}
+
+// changedFields возвращает колонки, значения которых отличаются в двух состояниях персоны.
+// Для созданной персоны (before == nil) возвращаются все заполненные колонки.
+func changedFields(before, after *entities.Person) []string {
+	if before == nil {
+		before = &entities.Person{}
+	}
+
+	candidates := []struct {
+		column string
+		equal  bool
+	}{
+		{"name", before.Name == after.Name},
+		{"surname", before.Surname == after.Surname},
+		{"patronymic", equalPtr(before.Patronymic, after.Patronymic)},
+		{"age", equalPtr(before.Age, after.Age)},
+		{"gender", equalPtr(before.Gender, after.Gender)},
+		{"gender_probability", equalPtr(before.GenderProbability, after.GenderProbability)},
+		{"nationality", equalPtr(before.Nationality, after.Nationality)},
+		{"nationality_probability", equalPtr(before.NationalityProbability, after.NationalityProbability)},
+		{"deleted_at", (before.DeletedAt == nil) == (after.DeletedAt == nil)},
+	}
+
+	changed := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !candidate.equal {
+			changed = append(changed, candidate.column)
+		}
+	}
+	return changed
+}
+
+// equalPtr сравнивает значения по указателям, считая равными два nil.
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// clonePerson возвращает копию персоны, не разделяющую с оригиналом значения необязательных колонок.
+func clonePerson(p *entities.Person) *entities.Person {
+	result := *p
+	result.Patronymic = clonePtr(p.Patronymic)
+	result.Age = clonePtr(p.Age)
+	result.Gender = clonePtr(p.Gender)
+	result.GenderProbability = clonePtr(p.GenderProbability)
+	result.Nationality = clonePtr(p.Nationality)
+	result.NationalityProbability = clonePtr(p.NationalityProbability)
+	result.DeletedAt = clonePtr(p.DeletedAt)
+	return &result
+}
+
+// clonePtr возвращает указатель на копию значения или nil.
+func clonePtr[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/phonetic"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/trigram"
+	"go.uber.org/zap"
+)
+
+// SearchPersons выполняет нечеткий поиск персон по триграммному сходству с именем, фамилией
+// и отчеством, вычисляемому так же, как функция similarity расширения pg_trgm.
+// В фонетическом режиме оценка равна доле ключей запроса, совпавших с ключами имени и фамилии.
+func (r *PersonRepository) SearchPersons(ctx context.Context, query string, limit int, usePhonetic bool) ([]*entities.PersonSearchResult, error) {
+	logger.Debug(ctx, "searching persons",
+		zap.String("query", query),
+		zap.Int("limit", limit),
+		zap.Bool("phonetic", usePhonetic))
+
+	score := func(rec *record) (float64, bool) {
+		best := float64(0)
+		matched := false
+		for _, value := range []string{rec.person.Name, rec.person.Surname} {
+			similarity := trigram.Similarity(value, query)
+			best = max(best, similarity)
+			matched = matched || similarity >= trigram.Threshold
+		}
+		if rec.person.Patronymic != nil {
+			similarity := trigram.Similarity(*rec.person.Patronymic, query)
+			best = max(best, similarity)
+			matched = matched || similarity >= trigram.Threshold
+		}
+		return best, matched
+	}
+
+	if usePhonetic {
+		keys := phonetic.Keys(query)
+		if len(keys) == 0 {
+			return []*entities.PersonSearchResult{}, nil
+		}
+		score = func(rec *record) (float64, bool) {
+			if !overlaps(rec.namePhonetic, keys) && !overlaps(rec.surnamePhonetic, keys) {
+				return 0, false
+			}
+			matched := make(map[string]bool)
+			for _, key := range slices.Concat(rec.namePhonetic, rec.surnamePhonetic) {
+				if slices.Contains(keys, key) {
+					matched[key] = true
+				}
+			}
+			return float64(len(matched)) / float64(len(keys)), true
+		}
+	}
+
+	results := make([]*entities.PersonSearchResult, 0, limit)
+	r.session.read(func(data *state) {
+		for _, rec := range data.persons {
+			if rec.person.DeletedAt != nil && !person.IncludeDeleted(ctx) {
+				continue
+			}
+			if value, ok := score(rec); ok {
+				results = append(results, &entities.PersonSearchResult{Person: *clonePerson(&rec.person), Score: value})
+			}
+		}
+	})
+
+	slices.SortFunc(results, func(a, b *entities.PersonSearchResult) int {
+		if result := cmp.Compare(b.Score, a.Score); result != 0 {
+			return result
+		}
+		return bytes.Compare(a.Person.ID[:], b.Person.ID[:])
+	})
+	return results[:min(limit, len(results))], nil
+}
+
+// FindDuplicatePairs находит пары похожих неудаленных персон с теми же кандидатами и оценками,
+// что и PostgreSQL-реализация: 1 при совпадении имени, фамилии и отчества без учета регистра,
+// 0.9 или 0.75 при совпадении фонетических ключей имени и фамилии, иначе триграммное сходство полного имени.
+func (r *PersonRepository) FindDuplicatePairs(ctx context.Context, minScore float64, limit int) ([]entities.PersonDuplicatePair, error) {
+	logger.Debug(ctx, "finding duplicate persons",
+		zap.Float64("min_score", minScore),
+		zap.Int("limit", limit))
+
+	var active []*record
+	r.session.read(func(data *state) {
+		for _, rec := range data.persons {
+			if rec.person.DeletedAt == nil {
+				active = append(active, rec)
+			}
+		}
+	})
+
+	pairs := make([]entities.PersonDuplicatePair, 0)
+	for _, a := range active {
+		for _, b := range active {
+			if bytes.Compare(a.person.ID[:], b.person.ID[:]) >= 0 {
+				continue
+			}
+			if !overlaps(a.surnamePhonetic, b.surnamePhonetic) &&
+				trigram.Similarity(a.person.Surname, b.person.Surname) < trigram.Threshold {
+				continue
+			}
+			if score := duplicateScore(a, b); score >= minScore {
+				pairs = append(pairs, entities.PersonDuplicatePair{PersonID: a.person.ID, DuplicateID: b.person.ID, Score: score})
+			}
+		}
+	}
+
+	slices.SortFunc(pairs, func(a, b entities.PersonDuplicatePair) int {
+		if result := cmp.Compare(b.Score, a.Score); result != 0 {
+			return result
+		}
+		if result := bytes.Compare(a.PersonID[:], b.PersonID[:]); result != 0 {
+			return result
+		}
+		return bytes.Compare(a.DuplicateID[:], b.DuplicateID[:])
+	})
+	return pairs[:min(limit, len(pairs))], nil
+}
+
+// duplicateScore оценивает сходство двух персон как наибольшую из оценок FindDuplicatePairs.
+func duplicateScore(a, b *record) float64 {
+	patronymicA, patronymicB := "", ""
+	if a.person.Patronymic != nil {
+		patronymicA = *a.person.Patronymic
+	}
+	if b.person.Patronymic != nil {
+		patronymicB = *b.person.Patronymic
+	}
+
+	if strings.EqualFold(a.person.Name, b.person.Name) && strings.EqualFold(a.person.Surname, b.person.Surname) &&
+		strings.EqualFold(patronymicA, patronymicB) {
+		return 1
+	}
+
+	score := trigram.Similarity(fullName(&a.person), fullName(&b.person))
+	if overlaps(a.namePhonetic, b.namePhonetic) && overlaps(a.surnamePhonetic, b.surnamePhonetic) {
+		if a.person.Patronymic == nil || b.person.Patronymic == nil || strings.EqualFold(patronymicA, patronymicB) {
+			score = max(score, 0.9)
+		} else {
+			score = max(score, 0.75)
+		}
+	}
+	return score
+}
+
+// fullName соединяет имя, фамилию и отчество через пробел, пропуская пустое отчество (concat_ws).
+func fullName(p *entities.Person) string {
+	if p.Patronymic == nil {
+		return p.Name + " " + p.Surname
+	}
+	return p.Name + " " + p.Surname + " " + *p.Patronymic
+}
+
+// overlaps сообщает, есть ли у наборов ключей общий элемент (оператор && для массивов).
+func overlaps(a, b []string) bool {
+	return slices.ContainsFunc(a, func(key string) bool { return slices.Contains(b, key) })
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// coverageColumns перечисляет колонки, заполненность которых считает GetStats.
+var coverageColumns = []string{
+	"patronymic",
+	"age",
+	"gender",
+	"gender_probability",
+	"nationality",
+	"nationality_probability",
+}
+
+// GetStats вычисляет статистику по персонам, отобранным фильтром GetPersons, на одном снимке данных.
+func (r *PersonRepository) GetStats(ctx context.Context, filter map[string]any, opts person.StatsOptions) (*entities.PersonStats, error) {
+	logger.Debug(ctx, "getting person stats",
+		zap.Any("filter", filter),
+		zap.Int("top_nationalities", opts.TopNationalities),
+		zap.Int("age_bucket_width", opts.AgeBucketWidth))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var persons []*entities.Person
+	r.session.read(func(data *state) {
+		for _, rec := range data.persons {
+			if selection.matches(rec) {
+				persons = append(persons, &rec.person)
+			}
+		}
+	})
+
+	stats := &entities.PersonStats{
+		Total:          len(persons),
+		AgeBucketWidth: opts.AgeBucketWidth,
+		Coverage:       make([]entities.PersonStatsFieldCoverage, len(coverageColumns)),
+		Age:            make([]entities.PersonStatsAgeBucket, 0),
+	}
+
+	genders := make(map[string]int)
+	nationalities := make(map[string]int)
+	ages := make(map[int]int)
+	var genderProbability, nationalityProbability []float64
+	for _, p := range persons {
+		if p.Gender != nil {
+			genders[*p.Gender]++
+		}
+		if p.Nationality != nil {
+			nationalities[*p.Nationality]++
+		}
+		if p.Age != nil {
+			ages[*p.Age/opts.AgeBucketWidth*opts.AgeBucketWidth]++
+		}
+		if p.GenderProbability != nil {
+			genderProbability = append(genderProbability, *p.GenderProbability)
+		}
+		if p.NationalityProbability != nil {
+			nationalityProbability = append(nationalityProbability, *p.NationalityProbability)
+		}
+	}
+
+	for i, column := range coverageColumns {
+		filled := 0
+		for _, p := range persons {
+			if columnFilled(p, column) {
+				filled++
+			}
+		}
+		stats.Coverage[i] = entities.PersonStatsFieldCoverage{Field: column, Filled: filled}
+		if stats.Total > 0 {
+			stats.Coverage[i].Ratio = float64(filled) / float64(stats.Total)
+		}
+	}
+
+	stats.Gender = valueCounts(genders)
+	stats.Nationality = valueCounts(nationalities)
+	if len(stats.Nationality) > opts.TopNationalities {
+		stats.Nationality = stats.Nationality[:opts.TopNationalities]
+	}
+	for _, count := range nationalities {
+		stats.NationalityOther += count
+	}
+	for _, count := range stats.Nationality {
+		stats.NationalityOther -= count.Count
+	}
+
+	for from, count := range ages {
+		stats.Age = append(stats.Age, entities.PersonStatsAgeBucket{From: from, To: from + opts.AgeBucketWidth - 1, Count: count})
+	}
+	slices.SortFunc(stats.Age, func(a, b entities.PersonStatsAgeBucket) int { return cmp.Compare(a.From, b.From) })
+
+	stats.AverageGenderProbability = average(genderProbability)
+	stats.AverageNationalityProbability = average(nationalityProbability)
+	return stats, nil
+}
+
+// valueCounts упорядочивает количества по убыванию, а равные - по значению.
+func valueCounts(counts map[string]int) []entities.PersonStatsValueCount {
+	result := make([]entities.PersonStatsValueCount, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, entities.PersonStatsValueCount{Value: value, Count: count})
+	}
+	slices.SortFunc(result, func(a, b entities.PersonStatsValueCount) int {
+		if order := cmp.Compare(b.Count, a.Count); order != 0 {
+			return order
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return result
+}
+
+// average возвращает среднее значение или nil для пустого набора, как AVG в SQL.
+func average(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sum := float64(0)
+	for _, value := range values {
+		sum += value
+	}
+	result := sum / float64(len(values))
+	return &result
+}
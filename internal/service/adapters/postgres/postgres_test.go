@@ -0,0 +1,83 @@
+package postgres_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/migrations"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate/migratetest"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrationsRoundTrip проверяет обратимость миграций на базе из PG_TEST_DSN.
+func TestMigrationsRoundTrip(t *testing.T) {
+	dsn, db := testSchema(t, "migrations_round_trip")
+
+	migratetest.RoundTrip(t, dsn, migrate.Config{FS: migrations.Postgres()}, db.Schema)
+}
+
+// TestTablesIsolateTenants проверяет, что каждая таблица схемы содержит tenant_id
+// и защищена принудительными политиками строк, в том числе таблицы будущих миграций.
+func TestTablesIsolateTenants(t *testing.T) {
+	ctx := context.Background()
+	dsn, db := testSchema(t, "tenant_isolation")
+	require.NoError(t, migrate.NewAdapter(migrate.Config{FS: migrations.Postgres()}).Up(ctx, dsn))
+
+	rows, err := db.WritePool().Query(ctx, `
+        SELECT r.relname
+        FROM pg_class r
+        JOIN pg_namespace n ON n.oid = r.relnamespace
+        WHERE n.nspname = current_schema() AND r.relkind = 'r' AND r.relname <> 'schema_migrations'
+          AND (NOT r.relrowsecurity OR NOT r.relforcerowsecurity
+               OR NOT EXISTS (SELECT 1 FROM pg_policy p WHERE p.polrelid = r.oid)
+               OR NOT EXISTS (
+                   SELECT 1 FROM pg_attribute a
+                   WHERE a.attrelid = r.oid AND a.attname = 'tenant_id' AND NOT a.attisdropped
+               ))`)
+	require.NoError(t, err)
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	require.NoError(t, err)
+	assert.Empty(t, tables, "tables without tenant_id or forced row-level security")
+}
+
+// testSchema создает на базе из PG_TEST_DSN отдельную схему, которая удаляется после теста,
+// и возвращает строку подключения к ней и подключение к базе.
+func testSchema(t *testing.T, schema string) (string, *postgres.Database) {
+	t.Helper()
+
+	dsn := os.Getenv("PG_TEST_DSN")
+	if dsn == "" || testing.Short() {
+		t.Skip("PG_TEST_DSN is not set")
+	}
+
+	ctx := context.Background()
+
+	admin, err := postgres.NewWithDSN(ctx, dsn, 1, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close(ctx) })
+	_, err = admin.WritePool().Exec(ctx, "DROP SCHEMA IF EXISTS "+schema+" CASCADE; CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := admin.WritePool().Exec(ctx, "DROP SCHEMA IF EXISTS "+schema+" CASCADE")
+		require.NoError(t, err)
+	})
+
+	// Расширения уже могут быть установлены в public, поэтому она остается в пути поиска.
+	parsed, err := url.Parse(dsn)
+	require.NoError(t, err)
+	query := parsed.Query()
+	query.Set("search_path", schema+",public")
+	parsed.RawQuery = query.Encode()
+
+	db, err := postgres.NewWithDSN(ctx, parsed.String(), 1, 2)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close(ctx) })
+
+	return parsed.String(), db
+}
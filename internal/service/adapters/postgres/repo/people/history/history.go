@@ -0,0 +1,162 @@
+// Package history содержит реализацию репозитория истории изменений персон с использованием PostgreSQL.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// ErrHistoryNotFound возникает, когда запись истории не найдена.
+var ErrHistoryNotFound = errors.New("person history not found")
+
+// historyColumns перечисляет колонки таблицы person_history в порядке сканирования scanHistory.
+const historyColumns = `id, person_id, version, operation, before, after, changed_fields, related_ids, actor, request_id, created_at`
+
+// Проверка реализации интерфейса.
+var _ history.Repository = (*Repository)(nil)
+
+// Repository реализует интерфейс history.Repository
+// с использованием PostgreSQL в качестве хранилища.
+type Repository struct {
+	db postgres.Provider
+	// tx задан у копии репозитория, привязанной к транзакции методом WithTx.
+	tx pgx.Tx
+}
+
+// NewRepository создает новый экземпляр репозитория истории изменений персон.
+func NewRepository(db postgres.Provider) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// WithTx возвращает копию репозитория, выполняющую запросы в транзакции tx.
+func (r *Repository) WithTx(tx pgx.Tx) *Repository {
+	return &Repository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// querier возвращает транзакцию, к которой привязан репозиторий, или пул соединений.
+// Без арендатора в контексте запросы через querier завершаются ошибкой tenant.ErrMissing.
+func (r *Repository) querier(ctx context.Context) postgres.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return postgres.ForTenant(ctx, r.db.WritePool())
+}
+
+// GetHistory получает историю изменений персоны, начиная с последней версии.
+func (r *Repository) GetHistory(ctx context.Context, personID uuid.UUID, offset, limit int) ([]*entities.PersonHistory, int, error) {
+	logger.Debug(ctx, "getting person history",
+		zap.String("id", personID.String()),
+		zap.Int("offset", offset),
+		zap.Int("limit", limit))
+
+	var total int
+	err := r.querier(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM person_history WHERE person_id = $1`, personID).Scan(&total)
+	if err != nil {
+		logger.Error(ctx, "failed to count person history", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count person history: %w", err)
+	}
+
+	if total == 0 {
+		return []*entities.PersonHistory{}, 0, nil
+	}
+
+	query := `
+        SELECT ` + historyColumns + `
+        FROM person_history
+        WHERE person_id = $1
+        ORDER BY version DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	rows, err := r.querier(ctx).Query(ctx, query, personID, limit, offset)
+	if err != nil {
+		logger.Error(ctx, "failed to query person history", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to query person history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*entities.PersonHistory, 0, limit)
+	for rows.Next() {
+		entry, err := scanHistory(rows)
+		if err != nil {
+			logger.Error(ctx, "failed to scan person history row", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan person history row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return nil, 0, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	return entries, total, nil
+}
+
+// GetByVersion получает запись истории, в результате которой персона получила указанную версию.
+func (r *Repository) GetByVersion(ctx context.Context, personID uuid.UUID, version int) (*entities.PersonHistory, error) {
+	logger.Debug(ctx, "getting person history entry",
+		zap.String("id", personID.String()),
+		zap.Int("version", version))
+
+	query := `
+        SELECT ` + historyColumns + `
+        FROM person_history
+        WHERE person_id = $1 AND version = $2
+    `
+
+	entry, err := scanHistory(r.querier(ctx).QueryRow(ctx, query, personID, version))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id %s, version %d", ErrHistoryNotFound, personID, version)
+		}
+		logger.Error(ctx, "failed to get person history entry", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person history entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// scanHistory считывает строку с колонками historyColumns в запись истории.
+func scanHistory(row pgx.Row) (*entities.PersonHistory, error) {
+	var entry entities.PersonHistory
+	var actor sql.NullString
+	var requestID sql.NullString
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.PersonID,
+		&entry.Version,
+		&entry.Operation,
+		&entry.Before,
+		&entry.After,
+		&entry.ChangedFields,
+		&entry.RelatedIDs,
+		&actor,
+		&requestID,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan person history: %w", err)
+	}
+
+	entry.Actor = actor.String
+	entry.RequestID = requestID.String
+
+	return &entry, nil
+}
@@ -0,0 +1,146 @@
+// Package imports содержит реализацию репозитория результатов импорта персон с использованием PostgreSQL.
+package imports
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/consistency"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// ErrImportNotFound возникает, когда результат импорта не найден.
+var ErrImportNotFound = errors.New("person import not found")
+
+// Проверка реализации интерфейса.
+var _ imports.Repository = (*Repository)(nil)
+
+// Repository реализует интерфейс imports.Repository
+// с использованием PostgreSQL в качестве хранилища.
+type Repository struct {
+	db postgres.Provider
+	// tx задан у копии репозитория, привязанной к транзакции методом WithTx.
+	tx pgx.Tx
+}
+
+// NewRepository создает новый экземпляр репозитория результатов импорта персон.
+func NewRepository(db postgres.Provider) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// WithTx возвращает копию репозитория, выполняющую запросы в транзакции tx.
+func (r *Repository) WithTx(tx pgx.Tx) *Repository {
+	return &Repository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// querier возвращает транзакцию, к которой привязан репозиторий, или пул соединений.
+// Без арендатора в контексте запросы через querier завершаются ошибкой tenant.ErrMissing.
+func (r *Repository) querier(ctx context.Context) postgres.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return postgres.ForTenant(ctx, r.db.WritePool())
+}
+
+// CreateImport сохраняет результат импорта.
+func (r *Repository) CreateImport(ctx context.Context, result *entities.PersonImport) error {
+	logger.Debug(ctx, "saving person import",
+		zap.String("id", result.ID.String()),
+		zap.Int("total", result.Total),
+		zap.Int("failed", result.Failed))
+
+	if result.ID == uuid.Nil {
+		result.ID = uuid.New()
+	}
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now().UTC()
+	}
+	rowErrors := result.Errors
+	if rowErrors == nil {
+		rowErrors = []entities.PersonImportError{}
+	}
+
+	query := `
+        INSERT INTO person_imports (
+            id, format, file_name, dry_run, enrich, total, valid, imported, failed, errors, actor, created_at
+        ) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12)
+    `
+
+	_, err := r.querier(ctx).Exec(ctx, query,
+		result.ID,
+		result.Format,
+		result.FileName,
+		result.DryRun,
+		result.Enrich,
+		result.Total,
+		result.Valid,
+		result.Imported,
+		result.Failed,
+		rowErrors,
+		result.Actor,
+		result.CreatedAt,
+	)
+	if err != nil {
+		logger.Error(ctx, "failed to save person import", zap.Error(err))
+		return fmt.Errorf("failed to save person import: %w", err)
+	}
+	consistency.MarkWritten(ctx)
+
+	return nil
+}
+
+// GetImport получает результат импорта по идентификатору вместе с построчными ошибками.
+func (r *Repository) GetImport(ctx context.Context, id uuid.UUID) (*entities.PersonImport, error) {
+	logger.Debug(ctx, "getting person import", zap.String("id", id.String()))
+
+	query := `
+        SELECT id, format, file_name, dry_run, enrich, total, valid, imported, failed, errors, actor, created_at
+        FROM person_imports
+        WHERE id = $1
+    `
+
+	var result entities.PersonImport
+	var fileName sql.NullString
+	var actor sql.NullString
+
+	err := r.querier(ctx).QueryRow(ctx, query, id).Scan(
+		&result.ID,
+		&result.Format,
+		&fileName,
+		&result.DryRun,
+		&result.Enrich,
+		&result.Total,
+		&result.Valid,
+		&result.Imported,
+		&result.Failed,
+		&result.Errors,
+		&actor,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id %s", ErrImportNotFound, id)
+		}
+		logger.Error(ctx, "failed to get person import", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person import: %w", err)
+	}
+
+	result.FileName = fileName.String
+	result.Actor = actor.String
+
+	return &result, nil
+}
@@ -2,10 +2,15 @@
 package people
 
 import (
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/postgres/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/postgres/repo/people/imports"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/postgres/repo/people/person"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	importsrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
 	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
 )
 
 // Проверка реализации интерфейса.
@@ -13,13 +18,26 @@ var _ people.Repositories = (*Repositories)(nil)
 
 // Repositories реализует интерфейс people.Repositories для PostgreSQL.
 type Repositories struct {
-	personRepo personrepo.Repository
+	personRepo  *person.Repository
+	historyRepo *history.Repository
+	importsRepo *imports.Repository
 }
 
 // NewRepositories создает новый экземпляр репозиториев для работы с данными о людях.
 func NewRepositories(db postgres.Provider) *Repositories {
 	return &Repositories{
-		personRepo: person.NewRepository(db),
+		personRepo:  person.NewRepository(db),
+		historyRepo: history.NewRepository(db),
+		importsRepo: imports.NewRepository(db),
+	}
+}
+
+// WithTx возвращает копию репозиториев, выполняющих запросы в транзакции tx.
+func (r *Repositories) WithTx(tx pgx.Tx) *Repositories {
+	return &Repositories{
+		personRepo:  r.personRepo.WithTx(tx),
+		historyRepo: r.historyRepo.WithTx(tx),
+		importsRepo: r.importsRepo.WithTx(tx),
 	}
 }
 
@@ -27,3 +45,13 @@ func NewRepositories(db postgres.Provider) *Repositories {
 func (r *Repositories) Person() personrepo.Repository {
 	return r.personRepo
 }
+
+// History возвращает репозиторий истории изменений персон.
+func (r *Repositories) History() historyrepo.Repository {
+	return r.historyRepo
+}
+
+// Imports возвращает репозиторий результатов импорта персон.
+func (r *Repositories) Imports() importsrepo.Repository {
+	return r.importsRepo
+}
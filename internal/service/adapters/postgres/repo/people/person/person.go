@@ -6,13 +6,18 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/consistency"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/phonetic"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -23,15 +28,51 @@ import (
 var (
 	ErrPersonNotFound      = errors.New("person not found")
 	ErrPersonAlreadyExists = errors.New("person already exists")
+	ErrUnknownColumn       = errors.New("unknown person column")
 )
 
-// Проверка реализации интерфейса.
-var _ person.Repository = (*Repository)(nil)
+// personColumns перечисляет колонки таблицы persons в порядке сканирования scanPerson.
+const personColumns = `id, name, surname, patronymic, age, gender, gender_probability,
+               nationality, nationality_probability, created_at, updated_at, version, deleted_at`
+
+// patchableColumns содержит колонки, которые допускается изменять частичным обновлением.
+var patchableColumns = map[string]bool{
+	"name":                    true,
+	"surname":                 true,
+	"patronymic":              true,
+	"age":                     true,
+	"gender":                  true,
+	"gender_probability":      true,
+	"nationality":             true,
+	"nationality_probability": true,
+}
+
+// streamBatchSize определяет число строк, читаемых из курсора StreamPersons за один FETCH.
+const streamBatchSize = 1000
+
+// rangeConditions сопоставляет фильтры диапазонов с условиями; %d заменяется номером параметра.
+var rangeConditions = map[string]string{
+	person.FilterAgeMin:                    "age >= $%d",
+	person.FilterAgeMax:                    "age <= $%d",
+	person.FilterGenderProbabilityMin:      "gender_probability >= $%d",
+	person.FilterNationalityProbabilityMin: "nationality_probability >= $%d",
+	person.FilterCreatedAfter:              "created_at >= $%d",
+	person.FilterCreatedBefore:             "created_at < $%d",
+	person.FilterUpdatedAfter:              "updated_at >= $%d",
+}
+
+// Проверка реализации интерфейсов.
+var (
+	_ person.Repository      = (*Repository)(nil)
+	_ person.PhoneticIndexer = (*Repository)(nil)
+)
 
 // Repository реализует интерфейс person.PersonRepository
 // с использованием PostgreSQL в качестве хранилища.
 type Repository struct {
 	db postgres.Provider
+	// tx задан у копии репозитория, привязанной к транзакции методом WithTx.
+	tx pgx.Tx
 }
 
 // NewRepository создает новый экземпляр репозитория персон.
@@ -41,40 +82,45 @@ func NewRepository(db postgres.Provider) *Repository {
 	}
 }
 
+// WithTx возвращает копию репозитория, выполняющую запросы в транзакции tx.
+func (r *Repository) WithTx(tx pgx.Tx) *Repository {
+	return &Repository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// querier возвращает транзакцию, к которой привязан репозиторий, или пул соединений.
+// Без арендатора в контексте запросы через querier завершаются ошибкой tenant.ErrMissing.
+func (r *Repository) querier(ctx context.Context) postgres.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return postgres.ForTenant(ctx, r.db.WritePool())
+}
+
+// readQuerier возвращает соединения для чтений, допускающих отставание реплик: транзакцию WithTx,
+// основной сервер, если запрос уже изменял данные (read-your-writes), или реплику для чтения.
+func (r *Repository) readQuerier(ctx context.Context) postgres.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	if consistency.Written(ctx) {
+		return postgres.ForTenant(ctx, r.db.WritePool())
+	}
+	return postgres.ForTenant(ctx, r.db.ReadPool())
+}
+
 // GetByID получает персону по идентификатору.
 func (r *Repository) GetByID(ctx context.Context, personID uuid.UUID) (*entities.Person, error) {
 	logger.Debug(ctx, "getting person by ID", zap.String("id", personID.String()))
 
 	query := `
-        SELECT id, name, surname, patronymic, age, gender, gender_probability, 
-               nationality, nationality_probability, created_at, updated_at
+        SELECT ` + personColumns + `
         FROM persons
-        WHERE id = $1
-    `
-
-	var person entities.Person
-	var patronymic sql.NullString
-	var age sql.NullInt32
-	var gender sql.NullString
-	var genderProb sql.NullFloat64
-	var nationality sql.NullString
-	var nationalityProb sql.NullFloat64
-
-	row := r.db.Pool().QueryRow(ctx, query, personID)
-	err := row.Scan(
-		&person.ID,
-		&person.Name,
-		&person.Surname,
-		&patronymic,
-		&age,
-		&gender,
-		&genderProb,
-		&nationality,
-		&nationalityProb,
-		&person.CreatedAt,
-		&person.UpdatedAt,
-	)
+        WHERE id = $1` + notDeletedCondition(ctx)
 
+	person, err := scanPerson(r.readQuerier(ctx).QueryRow(ctx, query, personID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			logger.Debug(ctx, "person not found", zap.String("id", personID.String()))
@@ -84,150 +130,480 @@ func (r *Repository) GetByID(ctx context.Context, personID uuid.UUID) (*entities
 		return nil, fmt.Errorf("failed to get person: %w", err)
 	}
 
-	// Конвертируем nullable поля
-	if patronymic.Valid {
-		person.Patronymic = &patronymic.String
+	return person, nil
+}
+
+// GetPersons получает список персон с фильтрацией и пагинацией.
+func (r *Repository) GetPersons(ctx context.Context, filter map[string]any, offset, limit int) ([]*entities.Person, int, error) {
+	logger.Debug(ctx, "getting persons with filter",
+		zap.Any("filter", filter),
+		zap.Int("offset", offset),
+		zap.Int("limit", limit))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return nil, 0, err
 	}
-	if age.Valid {
-		ageVal := int(age.Int32)
-		person.Age = &ageVal
+
+	// Количество и страница читаются с одного сервера, чтобы не смешивать состояния разных реплик.
+	querier := r.readQuerier(ctx)
+
+	total := -1
+	if !selection.skipCount {
+		// Запрос общего количества записей.
+		countQuery := `SELECT COUNT(*) FROM persons WHERE ` + selection.where
+		err := querier.QueryRow(ctx, countQuery, selection.args...).Scan(&total)
+		if err != nil {
+			logger.Error(ctx, "failed to count persons", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to count persons: %w", err)
+		}
+
+		if total == 0 {
+			return []*entities.Person{}, 0, nil
+		}
 	}
-	if gender.Valid {
-		person.Gender = &gender.String
+
+	dataQuery, args, err := selection.orderedQuery()
+	if err != nil {
+		return nil, 0, err
 	}
-	if genderProb.Valid {
-		person.GenderProbability = &genderProb.Float64
+	if selection.cursor != nil {
+		offset = 0
 	}
-	if nationality.Valid {
-		person.Nationality = &nationality.String
+	dataQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := querier.Query(ctx, dataQuery, args...)
+	if err != nil {
+		logger.Error(ctx, "failed to query persons", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to query persons: %w", err)
 	}
-	if nationalityProb.Valid {
-		person.NationalityProbability = &nationalityProb.Float64
+	defer rows.Close()
+
+	persons := make([]*entities.Person, 0, limit)
+
+	for rows.Next() {
+		person, err := scanPerson(rows)
+		if err != nil {
+			logger.Error(ctx, "failed to scan person row", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan person row: %w", err)
+		}
+		persons = append(persons, person)
 	}
 
-	return &person, nil
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return nil, 0, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	return persons, total, nil
 }
 
-// GetPersons получает список персон с фильтрацией и пагинацией.
-func (r *Repository) GetPersons(ctx context.Context, filter map[string]any, offset, limit int) ([]*entities.Person, int, error) {
-	logger.Debug(ctx, "getting persons with filter",
-		zap.Any("filter", filter),
-		zap.Int("offset", offset),
-		zap.Int("limit", limit))
+// StreamPersons читает персоны, отобранные фильтром GetPersons, через серверный курсор PostgreSQL
+// порциями по streamBatchSize строк и передает их в fn по одной.
+// Выборка читается в одной транзакции REPEATABLE READ, поэтому видит согласованный снимок данных.
+func (r *Repository) StreamPersons(ctx context.Context, filter map[string]any, fn func(*entities.Person) error) error {
+	logger.Debug(ctx, "streaming persons with filter", zap.Any("filter", filter))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return err
+	}
+	query, args, err := selection.orderedQuery()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.beginSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Курсор закрывается вместе с транзакцией; изменений в ней нет, поэтому она всегда откатывается.
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn(ctx, "failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, `DECLARE persons_stream NO SCROLL CURSOR FOR `+query, args...); err != nil {
+		logger.Error(ctx, "failed to declare persons cursor", zap.Error(err))
+		return fmt.Errorf("failed to declare persons cursor: %w", err)
+	}
+
+	fetchQuery := fmt.Sprintf(`FETCH FORWARD %d FROM persons_stream`, streamBatchSize)
+	for {
+		fetched, err := fetchPersons(ctx, tx, fetchQuery, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < streamBatchSize {
+			return nil
+		}
+	}
+}
+
+// fetchPersons выполняет FETCH из курсора и передает прочитанные персоны в fn.
+// Возвращает: количество прочитанных строк, ошибка.
+func fetchPersons(ctx context.Context, tx pgx.Tx, fetchQuery string, fn func(*entities.Person) error) (int, error) {
+	rows, err := tx.Query(ctx, fetchQuery)
+	if err != nil {
+		logger.Error(ctx, "failed to fetch persons", zap.Error(err))
+		return 0, fmt.Errorf("failed to fetch persons: %w", err)
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		person, err := scanPerson(rows)
+		if err != nil {
+			logger.Error(ctx, "failed to scan person row", zap.Error(err))
+			return 0, fmt.Errorf("failed to scan person row: %w", err)
+		}
+		fetched++
+		if err := fn(person); err != nil {
+			return 0, err
+		}
+	}
 
-	baseQuery := `FROM persons WHERE 1=1`
-	countQuery := `SELECT COUNT(*) ` + baseQuery
-	dataQuery := `
-        SELECT id, name, surname, patronymic, age, gender, gender_probability, 
-               nationality, nationality_probability, created_at, updated_at
-    ` + baseQuery
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return 0, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+	return fetched, nil
+}
 
-	var args []interface{}
+// selection описывает выборку персон, разобранную из фильтра GetPersons.
+type selection struct {
+	// where содержит условие отбора с параметрами args.
+	where      string
+	args       []any
+	cursor     *person.Cursor
+	sortFields []person.SortField
+	skipCount  bool
+}
+
+// parseFilter разбирает фильтр GetPersons в условия отбора, сортировку и курсор.
+func parseFilter(ctx context.Context, filter map[string]any) (*selection, error) {
+	var args []any
 	argNum := 1
-	var conditions []string
+	conditions := []string{"1=1"}
+	result := &selection{sortFields: person.DefaultSort}
+	usePhonetic, _ := filter[person.FilterPhonetic].(bool)
+
+	if deleted := notDeletedCondition(ctx); deleted != "" {
+		conditions = append(conditions, strings.TrimPrefix(deleted, " AND "))
+	}
 
 	for field, value := range filter {
 		switch field {
-		case "name", "surname", "patronymic", "gender", "nationality":
-			conditions = append(conditions, fmt.Sprintf("%s ILIKE $%d", field, argNum))
-			args = append(args, fmt.Sprintf("%%%v%%", value))
 		case "age":
 			conditions = append(conditions, fmt.Sprintf("%s = $%d", field, argNum))
 			args = append(args, value)
-		default:
-			logger.Warn(ctx, "ignoring unknown filter field", zap.String("field", field))
+		case person.FilterAgeMin, person.FilterAgeMax,
+			person.FilterGenderProbabilityMin, person.FilterNationalityProbabilityMin,
+			person.FilterCreatedAfter, person.FilterCreatedBefore, person.FilterUpdatedAfter:
+			condition := rangeConditions[field]
+			conditions = append(conditions, fmt.Sprintf(condition, argNum))
+			args = append(args, value)
+		case person.FilterMissing, person.FilterPresent:
+			columns, ok := value.([]string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a list of columns", person.ErrInvalidFilter, field)
+			}
+			check := " IS NULL"
+			if field == person.FilterPresent {
+				check = " IS NOT NULL"
+			}
+			for _, column := range columns {
+				if !person.NullableColumns[column] {
+					return nil, fmt.Errorf("%w: %s: unknown column %q", person.ErrInvalidFilter, field, column)
+				}
+				conditions = append(conditions, column+check)
+			}
+			continue
+		case person.FilterIDs:
+			ids, ok := value.([]uuid.UUID)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a list of ids", person.ErrInvalidFilter, field)
+			}
+			conditions = append(conditions, fmt.Sprintf("id = ANY($%d)", argNum))
+			args = append(args, ids)
+		case person.FilterCursor:
+			position, ok := value.(person.Cursor)
+			if !ok {
+				return nil, fmt.Errorf("%w: unexpected type %T", person.ErrInvalidCursor, value)
+			}
+			result.cursor = &position
+			continue
+		case person.FilterSort:
+			fields, ok := value.([]person.SortField)
+			if !ok || len(fields) == 0 {
+				return nil, fmt.Errorf("%w: unexpected value %v", person.ErrInvalidSort, value)
+			}
+			for _, field := range fields {
+				if !person.SortableColumns[field.Column] {
+					return nil, fmt.Errorf("%w: unknown column %q", person.ErrInvalidSort, field.Column)
+				}
+			}
+			result.sortFields = fields
 			continue
+		case person.FilterSkipCount:
+			result.skipCount, _ = value.(bool)
+			continue
+		case person.FilterPhonetic:
+			continue
+		default:
+			textFilter, ok := person.ParseTextFilterKey(field)
+			if !ok {
+				logger.Warn(ctx, "ignoring unknown filter field", zap.String("field", field))
+				continue
+			}
+			values, err := filterValues(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", person.ErrInvalidFilter, field, err)
+			}
+			if usePhonetic && field == textFilter.Column && (field == "name" || field == "surname") {
+				var keys []string
+				for _, value := range values {
+					keys = append(keys, phonetic.Keys(value)...)
+				}
+				conditions = append(conditions, fmt.Sprintf("%s_phonetic && $%d", field, argNum))
+				args = append(args, keys)
+			} else {
+				condition, patterns := textCondition(textFilter, values, argNum)
+				conditions = append(conditions, condition)
+				args = append(args, patterns)
+			}
 		}
 		argNum++
 	}
 
-	if len(conditions) > 0 {
-		filterCondition := " AND " + strings.Join(conditions, " AND ")
-		countQuery += filterCondition
-		dataQuery += filterCondition
+	result.where = strings.Join(conditions, " AND ")
+	result.args = args
+	return result, nil
+}
+
+// orderedQuery возвращает запрос выборки с условием курсора и ORDER BY, но без LIMIT и OFFSET.
+func (s *selection) orderedQuery() (string, []any, error) {
+	query := `SELECT ` + personColumns + ` FROM persons WHERE ` + s.where
+	args := slices.Clone(s.args)
+
+	// Курсор задает позицию по тому же ключу, что и сортировка, поэтому вставка новых
+	// записей во время постраничного обхода не приводит к пропускам и повторам.
+	if s.cursor != nil {
+		if len(s.cursor.Values) != len(s.sortFields) {
+			return "", nil, fmt.Errorf("%w: cursor does not match sort", person.ErrInvalidCursor)
+		}
+		condition, cursorArgs := keysetCondition(s.sortFields, *s.cursor, len(args)+1)
+		query += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	return query + " ORDER BY " + orderByClause(s.sortFields), args, nil
+}
+
+// likeEscaper экранирует специальные символы шаблонов LIKE в значениях фильтров.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// textCondition строит условие текстового фильтра без учета регистра для номера параметра argNum.
+// Несколько значений сравниваются через ILIKE ANY; исключение также пропускает пустые значения.
+// Возвращает: условие и шаблоны для параметра.
+func textCondition(filter person.TextFilter, values []string, argNum int) (string, []string) {
+	patterns := make([]string, 0, len(values))
+	for _, value := range values {
+		pattern := likeEscaper.Replace(value)
+		switch filter.Mode {
+		case person.MatchPrefix:
+			pattern += "%"
+		case person.MatchContains:
+			pattern = "%" + pattern + "%"
+		case person.MatchExact:
+		}
+		patterns = append(patterns, pattern)
 	}
 
-	// Запрос общего количества записей.
-	var total int
-	err := r.db.Pool().QueryRow(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		logger.Error(ctx, "failed to count persons", zap.Error(err))
-		return nil, 0, fmt.Errorf("failed to count persons: %w", err)
+	if filter.Negate {
+		return fmt.Sprintf("(%s IS NULL OR NOT (%s ILIKE ANY($%d)))", filter.Column, filter.Column, argNum), patterns
 	}
+	return fmt.Sprintf("%s ILIKE ANY($%d)", filter.Column, argNum), patterns
+}
 
-	if total == 0 {
-		return []*entities.Person{}, 0, nil
+// filterValues приводит значение текстового фильтра (string или []string) к непустому списку.
+func filterValues(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		if len(v) == 0 {
+			return nil, errors.New("empty list of values")
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unexpected type %T", value)
 	}
+}
 
-	dataQuery += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argNum, argNum+1)
-	args = append(args, limit, offset)
+// SearchPersons выполняет нечеткий поиск персон по триграммному сходству (pg_trgm)
+// с именем, фамилией и отчеством. Оценка равна наибольшему сходству среди этих колонок.
+// В фонетическом режиме оценка равна доле ключей запроса, совпавших с ключами имени и фамилии.
+func (r *Repository) SearchPersons(ctx context.Context, query string, limit int, usePhonetic bool) ([]*entities.PersonSearchResult, error) {
+	logger.Debug(ctx, "searching persons",
+		zap.String("query", query),
+		zap.Int("limit", limit),
+		zap.Bool("phonetic", usePhonetic))
+
+	// Оператор % использует GIN-индексы и порог pg_trgm.similarity_threshold (по умолчанию 0.3).
+	sqlQuery := `
+        SELECT ` + personColumns + `,
+               GREATEST(similarity(name, $1), similarity(surname, $1), COALESCE(similarity(patronymic, $1), 0)) AS score
+        FROM persons
+        WHERE (name % $1 OR surname % $1 OR patronymic % $1)` + notDeletedCondition(ctx) + `
+        ORDER BY score DESC, id
+        LIMIT $2
+    `
+	args := []any{query, limit}
+
+	if usePhonetic {
+		keys := phonetic.Keys(query)
+		if len(keys) == 0 {
+			return []*entities.PersonSearchResult{}, nil
+		}
+		// Оператор && использует GIN-индексы по массивам ключей.
+		sqlQuery = `
+        SELECT ` + personColumns + `,
+               (SELECT COUNT(DISTINCT k) FROM unnest(name_phonetic || surname_phonetic) AS k WHERE k = ANY($1))::float8 / $3 AS score
+        FROM persons
+        WHERE (name_phonetic && $1 OR surname_phonetic && $1)` + notDeletedCondition(ctx) + `
+        ORDER BY score DESC, id
+        LIMIT $2
+    `
+		args = []any{keys, limit, len(keys)}
+	}
 
-	rows, err := r.db.Pool().Query(ctx, dataQuery, args...)
+	rows, err := r.readQuerier(ctx).Query(ctx, sqlQuery, args...)
 	if err != nil {
-		logger.Error(ctx, "failed to query persons", zap.Error(err))
-		return nil, 0, fmt.Errorf("failed to query persons: %w", err)
+		logger.Error(ctx, "failed to search persons", zap.Error(err))
+		return nil, fmt.Errorf("failed to search persons: %w", err)
 	}
 	defer rows.Close()
 
-	var persons []*entities.Person
-
+	results := make([]*entities.PersonSearchResult, 0, limit)
 	for rows.Next() {
-		var person entities.Person
-		var patronymic sql.NullString
-		var age sql.NullInt32
-		var gender sql.NullString
-		var genderProb sql.NullFloat64
-		var nationality sql.NullString
-		var nationalityProb sql.NullFloat64
-
-		err := rows.Scan(
-			&person.ID,
-			&person.Name,
-			&person.Surname,
-			&patronymic,
-			&age,
-			&gender,
-			&genderProb,
-			&nationality,
-			&nationalityProb,
-			&person.CreatedAt,
-			&person.UpdatedAt,
-		)
+		var score float64
+		found, err := scanPerson(rows, &score)
 		if err != nil {
-			logger.Error(ctx, "failed to scan person row", zap.Error(err))
-			return nil, 0, fmt.Errorf("failed to scan person row: %w", err)
+			logger.Error(ctx, "failed to scan search result", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
 		}
+		results = append(results, &entities.PersonSearchResult{Person: *found, Score: score})
+	}
 
-		// Конвертируем nullable поля
-		if patronymic.Valid {
-			person.Patronymic = &patronymic.String
-		}
-		if age.Valid {
-			ageVal := int(age.Int32)
-			person.Age = &ageVal
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return nil, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	return results, nil
+}
+
+// UpsertByExternalID создает или обновляет персону, связанную с внешним идентификатором.
+// Связь вставляется первой через INSERT ... ON CONFLICT: при конфликте строка связи блокируется
+// и возвращает уже связанную персону, поэтому параллельные вызовы с одним ключом не создают дубликатов.
+// Внешний ключ связи проверяется при фиксации, что позволяет вставить персону после связи.
+func (r *Repository) UpsertByExternalID(ctx context.Context, source, externalID string, input *entities.Person) (bool, error) {
+	logger.Debug(ctx, "upserting person by external id",
+		zap.String("source", source),
+		zap.String("external_id", externalID))
+
+	linkQuery := `
+        INSERT INTO person_external_ids (source, external_id, person_id)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (tenant_id, source, external_id) DO UPDATE SET source = EXCLUDED.source
+        RETURNING person_id`
+
+	newID := uuid.New()
+	created := false
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		var personID uuid.UUID
+		if err := tx.QueryRow(ctx, linkQuery, source, externalID, newID).Scan(&personID); err != nil {
+			return fmt.Errorf("failed to link external id: %w", err)
 		}
-		if gender.Valid {
-			person.Gender = &gender.String
+
+		if personID == newID {
+			if input.Version != 0 {
+				return fmt.Errorf("%w: external id %s/%s is not linked, expected version %d",
+					person.ErrVersionConflict, source, externalID, input.Version)
+			}
+			now := time.Now().UTC()
+			candidate := *input
+			candidate.ID = newID
+			candidate.CreatedAt = now
+			candidate.UpdatedAt = now
+			candidate.Version = 1
+			candidate.DeletedAt = nil
+
+			inserted, err := insertPerson(ctx, tx, &candidate)
+			if err != nil {
+				return err
+			}
+			if err := insertHistory(ctx, tx, historyrepo.Operation(ctx, historyrepo.OperationCreate), nil, inserted); err != nil {
+				return err
+			}
+			*input = *inserted
+			created = true
+			return nil
 		}
-		if genderProb.Valid {
-			person.GenderProbability = &genderProb.Float64
+
+		before, err := lockPerson(ctx, tx, personID, input.Version)
+		if err != nil {
+			return err
 		}
-		if nationality.Valid {
-			person.Nationality = &nationality.String
+		candidate := *input
+		candidate.ID = personID
+		updated, err := writePerson(ctx, tx, &candidate)
+		if err != nil {
+			return err
 		}
-		if nationalityProb.Valid {
-			person.NationalityProbability = &nationalityProb.Float64
+		if err := insertHistory(ctx, tx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, updated); err != nil {
+			return err
 		}
+		*input = *updated
+		return nil
+	})
 
-		persons = append(persons, &person)
+	if err != nil {
+		if isExpectedError(err) {
+			return false, err
+		}
+		logger.Error(ctx, "failed to upsert person by external id", zap.Error(err))
+		return false, fmt.Errorf("failed to upsert person by external id: %w", err)
 	}
 
-	if rows.Err() != nil {
-		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
-		return nil, 0, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	return created, nil
+}
+
+// GetByExternalID получает неудаленную персону по внешнему идентификатору.
+func (r *Repository) GetByExternalID(ctx context.Context, source, externalID string) (*entities.Person, error) {
+	logger.Debug(ctx, "getting person by external id",
+		zap.String("source", source),
+		zap.String("external_id", externalID))
+
+	query := `
+        SELECT ` + prefixedPersonColumns("p") + `
+        FROM person_external_ids e
+        JOIN persons p ON p.id = e.person_id
+        WHERE e.source = $1 AND e.external_id = $2 AND p.deleted_at IS NULL`
+
+	person, err := scanPerson(r.querier(ctx).QueryRow(ctx, query, source, externalID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: external id %s/%s", ErrPersonNotFound, source, externalID)
+		}
+		logger.Error(ctx, "failed to get person by external id", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person: %w", err)
 	}
 
-	return persons, total, nil
+	return person, nil
 }
 
 // CreatePerson создает новую персону.
@@ -241,27 +617,19 @@ func (r *Repository) CreatePerson(ctx context.Context, person *entities.Person)
 	now := time.Now().UTC()
 	person.CreatedAt = now
 	person.UpdatedAt = now
+	person.Version = 1
 
-	query := `
-        INSERT INTO persons (
-            id, name, surname, patronymic, age, gender, gender_probability, 
-            nationality, nationality_probability, created_at, updated_at
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-    `
-
-	_, err := r.db.Pool().Exec(ctx, query,
-		person.ID,
-		person.Name,
-		person.Surname,
-		person.Patronymic,
-		person.Age,
-		person.Gender,
-		person.GenderProbability,
-		person.Nationality,
-		person.NationalityProbability,
-		person.CreatedAt,
-		person.UpdatedAt,
-	)
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		created, err := insertPerson(ctx, tx, person)
+		if err != nil {
+			return err
+		}
+		if err := insertHistory(ctx, tx, historyrepo.OperationCreate, nil, created); err != nil {
+			return err
+		}
+		*person = *created
+		return nil
+	})
 
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -278,74 +646,531 @@ func (r *Repository) CreatePerson(ctx context.Context, person *entities.Person)
 	return nil
 }
 
-// UpdatePerson обновляет существующую персону.
-func (r *Repository) UpdatePerson(ctx context.Context, person *entities.Person) error {
-	logger.Debug(ctx, "updating person", zap.String("id", person.ID.String()))
-
-	person.UpdatedAt = time.Now().UTC()
-
-	query := `
-        UPDATE persons
-        SET name = $2, surname = $3, patronymic = $4, age = $5, 
-            gender = $6, gender_probability = $7, nationality = $8, 
-            nationality_probability = $9, updated_at = $10
-        WHERE id = $1
-    `
-
-	result, err := r.db.Pool().Exec(ctx, query,
-		person.ID,
-		person.Name,
-		person.Surname,
-		person.Patronymic,
-		person.Age,
-		person.Gender,
-		person.GenderProbability,
-		person.Nationality,
-		person.NationalityProbability,
-		person.UpdatedAt,
-	)
+// CreatePersons создает персоны через COPY в одной транзакции вместе с записями истории.
+func (r *Repository) CreatePersons(ctx context.Context, persons []*entities.Person) error {
+	logger.Debug(ctx, "creating persons in bulk", zap.Int("count", len(persons)))
 
-	if err != nil {
-		logger.Error(ctx, "failed to update person", zap.Error(err))
-		return fmt.Errorf("failed to update person: %w", err)
+	if len(persons) == 0 {
+		return nil
 	}
 
-	if result.RowsAffected() == 0 {
-		logger.Error(ctx, "person not found for update", zap.String("id", person.ID.String()))
-		return fmt.Errorf("%w: id %s", ErrPersonNotFound, person.ID)
+	now := time.Now().UTC()
+	for _, person := range persons {
+		if person.ID == uuid.Nil {
+			person.ID = uuid.New()
+		}
+		person.CreatedAt = now
+		person.UpdatedAt = now
+		person.Version = 1
+		person.DeletedAt = nil
 	}
 
-	return nil
-}
+	requestID, _ := logger.RequestID(ctx)
+	actor := nullIfEmpty(historyrepo.Actor(ctx))
 
-// DeletePerson удаляет персону по идентификатору.
-func (r *Repository) DeletePerson(ctx context.Context, personID uuid.UUID) error {
-	logger.Debug(ctx, "deleting person", zap.String("id", personID.String()))
+	operation := historyrepo.Operation(ctx, historyrepo.OperationCreate)
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		err := copyThroughStaging(ctx, tx, "persons", []string{
+			"id", "name", "surname", "patronymic", "age", "gender", "gender_probability",
+			"nationality", "nationality_probability", "created_at", "updated_at", "version",
+			"name_phonetic", "surname_phonetic",
+		}, pgx.CopyFromSlice(len(persons), func(i int) ([]any, error) {
+			person := persons[i]
+			return []any{
+				person.ID,
+				person.Name,
+				person.Surname,
+				person.Patronymic,
+				person.Age,
+				person.Gender,
+				person.GenderProbability,
+				person.Nationality,
+				person.NationalityProbability,
+				person.CreatedAt,
+				person.UpdatedAt,
+				person.Version,
+				phonetic.Keys(person.Name),
+				phonetic.Keys(person.Surname),
+			}, nil
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to copy persons: %w", err)
+		}
 
-	query := `DELETE FROM persons WHERE id = $1`
+		err = copyThroughStaging(ctx, tx, "person_history", []string{
+			"person_id", "version", "operation", "after", "changed_fields", "actor", "request_id",
+		}, pgx.CopyFromSlice(len(persons), func(i int) ([]any, error) {
+			person := persons[i]
+			return []any{
+				person.ID,
+				person.Version,
+				operation,
+				person,
+				changedFields(nil, person),
+				actor,
+				nullIfEmpty(requestID),
+			}, nil
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to copy person history: %w", err)
+		}
+		return nil
+	})
 
-	result, err := r.db.Pool().Exec(ctx, query, personID)
 	if err != nil {
-		logger.Error(ctx, "failed to delete person", zap.Error(err))
-		return fmt.Errorf("failed to delete person: %w", err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Unique violation
+			logger.Error(ctx, "person with this ID already exists", zap.Error(err))
+			return fmt.Errorf("%w: %s", ErrPersonAlreadyExists, pgErr.Detail)
+		}
+		logger.Error(ctx, "failed to create persons", zap.Error(err))
+		return fmt.Errorf("failed to create persons: %w", err)
+	}
+
+	return nil
+}
+
+// copyThroughStaging загружает строки в таблицу table по протоколу COPY. COPY FROM недоступен
+// для таблиц с политиками защиты строк, поэтому строки копируются во временную таблицу без политик
+// и переносятся из нее запросом INSERT, для которого политики проверяются, а арендатор берется из сессии.
+func copyThroughStaging(ctx context.Context, tx pgx.Tx, table string, columns []string, rows pgx.CopyFromSource) error {
+	staging := table + "_staging"
+	columnList := strings.Join(columns, ", ")
+
+	// Временная таблица содержит только загружаемые колонки, без ограничений и значений по умолчанию.
+	createQuery := fmt.Sprintf(`CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA`,
+		staging, columnList, table)
+	if _, err := tx.Exec(ctx, createQuery); err != nil {
+		return fmt.Errorf("failed to create staging table: %w", err)
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{staging}, columns, rows); err != nil {
+		return fmt.Errorf("failed to copy rows to staging table: %w", err)
+	}
+
+	insertQuery := fmt.Sprintf(`INSERT INTO %s (%s, tenant_id) SELECT %s, current_setting('%s') FROM %s`,
+		table, columnList, columnList, postgres.TenantSetting, staging)
+	if _, err := tx.Exec(ctx, insertQuery); err != nil {
+		return err
+	}
+	// Таблица удаляется сразу, чтобы повторная загрузка в той же транзакции могла создать ее заново.
+	if _, err := tx.Exec(ctx, "DROP TABLE "+staging); err != nil {
+		return fmt.Errorf("failed to drop staging table: %w", err)
 	}
+	return nil
+}
+
+// UpdatePerson обновляет существующую персону.
+func (r *Repository) UpdatePerson(ctx context.Context, person *entities.Person) error {
+	logger.Debug(ctx, "updating person", zap.String("id", person.ID.String()))
+
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := lockPerson(ctx, tx, person.ID, person.Version)
+		if err != nil {
+			return err
+		}
+
+		updated, err := writePerson(ctx, tx, person)
+		if err != nil {
+			return err
+		}
+		if err := insertHistory(ctx, tx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, updated); err != nil {
+			return err
+		}
+		*person = *updated
+		return nil
+	})
 
-	if result.RowsAffected() == 0 {
-		logger.Debug(ctx, "person not found for deletion", zap.String("id", personID.String()))
-		return fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+	if err != nil {
+		if isExpectedError(err) {
+			return err
+		}
+		logger.Error(ctx, "failed to update person", zap.Error(err))
+		return fmt.Errorf("failed to update person: %w", err)
 	}
 
 	return nil
 }
 
+// PatchPerson частично обновляет персону, изменяя только переданные колонки.
+func (r *Repository) PatchPerson(ctx context.Context, personID uuid.UUID, fields map[string]any, version int) (*entities.Person, error) {
+	logger.Debug(ctx, "patching person",
+		zap.String("id", personID.String()),
+		zap.Any("fields", fields),
+		zap.Int("version", version))
+
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		if !patchableColumns[column] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	args := []any{personID}
+	assignments := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		args = append(args, fields[column])
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+		// Фонетические ключи пересчитываются вместе с именем и фамилией.
+		if column == "name" || column == "surname" {
+			value, _ := fields[column].(string)
+			args = append(args, phonetic.Keys(value))
+			assignments = append(assignments, fmt.Sprintf("%s_phonetic = $%d", column, len(args)))
+		}
+	}
+	args = append(args, time.Now().UTC())
+	assignments = append(assignments, fmt.Sprintf("updated_at = $%d", len(args)), "version = version + 1")
+
+	query := `
+        UPDATE persons
+        SET ` + strings.Join(assignments, ", ") + `
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	var patched *entities.Person
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := lockPerson(ctx, tx, personID, version)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			patched = before
+			return nil
+		}
+
+		patched, err = scanPerson(tx.QueryRow(ctx, query, args...))
+		if err != nil {
+			return err
+		}
+		return insertHistory(ctx, tx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, patched)
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return nil, err
+		}
+		logger.Error(ctx, "failed to patch person", zap.Error(err))
+		return nil, fmt.Errorf("failed to patch person: %w", err)
+	}
+
+	return patched, nil
+}
+
+// DeletePerson мягко удаляет персону по идентификатору, устанавливая deleted_at.
+// Запись остается в таблице до восстановления или очистки через PurgeDeleted.
+func (r *Repository) DeletePerson(ctx context.Context, personID uuid.UUID, version int) error {
+	logger.Debug(ctx, "deleting person", zap.String("id", personID.String()), zap.Int("version", version))
+
+	query := `
+        UPDATE persons
+        SET deleted_at = $2, updated_at = $2, version = version + 1
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := lockPerson(ctx, tx, personID, version)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := scanPerson(tx.QueryRow(ctx, query, personID, time.Now().UTC()))
+		if err != nil {
+			return err
+		}
+		return insertHistory(ctx, tx, historyrepo.OperationDelete, before, deleted)
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return err
+		}
+		logger.Error(ctx, "failed to delete person", zap.Error(err))
+		return fmt.Errorf("failed to delete person: %w", err)
+	}
+
+	return nil
+}
+
+// RestorePerson восстанавливает мягко удаленную персону.
+func (r *Repository) RestorePerson(ctx context.Context, personID uuid.UUID) (*entities.Person, error) {
+	logger.Debug(ctx, "restoring person", zap.String("id", personID.String()))
+
+	query := `
+        UPDATE persons
+        SET deleted_at = NULL, merged_into = NULL, updated_at = $2, version = version + 1
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	var restored *entities.Person
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		before, err := scanPerson(tx.QueryRow(ctx, `
+        SELECT `+personColumns+`
+        FROM persons
+        WHERE id = $1
+        FOR UPDATE`, personID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+			}
+			return err
+		}
+		if before.DeletedAt == nil {
+			return fmt.Errorf("%w: id %s", person.ErrPersonNotDeleted, personID)
+		}
+
+		restored, err = scanPerson(tx.QueryRow(ctx, query, personID, time.Now().UTC()))
+		if err != nil {
+			return err
+		}
+		return insertHistory(ctx, tx, historyrepo.OperationRestore, before, restored)
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return nil, err
+		}
+		logger.Error(ctx, "failed to restore person", zap.Error(err))
+		return nil, fmt.Errorf("failed to restore person: %w", err)
+	}
+
+	return restored, nil
+}
+
+// FindDuplicatePairs находит пары похожих неудаленных персон. Кандидаты отбираются по общим
+// фонетическим ключам фамилии или триграммному сходству фамилий, после чего пара оценивается:
+//   - 1 - имя, фамилия и отчество совпадают без учета регистра;
+//   - 0.9 - совпадают фонетические ключи имени и фамилии (транслитерация), а отчество
+//     совпадает или не заполнено, 0.75 - при разных отчествах;
+//   - иначе - триграммное сходство полного имени (опечатки).
+//
+// Берется наибольшая из оценок.
+func (r *Repository) FindDuplicatePairs(ctx context.Context, minScore float64, limit int) ([]entities.PersonDuplicatePair, error) {
+	logger.Debug(ctx, "finding duplicate persons",
+		zap.Float64("min_score", minScore),
+		zap.Int("limit", limit))
+
+	query := `
+        SELECT person_id, duplicate_id, score
+        FROM (
+            SELECT a.id AS person_id, b.id AS duplicate_id,
+                   (CASE
+                        WHEN lower(a.name) = lower(b.name) AND lower(a.surname) = lower(b.surname)
+                             AND lower(COALESCE(a.patronymic, '')) = lower(COALESCE(b.patronymic, '')) THEN 1
+                        ELSE GREATEST(
+                            similarity(concat_ws(' ', a.name, a.surname, a.patronymic),
+                                       concat_ws(' ', b.name, b.surname, b.patronymic)),
+                            CASE
+                                WHEN NOT COALESCE(a.name_phonetic && b.name_phonetic
+                                                  AND a.surname_phonetic && b.surname_phonetic, FALSE) THEN 0
+                                WHEN a.patronymic IS NULL OR b.patronymic IS NULL
+                                     OR lower(a.patronymic) = lower(b.patronymic) THEN 0.9
+                                ELSE 0.75
+                            END)
+                    END)::float8 AS score
+            FROM persons a
+            JOIN persons b ON a.id < b.id
+                AND (a.surname_phonetic && b.surname_phonetic OR a.surname % b.surname)
+            WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL
+        ) AS pairs
+        WHERE score >= $1
+        ORDER BY score DESC, person_id, duplicate_id
+        LIMIT $2`
+
+	rows, err := r.querier(ctx).Query(ctx, query, minScore, limit)
+	if err != nil {
+		logger.Error(ctx, "failed to find duplicate persons", zap.Error(err))
+		return nil, fmt.Errorf("failed to find duplicate persons: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := make([]entities.PersonDuplicatePair, 0)
+	for rows.Next() {
+		var pair entities.PersonDuplicatePair
+		if err := rows.Scan(&pair.PersonID, &pair.DuplicateID, &pair.Score); err != nil {
+			logger.Error(ctx, "failed to scan duplicate pair", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan duplicate pair: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return nil, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	return pairs, nil
+}
+
+// MergePersons объединяет персоны mergedIDs с персоной survivorID в одной транзакции.
+// Строки блокируются в порядке идентификаторов, чтобы параллельные объединения не взаимоблокировались.
+// Ссылки на поглощенные персоны из ранее выполненных объединений и их внешние идентификаторы
+// перенаправляются на survivor.
+func (r *Repository) MergePersons(ctx context.Context, survivorID uuid.UUID, mergedIDs []uuid.UUID, version int, resolve person.MergeResolver) (*entities.Person, error) {
+	logger.Debug(ctx, "merging persons",
+		zap.String("survivor", survivorID.String()),
+		zap.Int("merged", len(mergedIDs)),
+		zap.Int("version", version))
+
+	if len(mergedIDs) == 0 || slices.Contains(mergedIDs, survivorID) {
+		return nil, fmt.Errorf("%w: merged persons must not be empty or contain the survivor", person.ErrInvalidMerge)
+	}
+
+	deleteQuery := `
+        UPDATE persons
+        SET deleted_at = $2, updated_at = $2, merged_into = $3, version = version + 1
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	var survivor *entities.Person
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		locked, err := lockPersons(ctx, tx, append([]uuid.UUID{survivorID}, mergedIDs...))
+		if err != nil {
+			return err
+		}
+		before := locked[survivorID]
+		if version != 0 && before.Version != version {
+			return fmt.Errorf("%w: id %s, expected version %d, actual %d",
+				person.ErrVersionConflict, survivorID, version, before.Version)
+		}
+
+		merged := make([]*entities.Person, 0, len(mergedIDs))
+		for _, id := range mergedIDs {
+			merged = append(merged, locked[id])
+		}
+
+		enriched, err := enrichedFields(ctx, tx, append([]uuid.UUID{survivorID}, mergedIDs...))
+		if err != nil {
+			return err
+		}
+
+		resolved := resolve(before, merged, enriched)
+		resolved.ID = survivorID
+		survivor, err = writePerson(ctx, tx, resolved)
+		if err != nil {
+			return err
+		}
+		if err := insertHistory(ctx, tx, historyrepo.OperationMerge, before, survivor, mergedIDs...); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, previous := range merged {
+			deleted, err := scanPerson(tx.QueryRow(ctx, deleteQuery, previous.ID, now, survivorID))
+			if err != nil {
+				return err
+			}
+			if err := insertHistory(ctx, tx, historyrepo.OperationMerge, previous, deleted, survivorID); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE persons SET merged_into = $1 WHERE merged_into = ANY($2)`, survivorID, mergedIDs); err != nil {
+			return fmt.Errorf("failed to redirect merged persons: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `UPDATE person_external_ids SET person_id = $1 WHERE person_id = ANY($2)`, survivorID, mergedIDs); err != nil {
+			return fmt.Errorf("failed to move external ids: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return nil, err
+		}
+		logger.Error(ctx, "failed to merge persons", zap.Error(err))
+		return nil, fmt.Errorf("failed to merge persons: %w", err)
+	}
+
+	return survivor, nil
+}
+
+// MergedInto возвращает персону, с которой была объединена персона, или uuid.Nil.
+func (r *Repository) MergedInto(ctx context.Context, personID uuid.UUID) (uuid.UUID, error) {
+	logger.Debug(ctx, "getting merge target", zap.String("id", personID.String()))
+
+	var target uuid.NullUUID
+	err := r.querier(ctx).QueryRow(ctx, `SELECT merged_into FROM persons WHERE id = $1`, personID).Scan(&target)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, nil
+		}
+		logger.Error(ctx, "failed to get merge target", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("failed to get merge target: %w", err)
+	}
+
+	return target.UUID, nil
+}
+
+// PurgeDeleted безвозвратно удаляет персоны, мягко удаленные раньше deletedBefore.
+func (r *Repository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	logger.Debug(ctx, "purging deleted persons", zap.Time("deleted_before", deletedBefore))
+
+	query := `DELETE FROM persons WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.querier(ctx).Exec(ctx, query, deletedBefore)
+	if err != nil {
+		logger.Error(ctx, "failed to purge deleted persons", zap.Error(err))
+		return 0, fmt.Errorf("failed to purge deleted persons: %w", err)
+	}
+	consistency.MarkWritten(ctx)
+
+	return result.RowsAffected(), nil
+}
+
+// BackfillPhoneticKeys вычисляет фонетические ключи для записей, созданных до их появления.
+// Версия и история не меняются, так как ключи являются производными данными.
+func (r *Repository) BackfillPhoneticKeys(ctx context.Context, batchSize int) (int, error) {
+	logger.Debug(ctx, "backfilling phonetic keys", zap.Int("batch_size", batchSize))
+
+	query := `
+        SELECT id, name, surname
+        FROM persons
+        WHERE name_phonetic IS NULL OR surname_phonetic IS NULL
+        LIMIT $1`
+
+	rows, err := r.querier(ctx).Query(ctx, query, batchSize)
+	if err != nil {
+		logger.Error(ctx, "failed to query persons without phonetic keys", zap.Error(err))
+		return 0, fmt.Errorf("failed to query persons without phonetic keys: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for rows.Next() {
+		var id uuid.UUID
+		var name, surname string
+		if err := rows.Scan(&id, &name, &surname); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan person row: %w", err)
+		}
+		batch.Queue(`UPDATE persons SET name_phonetic = $2, surname_phonetic = $3 WHERE id = $1`,
+			id, phonetic.Keys(name), phonetic.Keys(surname))
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return 0, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	if batch.Len() == 0 {
+		return 0, nil
+	}
+	if err := r.querier(ctx).SendBatch(ctx, batch).Close(); err != nil {
+		logger.Error(ctx, "failed to update phonetic keys", zap.Error(err))
+		return 0, fmt.Errorf("failed to update phonetic keys: %w", err)
+	}
+	consistency.MarkWritten(ctx)
+
+	return batch.Len(), nil
+}
+
 // ExistsByID проверяет существование персоны по идентификатору.
 func (r *Repository) ExistsByID(ctx context.Context, personID uuid.UUID) (bool, error) {
 	logger.Debug(ctx, "checking if person exists", zap.String("id", personID.String()))
 
-	query := `SELECT EXISTS(SELECT 1 FROM persons WHERE id = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM persons WHERE id = $1` + notDeletedCondition(ctx) + `)`
 
 	var exists bool
-	err := r.db.Pool().QueryRow(ctx, query, personID).Scan(&exists)
+	err := r.readQuerier(ctx).QueryRow(ctx, query, personID).Scan(&exists)
 	if err != nil {
 		logger.Error(ctx, "failed to check if person exists", zap.Error(err))
 		return false, fmt.Errorf("failed to check if person exists: %w", err)
@@ -353,3 +1178,473 @@ func (r *Repository) ExistsByID(ctx context.Context, personID uuid.UUID) (bool,
 
 	return exists, nil
 }
+
+// inTx выполняет fn в транзакции и фиксирует ее, если fn завершилась без ошибки.
+// Вне WithTx транзакция, прерванная конфликтом сериализации, повторяется; внутри WithTx fn выполняется
+// на точке сохранения, и ошибка fn откатывает только ее изменения, а повтор выполняет внешняя транзакция.
+func (r *Repository) inTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	if r.tx == nil {
+		if err := postgres.RequireTenant(ctx); err != nil {
+			return err
+		}
+		return postgres.RunInTx(ctx, r.db.WritePool(), pgx.TxOptions{}, fn)
+	}
+
+	tx, err := r.tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	defer func() {
+		// После успешной фиксации Rollback возвращает pgx.ErrTxClosed, который не является ошибкой.
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn(ctx, "failed to rollback to savepoint", zap.Error(err))
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
+
+// beginSnapshot начинает транзакцию для согласованного чтения: читающую транзакцию со снимком данных
+// на момент начала или точку сохранения внутри транзакции WithTx.
+func (r *Repository) beginSnapshot(ctx context.Context) (pgx.Tx, error) {
+	var tx pgx.Tx
+	var err error
+	if r.tx != nil {
+		tx, err = r.tx.Begin(ctx)
+	} else {
+		if err := postgres.RequireTenant(ctx); err != nil {
+			return nil, err
+		}
+		tx, err = r.db.WritePool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// lockPerson блокирует строку неудаленной персоны до конца транзакции и проверяет ожидаемую версию.
+// Версия 0 отключает проверку.
+func lockPerson(ctx context.Context, tx pgx.Tx, personID uuid.UUID, version int) (*entities.Person, error) {
+	query := `
+        SELECT ` + personColumns + `
+        FROM persons
+        WHERE id = $1 AND deleted_at IS NULL
+        FOR UPDATE`
+
+	current, err := scanPerson(tx.QueryRow(ctx, query, personID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Debug(ctx, "person not found for modification", zap.String("id", personID.String()))
+			return nil, fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+		}
+		return nil, err
+	}
+
+	if version != 0 && current.Version != version {
+		logger.Debug(ctx, "person version conflict",
+			zap.String("id", personID.String()),
+			zap.Int("expected", version),
+			zap.Int("actual", current.Version))
+		return nil, fmt.Errorf("%w: id %s, expected version %d, actual %d",
+			person.ErrVersionConflict, personID, version, current.Version)
+	}
+
+	return current, nil
+}
+
+// insertPerson вставляет персону со всеми колонками, включая время создания и версию.
+// Возвращает: сохраненное состояние персоны, ошибка.
+func insertPerson(ctx context.Context, tx pgx.Tx, person *entities.Person) (*entities.Person, error) {
+	query := `
+        INSERT INTO persons (
+            id, name, surname, patronymic, age, gender, gender_probability, 
+            nationality, nationality_probability, created_at, updated_at, version,
+            name_phonetic, surname_phonetic
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+        RETURNING ` + personColumns
+
+	return scanPerson(tx.QueryRow(ctx, query,
+		person.ID,
+		person.Name,
+		person.Surname,
+		person.Patronymic,
+		person.Age,
+		person.Gender,
+		person.GenderProbability,
+		person.Nationality,
+		person.NationalityProbability,
+		person.CreatedAt,
+		person.UpdatedAt,
+		person.Version,
+		phonetic.Keys(person.Name),
+		phonetic.Keys(person.Surname),
+	))
+}
+
+// writePerson записывает все изменяемые колонки персоны, увеличивая версию.
+// Возвращает: новое состояние персоны, ошибка.
+func writePerson(ctx context.Context, tx pgx.Tx, person *entities.Person) (*entities.Person, error) {
+	query := `
+        UPDATE persons
+        SET name = $2, surname = $3, patronymic = $4, age = $5, 
+            gender = $6, gender_probability = $7, nationality = $8, 
+            nationality_probability = $9, updated_at = $10, version = version + 1,
+            name_phonetic = $11, surname_phonetic = $12
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	return scanPerson(tx.QueryRow(ctx, query,
+		person.ID,
+		person.Name,
+		person.Surname,
+		person.Patronymic,
+		person.Age,
+		person.Gender,
+		person.GenderProbability,
+		person.Nationality,
+		person.NationalityProbability,
+		time.Now().UTC(),
+		phonetic.Keys(person.Name),
+		phonetic.Keys(person.Surname),
+	))
+}
+
+// lockPersons блокирует строки неудаленных персон до конца транзакции в порядке идентификаторов.
+// Возвращает: персоны по идентификаторам, ErrPersonNotFound, если какой-либо персоны нет.
+func lockPersons(ctx context.Context, tx pgx.Tx, ids []uuid.UUID) (map[uuid.UUID]*entities.Person, error) {
+	query := `
+        SELECT ` + personColumns + `
+        FROM persons
+        WHERE id = ANY($1) AND deleted_at IS NULL
+        ORDER BY id
+        FOR UPDATE`
+
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock persons: %w", err)
+	}
+	defer rows.Close()
+
+	locked := make(map[uuid.UUID]*entities.Person, len(ids))
+	for rows.Next() {
+		current, err := scanPerson(rows)
+		if err != nil {
+			return nil, err
+		}
+		locked[current.ID] = current
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	for _, id := range ids {
+		if _, ok := locked[id]; !ok {
+			logger.Debug(ctx, "person not found for modification", zap.String("id", id.String()))
+			return nil, fmt.Errorf("%w: id %s", ErrPersonNotFound, id)
+		}
+	}
+	return locked, nil
+}
+
+// enrichedFields возвращает для каждой персоны колонки, последнее изменение которых
+// по истории было сделано обогащением.
+func enrichedFields(ctx context.Context, tx pgx.Tx, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	query := `
+        SELECT person_id, field
+        FROM (
+            SELECT DISTINCT ON (person_id, field) person_id, field, operation
+            FROM person_history, unnest(changed_fields) AS field
+            WHERE person_id = ANY($1)
+            ORDER BY person_id, field, version DESC
+        ) AS latest
+        WHERE operation = $2`
+
+	rows, err := tx.Query(ctx, query, ids, historyrepo.OperationEnrich)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query enriched fields: %w", err)
+	}
+	defer rows.Close()
+
+	enriched := make(map[uuid.UUID][]string, len(ids))
+	for rows.Next() {
+		var personID uuid.UUID
+		var field string
+		if err := rows.Scan(&personID, &field); err != nil {
+			return nil, fmt.Errorf("failed to scan enriched field: %w", err)
+		}
+		enriched[personID] = append(enriched[personID], field)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+	return enriched, nil
+}
+
+// insertHistory записывает изменение персоны в person_history.
+// Инициатор и идентификатор запроса берутся из контекста; related перечисляет
+// другие персоны, участвовавшие в операции.
+func insertHistory(ctx context.Context, tx pgx.Tx, operation string, before, after *entities.Person, related ...uuid.UUID) error {
+	query := `
+        INSERT INTO person_history (
+            person_id, version, operation, before, after, changed_fields, related_ids, actor, request_id
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `
+
+	requestID, _ := logger.RequestID(ctx)
+
+	_, err := tx.Exec(ctx, query,
+		after.ID,
+		after.Version,
+		operation,
+		before,
+		after,
+		changedFields(before, after),
+		related,
+		nullIfEmpty(historyrepo.Actor(ctx)),
+		nullIfEmpty(requestID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write person history: %w", err)
+	}
+	return nil
+}
+
+// changedFields возвращает колонки, значения которых отличаются в двух состояниях персоны.
+// Для созданной персоны (before == nil) возвращаются все заполненные колонки.
+func changedFields(before, after *entities.Person) []string {
+	if before == nil {
+		before = &entities.Person{}
+	}
+
+	candidates := []struct {
+		column string
+		equal  bool
+	}{
+		{"name", before.Name == after.Name},
+		{"surname", before.Surname == after.Surname},
+		{"patronymic", equalPtr(before.Patronymic, after.Patronymic)},
+		{"age", equalPtr(before.Age, after.Age)},
+		{"gender", equalPtr(before.Gender, after.Gender)},
+		{"gender_probability", equalPtr(before.GenderProbability, after.GenderProbability)},
+		{"nationality", equalPtr(before.Nationality, after.Nationality)},
+		{"nationality_probability", equalPtr(before.NationalityProbability, after.NationalityProbability)},
+		{"deleted_at", (before.DeletedAt == nil) == (after.DeletedAt == nil)},
+	}
+
+	changed := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !candidate.equal {
+			changed = append(changed, candidate.column)
+		}
+	}
+	return changed
+}
+
+// equalPtr сравнивает значения по указателям, считая равными два nil.
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// nullIfEmpty возвращает nil для пустой строки, чтобы сохранить в колонке NULL.
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// isExpectedError сообщает, является ли ошибка ожидаемым результатом операции,
+// который не нужно логировать как сбой хранилища.
+func isExpectedError(err error) bool {
+	return errors.Is(err, ErrPersonNotFound) ||
+		errors.Is(err, person.ErrVersionConflict) ||
+		errors.Is(err, person.ErrPersonNotDeleted) ||
+		errors.Is(err, person.ErrInvalidMerge)
+}
+
+// orderByClause формирует ORDER BY по колонкам сортировки с идентификатором в качестве
+// последнего ключа, чтобы порядок строк с одинаковыми значениями был детерминированным.
+// Идентификатор сортируется в направлении первой колонки, чтобы сортировка по одной колонке
+// в любом направлении читалась индексом (колонка, id) прямым или обратным проходом.
+func orderByClause(sortFields []person.SortField) string {
+	parts := make([]string, 0, len(sortFields)+1)
+	for _, field := range sortFields {
+		parts = append(parts, field.Column+direction(field.Desc))
+	}
+	parts = append(parts, "id"+direction(sortFields[0].Desc))
+	return strings.Join(parts, ", ")
+}
+
+// direction возвращает направление сортировки для ORDER BY.
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// keysetCondition строит условие отбора строк, следующих за курсором в порядке orderByClause.
+// Номера параметров начинаются с argNum. PostgreSQL упорядочивает NULL как значение больше
+// любого другого (в конце при ASC и в начале при DESC), что учитывается в сравнении.
+func keysetCondition(sortFields []person.SortField, cursor person.Cursor, argNum int) (string, []any) {
+	keys := append(append([]person.SortField{}, sortFields...),
+		person.SortField{Column: "id", Desc: sortFields[0].Desc})
+	values := append(append([]any{}, cursor.Values...), cursor.ID)
+
+	var args []any
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			args = append(args, value)
+			placeholders[i] = fmt.Sprintf("$%d", argNum+len(args)-1)
+		}
+	}
+
+	alternatives := make([]string, 0, len(keys))
+	for i, key := range keys {
+		after := keyAfter(key, values[i], placeholders[i])
+		if after == "" {
+			continue
+		}
+
+		parts := make([]string, 0, i+1)
+		for j := range i {
+			if values[j] == nil {
+				parts = append(parts, keys[j].Column+" IS NULL")
+			} else {
+				parts = append(parts, fmt.Sprintf("%s = %s", keys[j].Column, placeholders[j]))
+			}
+		}
+		parts = append(parts, after)
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(alternatives) == 0 {
+		return "FALSE", args
+	}
+
+	condition := "(" + strings.Join(alternatives, " OR ") + ")"
+	if bound := leadingBound(keys[0], values[0], placeholders[0]); bound != "" {
+		condition = "(" + bound + " AND " + condition + ")"
+	}
+	return condition, args
+}
+
+// leadingBound возвращает избыточное условие на первую колонку сортировки. Оно не меняет
+// результат keysetCondition, но позволяет начать чтение индекса с позиции курсора,
+// а не перебирать строки с начала. Пустая строка означает, что ограничения нет.
+func leadingBound(key person.SortField, value any, placeholder string) string {
+	switch {
+	case value == nil && key.Desc:
+		return ""
+	case value == nil:
+		return key.Column + " IS NULL"
+	case key.Desc:
+		return fmt.Sprintf("%s <= %s", key.Column, placeholder)
+	case person.NullableColumns[key.Column]:
+		return fmt.Sprintf("(%s >= %s OR %s IS NULL)", key.Column, placeholder, key.Column)
+	default:
+		return fmt.Sprintf("%s >= %s", key.Column, placeholder)
+	}
+}
+
+// keyAfter возвращает условие, при котором значение колонки следует за значением курсора.
+// Пустая строка означает, что таких значений нет.
+func keyAfter(key person.SortField, value any, placeholder string) string {
+	switch {
+	case value == nil && key.Desc:
+		return key.Column + " IS NOT NULL"
+	case value == nil:
+		return ""
+	case key.Desc:
+		return fmt.Sprintf("%s < %s", key.Column, placeholder)
+	case person.NullableColumns[key.Column]:
+		return fmt.Sprintf("(%s > %s OR %s IS NULL)", key.Column, placeholder, key.Column)
+	default:
+		return fmt.Sprintf("%s > %s", key.Column, placeholder)
+	}
+}
+
+// notDeletedCondition возвращает условие, исключающее мягко удаленные персоны,
+// если контекст не запрашивает их явно через person.WithDeleted.
+func notDeletedCondition(ctx context.Context) string {
+	if person.IncludeDeleted(ctx) {
+		return ""
+	}
+	return " AND deleted_at IS NULL"
+}
+
+// prefixedPersonColumns возвращает personColumns с псевдонимом таблицы для запросов с соединениями.
+func prefixedPersonColumns(alias string) string {
+	columns := strings.Split(personColumns, ",")
+	for i, column := range columns {
+		columns[i] = alias + "." + strings.TrimSpace(column)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// scanPerson считывает строку с колонками personColumns в сущность персоны.
+// Значения дополнительных колонок, следующих за personColumns, считываются в extra.
+func scanPerson(row pgx.Row, extra ...any) (*entities.Person, error) {
+	var person entities.Person
+	var patronymic sql.NullString
+	var age sql.NullInt32
+	var gender sql.NullString
+	var genderProb sql.NullFloat64
+	var nationality sql.NullString
+	var nationalityProb sql.NullFloat64
+
+	dest := []any{
+		&person.ID,
+		&person.Name,
+		&person.Surname,
+		&patronymic,
+		&age,
+		&gender,
+		&genderProb,
+		&nationality,
+		&nationalityProb,
+		&person.CreatedAt,
+		&person.UpdatedAt,
+		&person.Version,
+		&person.DeletedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan person: %w", err)
+	}
+
+	// Конвертируем nullable поля
+	if patronymic.Valid {
+		person.Patronymic = &patronymic.String
+	}
+	if age.Valid {
+		ageVal := int(age.Int32)
+		person.Age = &ageVal
+	}
+	if gender.Valid {
+		person.Gender = &gender.String
+	}
+	if genderProb.Valid {
+		person.GenderProbability = &genderProb.Float64
+	}
+	if nationality.Valid {
+		person.Nationality = &nationality.String
+	}
+	if nationalityProb.Valid {
+		person.NationalityProbability = &nationalityProb.Float64
+	}
+
+	return &person, nil
+}
@@ -1 +1,36 @@
 package person_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/postgres/repo/people/person"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person/persontest"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRepository прогоняет общий набор тестов репозитория на базе из PG_TEST_DSN.
+// Перед каждым подтестом таблицы персон очищаются, поэтому база должна быть отдельной.
+func TestRepository(t *testing.T) {
+	dsn := os.Getenv("PG_TEST_DSN")
+	if dsn == "" || testing.Short() {
+		t.Skip("PG_TEST_DSN is not set")
+	}
+
+	ctx := context.Background()
+	require.NoError(t, migrate.NewAdapter(migrate.Config{Path: "../../../../../../../migrations"}).Up(ctx, dsn))
+
+	db, err := postgres.NewWithDSN(ctx, dsn, 1, 4)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close(ctx) })
+
+	persontest.RunRepositoryTests(t, func(t *testing.T) personrepo.Repository {
+		_, err := db.Pool().Exec(ctx, "TRUNCATE persons, person_history, person_external_ids, person_imports CASCADE")
+		require.NoError(t, err)
+		return person.NewRepository(db)
+	})
+}
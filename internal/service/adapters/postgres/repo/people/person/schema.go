@@ -0,0 +1,65 @@
+package person
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// schemaColumns перечисляет таблицы и колонки, к которым обращаются запросы репозитория.
+// Колонку tenant_id запросы не упоминают, но ее используют политики защиты строк и ограничение
+// уникальности внешних идентификаторов.
+var schemaColumns = map[string][]string{
+	"persons": append(strings.Fields(strings.ReplaceAll(personColumns, ",", " ")),
+		"merged_into", "name_phonetic", "surname_phonetic", "tenant_id"),
+	"person_history": {
+		"id", "person_id", "version", "operation", "before", "after", "changed_fields",
+		"related_ids", "actor", "request_id", "created_at", "tenant_id",
+	},
+	"person_external_ids": {"source", "external_id", "person_id", "created_at", "tenant_id"},
+}
+
+// CheckSchema проверяет, что в текущей схеме базы данных есть все колонки, используемые репозиторием.
+func (r *Repository) CheckSchema(ctx context.Context) error {
+	tables := make([]string, 0, len(schemaColumns))
+	for table := range schemaColumns {
+		tables = append(tables, table)
+	}
+
+	rows, err := r.db.WritePool().Query(ctx, `
+        SELECT table_name || '.' || column_name
+        FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = ANY($1)`, tables)
+	if err != nil {
+		logger.Error(ctx, "failed to read database schema", zap.Error(err))
+		return fmt.Errorf("failed to read database schema: %w", err)
+	}
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		logger.Error(ctx, "failed to read database schema", zap.Error(err))
+		return fmt.Errorf("failed to read database schema: %w", err)
+	}
+
+	var missing []string
+	for table, columns := range schemaColumns {
+		for _, column := range columns {
+			if name := table + "." + column; !slices.Contains(existing, name) {
+				missing = append(missing, name)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	slices.Sort(missing)
+	logger.Error(ctx, "database schema is missing columns used by the person repository",
+		zap.Strings("columns", missing))
+	return fmt.Errorf("%w: missing columns %s", person.ErrSchemaDrift, strings.Join(missing, ", "))
+}
@@ -0,0 +1,164 @@
+package person
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// coverageColumns перечисляет колонки, заполненность которых считает GetStats, в порядке сканирования.
+var coverageColumns = []string{
+	"patronymic",
+	"age",
+	"gender",
+	"gender_probability",
+	"nationality",
+	"nationality_probability",
+}
+
+// GetStats вычисляет статистику по персонам, отобранным фильтром GetPersons.
+// Все агрегаты считаются в PostgreSQL одним пакетом запросов на общем снимке данных.
+func (r *Repository) GetStats(ctx context.Context, filter map[string]any, opts person.StatsOptions) (*entities.PersonStats, error) {
+	logger.Debug(ctx, "getting person stats",
+		zap.Any("filter", filter),
+		zap.Int("top_nationalities", opts.TopNationalities),
+		zap.Int("age_bucket_width", opts.AgeBucketWidth))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	where := selection.where
+	args := selection.args
+	next := len(args) + 1
+
+	batch := &pgx.Batch{}
+	batch.Queue(`
+        SELECT COUNT(*), COUNT(patronymic), COUNT(age), COUNT(gender), COUNT(gender_probability),
+               COUNT(nationality), COUNT(nationality_probability),
+               AVG(gender_probability)::float8, AVG(nationality_probability)::float8
+        FROM persons
+        WHERE `+where, args...)
+	batch.Queue(`
+        SELECT gender, COUNT(*)
+        FROM persons
+        WHERE `+where+` AND gender IS NOT NULL
+        GROUP BY gender
+        ORDER BY COUNT(*) DESC, gender`, args...)
+	batch.Queue(fmt.Sprintf(`
+        SELECT nationality, COUNT(*)
+        FROM persons
+        WHERE %s AND nationality IS NOT NULL
+        GROUP BY nationality
+        ORDER BY COUNT(*) DESC, nationality
+        LIMIT $%d`, where, next), slices.Concat(args, []any{opts.TopNationalities})...)
+	batch.Queue(fmt.Sprintf(`
+        SELECT age / $%[2]d * $%[2]d AS bucket, COUNT(*)
+        FROM persons
+        WHERE %[1]s AND age IS NOT NULL
+        GROUP BY bucket
+        ORDER BY bucket`, where, next), slices.Concat(args, []any{opts.AgeBucketWidth})...)
+
+	tx, err := r.beginSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Транзакция только читает данные, поэтому всегда откатывается.
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn(ctx, "failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	results := tx.SendBatch(ctx, batch)
+	stats, err := readStats(results, opts.AgeBucketWidth)
+	if closeErr := results.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Error(ctx, "failed to get person stats", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// readStats считывает результаты пакета запросов GetStats в порядке их постановки.
+func readStats(results pgx.BatchResults, bucketWidth int) (*entities.PersonStats, error) {
+	stats := &entities.PersonStats{AgeBucketWidth: bucketWidth}
+
+	filled := make([]int, len(coverageColumns))
+	dest := []any{&stats.Total}
+	for i := range filled {
+		dest = append(dest, &filled[i])
+	}
+	dest = append(dest, &stats.AverageGenderProbability, &stats.AverageNationalityProbability)
+	if err := results.QueryRow().Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to scan totals: %w", err)
+	}
+
+	stats.Coverage = make([]entities.PersonStatsFieldCoverage, len(coverageColumns))
+	for i, column := range coverageColumns {
+		stats.Coverage[i] = entities.PersonStatsFieldCoverage{Field: column, Filled: filled[i]}
+		if stats.Total > 0 {
+			stats.Coverage[i].Ratio = float64(filled[i]) / float64(stats.Total)
+		}
+	}
+
+	var err error
+	if stats.Gender, err = readValueCounts(results); err != nil {
+		return nil, fmt.Errorf("failed to read gender counts: %w", err)
+	}
+	if stats.Nationality, err = readValueCounts(results); err != nil {
+		return nil, fmt.Errorf("failed to read nationality counts: %w", err)
+	}
+	// Заполненность национальности равна сумме по всем значениям, поэтому остаток не требует отдельного запроса.
+	stats.NationalityOther = filled[slices.Index(coverageColumns, "nationality")]
+	for _, count := range stats.Nationality {
+		stats.NationalityOther -= count.Count
+	}
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query age histogram: %w", err)
+	}
+	stats.Age, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (entities.PersonStatsAgeBucket, error) {
+		var bucket entities.PersonStatsAgeBucket
+		if err := row.Scan(&bucket.From, &bucket.Count); err != nil {
+			return bucket, fmt.Errorf("failed to scan age bucket: %w", err)
+		}
+		bucket.To = bucket.From + bucketWidth - 1
+		return bucket, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age histogram: %w", err)
+	}
+
+	return stats, nil
+}
+
+// readValueCounts считывает очередной результат пакета из строк (значение, количество).
+func readValueCounts(results pgx.BatchResults) ([]entities.PersonStatsValueCount, error) {
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query counts: %w", err)
+	}
+	counts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entities.PersonStatsValueCount, error) {
+		var count entities.PersonStatsValueCount
+		if err := row.Scan(&count.Value, &count.Count); err != nil {
+			return count, fmt.Errorf("failed to scan count: %w", err)
+		}
+		return count, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read counts: %w", err)
+	}
+	return counts, nil
+}
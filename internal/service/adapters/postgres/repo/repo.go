@@ -2,10 +2,13 @@
 package repo
 
 import (
+	"context"
+
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/postgres/repo/people"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
 	peoplerepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
 )
 
 // Проверка реализации интерфейса.
@@ -13,12 +16,16 @@ var _ repo.Repositories = (*Repositories)(nil)
 
 // Repositories реализует интерфейс repo.Repositories для PostgreSQL.
 type Repositories struct {
-	peopleRepo peoplerepo.Repositories
+	db         postgres.Provider
+	peopleRepo *people.Repositories
+	// inTx означает, что репозитории привязаны к транзакции WithTx.
+	inTx bool
 }
 
 // NewRepositories создает новый экземпляр репозиториев с PostgreSQL.
 func NewRepositories(db postgres.Provider) *Repositories {
 	return &Repositories{
+		db:         db,
 		peopleRepo: people.NewRepositories(db),
 	}
 }
@@ -27,3 +34,22 @@ func NewRepositories(db postgres.Provider) *Repositories {
 func (r *Repositories) People() peoplerepo.Repositories {
 	return r.peopleRepo
 }
+
+// WithTx выполняет fn в транзакции уровня REPEATABLE READ: чтения внутри fn видят один снимок данных,
+// а изменение прочитанных строк другой транзакцией прерывает fn конфликтом сериализации и приводит к повтору.
+func (r *Repositories) WithTx(ctx context.Context, fn func(tx repo.Repositories) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+	if err := postgres.RequireTenant(ctx); err != nil {
+		return err
+	}
+
+	return postgres.RunInTx(ctx, r.db.WritePool(), pgx.TxOptions{IsoLevel: pgx.RepeatableRead}, func(tx pgx.Tx) error {
+		return fn(&Repositories{
+			db:         r.db,
+			peopleRepo: r.peopleRepo.WithTx(tx),
+			inTx:       true,
+		})
+	})
+}
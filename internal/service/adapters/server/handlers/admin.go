@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/gofiber/fiber/v3"
+)
+
+// HeaderAdminToken заголовок, в котором передается токен администратора.
+const HeaderAdminToken = "X-Admin-Token"
+
+// adminLocalsKey ключ, под которым в контексте запроса хранится признак администратора.
+const adminLocalsKey = "is_admin"
+
+// Ошибки, связанные с административным доступом.
+var (
+	ErrAdminRequired         = errors.New("admin privileges required")
+	ErrInvalidIncludeDeleted = errors.New("invalid include_deleted parameter")
+)
+
+// AdminMiddleware помечает запрос как административный, если заголовок X-Admin-Token
+// совпадает с настроенным токеном. Пустой токен отключает административный доступ.
+func AdminMiddleware(token string) fiber.Handler {
+	return func(ctx fiber.Ctx) error {
+		if token != "" && subtle.ConstantTimeCompare([]byte(ctx.Get(HeaderAdminToken)), []byte(token)) == 1 {
+			ctx.Locals(adminLocalsKey, true)
+		}
+		return ctx.Next()
+	}
+}
+
+// isAdmin сообщает, выполнен ли запрос администратором.
+func isAdmin(ctx fiber.Ctx) bool {
+	admin, _ := ctx.Locals(adminLocalsKey).(bool)
+	return admin
+}
+
+// readContext возвращает контекст для чтения персон с учетом параметра include_deleted.
+// Просмотр мягко удаленных персон доступен только администраторам.
+func readContext(ctx fiber.Ctx) (context.Context, error) {
+	requestCtx := ctx.Context()
+
+	value := ctx.Query("include_deleted")
+	if value == "" {
+		return requestCtx, nil
+	}
+
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidIncludeDeleted, value)
+	}
+	if !include {
+		return requestCtx, nil
+	}
+	if !isAdmin(ctx) {
+		return nil, fmt.Errorf("%w: include_deleted", ErrAdminRequired)
+	}
+	return personrepo.WithDeleted(requestCtx), nil
+}
+
+// sendReadContextError отвечает на некорректный или недоступный параметр include_deleted.
+func sendReadContextError(ctx fiber.Ctx, err error) error {
+	if errors.Is(err, ErrAdminRequired) {
+		return sendError(ctx, fiber.StatusForbidden, "Admin privileges required to include deleted persons", err)
+	}
+	return sendError(ctx, fiber.StatusBadRequest, "Invalid include_deleted parameter", err)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"strings"
+
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Заголовки, из которых берутся метаданные для истории изменений.
+const (
+	HeaderRequestID = "X-Request-ID"
+	HeaderActor     = "X-Actor"
+)
+
+// maxActorLength ограничивает длину инициатора изменения размером колонки person_history.actor.
+const maxActorLength = 255
+
+// AuditMiddleware добавляет в контекст запроса идентификатор запроса и инициатора изменения.
+// Идентификатор берется из заголовка X-Request-ID (или генерируется) и возвращается в ответе,
+// инициатор берется из заголовка X-Actor.
+func AuditMiddleware() fiber.Handler {
+	return func(ctx fiber.Ctx) error {
+		requestCtx := logger.WithRequestID(ctx.Context(), ctx.Get(HeaderRequestID))
+		if requestID, ok := logger.RequestID(requestCtx); ok {
+			ctx.Set(HeaderRequestID, requestID)
+		}
+
+		if actor := strings.TrimSpace(ctx.Get(HeaderActor)); actor != "" {
+			if runes := []rune(actor); len(runes) > maxActorLength {
+				actor = string(runes[:maxActorLength])
+			}
+			requestCtx = historyrepo.WithActor(requestCtx, actor)
+		}
+
+		ctx.SetContext(requestCtx)
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Типы содержимого NDJSON, принимаемые массовым созданием.
+const (
+	mimeApplicationXNDJSON = "application/x-ndjson"
+	mimeApplicationNDJSON  = "application/ndjson"
+)
+
+// Режимы массового создания персон.
+const (
+	// BulkModeAtomic создает все записи в одной транзакции либо не создает ни одной.
+	BulkModeAtomic = "atomic"
+	// BulkModePartial создает записи частями; ошибка части не отменяет остальные.
+	BulkModePartial = "partial"
+)
+
+// Ограничения массового создания персон.
+const (
+	maxBulkRecords       = 10000
+	defaultBulkChunkSize = 1000
+)
+
+// Ошибки массового создания персон.
+var (
+	ErrInvalidBulkRequest = errors.New("invalid bulk request")
+	ErrTooManyRecords     = errors.New("too many records")
+	ErrInvalidPerson      = entities.ErrInvalidPerson
+)
+
+// bulkResult описывает результат обработки одной записи массового создания.
+type bulkResult struct {
+	Index int        `json:"index"`
+	ID    *uuid.UUID `json:"id,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+// BulkCreatePersons godoc
+// @Summary Create persons in bulk
+// @Description Create many persons from a JSON array (application/json) or NDJSON stream (application/x-ndjson).
+// @Description Every record is validated; in atomic mode nothing is created if any record is invalid or the insert fails,
+// @Description in partial mode valid records are inserted in chunks and each record reports its own id or error
+// @Tags persons
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Param persons body []entities.Person true "Persons to create"
+// @Param mode query string false "Insert mode" Enums(atomic, partial) default(atomic)
+// @Param chunk_size query int false "Records per transaction in partial mode" default(1000) minimum(1) maximum(10000)
+// @Success 201 {object} map[string]interface{} "All persons created"
+// @Success 207 {object} map[string]interface{} "Some persons were not created (partial mode)"
+// @Failure 400 {object} map[string]string "Bad request - Malformed body, mode or chunk_size"
+// @Failure 409 {object} map[string]interface{} "A person with the same ID already exists"
+// @Failure 413 {object} map[string]string "Too many records"
+// @Failure 422 {object} map[string]interface{} "Validation failed (atomic mode)"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/bulk [post]
+func (h *PersonHandler) BulkCreatePersons(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	logger.Debug(requestCtx, "handling bulk create persons request")
+
+	mode := ctx.Query("mode", BulkModeAtomic)
+	if mode != BulkModeAtomic && mode != BulkModePartial {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid mode parameter",
+			fmt.Errorf("%w: unknown mode %q", ErrInvalidBulkRequest, mode))
+	}
+
+	chunkSize := defaultBulkChunkSize
+	if chunkStr := ctx.Query("chunk_size"); chunkStr != "" {
+		size, err := strconv.Atoi(chunkStr)
+		if err != nil || size < 1 || size > maxBulkRecords {
+			return sendError(ctx, fiber.StatusBadRequest, "Invalid chunk_size parameter",
+				fmt.Errorf("%w: chunk_size %q", ErrInvalidBulkRequest, chunkStr))
+		}
+		chunkSize = size
+	}
+
+	records, err := decodeBulkRecords(ctx.Get(fiber.HeaderContentType), ctx.Body())
+	if err != nil {
+		if errors.Is(err, ErrTooManyRecords) {
+			return sendError(ctx, fiber.StatusRequestEntityTooLarge,
+				fmt.Sprintf("At most %d records are allowed per request", maxBulkRecords), err)
+		}
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid request body: "+err.Error(), err)
+	}
+
+	results := make([]bulkResult, len(records))
+	valid := make([]*entities.Person, 0, len(records))
+	indexes := make([]int, 0, len(records))
+	seen := make(map[uuid.UUID]int, len(records))
+	for i, record := range records {
+		results[i].Index = i
+		person, err := parseBulkPerson(record)
+		if err == nil && person.ID != uuid.Nil {
+			if first, ok := seen[person.ID]; ok {
+				err = fmt.Errorf("%w: duplicate id of record %d", ErrInvalidPerson, first)
+			} else {
+				seen[person.ID] = i
+			}
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		valid = append(valid, person)
+		indexes = append(indexes, i)
+	}
+
+	if mode == BulkModeAtomic {
+		if len(valid) != len(records) {
+			return sendBulkResponse(ctx, fiber.StatusUnprocessableEntity, results)
+		}
+		chunkSize = max(len(valid), 1)
+	}
+
+	status := fiber.StatusCreated
+	for start := 0; start < len(valid); start += chunkSize {
+		end := min(start+chunkSize, len(valid))
+		chunk := valid[start:end]
+
+		if err := h.repositories.People().Person().CreatePersons(requestCtx, chunk); err != nil {
+			logger.Error(requestCtx, "failed to create persons chunk",
+				zap.Int("offset", start),
+				zap.Int("count", len(chunk)),
+				zap.Error(err))
+
+			if mode == BulkModeAtomic {
+				if strings.Contains(err.Error(), "already exists") {
+					return sendError(ctx, fiber.StatusConflict, "Person with the same ID already exists",
+						fmt.Errorf("failed to create persons: %w", err))
+				}
+				return sendError(ctx, fiber.StatusInternalServerError, "Failed to create persons",
+					fmt.Errorf("failed to create persons: %w", err))
+			}
+
+			message := "failed to create persons"
+			if strings.Contains(err.Error(), "already exists") {
+				message = "person with the same ID already exists in this chunk"
+			}
+			for _, index := range indexes[start:end] {
+				results[index].Error = message
+			}
+			continue
+		}
+
+		for i, person := range chunk {
+			results[indexes[start+i]].ID = &person.ID
+		}
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			status = fiber.StatusMultiStatus
+			break
+		}
+	}
+	return sendBulkResponse(ctx, status, results)
+}
+
+// decodeBulkRecords разбирает тело запроса как JSON-массив или NDJSON в зависимости от типа содержимого.
+// Записи возвращаются без разбора, чтобы ошибка одной записи не отменяла обработку остальных.
+func decodeBulkRecords(contentType string, body []byte) ([]json.RawMessage, error) {
+	mediaType := fiber.MIMEApplicationJSON
+	if contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBulkRequest, err)
+		}
+		mediaType = parsed
+	}
+
+	var records []json.RawMessage
+	switch mediaType {
+	case mimeApplicationXNDJSON, mimeApplicationNDJSON:
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			if len(records) == maxBulkRecords {
+				return nil, fmt.Errorf("%w: limit is %d", ErrTooManyRecords, maxBulkRecords)
+			}
+			records = append(records, json.RawMessage(bytes.Clone(line)))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBulkRequest, err)
+		}
+	case fiber.MIMEApplicationJSON:
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			return nil, fmt.Errorf("%w: body must be a JSON array", ErrInvalidBulkRequest)
+		}
+		for decoder.More() {
+			if len(records) == maxBulkRecords {
+				return nil, fmt.Errorf("%w: limit is %d", ErrTooManyRecords, maxBulkRecords)
+			}
+			var record json.RawMessage
+			if err := decoder.Decode(&record); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidBulkRequest, err)
+			}
+			records = append(records, record)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBulkRequest, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported content type %s", ErrInvalidBulkRequest, mediaType)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no records", ErrInvalidBulkRequest)
+	}
+	return records, nil
+}
+
+// parseBulkPerson разбирает и проверяет одну запись массового создания.
+func parseBulkPerson(record json.RawMessage) (*entities.Person, error) {
+	var person entities.Person
+	if err := json.Unmarshal(record, &person); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPerson, err)
+	}
+	if err := person.Validate(); err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+// sendBulkResponse отправляет результаты массового создания с итоговыми счетчиками.
+func sendBulkResponse(ctx fiber.Ctx, status int, results []bulkResult) error {
+	created := 0
+	for _, result := range results {
+		if result.ID != nil {
+			created++
+		}
+	}
+
+	if err := ctx.Status(status).JSON(fiber.Map{
+		"created": created,
+		"failed":  len(results) - created,
+		"results": results,
+	}); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	if status >= fiber.StatusBadRequest {
+		return fmt.Errorf("%w: %d of %d records are invalid", ErrInvalidPerson, len(results)-created, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"github.com/flexer2006/case-person-enrichment-go/pkg/consistency"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ReadYourWritesMiddleware отмечает в контексте запроса его записи в базу данных: после первой
+// записи чтения того же запроса выполняются на основном сервере, а не на отстающей реплике.
+func ReadYourWritesMiddleware() fiber.Handler {
+	return func(ctx fiber.Ctx) error {
+		ctx.SetContext(consistency.WithReadYourWrites(ctx.Context()))
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/exports"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+// ExportPersons godoc
+// @Summary Export persons to a file
+// @Description Stream persons matching the list filters as CSV, NDJSON or Parquet. Rows are read from a database cursor
+// @Description and written as they arrive, so the export is not limited by memory. The file is not paginated.
+// @Description Errors that occur after streaming has started terminate the response early.
+// @Tags persons
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.apache.parquet
+// @Produce application/gzip
+// @Param format query string false "File format" Enums(csv, ndjson, parquet) default(csv)
+// @Param columns query string false "Comma-separated columns in output order: id, name, surname, patronymic, age, gender, gender_probability, nationality, nationality_probability, created_at, updated_at, version, deleted_at" example(id,name,surname,age)
+// @Param gzip query bool false "Compress CSV and NDJSON with gzip, Parquet pages with the GZIP codec" default(false)
+// @Param sort query string false "Comma-separated sort columns, prefix - for descending" example(surname,-age)
+// @Param name query string false "Filter by name substring, comma-separated values are combined with OR"
+// @Param surname query string false "Filter by surname substring, comma-separated values are combined with OR"
+// @Param phonetic query bool false "Match name and surname by phonetic keys instead of substring" default(false)
+// @Param patronymic query string false "Filter by patronymic substring, comma-separated values are combined with OR"
+// @Param gender query string false "Comma-separated genders (exact match)" example(male,female)
+// @Param nationality query string false "Comma-separated nationality codes (exact match)" example(RU,UA,BY)
+// @Param age query int false "Filter by age"
+// @Param age_min query int false "Minimum age (inclusive)" minimum(0)
+// @Param age_max query int false "Maximum age (inclusive)" minimum(0)
+// @Param created_after query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param missing query string false "Comma-separated columns that must be empty"
+// @Param present query string false "Comma-separated columns that must be filled"
+// @Param include_deleted query bool false "Include soft-deleted persons (admin only)"
+// @Param X-Admin-Token header string false "Admin token"
+// @Success 200 {file} file "Exported persons"
+// @Failure 400 {object} map[string]string "Bad request - Invalid format, columns, gzip, filter or sort"
+// @Failure 403 {object} map[string]string "Admin privileges required"
+// @Router /persons/export [get]
+func (h *PersonHandler) ExportPersons(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	logger.Debug(requestCtx, "handling export persons request")
+
+	readCtx, err := readContext(ctx)
+	if err != nil {
+		return sendReadContextError(ctx, err)
+	}
+
+	filter, message, err := parseListFilter(ctx)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, message, err)
+	}
+
+	opts := exports.Options{
+		Format: ctx.Query("format", exports.FormatCSV),
+		Filter: filter,
+	}
+	switch opts.Format {
+	case exports.FormatCSV, exports.FormatNDJSON, exports.FormatParquet:
+	default:
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid format parameter, expected csv, ndjson or parquet",
+			fmt.Errorf("%w: %q", exports.ErrUnsupportedFormat, opts.Format))
+	}
+
+	if opts.Columns, err = exports.ParseColumns(ctx.Query("columns")); err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid columns parameter: "+err.Error(), fmt.Errorf("invalid columns: %w", err))
+	}
+
+	if gzipStr := ctx.Query("gzip"); gzipStr != "" {
+		if opts.Gzip, err = strconv.ParseBool(gzipStr); err != nil {
+			return sendError(ctx, fiber.StatusBadRequest, "Invalid gzip parameter", fmt.Errorf("invalid gzip: %w", err))
+		}
+	}
+
+	ctx.Attachment(exports.FileName(opts))
+	ctx.Set(fiber.HeaderContentType, exports.ContentType(opts))
+
+	repository := h.repositories.People().Person()
+	// Функция записи выполняется после выхода из обработчика, поэтому не обращается к fiber.Ctx.
+	if err := ctx.SendStreamWriter(func(w *bufio.Writer) {
+		count, err := exports.Export(readCtx, repository, w, opts)
+		if err != nil {
+			logger.Error(readCtx, "failed to export persons", zap.Int("written", count), zap.Error(err))
+			return
+		}
+		if err := w.Flush(); err != nil {
+			logger.Warn(readCtx, "failed to flush export", zap.Error(err))
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to start export stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+// maxExternalIDLength ограничивает длину внешнего идентификатора размером колонки person_external_ids.external_id.
+const maxExternalIDLength = 255
+
+// sourcePattern задает допустимое имя внешней системы: до 50 латинских букв, цифр, '.', '_' и '-'.
+var sourcePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,49}$`)
+
+// ErrInvalidExternalReference возникает при некорректном имени системы или внешнем идентификаторе.
+var ErrInvalidExternalReference = errors.New("invalid external reference")
+
+// UpsertPersonByExternalID godoc
+// @Summary Create or update person by external reference
+// @Description Create a person linked to the external id of an upstream system, or replace the person already linked to it.
+// @Description Re-sending the same record is idempotent, so repeated imports do not create duplicates. If-Match is checked only when the person exists
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param source path string true "Upstream system name" maxlength(50)
+// @Param externalId path string true "Person id in the upstream system (URL-encoded)" maxlength(255)
+// @Param person body entities.Person true "Person data"
+// @Param If-Match header string false "Expected person version (ETag)"
+// @Success 200 {object} entities.Person "Person updated"
+// @Success 201 {object} entities.Person "Person created"
+// @Header 200,201 {string} ETag "Person version"
+// @Failure 400 {object} map[string]string "Bad request - Invalid source, external id or body"
+// @Failure 404 {object} map[string]string "Linked person is deleted"
+// @Failure 412 {object} map[string]string "Person version does not match If-Match or the person does not exist"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/by-external/{source}/{externalId} [put]
+func (h *PersonHandler) UpsertPersonByExternalID(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+
+	source, externalID, err := externalReference(ctx)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, err.Error(), err)
+	}
+
+	logger.Debug(requestCtx, "handling upsert person by external id request",
+		zap.String("source", source),
+		zap.String("external_id", externalID))
+
+	match, err := ifMatchVersion(ctx)
+	if err != nil {
+		return sendPreconditionError(ctx, err)
+	}
+
+	var person entities.Person
+	if err := ctx.Bind().Body(&person); err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid request body", fmt.Errorf("invalid request body: %w", err))
+	}
+	if err := person.Validate(); err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, err.Error(), err)
+	}
+	person.Version = match.version
+
+	// If-Match: * разрешает только обновление, поэтому наличие связанной персоны проверяется
+	// в той же транзакции, что и запись.
+	var created bool
+	err = h.repositories.WithTx(requestCtx, func(tx repo.Repositories) error {
+		if match.any {
+			if _, err := tx.People().Person().GetByExternalID(requestCtx, source, externalID); err != nil {
+				return err
+			}
+		}
+		upserted := person
+		var err error
+		if created, err = tx.People().Person().UpsertByExternalID(requestCtx, source, externalID, &upserted); err != nil {
+			return err
+		}
+		person = upserted
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, personrepo.ErrVersionConflict):
+			return sendError(ctx, fiber.StatusPreconditionFailed, "Person has been modified", fmt.Errorf("version conflict: %w", err))
+		case strings.Contains(err.Error(), "not found"):
+			return sendMissingTarget(ctx, match, "Person linked to the external id is deleted", fmt.Errorf("person not found: %w", err))
+		}
+		logger.Error(requestCtx, "failed to upsert person by external id", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to save person", fmt.Errorf("failed to upsert person: %w", err))
+	}
+
+	status := fiber.StatusOK
+	if created {
+		status = fiber.StatusCreated
+		ctx.Location("/api/v1/persons/" + person.ID.String())
+	}
+	setETag(ctx, person.Version)
+	if err := ctx.Status(status).JSON(person); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// GetPersonByExternalID godoc
+// @Summary Get person by external reference
+// @Description Get the person linked to the external id of an upstream system
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param source path string true "Upstream system name" maxlength(50)
+// @Param externalId path string true "Person id in the upstream system (URL-encoded)" maxlength(255)
+// @Success 200 {object} entities.Person "Successfully retrieved person"
+// @Header 200 {string} ETag "Current person version"
+// @Failure 400 {object} map[string]string "Bad request - Invalid source or external id"
+// @Failure 404 {object} map[string]string "Person not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/by-external/{source}/{externalId} [get]
+func (h *PersonHandler) GetPersonByExternalID(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+
+	source, externalID, err := externalReference(ctx)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, err.Error(), err)
+	}
+
+	logger.Debug(requestCtx, "handling get person by external id request",
+		zap.String("source", source),
+		zap.String("external_id", externalID))
+
+	person, err := h.repositories.People().Person().GetByExternalID(requestCtx, source, externalID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return sendError(ctx, fiber.StatusNotFound, "Person not found", fmt.Errorf("person not found: %w", err))
+		}
+		logger.Error(requestCtx, "failed to get person by external id", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to get person", fmt.Errorf("failed to get person: %w", err))
+	}
+
+	setETag(ctx, person.Version)
+	if err := ctx.JSON(person); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// externalReference извлекает и проверяет имя внешней системы и внешний идентификатор из пути.
+func externalReference(ctx fiber.Ctx) (string, string, error) {
+	source := ctx.Params("source")
+	if !sourcePattern.MatchString(source) {
+		return "", "", fmt.Errorf("%w: source must be 1-50 letters, digits, '.', '_' or '-'", ErrInvalidExternalReference)
+	}
+
+	externalID, err := url.PathUnescape(ctx.Params("externalId"))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: external id is not properly escaped", ErrInvalidExternalReference)
+	}
+	if strings.TrimSpace(externalID) == "" || utf8.RuneCountInString(externalID) > maxExternalIDLength {
+		return "", "", fmt.Errorf("%w: external id must be 1-%d characters", ErrInvalidExternalReference, maxExternalIDLength)
+	}
+	return source, externalID, nil
+}
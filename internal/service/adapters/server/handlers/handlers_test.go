@@ -2,10 +2,13 @@ package handlers_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -14,13 +17,20 @@ import (
 
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/server/handlers"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/age"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/gender"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/nationality"
 	personapi "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
 	repopeople "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	importsrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
 	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	tenantsetup "github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -31,12 +41,17 @@ import (
 type MockAPI struct {
 	mock.Mock
 	mockPeopleServices *MockPeopleServices
+	reserveErr         error
 }
 
 func (m *MockAPI) People() people.Services {
 	return m.mockPeopleServices
 }
 
+func (m *MockAPI) ReserveEnrichment(_ context.Context) error {
+	return m.reserveErr
+}
+
 type MockPeopleServices struct {
 	mock.Mock
 	mockPersonService      *MockPersonService
@@ -140,15 +155,67 @@ func (m *MockRepositories) People() repopeople.Repositories {
 	return m.mockPeopleRepositories
 }
 
+// WithTx выполняет fn без транзакции: моки не хранят данные.
+func (m *MockRepositories) WithTx(_ context.Context, fn func(tx repo.Repositories) error) error {
+	return fn(m)
+}
+
 type MockPeopleRepositories struct {
 	mock.Mock
-	mockPersonRepository *MockPersonRepository
+	mockPersonRepository  *MockPersonRepository
+	mockHistoryRepository *MockHistoryRepository
+	mockImportsRepository *MockImportsRepository
 }
 
 func (m *MockPeopleRepositories) Person() personrepo.Repository {
 	return m.mockPersonRepository
 }
 
+func (m *MockPeopleRepositories) History() historyrepo.Repository {
+	return m.mockHistoryRepository
+}
+
+func (m *MockPeopleRepositories) Imports() importsrepo.Repository {
+	return m.mockImportsRepository
+}
+
+type MockHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockHistoryRepository) GetHistory(ctx context.Context, id uuid.UUID, offset, limit int) ([]*entities.PersonHistory, int, error) {
+	args := m.Called(ctx, id, offset, limit)
+	if entries, ok := args.Get(0).([]*entities.PersonHistory); ok {
+		return entries, args.Int(1), args.Error(2)
+	}
+	return nil, args.Int(1), args.Error(2)
+}
+
+func (m *MockHistoryRepository) GetByVersion(ctx context.Context, id uuid.UUID, version int) (*entities.PersonHistory, error) {
+	args := m.Called(ctx, id, version)
+	if entry, ok := args.Get(0).(*entities.PersonHistory); ok {
+		return entry, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockImportsRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportsRepository) CreateImport(ctx context.Context, result *entities.PersonImport) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
+func (m *MockImportsRepository) GetImport(ctx context.Context, id uuid.UUID) (*entities.PersonImport, error) {
+	args := m.Called(ctx, id)
+	if result, ok := args.Get(0).(*entities.PersonImport); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 type MockPersonRepository struct {
 	mock.Mock
 }
@@ -169,21 +236,112 @@ func (m *MockPersonRepository) GetPersons(ctx context.Context, filter map[string
 	return nil, args.Int(1), args.Error(2)
 }
 
+func (m *MockPersonRepository) StreamPersons(ctx context.Context, filter map[string]any, fn func(*entities.Person) error) error {
+	args := m.Called(ctx, filter)
+	if persons, ok := args.Get(0).([]*entities.Person); ok {
+		for _, person := range persons {
+			if err := fn(person); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockPersonRepository) FindDuplicatePairs(ctx context.Context, minScore float64, limit int) ([]entities.PersonDuplicatePair, error) {
+	args := m.Called(ctx, minScore, limit)
+	if pairs, ok := args.Get(0).([]entities.PersonDuplicatePair); ok {
+		return pairs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPersonRepository) MergePersons(ctx context.Context, survivorID uuid.UUID, mergedIDs []uuid.UUID, version int, resolve personrepo.MergeResolver) (*entities.Person, error) {
+	args := m.Called(ctx, survivorID, mergedIDs, version)
+	if person, ok := args.Get(0).(*entities.Person); ok {
+		return person, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPersonRepository) MergedInto(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, id)
+	if target, ok := args.Get(0).(uuid.UUID); ok {
+		return target, args.Error(1)
+	}
+	return uuid.Nil, args.Error(1)
+}
+
+func (m *MockPersonRepository) UpsertByExternalID(ctx context.Context, source, externalID string, person *entities.Person) (bool, error) {
+	args := m.Called(ctx, source, externalID, person)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPersonRepository) GetByExternalID(ctx context.Context, source, externalID string) (*entities.Person, error) {
+	args := m.Called(ctx, source, externalID)
+	if person, ok := args.Get(0).(*entities.Person); ok {
+		return person, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPersonRepository) GetStats(ctx context.Context, filter map[string]any, opts personrepo.StatsOptions) (*entities.PersonStats, error) {
+	args := m.Called(ctx, filter, opts)
+	if stats, ok := args.Get(0).(*entities.PersonStats); ok {
+		return stats, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockPersonRepository) CreatePerson(ctx context.Context, person *entities.Person) error {
 	args := m.Called(ctx, person)
 	return args.Error(0)
 }
 
+func (m *MockPersonRepository) CreatePersons(ctx context.Context, persons []*entities.Person) error {
+	args := m.Called(ctx, persons)
+	return args.Error(0)
+}
+
 func (m *MockPersonRepository) UpdatePerson(ctx context.Context, person *entities.Person) error {
 	args := m.Called(ctx, person)
 	return args.Error(0)
 }
 
-func (m *MockPersonRepository) DeletePerson(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockPersonRepository) PatchPerson(ctx context.Context, id uuid.UUID, fields map[string]any, version int) (*entities.Person, error) {
+	args := m.Called(ctx, id, fields, version)
+	if person, ok := args.Get(0).(*entities.Person); ok {
+		return person, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPersonRepository) DeletePerson(ctx context.Context, id uuid.UUID, version int) error {
+	args := m.Called(ctx, id, version)
 	return args.Error(0)
 }
 
+func (m *MockPersonRepository) SearchPersons(ctx context.Context, query string, limit int, phonetic bool) ([]*entities.PersonSearchResult, error) {
+	args := m.Called(ctx, query, limit, phonetic)
+	if results, ok := args.Get(0).([]*entities.PersonSearchResult); ok {
+		return results, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPersonRepository) RestorePerson(ctx context.Context, id uuid.UUID) (*entities.Person, error) {
+	args := m.Called(ctx, id)
+	if person, ok := args.Get(0).(*entities.Person); ok {
+		return person, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPersonRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockPersonRepository) ExistsByID(ctx context.Context, id uuid.UUID) (bool, error) {
 	args := m.Called(ctx, id)
 	return args.Bool(0), args.Error(1)
@@ -601,210 +759,1767 @@ func TestGetPersonsDirectHandler(t *testing.T) {
 		mockPersonRepository.AssertExpectations(t)
 	})
 }
-func TestGetPersonByID(t *testing.T) {
-	setupTest := func() (*fiber.App, *MockPersonRepository, *handlers.PersonHandler) {
+func TestGetPersonsCursorPagination(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
 		app := fiber.New(fiber.Config{
-			ErrorHandler: func(c fiber.Ctx, err error) error {
-				code := fiber.StatusInternalServerError
-
-				if e, ok := err.(*fiber.Error); ok {
-					code = e.Code
-				} else {
-					if errors.Is(err, handlers.ErrPersonNotFound) {
-						code = fiber.StatusNotFound
-					}
-					if strings.Contains(err.Error(), "invalid UUID") {
-						code = fiber.StatusBadRequest
-					}
-				}
-
-				return c.Status(code).JSON(fiber.Map{
-					"error": err.Error(),
-				})
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
 			},
 		})
 
-		mockPersonService := &MockPersonService{}
-		mockAgeService := &MockAgeService{}
-		mockGenderService := &MockGenderService{}
-		mockNationalityService := &MockNationalityService{}
-
-		mockPeopleServices := &MockPeopleServices{
-			mockPersonService:      mockPersonService,
-			mockAgeService:         mockAgeService,
-			mockGenderService:      mockGenderService,
-			mockNationalityService: mockNationalityService,
-		}
-
-		mockAPI := &MockAPI{
-			mockPeopleServices: mockPeopleServices,
-		}
-
 		mockPersonRepository := &MockPersonRepository{}
-		mockPeopleRepositories := &MockPeopleRepositories{
-			mockPersonRepository: mockPersonRepository,
-		}
-
 		mockRepositories := &MockRepositories{
-			mockPeopleRepositories: mockPeopleRepositories,
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
 		}
 
-		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
-		return app, mockPersonRepository, handler
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Get("/persons", handler.GetPersons)
+		return app, mockPersonRepository
 	}
 
-	createTestPerson := func() *entities.Person {
-		now := time.Now()
-		age := 25
-		gender := "male"
-		genderProb := 0.95
-		nationality := "RU"
-		nationProb := 0.90
-		patronymic := "Ivanovich"
-
-		return &entities.Person{
-			ID:                     uuid.New(),
-			Name:                   "Ivan",
-			Surname:                "Petrov",
-			Patronymic:             &patronymic,
-			Age:                    &age,
-			Gender:                 &gender,
-			GenderProbability:      &genderProb,
-			Nationality:            &nationality,
-			NationalityProbability: &nationProb,
-			CreatedAt:              now,
-			UpdatedAt:              now,
-		}
+	createdAt := time.Date(2025, 5, 1, 12, 30, 0, 123456000, time.UTC)
+	page := []*entities.Person{
+		{ID: uuid.New(), Name: "Ivan", Surname: "Petrov", CreatedAt: createdAt.Add(time.Minute)},
+		{ID: uuid.New(), Name: "Anna", Surname: "Ivanova", CreatedAt: createdAt},
 	}
 
-	t.Run("should return a person when found", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		testPerson := createTestPerson()
+	decode := func(t *testing.T, resp *http.Response) map[string]any {
+		t.Helper()
+		var result map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return result
+	}
 
-		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+	t.Run("should return next cursor for a full page", func(t *testing.T) {
+		app, mockRepo := setupTest()
 
-		app.Get("/persons/:id", handler.GetPersonByID)
+		mockRepo.On("GetPersons", mock.Anything, map[string]any{}, 0, 2).Return(page, 5, nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/persons/"+testPerson.ID.String(), nil)
-		resp, err := app.Test(req)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?limit=2", nil))
 
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
-		mockRepo.AssertExpectations(t)
 
-		var returnedPerson entities.Person
-		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
-		require.NoError(t, err)
+		result := decode(t, resp)
+		assert.InDelta(t, 5, result["total"], 0)
+		token, ok := result["next_cursor"].(string)
+		require.True(t, ok, "next_cursor should be present")
 
-		assert.Equal(t, testPerson.ID, returnedPerson.ID)
-		assert.Equal(t, testPerson.Name, returnedPerson.Name)
-		assert.Equal(t, testPerson.Surname, returnedPerson.Surname)
-		assert.Equal(t, *testPerson.Patronymic, *returnedPerson.Patronymic)
-		assert.Equal(t, *testPerson.Age, *returnedPerson.Age)
-		assert.Equal(t, *testPerson.Gender, *returnedPerson.Gender)
-		assert.Equal(t, *testPerson.Nationality, *returnedPerson.Nationality)
+		cursor, err := personrepo.DecodeCursor(token, personrepo.DefaultSort)
+		require.NoError(t, err)
+		assert.Equal(t, page[1].ID, cursor.ID)
+		require.Len(t, cursor.Values, 1)
+		assert.True(t, cursor.Values[0].(time.Time).Equal(createdAt))
 	})
 
-	t.Run("should return 400 for invalid UUID", func(t *testing.T) {
-		app, _, handler := setupTest()
+	t.Run("should pass decoded cursor and ignore offset", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		cursor := personrepo.CursorAfter(page[1], personrepo.DefaultSort)
 
-		app.Get("/persons/:id", func(c fiber.Ctx) error {
-			err := handler.GetPersonByID(c)
-			if err != nil && strings.Contains(err.Error(), "invalid UUID") {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid UUID format",
-				})
-			}
-			return err
-		})
+		mockRepo.On("GetPersons", mock.Anything, mock.MatchedBy(func(filter map[string]any) bool {
+			position, ok := filter[personrepo.FilterCursor].(personrepo.Cursor)
+			return ok && position.ID == cursor.ID && position.Values[0].(time.Time).Equal(createdAt)
+		}), 0, 2).Return(page[:1], 5, nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/persons/not-a-uuid", nil)
-		resp, err := app.Test(req)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?limit=2&offset=40&cursor="+cursor.Encode(), nil))
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
-		require.NoError(t, err)
-		assert.Equal(t, "Invalid UUID format", errorResp["error"])
+		result := decode(t, resp)
+		assert.NotContains(t, result, "next_cursor", "partial page should not have next cursor")
+		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should return 404 when person not found", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		testID := uuid.New()
-		notFoundErr := errors.New("person not found: " + testID.String())
+	t.Run("should skip total count", func(t *testing.T) {
+		app, mockRepo := setupTest()
 
-		mockRepo.On("GetByID", mock.Anything, testID).Return(nil, notFoundErr)
-
-		app.Get("/persons/:id", func(c fiber.Ctx) error {
-			err := handler.GetPersonByID(c)
-			if err != nil && strings.Contains(err.Error(), "not found") {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error": "Person not found",
-				})
-			}
-			return err
-		})
+		mockRepo.On("GetPersons", mock.Anything, map[string]any{personrepo.FilterSkipCount: true}, 0, 10).Return(page, -1, nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/persons/"+testID.String(), nil)
-		resp, err := app.Test(req)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?skip_count=true", nil))
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, decode(t, resp), "total")
 		mockRepo.AssertExpectations(t)
-
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
-		require.NoError(t, err)
-		assert.Equal(t, "Person not found", errorResp["error"])
 	})
 
-	t.Run("should return 500 on repository error", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		testID := uuid.New()
-		dbErr := errors.New("database connection error")
-
-		mockRepo.On("GetByID", mock.Anything, testID).Return(nil, dbErr)
+	t.Run("should pass parsed sort and issue cursor for it", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		age := 30
+		sorted := []*entities.Person{
+			{ID: uuid.New(), Name: "Anna", Surname: "Ivanova", CreatedAt: createdAt},
+			{ID: uuid.New(), Name: "Ivan", Surname: "Ivanova", Age: &age, CreatedAt: createdAt},
+		}
+		expectedSort := []personrepo.SortField{{Column: "surname"}, {Column: "age", Desc: true}, {Column: "created_at"}}
 
-		app.Get("/persons/:id", func(c fiber.Ctx) error {
-			err := handler.GetPersonByID(c)
-			if err != nil && !strings.Contains(err.Error(), "not found") {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to get person",
-				})
-			}
-			return err
-		})
+		mockRepo.On("GetPersons", mock.Anything, map[string]any{personrepo.FilterSort: expectedSort}, 0, 2).Return(sorted, 2, nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/persons/"+testID.String(), nil)
-		resp, err := app.Test(req)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?limit=2&sort=surname,-age,created_at", nil))
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
 		mockRepo.AssertExpectations(t)
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
+		token, ok := decode(t, resp)["next_cursor"].(string)
+		require.True(t, ok)
+
+		cursor, err := personrepo.DecodeCursor(token, expectedSort)
 		require.NoError(t, err)
-		assert.Equal(t, "Failed to get person", errorResp["error"])
+		assert.Equal(t, []any{"Ivanova", 30, createdAt}, cursor.Values)
+
+		_, err = personrepo.DecodeCursor(token, personrepo.DefaultSort)
+		assert.ErrorIs(t, err, personrepo.ErrInvalidCursor, "cursor must not be reused with another sort")
 	})
 
-	t.Run("should handle JSON marshaling error", func(t *testing.T) {
-		testID := uuid.New()
-		testPerson := createTestPerson()
-		testPerson.ID = testID
+	t.Run("should reject invalid sort", func(t *testing.T) {
+		for _, sort := range []string{"id", "-patronymic", "age,-age", "name,,age", ","} {
+			app, mockRepo := setupTest()
 
-		mockPersonService := &MockPersonService{}
-		mockAgeService := &MockAgeService{}
-		mockGenderService := &MockGenderService{}
-		mockNationalityService := &MockNationalityService{}
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?sort="+sort, nil))
 
-		mockPeopleServices := &MockPeopleServices{
-			mockPersonService:      mockPersonService,
-			mockAgeService:         mockAgeService,
-			mockGenderService:      mockGenderService,
-			mockNationalityService: mockNationalityService,
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, sort)
+			mockRepo.AssertNotCalled(t, "GetPersons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should reject malformed cursor and skip_count", func(t *testing.T) {
+		for _, query := range []string{"cursor=not-a-cursor", "cursor=e30", "skip_count=sometimes"} {
+			app, mockRepo := setupTest()
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?"+query, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+			mockRepo.AssertNotCalled(t, "GetPersons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestGetPersonsRangeFilters(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Get("/persons", handler.GetPersons)
+		return app, mockPersonRepository
+	}
+
+	t.Run("should translate range and null filters", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		expected := map[string]any{
+			personrepo.FilterAgeMin:                    18,
+			personrepo.FilterAgeMax:                    65,
+			personrepo.FilterGenderProbabilityMin:      0.9,
+			personrepo.FilterNationalityProbabilityMin: 0.5,
+			personrepo.FilterCreatedAfter:              time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			personrepo.FilterCreatedBefore:             time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC),
+			personrepo.FilterUpdatedAfter:              time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			personrepo.FilterMissing:                   []string{"age", "gender"},
+			personrepo.FilterPresent:                   []string{"nationality"},
+		}
+		mockRepo.On("GetPersons", mock.Anything, expected, 0, 10).Return([]*entities.Person{}, 0, nil)
+
+		query := "age_min=18&age_max=65&gender_probability_min=0.9&nationality_probability_min=0.5" +
+			"&created_after=2025-01-01&created_before=2025-02-01T12:00:00%2B03:00&updated_after=2025-01-15T00:00:00Z" +
+			"&missing=age,gender&present=nationality"
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?"+query, nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should translate multi-value and match-mode filters", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		expected := map[string]any{
+			"name":             "Dmit",
+			"nationality":      []string{"RU", "UA", "BY"},
+			"gender":           "male",
+			"surname_prefix":   []string{"Iva", "Pet"},
+			"patronymic_exact": []string{"Ivanovich"},
+			"nationality_not":  []string{"KZ"},
+		}
+		mockRepo.On("GetPersons", mock.Anything, expected, 0, 10).Return([]*entities.Person{}, 0, nil)
+
+		query := "name=Dmit&nationality=RU,%20UA,BY&gender=male&surname_prefix=Iva,Pet" +
+			"&patronymic_exact=Ivanovich&nationality_not=KZ&unknown_prefix=x"
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?"+query, nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should pass phonetic mode with name filters", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		expected := map[string]any{
+			"name":                    "Dmitriy",
+			personrepo.FilterPhonetic: true,
+		}
+		mockRepo.On("GetPersons", mock.Anything, expected, 0, 10).Return([]*entities.Person{}, 0, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?name=Dmitriy&phonetic=true", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject malformed values", func(t *testing.T) {
+		queries := []string{
+			"age_min=abc",
+			"age_max=-1",
+			"age_min=40&age_max=30",
+			"gender_probability_min=1.5",
+			"nationality_probability_min=NaN",
+			"created_after=yesterday",
+			"created_after=2025-02-01&created_before=2025-01-01",
+			"updated_after=2025-13-01",
+			"missing=name",
+			"present=unknown",
+			"missing=age&present=age",
+			"phonetic=yes",
+			"nationality=,",
+			"gender_not=" + strings.Repeat("x,", 51),
+		}
+		for _, query := range queries {
+			app, mockRepo := setupTest()
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?"+query, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+			mockRepo.AssertNotCalled(t, "GetPersons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestSearchPersons(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Get("/persons/search", handler.SearchPersons)
+		app.Get("/persons/:id", handler.GetPersonByID)
+		return app, mockPersonRepository
+	}
+
+	t.Run("should return ranked results with score", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		results := []*entities.PersonSearchResult{
+			{Person: entities.Person{ID: uuid.New(), Name: "Dmitry", Surname: "Ivanov"}, Score: 0.54},
+			{Person: entities.Person{ID: uuid.New(), Name: "Dmitrii", Surname: "Petrov"}, Score: 0.41},
+		}
+
+		mockRepo.On("SearchPersons", mock.Anything, "Dmitriy", 10, false).Return(results, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/search?q=%20Dmitriy%20", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Data []map[string]any `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Len(t, body.Data, 2)
+		assert.Equal(t, "Dmitry", body.Data[0]["name"])
+		assert.InDelta(t, 0.54, body.Data[0]["score"], 1e-9)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should cap limit", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		mockRepo.On("SearchPersons", mock.Anything, "Ivan", 100, false).Return([]*entities.PersonSearchResult{}, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/search?q=Ivan&limit=1000", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject empty or too long query", func(t *testing.T) {
+		for _, query := range []string{"", "q=", "q=%20%20", "q=" + strings.Repeat("a", 101)} {
+			app, mockRepo := setupTest()
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/search?"+query, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+			mockRepo.AssertNotCalled(t, "SearchPersons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should search by phonetic keys", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("SearchPersons", mock.Anything, "Dmitriy", 10, true).Return([]*entities.PersonSearchResult{}, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/search?q=Dmitriy&phonetic=true", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, true, body["phonetic"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject malformed phonetic", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/search?q=Ivan&phonetic=maybe", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "SearchPersons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 500 on repository error", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		mockRepo.On("SearchPersons", mock.Anything, "Ivan", 10, false).Return(nil, errors.New("database error"))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/search?q=Ivan", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestBulkCreatePersons(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Post("/persons/bulk", handler.BulkCreatePersons)
+		return app, mockPersonRepository
+	}
+
+	type bulkResponse struct {
+		Created int `json:"created"`
+		Failed  int `json:"failed"`
+		Results []struct {
+			Index int        `json:"index"`
+			ID    *uuid.UUID `json:"id"`
+			Error string     `json:"error"`
+		} `json:"results"`
+	}
+
+	assignIDs := func(args mock.Arguments) {
+		for _, person := range args.Get(1).([]*entities.Person) {
+			person.ID = uuid.New()
+		}
+	}
+
+	bulkRequest := func(body, contentType, query string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/persons/bulk"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+
+	t.Run("should create all persons from JSON array", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("CreatePersons", mock.Anything, mock.MatchedBy(func(persons []*entities.Person) bool {
+			return len(persons) == 2 && persons[0].Name == "Ivan" && persons[1].Name == "Anna"
+		})).Run(assignIDs).Return(nil)
+
+		body := `[{"name": "Ivan", "surname": "Ivanov"}, {"name": "Anna", "surname": "Petrova", "age": 30}]`
+		resp, err := app.Test(bulkRequest(body, "application/json", ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var result bulkResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, 2, result.Created)
+		assert.Zero(t, result.Failed)
+		require.Len(t, result.Results, 2)
+		assert.NotNil(t, result.Results[0].ID)
+		assert.NotNil(t, result.Results[1].ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should accept NDJSON", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("CreatePersons", mock.Anything, mock.MatchedBy(func(persons []*entities.Person) bool {
+			return len(persons) == 2
+		})).Run(assignIDs).Return(nil)
+
+		body := "{\"name\": \"Ivan\", \"surname\": \"Ivanov\"}\n\n{\"name\": \"Anna\", \"surname\": \"Petrova\"}\n"
+		resp, err := app.Test(bulkRequest(body, "application/x-ndjson", ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject the whole batch on validation errors in atomic mode", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		body := `[{"name": "Ivan", "surname": "Ivanov"}, {"name": ""}, {"name": "Anna", "surname": "Petrova", "nationality": "RUS"}]`
+		resp, err := app.Test(bulkRequest(body, "application/json", ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		var result bulkResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Zero(t, result.Created)
+		assert.Equal(t, 3, result.Failed)
+		assert.Empty(t, result.Results[0].Error)
+		assert.Contains(t, result.Results[1].Error, "name and surname are required")
+		assert.Contains(t, result.Results[2].Error, "nationality")
+		mockRepo.AssertNotCalled(t, "CreatePersons", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should insert valid chunks in partial mode", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("CreatePersons", mock.Anything, mock.MatchedBy(func(persons []*entities.Person) bool {
+			return len(persons) == 1 && persons[0].Name == "Ivan"
+		})).Run(assignIDs).Return(nil)
+		mockRepo.On("CreatePersons", mock.Anything, mock.MatchedBy(func(persons []*entities.Person) bool {
+			return len(persons) == 1 && persons[0].Name == "Anna"
+		})).Return(errors.New("database error"))
+
+		body := `[{"name": "Ivan", "surname": "Ivanov"}, {"name": "Oleg", "surname": "Olegov", "age": "old"}, {"name": "Anna", "surname": "Petrova"}]`
+		resp, err := app.Test(bulkRequest(body, "application/json", "?mode=partial&chunk_size=1"))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+
+		var result bulkResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, 2, result.Failed)
+		assert.NotNil(t, result.Results[0].ID)
+		assert.Contains(t, result.Results[1].Error, "invalid person")
+		assert.Equal(t, "failed to create persons", result.Results[2].Error)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should report duplicate ids", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		id := uuid.New()
+
+		body := fmt.Sprintf(`[{"id": %q, "name": "Ivan", "surname": "Ivanov"}, {"id": %q, "name": "Anna", "surname": "Petrova"}]`, id, id)
+		resp, err := app.Test(bulkRequest(body, "application/json", ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "CreatePersons", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 409 when a person already exists", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("CreatePersons", mock.Anything, mock.Anything).Return(errors.New("person already exists: key (id)"))
+
+		resp, err := app.Test(bulkRequest(`[{"name": "Ivan", "surname": "Ivanov"}]`, "application/json", ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("should reject malformed requests", func(t *testing.T) {
+		cases := []struct {
+			body        string
+			contentType string
+			query       string
+		}{
+			{`{"name": "Ivan"}`, "application/json", ""},
+			{`[]`, "application/json", ""},
+			{`[{"name": "Ivan", "surname": "Ivanov"}`, "application/json", ""},
+			{`name,surname`, "text/csv", ""},
+			{`[{"name": "Ivan", "surname": "Ivanov"}]`, "application/json", "?mode=best-effort"},
+			{`[{"name": "Ivan", "surname": "Ivanov"}]`, "application/json", "?mode=partial&chunk_size=0"},
+		}
+		for _, tc := range cases {
+			app, mockRepo := setupTest()
+
+			resp, err := app.Test(bulkRequest(tc.body, tc.contentType, tc.query))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tc.body+tc.query)
+			mockRepo.AssertNotCalled(t, "CreatePersons", mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestImportHandlers(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository, *MockImportsRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+		mockPersonRepository := &MockPersonRepository{}
+		mockImportsRepository := &MockImportsRepository{}
+		mockRepos := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository:  mockPersonRepository,
+				mockImportsRepository: mockImportsRepository,
+			},
+		}
+		handler := handlers.NewImportHandler(&MockAPI{mockPeopleServices: &MockPeopleServices{}}, mockRepos)
+		app.Post("/imports", handler.CreateImport)
+		app.Get("/imports/:id", handler.GetImport)
+		app.Get("/imports/:id/report", handler.GetImportReport)
+		return app, mockPersonRepository, mockImportsRepository
+	}
+
+	upload := func(t *testing.T, fileName, content string, fields map[string]string) *http.Request {
+		t.Helper()
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		if fileName != "" {
+			part, err := writer.CreateFormFile("file", fileName)
+			require.NoError(t, err)
+			_, err = part.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		for name, value := range fields {
+			require.NoError(t, writer.WriteField(name, value))
+		}
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/imports", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	t.Run("imports CSV file with mapping", func(t *testing.T) {
+		app, mockPersonRepo, mockImportsRepo := setupTest()
+		mockPersonRepo.On("CreatePersons", mock.Anything, mock.MatchedBy(func(persons []*entities.Person) bool {
+			return len(persons) == 1 && persons[0].Name == "Ivan" && persons[0].Surname == "Ivanov"
+		})).Return(nil)
+		mockImportsRepo.On("CreateImport", mock.Anything, mock.Anything).Return(nil)
+
+		req := upload(t, "people.csv", "Имя;Фамилия\nIvan;Ivanov\n;Petrov\n", map[string]string{
+			"mapping":   `{"Имя": "name", "Фамилия": "surname"}`,
+			"delimiter": ";",
+		})
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		var result entities.PersonImport
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, "csv", result.Format)
+		assert.Equal(t, "people.csv", result.FileName)
+		assert.Equal(t, 2, result.Total)
+		assert.Equal(t, 1, result.Imported)
+		assert.Equal(t, 1, result.Failed)
+		assert.Equal(t, "/api/v1/imports/"+result.ID.String(), resp.Header.Get("Location"))
+		mockPersonRepo.AssertExpectations(t)
+	})
+
+	t.Run("dry run does not save persons", func(t *testing.T) {
+		app, mockPersonRepo, mockImportsRepo := setupTest()
+		mockImportsRepo.On("CreateImport", mock.Anything, mock.MatchedBy(func(result *entities.PersonImport) bool {
+			return result.DryRun && result.Valid == 1 && result.Imported == 0
+		})).Return(nil)
+
+		req := upload(t, "people.ndjson", `{"name":"Ivan","surname":"Ivanov"}`+"\n", map[string]string{"dry_run": "true"})
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		mockPersonRepo.AssertNotCalled(t, "CreatePersons", mock.Anything, mock.Anything)
+		mockImportsRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			fileName string
+			fields   map[string]string
+		}{
+			{name: "missing file"},
+			{name: "unknown format", fileName: "people.xlsx"},
+			{name: "invalid mapping", fileName: "people.csv", fields: map[string]string{"mapping": "[1]"}},
+			{name: "unknown field", fileName: "people.csv", fields: map[string]string{"mapping": `{"name": "nickname"}`}},
+			{name: "invalid delimiter", fileName: "people.csv", fields: map[string]string{"delimiter": ";;"}},
+			{name: "invalid dry_run", fileName: "people.csv", fields: map[string]string{"dry_run": "maybe"}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				app, _, _ := setupTest()
+
+				resp, err := app.Test(upload(t, tc.fileName, "name,surname\nIvan,Ivanov\n", tc.fields))
+				require.NoError(t, err)
+				defer func() { _ = resp.Body.Close() }()
+
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+		}
+	})
+
+	t.Run("downloads error report", func(t *testing.T) {
+		app, _, mockImportsRepo := setupTest()
+		importID := uuid.New()
+		mockImportsRepo.On("GetImport", mock.Anything, importID).Return(&entities.PersonImport{
+			ID:     importID,
+			Failed: 1,
+			Errors: []entities.PersonImportError{{Row: 3, Field: "age", Message: "must be an integer"}},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/imports/"+importID.String()+"/report", nil)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "row,field,error\n3,age,must be an integer\n", string(body))
+	})
+
+	t.Run("returns not found for unknown import", func(t *testing.T) {
+		app, _, mockImportsRepo := setupTest()
+		importID := uuid.New()
+		mockImportsRepo.On("GetImport", mock.Anything, importID).Return(nil, errors.New("person import not found"))
+
+		req := httptest.NewRequest(http.MethodGet, "/imports/"+importID.String(), nil)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestExportPersons(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Get("/persons/export", handler.ExportPersons)
+		return app, mockPersonRepository
+	}
+
+	persons := []*entities.Person{
+		{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), Name: "Ivan", Surname: "Ivanov"},
+		{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"), Name: "Anna", Surname: "Petrova"},
+	}
+
+	t.Run("should stream CSV with list filters and selected columns", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		mockRepo.On("StreamPersons", mock.Anything, mock.MatchedBy(func(filter map[string]any) bool {
+			return filter["nationality"] != nil && filter["age_min"] == 30 && filter[personrepo.FilterSort] != nil
+		})).Return(persons, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet,
+			"/persons/export?nationality=RU&age_min=30&sort=surname&columns=id,surname", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+		assert.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), `filename="persons.csv"`)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "id,surname\n"+
+			"550e8400-e29b-41d4-a716-446655440000,Ivanov\n"+
+			"550e8400-e29b-41d4-a716-446655440001,Petrova\n", string(body))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should stream gzip-compressed NDJSON", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		mockRepo.On("StreamPersons", mock.Anything, mock.Anything).Return(persons, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/export?format=ndjson&gzip=true&columns=name", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/gzip", resp.Header.Get(fiber.HeaderContentType))
+		assert.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), `filename="persons.ndjson.gz"`)
+
+		reader, err := gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, `{"name":"Ivan"}`+"\n"+`{"name":"Anna"}`+"\n", string(body))
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"format=xlsx", "columns=name,phonetic", "gzip=maybe", "sort=unknown", "age_min=abc"} {
+			app, mockRepo := setupTest()
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/export?"+query, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+			mockRepo.AssertNotCalled(t, "StreamPersons", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should require admin to include deleted persons", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/export?include_deleted=true", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "StreamPersons", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFindDuplicates(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Get("/persons/duplicates", handler.FindDuplicates)
+		return app, mockPersonRepository
+	}
+
+	t.Run("should return clusters of duplicates", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		first := &entities.Person{ID: uuid.New(), Name: "Dmitriy", Surname: "Ivanov"}
+		second := &entities.Person{ID: uuid.New(), Name: "Дмитрий", Surname: "Иванов"}
+
+		mockRepo.On("FindDuplicatePairs", mock.Anything, 0.8, mock.Anything).Return([]entities.PersonDuplicatePair{
+			{PersonID: first.ID, DuplicateID: second.ID, Score: 0.9},
+		}, nil)
+		mockRepo.On("GetPersons", mock.Anything, mock.Anything, 0, 2).Return([]*entities.Person{first, second}, -1, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/duplicates?min_score=0.8", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Data     []entities.PersonDuplicateCluster `json:"data"`
+			MinScore float64                           `json:"min_score"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Len(t, body.Data, 1)
+		assert.InDelta(t, 0.9, body.Data[0].Score, 1e-9)
+		assert.Len(t, body.Data[0].Persons, 2)
+		assert.InDelta(t, 0.8, body.MinScore, 1e-9)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid min_score", func(t *testing.T) {
+		for _, value := range []string{"abc", "-0.1", "1.5"} {
+			app, mockRepo := setupTest()
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/duplicates?min_score="+value, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, value)
+			mockRepo.AssertNotCalled(t, "FindDuplicatePairs", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestMergePersons(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Post("/persons/merge", handler.MergePersons)
+		return app, mockPersonRepository
+	}
+
+	mergeRequest := func(body string, ifMatch string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/persons/merge", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		}
+		return req
+	}
+
+	survivorID := uuid.New()
+	mergedID := uuid.New()
+
+	t.Run("should merge persons into the survivor", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		survivor := &entities.Person{ID: survivorID, Name: "Ivan", Surname: "Ivanov", Version: 4}
+
+		mockRepo.On("MergePersons", mock.Anything, survivorID, []uuid.UUID{mergedID}, 3).Return(survivor, nil)
+
+		body := fmt.Sprintf(`{"survivor_id":%q,"ids":[%q,%q,%q]}`, survivorID, survivorID, mergedID, mergedID)
+		resp, err := app.Test(mergeRequest(body, `"3"`))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"4"`, resp.Header.Get(fiber.HeaderETag))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject merge without other persons", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		resp, err := app.Test(mergeRequest(fmt.Sprintf(`{"survivor_id":%q,"ids":[%q]}`, survivorID, survivorID), ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "MergePersons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject missing survivor", func(t *testing.T) {
+		app, _ := setupTest()
+
+		resp, err := app.Test(mergeRequest(fmt.Sprintf(`{"ids":[%q]}`, mergedID), ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("should map repository errors", func(t *testing.T) {
+		cases := []struct {
+			err    error
+			status int
+		}{
+			{fmt.Errorf("%w: id %s", errors.New("person not found"), mergedID), http.StatusNotFound},
+			{personrepo.ErrVersionConflict, http.StatusPreconditionFailed},
+			{errors.New("db error"), http.StatusInternalServerError},
+		}
+		for _, tc := range cases {
+			app, mockRepo := setupTest()
+			mockRepo.On("MergePersons", mock.Anything, survivorID, []uuid.UUID{mergedID}, 0).Return(nil, tc.err)
+
+			resp, err := app.Test(mergeRequest(fmt.Sprintf(`{"survivor_id":%q,"ids":[%q]}`, survivorID, mergedID), ""))
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode, tc.err.Error())
+		}
+	})
+}
+
+func TestExternalReference(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Get("/persons/by-external/:source/:externalId", handler.GetPersonByExternalID)
+		app.Put("/persons/by-external/:source/:externalId", handler.UpsertPersonByExternalID)
+		return app, mockPersonRepository
+	}
+
+	upsertRequest := func(target, body, ifMatch string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		}
+		return req
+	}
+
+	personID := uuid.New()
+	body := `{"name":"Ivan","surname":"Ivanov"}`
+
+	t.Run("should create person linked to external id", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("UpsertByExternalID", mock.Anything, "crm", "A/42", mock.MatchedBy(func(person *entities.Person) bool {
+			return person.Name == "Ivan" && person.Version == 0
+		})).Run(func(args mock.Arguments) {
+			person := args.Get(3).(*entities.Person)
+			person.ID = personID
+			person.Version = 1
+		}).Return(true, nil)
+
+		resp, err := app.Test(upsertRequest("/persons/by-external/crm/A%2F42", body, ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v1/persons/"+personID.String(), resp.Header.Get(fiber.HeaderLocation))
+		assert.Equal(t, `"1"`, resp.Header.Get(fiber.HeaderETag))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should update linked person with expected version", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("UpsertByExternalID", mock.Anything, "crm", "42", mock.MatchedBy(func(person *entities.Person) bool {
+			return person.Version == 2
+		})).Run(func(args mock.Arguments) {
+			args.Get(3).(*entities.Person).Version = 3
+		}).Return(false, nil)
+
+		resp, err := app.Test(upsertRequest("/persons/by-external/crm/42", body, `"2"`))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(fiber.HeaderLocation))
+		assert.Equal(t, `"3"`, resp.Header.Get(fiber.HeaderETag))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should update linked person with wildcard If-Match", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("GetByExternalID", mock.Anything, "crm", "42").Return(&entities.Person{ID: personID, Version: 2}, nil)
+		mockRepo.On("UpsertByExternalID", mock.Anything, "crm", "42", mock.MatchedBy(func(person *entities.Person) bool {
+			return person.Version == 0
+		})).Run(func(args mock.Arguments) {
+			args.Get(3).(*entities.Person).Version = 3
+		}).Return(false, nil)
+
+		resp, err := app.Test(upsertRequest("/persons/by-external/crm/42", body, "*"))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not create person for wildcard If-Match", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("GetByExternalID", mock.Anything, "crm", "42").
+			Return(nil, fmt.Errorf("failed to get person: %w", errors.New("person not found")))
+
+		resp, err := app.Test(upsertRequest("/persons/by-external/crm/42", body, "*"))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "UpsertByExternalID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject invalid references and body", func(t *testing.T) {
+		cases := []struct {
+			target string
+			body   string
+		}{
+			{"/persons/by-external/crm%20system/42", body},
+			{"/persons/by-external/" + strings.Repeat("s", 51) + "/42", body},
+			{"/persons/by-external/crm/%20", body},
+			{"/persons/by-external/crm/" + strings.Repeat("x", 256), body},
+			{"/persons/by-external/crm/42", `{"name":"Ivan"}`},
+			{"/persons/by-external/crm/42", `{invalid`},
+		}
+		for _, tc := range cases {
+			app, mockRepo := setupTest()
+
+			resp, err := app.Test(upsertRequest(tc.target, tc.body, ""))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, tc.target)
+			mockRepo.AssertNotCalled(t, "UpsertByExternalID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should map upsert errors", func(t *testing.T) {
+		cases := []struct {
+			err    error
+			status int
+		}{
+			{personrepo.ErrVersionConflict, http.StatusPreconditionFailed},
+			{fmt.Errorf("%w: id %s", errors.New("person not found"), personID), http.StatusNotFound},
+			{errors.New("db error"), http.StatusInternalServerError},
+		}
+		for _, tc := range cases {
+			app, mockRepo := setupTest()
+			mockRepo.On("UpsertByExternalID", mock.Anything, "crm", "42", mock.Anything).Return(false, tc.err)
+
+			resp, err := app.Test(upsertRequest("/persons/by-external/crm/42", body, ""))
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode, tc.err.Error())
+		}
+	})
+
+	t.Run("should get person by external id", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		person := &entities.Person{ID: personID, Name: "Ivan", Surname: "Ivanov", Version: 5}
+		mockRepo.On("GetByExternalID", mock.Anything, "crm", "A/42").Return(person, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/by-external/crm/A%2F42", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"5"`, resp.Header.Get(fiber.HeaderETag))
+
+		var result entities.Person
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, personID, result.ID)
+	})
+
+	t.Run("should return 404 for unknown external id", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("GetByExternalID", mock.Anything, "crm", "42").
+			Return(nil, fmt.Errorf("failed to get person: %w", errors.New("person not found")))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/by-external/crm/42", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestGetPersonStats(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Get("/persons/stats", handler.GetPersonStats)
+		return app, mockPersonRepository
+	}
+
+	t.Run("should return stats with default options", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		probability := 0.9
+		stats := &entities.PersonStats{
+			Total:                    3,
+			Gender:                   []entities.PersonStatsValueCount{{Value: "male", Count: 2}},
+			AgeBucketWidth:           10,
+			Age:                      []entities.PersonStatsAgeBucket{{From: 20, To: 29, Count: 3}},
+			Coverage:                 []entities.PersonStatsFieldCoverage{{Field: "age", Filled: 3, Ratio: 1}},
+			AverageGenderProbability: &probability,
+		}
+		mockRepo.On("GetStats", mock.Anything, map[string]any{}, personrepo.StatsOptions{
+			TopNationalities: 10,
+			AgeBucketWidth:   10,
+		}).Return(stats, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/stats", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result entities.PersonStats
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, *stats, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should pass list filters and options", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("GetStats", mock.Anything, mock.MatchedBy(func(filter map[string]any) bool {
+			return filter[personrepo.FilterAgeMin] == 18 && filter["gender"] != nil
+		}), personrepo.StatsOptions{TopNationalities: 3, AgeBucketWidth: 5}).Return(&entities.PersonStats{}, nil)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/stats?gender=male&age_min=18&top=3&age_bucket=5", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"top=0", "top=101", "top=abc", "age_bucket=0", "age_bucket=1000", "age_min=abc"} {
+			app, mockRepo := setupTest()
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/stats?"+query, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+			mockRepo.AssertNotCalled(t, "GetStats", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should return 500 on repository error", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		mockRepo.On("GetStats", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/stats", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestGetPersonByID(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository, *handlers.PersonHandler) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(c fiber.Ctx, err error) error {
+				code := fiber.StatusInternalServerError
+
+				if e, ok := err.(*fiber.Error); ok {
+					code = e.Code
+				} else {
+					if errors.Is(err, handlers.ErrPersonNotFound) {
+						code = fiber.StatusNotFound
+					}
+					if strings.Contains(err.Error(), "invalid UUID") {
+						code = fiber.StatusBadRequest
+					}
+				}
+
+				return c.Status(code).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			},
+		})
+
+		mockPersonService := &MockPersonService{}
+		mockAgeService := &MockAgeService{}
+		mockGenderService := &MockGenderService{}
+		mockNationalityService := &MockNationalityService{}
+
+		mockPeopleServices := &MockPeopleServices{
+			mockPersonService:      mockPersonService,
+			mockAgeService:         mockAgeService,
+			mockGenderService:      mockGenderService,
+			mockNationalityService: mockNationalityService,
+		}
+
+		mockAPI := &MockAPI{
+			mockPeopleServices: mockPeopleServices,
+		}
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockPeopleRepositories := &MockPeopleRepositories{
+			mockPersonRepository: mockPersonRepository,
+		}
+
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: mockPeopleRepositories,
+		}
+
+		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
+		return app, mockPersonRepository, handler
+	}
+
+	createTestPerson := func() *entities.Person {
+		now := time.Now()
+		age := 25
+		gender := "male"
+		genderProb := 0.95
+		nationality := "RU"
+		nationProb := 0.90
+		patronymic := "Ivanovich"
+
+		return &entities.Person{
+			ID:                     uuid.New(),
+			Name:                   "Ivan",
+			Surname:                "Petrov",
+			Patronymic:             &patronymic,
+			Age:                    &age,
+			Gender:                 &gender,
+			GenderProbability:      &genderProb,
+			Nationality:            &nationality,
+			NationalityProbability: &nationProb,
+			CreatedAt:              now,
+			UpdatedAt:              now,
+		}
+	}
+
+	t.Run("should return a person when found", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		testPerson := createTestPerson()
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+
+		app.Get("/persons/:id", handler.GetPersonByID)
+
+		req := httptest.NewRequest(http.MethodGet, "/persons/"+testPerson.ID.String(), nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+
+		var returnedPerson entities.Person
+		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		require.NoError(t, err)
+
+		assert.Equal(t, testPerson.ID, returnedPerson.ID)
+		assert.Equal(t, testPerson.Name, returnedPerson.Name)
+		assert.Equal(t, testPerson.Surname, returnedPerson.Surname)
+		assert.Equal(t, *testPerson.Patronymic, *returnedPerson.Patronymic)
+		assert.Equal(t, *testPerson.Age, *returnedPerson.Age)
+		assert.Equal(t, *testPerson.Gender, *returnedPerson.Gender)
+		assert.Equal(t, *testPerson.Nationality, *returnedPerson.Nationality)
+	})
+
+	t.Run("should return 400 for invalid UUID", func(t *testing.T) {
+		app, _, handler := setupTest()
+
+		app.Get("/persons/:id", func(c fiber.Ctx) error {
+			err := handler.GetPersonByID(c)
+			if err != nil && strings.Contains(err.Error(), "invalid UUID") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid UUID format",
+				})
+			}
+			return err
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/persons/not-a-uuid", nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
+		require.NoError(t, err)
+		assert.Equal(t, "Invalid UUID format", errorResp["error"])
+	})
+
+	t.Run("should return 404 when person not found", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		testID := uuid.New()
+		notFoundErr := errors.New("person not found: " + testID.String())
+
+		mockRepo.On("GetByID", mock.Anything, testID).Return(nil, notFoundErr)
+		mockRepo.On("MergedInto", mock.Anything, testID).Return(uuid.Nil, nil)
+
+		app.Get("/persons/:id", func(c fiber.Ctx) error {
+			err := handler.GetPersonByID(c)
+			if err != nil && strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Person not found",
+				})
+			}
+			return err
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/persons/"+testID.String(), nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
+		require.NoError(t, err)
+		assert.Equal(t, "Person not found", errorResp["error"])
+	})
+
+	t.Run("should redirect merged person to the survivor", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		mergedID := uuid.New()
+		survivorID := uuid.New()
+
+		mockRepo.On("GetByID", mock.Anything, mergedID).Return(nil, errors.New("person not found"))
+		mockRepo.On("MergedInto", mock.Anything, mergedID).Return(survivorID, nil)
+
+		app.Get("/persons/:id", handler.GetPersonByID)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/"+strings.ToUpper(mergedID.String())+"?include_deleted=false", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusPermanentRedirect, resp.StatusCode)
+		assert.Equal(t, "/persons/"+survivorID.String()+"?include_deleted=false", resp.Header.Get(fiber.HeaderLocation))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, survivorID.String(), body["merged_into"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return 500 on repository error", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		testID := uuid.New()
+		dbErr := errors.New("database connection error")
+
+		mockRepo.On("GetByID", mock.Anything, testID).Return(nil, dbErr)
+
+		app.Get("/persons/:id", func(c fiber.Ctx) error {
+			err := handler.GetPersonByID(c)
+			if err != nil && !strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to get person",
+				})
+			}
+			return err
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/persons/"+testID.String(), nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
+		require.NoError(t, err)
+		assert.Equal(t, "Failed to get person", errorResp["error"])
+	})
+
+	t.Run("should handle JSON marshaling error", func(t *testing.T) {
+		testID := uuid.New()
+		testPerson := createTestPerson()
+		testPerson.ID = testID
+
+		mockPersonService := &MockPersonService{}
+		mockAgeService := &MockAgeService{}
+		mockGenderService := &MockGenderService{}
+		mockNationalityService := &MockNationalityService{}
+
+		mockPeopleServices := &MockPeopleServices{
+			mockPersonService:      mockPersonService,
+			mockAgeService:         mockAgeService,
+			mockGenderService:      mockGenderService,
+			mockNationalityService: mockNationalityService,
+		}
+
+		mockAPI := &MockAPI{
+			mockPeopleServices: mockPeopleServices,
+		}
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockPersonRepository.On("GetByID", mock.Anything, testID).Return(testPerson, nil)
+
+		mockPeopleRepositories := &MockPeopleRepositories{
+			mockPersonRepository: mockPersonRepository,
+		}
+
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: mockPeopleRepositories,
+		}
+
+		app := fiber.New(fiber.Config{
+			JSONEncoder: func(v interface{}) ([]byte, error) {
+				return nil, errors.New("forced JSON marshal error")
+			},
+		})
+
+		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
+
+		var capturedErr error
+		app.Get("/persons/:id", func(c fiber.Ctx) error {
+			err := handler.GetPersonByID(c)
+			capturedErr = err
+			return err
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/persons/"+testID.String(), nil)
+		resp, _ := app.Test(req)
+
+		assert.NotNil(t, capturedErr)
+		assert.Contains(t, capturedErr.Error(), "failed to send JSON response")
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		mockPersonRepository.AssertExpectations(t)
+	})
+
+	t.Run("should handle person with partial data", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+
+		now := time.Now()
+		partialPerson := &entities.Person{
+			ID:        uuid.New(),
+			Name:      "John",
+			Surname:   "Doe",
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		mockRepo.On("GetByID", mock.Anything, partialPerson.ID).Return(partialPerson, nil)
+
+		app.Get("/persons/:id", handler.GetPersonByID)
+
+		req := httptest.NewRequest(http.MethodGet, "/persons/"+partialPerson.ID.String(), nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+
+		var returnedPerson entities.Person
+		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		require.NoError(t, err)
+
+		assert.Equal(t, partialPerson.ID, returnedPerson.ID)
+		assert.Equal(t, partialPerson.Name, returnedPerson.Name)
+		assert.Equal(t, partialPerson.Surname, returnedPerson.Surname)
+		assert.Nil(t, returnedPerson.Patronymic)
+		assert.Nil(t, returnedPerson.Age)
+		assert.Nil(t, returnedPerson.Gender)
+		assert.Nil(t, returnedPerson.GenderProbability)
+		assert.Nil(t, returnedPerson.Nationality)
+		assert.Nil(t, returnedPerson.NationalityProbability)
+	})
+}
+
+func TestCreatePerson(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository, *handlers.PersonHandler) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(c fiber.Ctx, err error) error {
+				code := fiber.StatusInternalServerError
+				errMsg := "Internal Server Error"
+
+				if fiber.IsChild() {
+					return fiber.DefaultErrorHandler(c, err)
+				}
+
+				if err != nil {
+					if errors.Is(err, handlers.ErrNameSurnameRequired) {
+						code = fiber.StatusBadRequest
+						errMsg = "Name and surname are required"
+					} else if strings.Contains(err.Error(), "invalid request body") {
+						code = fiber.StatusBadRequest
+						errMsg = "Invalid request body"
+					}
+				}
+
+				return c.Status(code).JSON(fiber.Map{
+					"error": errMsg,
+				})
+			},
+		})
+
+		mockPersonService := &MockPersonService{}
+		mockAgeService := &MockAgeService{}
+		mockGenderService := &MockGenderService{}
+		mockNationalityService := &MockNationalityService{}
+
+		mockPeopleServices := &MockPeopleServices{
+			mockPersonService:      mockPersonService,
+			mockAgeService:         mockAgeService,
+			mockGenderService:      mockGenderService,
+			mockNationalityService: mockNationalityService,
+		}
+
+		mockAPI := &MockAPI{
+			mockPeopleServices: mockPeopleServices,
+		}
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockPeopleRepositories := &MockPeopleRepositories{
+			mockPersonRepository: mockPersonRepository,
+		}
+
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: mockPeopleRepositories,
+		}
+
+		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
+		return app, mockPersonRepository, handler
+	}
+
+	t.Run("should successfully create a person", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		app.Post("/persons", handler.CreatePerson)
+
+		personID := uuid.New()
+		personData := map[string]interface{}{
+			"id":      personID.String(),
+			"name":    "John",
+			"surname": "Doe",
+		}
+
+		expectedPerson := &entities.Person{
+			ID:      personID,
+			Name:    "John",
+			Surname: "Doe",
+		}
+
+		mockRepo.On("CreatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+			return p.ID == personID && p.Name == "John" && p.Surname == "Doe"
+		})).Return(nil)
+
+		bodyBytes, err := json.Marshal(personData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var returnedPerson entities.Person
+		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		require.NoError(t, err)
+
+		assert.Equal(t, expectedPerson.ID, returnedPerson.ID)
+		assert.Equal(t, expectedPerson.Name, returnedPerson.Name)
+		assert.Equal(t, expectedPerson.Surname, returnedPerson.Surname)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should generate UUID if not provided", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		app.Post("/persons", handler.CreatePerson)
+
+		personData := map[string]interface{}{
+			"name":    "Jane",
+			"surname": "Smith",
+		}
+
+		var capturedPerson *entities.Person
+		mockRepo.On("CreatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+			capturedPerson = p
+			return p.ID != uuid.Nil && p.Name == "Jane" && p.Surname == "Smith"
+		})).Return(nil)
+
+		bodyBytes, err := json.Marshal(personData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var returnedPerson entities.Person
+		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, uuid.Nil, returnedPerson.ID)
+		assert.Equal(t, "Jane", returnedPerson.Name)
+		assert.Equal(t, "Smith", returnedPerson.Surname)
+
+		mockRepo.AssertExpectations(t)
+		assert.NotEqual(t, uuid.Nil, capturedPerson.ID)
+	})
+
+	t.Run("should return 400 for invalid request body", func(t *testing.T) {
+		app, _, handler := setupTest()
+
+		app.Post("/persons", func(c fiber.Ctx) error {
+			err := handler.CreatePerson(c)
+			if err != nil && strings.Contains(err.Error(), "invalid request body") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid request body",
+				})
+			}
+			return err
+		})
+
+		invalidJSON := `{"name": "John", "surname": "Doe", invalid_json}`
+
+		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader([]byte(invalidJSON)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Invalid request body")
+	})
+
+	t.Run("should return 400 when name is missing", func(t *testing.T) {
+		app, _, handler := setupTest()
+
+		app.Post("/persons", func(c fiber.Ctx) error {
+			err := handler.CreatePerson(c)
+			if errors.Is(err, handlers.ErrNameSurnameRequired) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Name and surname are required",
+				})
+			}
+			return err
+		})
+
+		personData := map[string]interface{}{
+			"surname": "Doe",
+		}
+
+		bodyBytes, err := json.Marshal(personData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Name and surname are required")
+	})
+
+	t.Run("should return 400 when surname is missing", func(t *testing.T) {
+		app, _, handler := setupTest()
+
+		app.Post("/persons", func(c fiber.Ctx) error {
+			err := handler.CreatePerson(c)
+			if errors.Is(err, handlers.ErrNameSurnameRequired) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Name and surname are required",
+				})
+			}
+			return err
+		})
+
+		personData := map[string]interface{}{
+			"name": "John",
+		}
+
+		bodyBytes, err := json.Marshal(personData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Name and surname are required")
+	})
+
+	t.Run("should return 500 when repository fails", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+
+		app.Post("/persons", func(c fiber.Ctx) error {
+			err := handler.CreatePerson(c)
+			if err != nil && strings.Contains(err.Error(), "failed to create person") {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to create person",
+				})
+			}
+			return err
+		})
+
+		personData := map[string]interface{}{
+			"name":    "John",
+			"surname": "Doe",
+		}
+
+		mockRepo.On("CreatePerson", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+		bodyBytes, err := json.Marshal(personData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Failed to create person")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should handle JSON marshaling error on response", func(t *testing.T) {
+		app := fiber.New(fiber.Config{
+			JSONEncoder: func(v interface{}) ([]byte, error) {
+				return nil, errors.New("forced JSON marshal error")
+			},
+		})
+
+		mockPersonService := &MockPersonService{}
+		mockAgeService := &MockAgeService{}
+		mockGenderService := &MockGenderService{}
+		mockNationalityService := &MockNationalityService{}
+
+		mockPeopleServices := &MockPeopleServices{
+			mockPersonService:      mockPersonService,
+			mockAgeService:         mockAgeService,
+			mockGenderService:      mockGenderService,
+			mockNationalityService: mockNationalityService,
 		}
 
 		mockAPI := &MockAPI{
@@ -812,7 +2527,8 @@ func TestGetPersonByID(t *testing.T) {
 		}
 
 		mockPersonRepository := &MockPersonRepository{}
-		mockPersonRepository.On("GetByID", mock.Anything, testID).Return(testPerson, nil)
+
+		mockPersonRepository.On("CreatePerson", mock.Anything, mock.Anything).Return(nil)
 
 		mockPeopleRepositories := &MockPeopleRepositories{
 			mockPersonRepository: mockPersonRepository,
@@ -822,22 +2538,26 @@ func TestGetPersonByID(t *testing.T) {
 			mockPeopleRepositories: mockPeopleRepositories,
 		}
 
-		app := fiber.New(fiber.Config{
-			JSONEncoder: func(v interface{}) ([]byte, error) {
-				return nil, errors.New("forced JSON marshal error")
-			},
-		})
-
 		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
 
+		// Create valid person data
+		personData := map[string]interface{}{
+			"name":    "John",
+			"surname": "Doe",
+		}
+
 		var capturedErr error
-		app.Get("/persons/:id", func(c fiber.Ctx) error {
-			err := handler.GetPersonByID(c)
+		app.Post("/persons", func(c fiber.Ctx) error {
+			err := handler.CreatePerson(c)
 			capturedErr = err
 			return err
 		})
 
-		req := httptest.NewRequest(http.MethodGet, "/persons/"+testID.String(), nil)
+		bodyBytes, err := json.Marshal(personData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
 		resp, _ := app.Test(req)
 
 		assert.NotNil(t, capturedErr)
@@ -846,46 +2566,74 @@ func TestGetPersonByID(t *testing.T) {
 		mockPersonRepository.AssertExpectations(t)
 	})
 
-	t.Run("should handle person with partial data", func(t *testing.T) {
+	t.Run("should create a person with all optional fields", func(t *testing.T) {
 		app, mockRepo, handler := setupTest()
+		app.Post("/persons", handler.CreatePerson)
 
 		now := time.Now()
-		partialPerson := &entities.Person{
-			ID:        uuid.New(),
-			Name:      "John",
-			Surname:   "Doe",
-			CreatedAt: now,
-			UpdatedAt: now,
+		age := 30
+		gender := "female"
+		genderProb := 0.95
+		nationality := "US"
+		nationProb := 0.85
+		patronymic := "Alex"
+
+		personID := uuid.New()
+		personData := map[string]interface{}{
+			"id":                      personID.String(),
+			"name":                    "Jane",
+			"surname":                 "Smith",
+			"patronymic":              patronymic,
+			"age":                     age,
+			"gender":                  gender,
+			"gender_probability":      genderProb,
+			"nationality":             nationality,
+			"nationality_probability": nationProb,
+			"created_at":              now,
+			"updated_at":              now,
 		}
 
-		mockRepo.On("GetByID", mock.Anything, partialPerson.ID).Return(partialPerson, nil)
+		mockRepo.On("CreatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+			return p.ID == personID &&
+				p.Name == "Jane" &&
+				p.Surname == "Smith" &&
+				*p.Patronymic == patronymic &&
+				*p.Age == age &&
+				*p.Gender == gender &&
+				*p.GenderProbability == genderProb &&
+				*p.Nationality == nationality &&
+				*p.NationalityProbability == nationProb
+		})).Return(nil)
 
-		app.Get("/persons/:id", handler.GetPersonByID)
+		bodyBytes, err := json.Marshal(personData)
+		require.NoError(t, err)
 
-		req := httptest.NewRequest(http.MethodGet, "/persons/"+partialPerson.ID.String(), nil)
+		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
-		mockRepo.AssertExpectations(t)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
 		var returnedPerson entities.Person
 		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
 		require.NoError(t, err)
 
-		assert.Equal(t, partialPerson.ID, returnedPerson.ID)
-		assert.Equal(t, partialPerson.Name, returnedPerson.Name)
-		assert.Equal(t, partialPerson.Surname, returnedPerson.Surname)
-		assert.Nil(t, returnedPerson.Patronymic)
-		assert.Nil(t, returnedPerson.Age)
-		assert.Nil(t, returnedPerson.Gender)
-		assert.Nil(t, returnedPerson.GenderProbability)
-		assert.Nil(t, returnedPerson.Nationality)
-		assert.Nil(t, returnedPerson.NationalityProbability)
+		assert.Equal(t, personID, returnedPerson.ID)
+		assert.Equal(t, "Jane", returnedPerson.Name)
+		assert.Equal(t, "Smith", returnedPerson.Surname)
+		assert.Equal(t, patronymic, *returnedPerson.Patronymic)
+		assert.Equal(t, age, *returnedPerson.Age)
+		assert.Equal(t, gender, *returnedPerson.Gender)
+		assert.Equal(t, genderProb, *returnedPerson.GenderProbability)
+		assert.Equal(t, nationality, *returnedPerson.Nationality)
+		assert.Equal(t, nationProb, *returnedPerson.NationalityProbability)
+
+		mockRepo.AssertExpectations(t)
 	})
 }
 
-func TestCreatePerson(t *testing.T) {
+func TestUpdatePerson(t *testing.T) {
 	setupTest := func() (*fiber.App, *MockPersonRepository, *handlers.PersonHandler) {
 		app := fiber.New(fiber.Config{
 			ErrorHandler: func(c fiber.Ctx, err error) error {
@@ -900,9 +2648,15 @@ func TestCreatePerson(t *testing.T) {
 					if errors.Is(err, handlers.ErrNameSurnameRequired) {
 						code = fiber.StatusBadRequest
 						errMsg = "Name and surname are required"
+					} else if errors.Is(err, handlers.ErrPersonNotFound) {
+						code = fiber.StatusNotFound
+						errMsg = "Person not found"
 					} else if strings.Contains(err.Error(), "invalid request body") {
 						code = fiber.StatusBadRequest
 						errMsg = "Invalid request body"
+					} else if strings.Contains(err.Error(), "invalid UUID") {
+						code = fiber.StatusBadRequest
+						errMsg = "Invalid UUID format"
 					}
 				}
 
@@ -933,227 +2687,304 @@ func TestCreatePerson(t *testing.T) {
 			mockPersonRepository: mockPersonRepository,
 		}
 
-		mockRepositories := &MockRepositories{
-			mockPeopleRepositories: mockPeopleRepositories,
-		}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: mockPeopleRepositories,
+		}
+
+		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
+		return app, mockPersonRepository, handler
+	}
+
+	createTestPerson := func() *entities.Person {
+		now := time.Now()
+		age := 25
+		gender := "male"
+		genderProb := 0.95
+		nationality := "RU"
+		nationProb := 0.90
+		patronymic := "Ivanovich"
+
+		return &entities.Person{
+			ID:                     uuid.New(),
+			Name:                   "Ivan",
+			Surname:                "Petrov",
+			Patronymic:             &patronymic,
+			Age:                    &age,
+			Gender:                 &gender,
+			GenderProbability:      &genderProb,
+			Nationality:            &nationality,
+			NationalityProbability: &nationProb,
+			CreatedAt:              now,
+			UpdatedAt:              now,
+		}
+	}
+
+	t.Run("should successfully update a person", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		personID := uuid.New()
+
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+
+		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+			return p.ID == personID &&
+				p.Name == "John" &&
+				p.Surname == "Smith"
+		})).Return(nil)
+
+		app.Put("/persons/:id", handler.UpdatePerson)
+
+		updateData := map[string]interface{}{
+			"name":    "John",
+			"surname": "Smith",
+		}
+
+		bodyBytes, err := json.Marshal(updateData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var returnedPerson entities.Person
+		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		require.NoError(t, err)
+
+		assert.Equal(t, personID, returnedPerson.ID)
+		assert.Equal(t, "John", returnedPerson.Name)
+		assert.Equal(t, "Smith", returnedPerson.Surname)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 for invalid UUID", func(t *testing.T) {
+		app, _, handler := setupTest()
+
+		app.Put("/persons/:id", handler.UpdatePerson)
+
+		updateData := map[string]interface{}{
+			"name":    "John",
+			"surname": "Smith",
+		}
+
+		bodyBytes, err := json.Marshal(updateData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/persons/not-a-uuid", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
 
-		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
-		return app, mockPersonRepository, handler
-	}
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	t.Run("should successfully create a person", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		app.Post("/persons", handler.CreatePerson)
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
+		require.NoError(t, err)
+		assert.Equal(t, "Invalid UUID format", errorResp["error"])
+	})
 
+	t.Run("should return 404 when person not found", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
 		personID := uuid.New()
-		personData := map[string]interface{}{
-			"id":      personID.String(),
-			"name":    "John",
-			"surname": "Doe",
-		}
 
-		expectedPerson := &entities.Person{
-			ID:      personID,
-			Name:    "John",
-			Surname: "Doe",
-		}
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(false, nil)
 
-		mockRepo.On("CreatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
-			return p.ID == personID && p.Name == "John" && p.Surname == "Doe"
-		})).Return(nil)
+		app.Put("/persons/:id", handler.UpdatePerson)
 
-		bodyBytes, err := json.Marshal(personData)
+		updateData := map[string]interface{}{
+			"name":    "John",
+			"surname": "Smith",
+		}
+
+		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
 
-		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 
-		var returnedPerson entities.Person
-		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
 		require.NoError(t, err)
-
-		assert.Equal(t, expectedPerson.ID, returnedPerson.ID)
-		assert.Equal(t, expectedPerson.Name, returnedPerson.Name)
-		assert.Equal(t, expectedPerson.Surname, returnedPerson.Surname)
+		assert.Equal(t, "Person not found", errorResp["error"])
 
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should generate UUID if not provided", func(t *testing.T) {
+	t.Run("should return 400 for invalid request body", func(t *testing.T) {
 		app, mockRepo, handler := setupTest()
-		app.Post("/persons", handler.CreatePerson)
+		personID := uuid.New()
 
-		personData := map[string]interface{}{
-			"name":    "Jane",
-			"surname": "Smith",
-		}
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
 
-		var capturedPerson *entities.Person
-		mockRepo.On("CreatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
-			capturedPerson = p
-			return p.ID != uuid.Nil && p.Name == "Jane" && p.Surname == "Smith"
-		})).Return(nil)
+		app.Put("/persons/:id", handler.UpdatePerson)
 
-		bodyBytes, err := json.Marshal(personData)
-		require.NoError(t, err)
+		invalidJSON := `{"name": "John", "surname": "Smith", invalid_json}`
 
-		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader([]byte(invalidJSON)))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-		var returnedPerson entities.Person
-		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
 		require.NoError(t, err)
-
-		assert.NotEqual(t, uuid.Nil, returnedPerson.ID)
-		assert.Equal(t, "Jane", returnedPerson.Name)
-		assert.Equal(t, "Smith", returnedPerson.Surname)
+		assert.Equal(t, "Invalid request body", errorResp["error"])
 
 		mockRepo.AssertExpectations(t)
-		assert.NotEqual(t, uuid.Nil, capturedPerson.ID)
 	})
 
-	t.Run("should return 400 for invalid request body", func(t *testing.T) {
-		app, _, handler := setupTest()
+	t.Run("should return 400 when name is missing", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		personID := uuid.New()
 
-		app.Post("/persons", func(c fiber.Ctx) error {
-			err := handler.CreatePerson(c)
-			if err != nil && strings.Contains(err.Error(), "invalid request body") {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid request body",
-				})
-			}
-			return err
-		})
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
 
-		invalidJSON := `{"name": "John", "surname": "Doe", invalid_json}`
+		app.Put("/persons/:id", handler.UpdatePerson)
 
-		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader([]byte(invalidJSON)))
+		updateData := map[string]interface{}{
+			"surname": "Smith",
+		}
+
+		bodyBytes, err := json.Marshal(updateData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-		body, err := io.ReadAll(resp.Body)
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
 		require.NoError(t, err)
-		assert.Contains(t, string(body), "Invalid request body")
+		assert.Equal(t, "Name and surname are required", errorResp["error"])
+
+		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should return 400 when name is missing", func(t *testing.T) {
-		app, _, handler := setupTest()
+	t.Run("should return 400 when surname is missing", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		personID := uuid.New()
 
-		app.Post("/persons", func(c fiber.Ctx) error {
-			err := handler.CreatePerson(c)
-			if errors.Is(err, handlers.ErrNameSurnameRequired) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Name and surname are required",
-				})
-			}
-			return err
-		})
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
 
-		personData := map[string]interface{}{
-			"surname": "Doe",
+		app.Put("/persons/:id", handler.UpdatePerson)
+
+		updateData := map[string]interface{}{
+			"name": "John",
 		}
 
-		bodyBytes, err := json.Marshal(personData)
+		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
 
-		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-		body, err := io.ReadAll(resp.Body)
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
 		require.NoError(t, err)
-		assert.Contains(t, string(body), "Name and surname are required")
+		assert.Equal(t, "Name and surname are required", errorResp["error"])
+
+		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should return 400 when surname is missing", func(t *testing.T) {
-		app, _, handler := setupTest()
+	t.Run("should return 500 when ExistsByID fails", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		personID := uuid.New()
 
-		app.Post("/persons", func(c fiber.Ctx) error {
-			err := handler.CreatePerson(c)
-			if errors.Is(err, handlers.ErrNameSurnameRequired) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Name and surname are required",
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(false, errors.New("database error"))
+
+		app.Put("/persons/:id", func(c fiber.Ctx) error {
+			err := handler.UpdatePerson(c)
+			if err != nil && strings.Contains(err.Error(), "failed to check if person exists") {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to check if person exists",
 				})
 			}
 			return err
 		})
 
-		personData := map[string]interface{}{
-			"name": "John",
+		updateData := map[string]interface{}{
+			"name":    "John",
+			"surname": "Smith",
 		}
 
-		bodyBytes, err := json.Marshal(personData)
+		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
 
-		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 
-		body, err := io.ReadAll(resp.Body)
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
 		require.NoError(t, err)
-		assert.Contains(t, string(body), "Name and surname are required")
+		assert.Equal(t, "Failed to check if person exists", errorResp["error"])
+
+		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should return 500 when repository fails", func(t *testing.T) {
+	t.Run("should return 500 when UpdatePerson fails", func(t *testing.T) {
 		app, mockRepo, handler := setupTest()
+		personID := uuid.New()
 
-		app.Post("/persons", func(c fiber.Ctx) error {
-			err := handler.CreatePerson(c)
-			if err != nil && strings.Contains(err.Error(), "failed to create person") {
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+
+		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+			return p.ID == personID && p.Name == "John" && p.Surname == "Smith"
+		})).Return(errors.New("database error"))
+
+		app.Put("/persons/:id", func(c fiber.Ctx) error {
+			err := handler.UpdatePerson(c)
+			if err != nil && strings.Contains(err.Error(), "failed to update person") {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to create person",
+					"error": "Failed to update person",
 				})
 			}
 			return err
 		})
 
-		personData := map[string]interface{}{
+		updateData := map[string]interface{}{
 			"name":    "John",
-			"surname": "Doe",
+			"surname": "Smith",
 		}
 
-		mockRepo.On("CreatePerson", mock.Anything, mock.Anything).Return(errors.New("database error"))
-
-		bodyBytes, err := json.Marshal(personData)
+		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
 
-		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 
-		body, err := io.ReadAll(resp.Body)
+		var errorResp map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&errorResp)
 		require.NoError(t, err)
-		assert.Contains(t, string(body), "Failed to create person")
+		assert.Equal(t, "Failed to update person", errorResp["error"])
 
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should handle JSON marshaling error on response", func(t *testing.T) {
-		app := fiber.New(fiber.Config{
-			JSONEncoder: func(v interface{}) ([]byte, error) {
-				return nil, errors.New("forced JSON marshal error")
-			},
-		})
+	t.Run("should handle JSON marshaling error", func(t *testing.T) {
+		personID := uuid.New()
 
 		mockPersonService := &MockPersonService{}
 		mockAgeService := &MockAgeService{}
@@ -1172,8 +3003,8 @@ func TestCreatePerson(t *testing.T) {
 		}
 
 		mockPersonRepository := &MockPersonRepository{}
-
-		mockPersonRepository.On("CreatePerson", mock.Anything, mock.Anything).Return(nil)
+		mockPersonRepository.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+		mockPersonRepository.On("UpdatePerson", mock.Anything, mock.Anything).Return(nil)
 
 		mockPeopleRepositories := &MockPeopleRepositories{
 			mockPersonRepository: mockPersonRepository,
@@ -1183,25 +3014,30 @@ func TestCreatePerson(t *testing.T) {
 			mockPeopleRepositories: mockPeopleRepositories,
 		}
 
-		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
+		app := fiber.New(fiber.Config{
+			JSONEncoder: func(v interface{}) ([]byte, error) {
+				return nil, errors.New("forced JSON marshal error")
+			},
+		})
 
-		// Create valid person data
-		personData := map[string]interface{}{
-			"name":    "John",
-			"surname": "Doe",
-		}
+		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
 
 		var capturedErr error
-		app.Post("/persons", func(c fiber.Ctx) error {
-			err := handler.CreatePerson(c)
+		app.Put("/persons/:id", func(c fiber.Ctx) error {
+			err := handler.UpdatePerson(c)
 			capturedErr = err
 			return err
 		})
 
-		bodyBytes, err := json.Marshal(personData)
+		updateData := map[string]interface{}{
+			"name":    "John",
+			"surname": "Smith",
+		}
+
+		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
 
-		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		resp, _ := app.Test(req)
 
@@ -1211,9 +3047,11 @@ func TestCreatePerson(t *testing.T) {
 		mockPersonRepository.AssertExpectations(t)
 	})
 
-	t.Run("should create a person with all optional fields", func(t *testing.T) {
+	t.Run("should update person with all optional fields", func(t *testing.T) {
 		app, mockRepo, handler := setupTest()
-		app.Post("/persons", handler.CreatePerson)
+		personID := uuid.New()
+
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
 
 		now := time.Now()
 		age := 30
@@ -1223,9 +3061,7 @@ func TestCreatePerson(t *testing.T) {
 		nationProb := 0.85
 		patronymic := "Alex"
 
-		personID := uuid.New()
-		personData := map[string]interface{}{
-			"id":                      personID.String(),
+		updateData := map[string]interface{}{
 			"name":                    "Jane",
 			"surname":                 "Smith",
 			"patronymic":              patronymic,
@@ -1238,7 +3074,7 @@ func TestCreatePerson(t *testing.T) {
 			"updated_at":              now,
 		}
 
-		mockRepo.On("CreatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
 			return p.ID == personID &&
 				p.Name == "Jane" &&
 				p.Surname == "Smith" &&
@@ -1250,15 +3086,17 @@ func TestCreatePerson(t *testing.T) {
 				*p.NationalityProbability == nationProb
 		})).Return(nil)
 
-		bodyBytes, err := json.Marshal(personData)
+		app.Put("/persons/:id", handler.UpdatePerson)
+
+		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
 
-		req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewReader(bodyBytes))
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
 		var returnedPerson entities.Person
 		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
@@ -1276,529 +3114,929 @@ func TestCreatePerson(t *testing.T) {
 
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("should use existing person as template for update", func(t *testing.T) {
+		app, mockRepo, handler := setupTest()
+		testPerson := createTestPerson()
+		personID := testPerson.ID
+
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+
+		updateData := map[string]interface{}{
+			"name":    "Updated",
+			"surname": testPerson.Surname,
+		}
+
+		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+			return p.ID == personID &&
+				p.Name == "Updated" &&
+				p.Surname == testPerson.Surname
+		})).Return(nil)
+
+		app.Put("/persons/:id", handler.UpdatePerson)
+
+		bodyBytes, err := json.Marshal(updateData)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var returnedPerson entities.Person
+		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		require.NoError(t, err)
+
+		assert.Equal(t, personID, returnedPerson.ID)
+		assert.Equal(t, "Updated", returnedPerson.Name)
+		assert.Equal(t, testPerson.Surname, returnedPerson.Surname)
+
+		mockRepo.AssertExpectations(t)
+	})
 }
 
-func TestUpdatePerson(t *testing.T) {
+func TestPatchPerson(t *testing.T) {
 	setupTest := func() (*fiber.App, *MockPersonRepository, *handlers.PersonHandler) {
 		app := fiber.New(fiber.Config{
-			ErrorHandler: func(c fiber.Ctx, err error) error {
-				code := fiber.StatusInternalServerError
-				errMsg := "Internal Server Error"
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
 
-				if fiber.IsChild() {
-					return fiber.DefaultErrorHandler(c, err)
-				}
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
 
-				if err != nil {
-					if errors.Is(err, handlers.ErrNameSurnameRequired) {
-						code = fiber.StatusBadRequest
-						errMsg = "Name and surname are required"
-					} else if errors.Is(err, handlers.ErrPersonNotFound) {
-						code = fiber.StatusNotFound
-						errMsg = "Person not found"
-					} else if strings.Contains(err.Error(), "invalid request body") {
-						code = fiber.StatusBadRequest
-						errMsg = "Invalid request body"
-					} else if strings.Contains(err.Error(), "invalid UUID") {
-						code = fiber.StatusBadRequest
-						errMsg = "Invalid UUID format"
-					}
-				}
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Patch("/persons/:id", handler.PatchPerson)
+		return app, mockPersonRepository, handler
+	}
 
-				return c.Status(code).JSON(fiber.Map{
-					"error": errMsg,
-				})
-			},
-		})
+	createTestPerson := func() *entities.Person {
+		now := time.Now()
+		age := 25
+		gender := "male"
+		genderProb := 0.95
+		nationality := "RU"
+		nationProb := 0.90
 
-		mockPersonService := &MockPersonService{}
-		mockAgeService := &MockAgeService{}
-		mockGenderService := &MockGenderService{}
-		mockNationalityService := &MockNationalityService{}
+		return &entities.Person{
+			ID:                     uuid.New(),
+			Name:                   "Ivan",
+			Surname:                "Petrov",
+			Age:                    &age,
+			Gender:                 &gender,
+			GenderProbability:      &genderProb,
+			Nationality:            &nationality,
+			NationalityProbability: &nationProb,
+			CreatedAt:              now,
+			UpdatedAt:              now,
+		}
+	}
 
-		mockPeopleServices := &MockPeopleServices{
-			mockPersonService:      mockPersonService,
-			mockAgeService:         mockAgeService,
-			mockGenderService:      mockGenderService,
-			mockNationalityService: mockNationalityService,
+	sendPatch := func(t *testing.T, app *fiber.App, personID, contentType, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPatch, "/persons/"+personID, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
 		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
 
-		mockAPI := &MockAPI{
-			mockPeopleServices: mockPeopleServices,
+	t.Run("should update only supplied fields with merge patch", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+		patched := *testPerson
+		age := 30
+		patched.Age = &age
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+		mockRepo.On("PatchPerson", mock.Anything, testPerson.ID, map[string]any{"age": 30}, 0).Return(&patched, nil)
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/merge-patch+json", `{"age": 30}`)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var returnedPerson entities.Person
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&returnedPerson))
+		assert.Equal(t, 30, *returnedPerson.Age)
+		assert.Equal(t, "male", *returnedPerson.Gender)
+		assert.Equal(t, "RU", *returnedPerson.Nationality)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should treat application/json as merge patch", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+		mockRepo.On("PatchPerson", mock.Anything, testPerson.ID, map[string]any{"name": "Petr"}, 0).Return(testPerson, nil)
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/json; charset=utf-8", `{"name": "Petr"}`)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should clear field on explicit null", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+		mockRepo.On("PatchPerson", mock.Anything, testPerson.ID, map[string]any{
+			"gender":             nil,
+			"gender_probability": nil,
+		}, 0).Return(testPerson, nil)
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/merge-patch+json",
+			`{"gender": null, "gender_probability": null}`)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should apply JSON patch operations", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+		mockRepo.On("PatchPerson", mock.Anything, testPerson.ID, map[string]any{
+			"surname":     "Sidorov",
+			"nationality": nil,
+		}, 0).Return(testPerson, nil)
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/json-patch+json", `[
+			{"op": "test", "path": "/surname", "value": "Petrov"},
+			{"op": "replace", "path": "/surname", "value": "Sidorov"},
+			{"op": "remove", "path": "/nationality"}
+		]`)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should skip repository update when nothing changes", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/merge-patch+json", `{"name": "Ivan", "patronymic": null}`)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "PatchPerson", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 409 when JSON patch test fails", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/json-patch+json",
+			`[{"op": "test", "path": "/name", "value": "Petr"}]`)
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "PatchPerson", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 400 when required field is cleared", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/merge-patch+json", `{"name": null}`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "PatchPerson", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 400 for immutable or unknown fields", func(t *testing.T) {
+		for _, body := range []string{`{"id": "` + uuid.NewString() + `"}`, `{"created_at": "2020-01-01T00:00:00Z"}`, `{"nickname": "Vanya"}`} {
+			app, mockRepo, _ := setupTest()
+			testPerson := createTestPerson()
+
+			mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+
+			resp := sendPatch(t, app, testPerson.ID.String(), "application/merge-patch+json", body)
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+			mockRepo.AssertNotCalled(t, "PatchPerson", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 		}
+	})
 
-		mockPersonRepository := &MockPersonRepository{}
-		mockPeopleRepositories := &MockPeopleRepositories{
-			mockPersonRepository: mockPersonRepository,
+	t.Run("should return 400 for invalid field values", func(t *testing.T) {
+		for _, body := range []string{`{"age": "thirty"}`, `{"age": 30.5}`, `{"gender_probability": 1.5}`, `{"surname": ""}`} {
+			app, mockRepo, _ := setupTest()
+			testPerson := createTestPerson()
+
+			mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+
+			resp := sendPatch(t, app, testPerson.ID.String(), "application/merge-patch+json", body)
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
 		}
+	})
+
+	t.Run("should return 400 for malformed patch documents", func(t *testing.T) {
+		app, _, _ := setupTest()
+		personID := uuid.NewString()
+
+		resp := sendPatch(t, app, personID, "application/merge-patch+json", `[1, 2]`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = sendPatch(t, app, personID, "application/json-patch+json", `[{"op": "rename", "path": "/name"}]`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("should return 415 for unsupported content type", func(t *testing.T) {
+		app, _, _ := setupTest()
+
+		resp := sendPatch(t, app, uuid.NewString(), "text/plain", `age=30`)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+
+	t.Run("should return 400 for invalid UUID", func(t *testing.T) {
+		app, _, _ := setupTest()
+
+		resp := sendPatch(t, app, "not-a-uuid", "application/merge-patch+json", `{"age": 30}`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("should return 404 when person not found", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		personID := uuid.New()
+
+		mockRepo.On("GetByID", mock.Anything, personID).Return(nil, errors.New("person not found"))
+
+		resp := sendPatch(t, app, personID.String(), "application/merge-patch+json", `{"age": 30}`)
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should expect read version without If-Match", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+		testPerson.Version = 4
+		patched := *testPerson
+		patched.Version = 5
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+		mockRepo.On("PatchPerson", mock.Anything, testPerson.ID, mock.Anything, 4).Return(&patched, nil)
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/merge-patch+json", `{"age": 30}`)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"5"`, resp.Header.Get("ETag"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return 500 when PatchPerson fails", func(t *testing.T) {
+		app, mockRepo, _ := setupTest()
+		testPerson := createTestPerson()
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+		mockRepo.On("PatchPerson", mock.Anything, testPerson.ID, mock.Anything, 0).Return(nil, errors.New("database error"))
+
+		resp := sendPatch(t, app, testPerson.ID.String(), "application/merge-patch+json", `{"age": 30}`)
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
 
+		mockPersonRepository := &MockPersonRepository{}
 		mockRepositories := &MockRepositories{
-			mockPeopleRepositories: mockPeopleRepositories,
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
 		}
 
-		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
-		return app, mockPersonRepository, handler
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Get("/persons/:id", handler.GetPersonByID)
+		app.Put("/persons/:id", handler.UpdatePerson)
+		app.Patch("/persons/:id", handler.PatchPerson)
+		app.Delete("/persons/:id", handler.DeletePerson)
+		app.Post("/persons/:id/enrich", handler.EnrichPerson)
+		return app, mockPersonRepository
 	}
 
-	createTestPerson := func() *entities.Person {
-		now := time.Now()
-		age := 25
-		gender := "male"
-		genderProb := 0.95
-		nationality := "RU"
-		nationProb := 0.90
-		patronymic := "Ivanovich"
-
+	createTestPerson := func(version int) *entities.Person {
 		return &entities.Person{
-			ID:                     uuid.New(),
-			Name:                   "Ivan",
-			Surname:                "Petrov",
-			Patronymic:             &patronymic,
-			Age:                    &age,
-			Gender:                 &gender,
-			GenderProbability:      &genderProb,
-			Nationality:            &nationality,
-			NationalityProbability: &nationProb,
-			CreatedAt:              now,
-			UpdatedAt:              now,
+			ID:      uuid.New(),
+			Name:    "Ivan",
+			Surname: "Petrov",
+			Version: version,
 		}
 	}
 
-	t.Run("should successfully update a person", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		personID := uuid.New()
-
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+	conflictErr := fmt.Errorf("%w: expected version 2, actual 3", personrepo.ErrVersionConflict)
 
-		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
-			return p.ID == personID &&
-				p.Name == "John" &&
-				p.Surname == "Smith"
-		})).Return(nil)
+	t.Run("should return ETag on get", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		testPerson := createTestPerson(3)
 
-		app.Put("/persons/:id", handler.UpdatePerson)
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
 
-		updateData := map[string]interface{}{
-			"name":    "John",
-			"surname": "Smith",
-		}
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/"+testPerson.ID.String(), nil))
 
-		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+	})
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
+	t.Run("should pass If-Match version to update and return new ETag", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		personID := uuid.New()
+
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+			return p.ID == personID && p.Version == 2
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*entities.Person).Version = 3
+		}).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), strings.NewReader(`{"name": "Ivan", "surname": "Petrov"}`))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"2"`)
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+		mockRepo.AssertExpectations(t)
+	})
 
-		var returnedPerson entities.Person
-		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
-		require.NoError(t, err)
+	t.Run("should return 412 when update version conflicts", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		personID := uuid.New()
 
-		assert.Equal(t, personID, returnedPerson.ID)
-		assert.Equal(t, "John", returnedPerson.Name)
-		assert.Equal(t, "Smith", returnedPerson.Surname)
+		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+		mockRepo.On("UpdatePerson", mock.Anything, mock.Anything).Return(conflictErr)
 
-		mockRepo.AssertExpectations(t)
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), strings.NewReader(`{"name": "Ivan", "surname": "Petrov"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"2"`)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
 	})
 
-	t.Run("should return 400 for invalid UUID", func(t *testing.T) {
-		app, _, handler := setupTest()
+	t.Run("should return 412 for weak or malformed If-Match", func(t *testing.T) {
+		for _, header := range []string{`W/"2"`, `"2", "3"`, `2`, `"abc"`} {
+			app, _ := setupTest()
 
-		app.Put("/persons/:id", handler.UpdatePerson)
+			req := httptest.NewRequest(http.MethodDelete, "/persons/"+uuid.NewString(), nil)
+			req.Header.Set("If-Match", header)
+			resp, err := app.Test(req)
 
-		updateData := map[string]interface{}{
-			"name":    "John",
-			"surname": "Smith",
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, header)
 		}
+	})
 
-		bodyBytes, err := json.Marshal(updateData)
-		require.NoError(t, err)
+	t.Run("should treat wildcard If-Match as unconditional", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		personID := uuid.New()
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/not-a-uuid", bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
+		mockRepo.On("DeletePerson", mock.Anything, personID, 0).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/persons/"+personID.String(), nil)
+		req.Header.Set("If-Match", "*")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
-		require.NoError(t, err)
-		assert.Equal(t, "Invalid UUID format", errorResp["error"])
+	t.Run("should return 412 for If-Match when person does not exist", func(t *testing.T) {
+		for _, header := range []string{"*", `"2"`} {
+			app, mockRepo := setupTest()
+			personID := uuid.New()
+
+			mockRepo.On("DeletePerson", mock.Anything, personID, mock.Anything).
+				Return(fmt.Errorf("failed to delete person: %w", errors.New("person not found")))
+			mockRepo.On("ExistsByID", mock.Anything, personID).Return(false, nil)
+
+			req := httptest.NewRequest(http.MethodDelete, "/persons/"+personID.String(), nil)
+			req.Header.Set("If-Match", header)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, header)
+
+			req = httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), strings.NewReader(`{"name": "Ivan", "surname": "Petrov"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", header)
+			resp, err = app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, header)
+		}
 	})
 
-	t.Run("should return 404 when person not found", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
+	t.Run("should return 412 when delete version conflicts", func(t *testing.T) {
+		app, mockRepo := setupTest()
 		personID := uuid.New()
 
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(false, nil)
-
-		app.Put("/persons/:id", handler.UpdatePerson)
+		mockRepo.On("DeletePerson", mock.Anything, personID, 2).Return(conflictErr)
 
-		updateData := map[string]interface{}{
-			"name":    "John",
-			"surname": "Smith",
-		}
+		req := httptest.NewRequest(http.MethodDelete, "/persons/"+personID.String(), nil)
+		req.Header.Set("If-Match", `"2"`)
+		resp, err := app.Test(req)
 
-		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
+	t.Run("should return 412 on patch when current version differs", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		testPerson := createTestPerson(3)
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+
+		req := httptest.NewRequest(http.MethodPatch, "/persons/"+testPerson.ID.String(), strings.NewReader(`{"age": 30}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", `"2"`)
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "PatchPerson", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 412 on enrich when current version differs", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		testPerson := createTestPerson(3)
+
+		mockRepo.On("GetByID", mock.Anything, testPerson.ID).Return(testPerson, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons/"+testPerson.ID.String()+"/enrich", nil)
+		req.Header.Set("If-Match", `"2"`)
+		resp, err := app.Test(req)
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
 		require.NoError(t, err)
-		assert.Equal(t, "Person not found", errorResp["error"])
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "UpdatePerson", mock.Anything, mock.Anything)
+	})
+}
 
-		mockRepo.AssertExpectations(t)
+func TestSoftDelete(t *testing.T) {
+	const adminToken = "secret"
+
+	setupTest := func() (*fiber.App, *MockPersonRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
+		})
+
+		mockPersonRepository := &MockPersonRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository: mockPersonRepository,
+			},
+		}
+
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Use(handlers.AdminMiddleware(adminToken))
+		app.Get("/persons", handler.GetPersons)
+		app.Get("/persons/:id", handler.GetPersonByID)
+		app.Post("/persons/:id/restore", handler.RestorePerson)
+		return app, mockPersonRepository
+	}
+
+	includesDeleted := mock.MatchedBy(func(ctx context.Context) bool {
+		return personrepo.IncludeDeleted(ctx)
+	})
+	excludesDeleted := mock.MatchedBy(func(ctx context.Context) bool {
+		return !personrepo.IncludeDeleted(ctx)
 	})
 
-	t.Run("should return 400 for invalid request body", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		personID := uuid.New()
+	t.Run("should exclude deleted persons by default", func(t *testing.T) {
+		app, mockRepo := setupTest()
 
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+		mockRepo.On("GetPersons", excludesDeleted, map[string]any{}, 0, 10).Return([]*entities.Person{}, 0, nil)
 
-		app.Put("/persons/:id", handler.UpdatePerson)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons", nil))
 
-		invalidJSON := `{"name": "John", "surname": "Smith", invalid_json}`
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader([]byte(invalidJSON)))
-		req.Header.Set("Content-Type", "application/json")
+	t.Run("should include deleted persons for admin", func(t *testing.T) {
+		app, mockRepo := setupTest()
+
+		mockRepo.On("GetPersons", includesDeleted, map[string]any{}, 0, 10).Return([]*entities.Person{}, 0, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/persons?include_deleted=true", nil)
+		req.Header.Set(handlers.HeaderAdminToken, adminToken)
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return deleted person by ID for admin", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		deletedAt := time.Now().UTC()
+		testPerson := &entities.Person{ID: uuid.New(), Name: "Ivan", Surname: "Petrov", Version: 2, DeletedAt: &deletedAt}
+
+		mockRepo.On("GetByID", includesDeleted, testPerson.ID).Return(testPerson, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/persons/"+testPerson.ID.String()+"?include_deleted=true", nil)
+		req.Header.Set(handlers.HeaderAdminToken, adminToken)
+		resp, err := app.Test(req)
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
 		require.NoError(t, err)
-		assert.Equal(t, "Invalid request body", errorResp["error"])
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
+		var result entities.Person
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.NotNil(t, result.DeletedAt)
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should return 400 when name is missing", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		personID := uuid.New()
-
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+	t.Run("should forbid include_deleted without admin token", func(t *testing.T) {
+		for _, token := range []string{"", "wrong"} {
+			app, mockRepo := setupTest()
 
-		app.Put("/persons/:id", handler.UpdatePerson)
+			req := httptest.NewRequest(http.MethodGet, "/persons?include_deleted=true", nil)
+			if token != "" {
+				req.Header.Set(handlers.HeaderAdminToken, token)
+			}
+			resp, err := app.Test(req)
 
-		updateData := map[string]interface{}{
-			"surname": "Smith",
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode, "token %q", token)
+			mockRepo.AssertNotCalled(t, "GetPersons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 		}
+	})
 
-		bodyBytes, err := json.Marshal(updateData)
-		require.NoError(t, err)
+	t.Run("should reject invalid include_deleted value", func(t *testing.T) {
+		app, _ := setupTest()
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := app.Test(req)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons?include_deleted=maybe", nil))
 
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
-		require.NoError(t, err)
-		assert.Equal(t, "Name and surname are required", errorResp["error"])
+	t.Run("should restore deleted person", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		testPerson := &entities.Person{ID: uuid.New(), Name: "Ivan", Surname: "Petrov", Version: 3}
+
+		mockRepo.On("RestorePerson", mock.Anything, testPerson.ID).Return(testPerson, nil)
 
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/persons/"+testPerson.ID.String()+"/restore", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should return 400 when surname is missing", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
+	t.Run("should return 409 when person is not deleted", func(t *testing.T) {
+		app, mockRepo := setupTest()
 		personID := uuid.New()
 
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
-
-		app.Put("/persons/:id", handler.UpdatePerson)
+		mockRepo.On("RestorePerson", mock.Anything, personID).
+			Return(nil, fmt.Errorf("%w: id %s", personrepo.ErrPersonNotDeleted, personID))
 
-		updateData := map[string]interface{}{
-			"name": "John",
-		}
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/persons/"+personID.String()+"/restore", nil))
 
-		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := app.Test(req)
+	t.Run("should return 404 when restoring unknown person", func(t *testing.T) {
+		app, mockRepo := setupTest()
+		personID := uuid.New()
 
-		require.NoError(t, err)
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockRepo.On("RestorePerson", mock.Anything, personID).Return(nil, errors.New("person not found"))
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
-		require.NoError(t, err)
-		assert.Equal(t, "Name and surname are required", errorResp["error"])
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/persons/"+personID.String()+"/restore", nil))
 
-		mockRepo.AssertExpectations(t)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	})
 
-	t.Run("should return 500 when ExistsByID fails", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		personID := uuid.New()
+	t.Run("should return 400 for invalid UUID on restore", func(t *testing.T) {
+		app, _ := setupTest()
 
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(false, errors.New("database error"))
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/persons/invalid-uuid/restore", nil))
 
-		app.Put("/persons/:id", func(c fiber.Ctx) error {
-			err := handler.UpdatePerson(c)
-			if err != nil && strings.Contains(err.Error(), "failed to check if person exists") {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to check if person exists",
-				})
-			}
-			return err
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestPersonHistory(t *testing.T) {
+	setupTest := func() (*fiber.App, *MockPersonRepository, *MockHistoryRepository) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			},
 		})
 
-		updateData := map[string]interface{}{
-			"name":    "John",
-			"surname": "Smith",
+		mockPersonRepository := &MockPersonRepository{}
+		mockHistoryRepository := &MockHistoryRepository{}
+		mockRepositories := &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{
+				mockPersonRepository:  mockPersonRepository,
+				mockHistoryRepository: mockHistoryRepository,
+			},
 		}
 
-		bodyBytes, err := json.Marshal(updateData)
-		require.NoError(t, err)
+		handler := handlers.NewPersonHandler(&MockAPI{}, mockRepositories)
+		app.Use(handlers.AuditMiddleware())
+		app.Get("/persons/:id/history", handler.GetPersonHistory)
+		app.Post("/persons/:id/history/:version/revert", handler.RevertPerson)
+		return app, mockPersonRepository, mockHistoryRepository
+	}
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := app.Test(req)
+	age := 30
+	snapshot := &entities.Person{Name: "Ivan", Surname: "Petrov", Age: &age, Version: 1}
 
-		require.NoError(t, err)
-		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	t.Run("should return person history", func(t *testing.T) {
+		app, mockPersonRepo, mockHistoryRepo := setupTest()
+		personID := uuid.New()
+		entries := []*entities.PersonHistory{
+			{PersonID: personID, Version: 2, Operation: historyrepo.OperationUpdate, ChangedFields: []string{"age"}},
+			{PersonID: personID, Version: 1, Operation: historyrepo.OperationCreate, ChangedFields: []string{"name", "surname"}},
+		}
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
-		require.NoError(t, err)
-		assert.Equal(t, "Failed to check if person exists", errorResp["error"])
+		mockPersonRepo.On("ExistsByID", mock.MatchedBy(personrepo.IncludeDeleted), personID).Return(true, nil)
+		mockHistoryRepo.On("GetHistory", mock.Anything, personID, 0, 5).Return(entries, 2, nil)
 
-		mockRepo.AssertExpectations(t)
-	})
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/"+personID.String()+"/history?limit=5", nil))
 
-	t.Run("should return 500 when UpdatePerson fails", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		personID := uuid.New()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+		var result struct {
+			Data  []entities.PersonHistory `json:"data"`
+			Total int                      `json:"total"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, 2, result.Total)
+		require.Len(t, result.Data, 2)
+		assert.Equal(t, []string{"age"}, result.Data[0].ChangedFields)
+		mockHistoryRepo.AssertExpectations(t)
+	})
 
-		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
-			return p.ID == personID && p.Name == "John" && p.Surname == "Smith"
-		})).Return(errors.New("database error"))
+	t.Run("should return 404 for unknown person history", func(t *testing.T) {
+		app, mockPersonRepo, _ := setupTest()
+		personID := uuid.New()
 
-		app.Put("/persons/:id", func(c fiber.Ctx) error {
-			err := handler.UpdatePerson(c)
-			if err != nil && strings.Contains(err.Error(), "failed to update person") {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to update person",
-				})
-			}
-			return err
-		})
+		mockPersonRepo.On("ExistsByID", mock.Anything, personID).Return(false, nil)
 
-		updateData := map[string]interface{}{
-			"name":    "John",
-			"surname": "Smith",
-		}
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/persons/"+personID.String()+"/history", nil))
 
-		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
+	t.Run("should revert person through update with revert operation", func(t *testing.T) {
+		app, mockPersonRepo, mockHistoryRepo := setupTest()
+		personID := uuid.New()
+		current := &entities.Person{ID: personID, Name: "Ivan", Surname: "Sidorov", Version: 3}
+
+		mockHistoryRepo.On("GetByVersion", mock.Anything, personID, 1).
+			Return(&entities.PersonHistory{PersonID: personID, Version: 1, After: snapshot}, nil)
+		mockPersonRepo.On("GetByID", mock.Anything, personID).Return(current, nil)
+		mockPersonRepo.On("UpdatePerson", mock.MatchedBy(func(ctx context.Context) bool {
+			return historyrepo.Operation(ctx, "") == historyrepo.OperationRevert && historyrepo.Actor(ctx) == "alice"
+		}), mock.MatchedBy(func(p *entities.Person) bool {
+			return p.ID == personID && p.Surname == "Petrov" && p.Age != nil && *p.Age == 30 && p.Version == 3
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*entities.Person).Version = 4
+		}).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons/"+personID.String()+"/history/1/revert", nil)
+		req.Header.Set(handlers.HeaderActor, "alice")
 		resp, err := app.Test(req)
 
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"4"`, resp.Header.Get("ETag"))
+		assert.NotEmpty(t, resp.Header.Get(handlers.HeaderRequestID))
+		mockPersonRepo.AssertExpectations(t)
+	})
 
-		var errorResp map[string]string
-		err = json.NewDecoder(resp.Body).Decode(&errorResp)
-		require.NoError(t, err)
-		assert.Equal(t, "Failed to update person", errorResp["error"])
+	t.Run("should return 412 when If-Match does not match current version", func(t *testing.T) {
+		app, mockPersonRepo, mockHistoryRepo := setupTest()
+		personID := uuid.New()
 
-		mockRepo.AssertExpectations(t)
+		mockHistoryRepo.On("GetByVersion", mock.Anything, personID, 1).
+			Return(&entities.PersonHistory{PersonID: personID, Version: 1, After: snapshot}, nil)
+		mockPersonRepo.On("GetByID", mock.Anything, personID).
+			Return(&entities.Person{ID: personID, Name: "Ivan", Surname: "Sidorov", Version: 3}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/persons/"+personID.String()+"/history/1/revert", nil)
+		req.Header.Set("If-Match", `"2"`)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+		mockPersonRepo.AssertNotCalled(t, "UpdatePerson", mock.Anything, mock.Anything)
 	})
 
-	t.Run("should handle JSON marshaling error", func(t *testing.T) {
+	t.Run("should return 404 for unknown history version", func(t *testing.T) {
+		app, _, mockHistoryRepo := setupTest()
 		personID := uuid.New()
 
-		mockPersonService := &MockPersonService{}
-		mockAgeService := &MockAgeService{}
-		mockGenderService := &MockGenderService{}
-		mockNationalityService := &MockNationalityService{}
+		mockHistoryRepo.On("GetByVersion", mock.Anything, personID, 7).Return(nil, errors.New("person history not found"))
 
-		mockPeopleServices := &MockPeopleServices{
-			mockPersonService:      mockPersonService,
-			mockAgeService:         mockAgeService,
-			mockGenderService:      mockGenderService,
-			mockNationalityService: mockNationalityService,
-		}
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/persons/"+personID.String()+"/history/7/revert", nil))
 
-		mockAPI := &MockAPI{
-			mockPeopleServices: mockPeopleServices,
-		}
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
 
-		mockPersonRepository := &MockPersonRepository{}
-		mockPersonRepository.On("ExistsByID", mock.Anything, personID).Return(true, nil)
-		mockPersonRepository.On("UpdatePerson", mock.Anything, mock.Anything).Return(nil)
+	t.Run("should return 400 for invalid version", func(t *testing.T) {
+		for _, version := range []string{"abc", "0", "-1"} {
+			app, _, _ := setupTest()
 
-		mockPeopleRepositories := &MockPeopleRepositories{
-			mockPersonRepository: mockPersonRepository,
-		}
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/persons/"+uuid.New().String()+"/history/"+version+"/revert", nil))
 
-		mockRepositories := &MockRepositories{
-			mockPeopleRepositories: mockPeopleRepositories,
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "version %q", version)
 		}
+	})
+}
 
-		app := fiber.New(fiber.Config{
-			JSONEncoder: func(v interface{}) ([]byte, error) {
-				return nil, errors.New("forced JSON marshal error")
-			},
-		})
+func TestAuditMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(handlers.AuditMiddleware())
 
-		handler := handlers.NewPersonHandler(mockAPI, mockRepositories)
+	var requestID, actor string
+	app.Get("/", func(ctx fiber.Ctx) error {
+		requestID, _ = logger.RequestID(ctx.Context())
+		actor = historyrepo.Actor(ctx.Context())
+		return ctx.SendStatus(fiber.StatusNoContent)
+	})
 
-		var capturedErr error
-		app.Put("/persons/:id", func(c fiber.Ctx) error {
-			err := handler.UpdatePerson(c)
-			capturedErr = err
-			return err
-		})
+	t.Run("should propagate request id and actor", func(t *testing.T) {
+		id := uuid.New().String()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(handlers.HeaderRequestID, id)
+		req.Header.Set(handlers.HeaderActor, " bob ")
 
-		updateData := map[string]interface{}{
-			"name":    "John",
-			"surname": "Smith",
-		}
+		resp, err := app.Test(req)
 
-		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
-
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
-		resp, _ := app.Test(req)
-
-		assert.NotNil(t, capturedErr)
-		assert.Contains(t, capturedErr.Error(), "failed to send JSON response")
-		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
-		mockPersonRepository.AssertExpectations(t)
+		assert.Equal(t, id, requestID)
+		assert.Equal(t, id, resp.Header.Get(handlers.HeaderRequestID))
+		assert.Equal(t, "bob", actor)
 	})
 
-	t.Run("should update person with all optional fields", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		personID := uuid.New()
+	t.Run("should generate request id when header is missing or invalid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(handlers.HeaderRequestID, "not-a-uuid")
 
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+		resp, err := app.Test(req)
 
-		now := time.Now()
-		age := 30
-		gender := "female"
-		genderProb := 0.95
-		nationality := "US"
-		nationProb := 0.85
-		patronymic := "Alex"
+		require.NoError(t, err)
+		assert.NotEqual(t, "not-a-uuid", requestID)
+		_, parseErr := uuid.Parse(requestID)
+		require.NoError(t, parseErr)
+		assert.Equal(t, requestID, resp.Header.Get(handlers.HeaderRequestID))
+		assert.Empty(t, actor)
+	})
+}
 
-		updateData := map[string]interface{}{
-			"name":                    "Jane",
-			"surname":                 "Smith",
-			"patronymic":              patronymic,
-			"age":                     age,
-			"gender":                  gender,
-			"gender_probability":      genderProb,
-			"nationality":             nationality,
-			"nationality_probability": nationProb,
-			"created_at":              now,
-			"updated_at":              now,
+func TestTenantMiddleware(t *testing.T) {
+	newApp := func(t *testing.T, registry *tenantsetup.Registry) (*fiber.App, *string) {
+		t.Helper()
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(_ fiber.Ctx, _ error) error {
+				return nil
+			},
+		})
+		app.Use(handlers.TenantMiddleware(registry))
+
+		var tenantID string
+		app.Get("/", func(ctx fiber.Ctx) error {
+			tenantID, _ = tenant.ID(ctx.Context())
+			return ctx.SendStatus(fiber.StatusNoContent)
+		})
+		return app, &tenantID
+	}
+	request := func(headers map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
 		}
+		return req
+	}
 
-		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
-			return p.ID == personID &&
-				p.Name == "Jane" &&
-				p.Surname == "Smith" &&
-				*p.Patronymic == patronymic &&
-				*p.Age == age &&
-				*p.Gender == gender &&
-				*p.GenderProbability == genderProb &&
-				*p.Nationality == nationality &&
-				*p.NationalityProbability == nationProb
-		})).Return(nil)
+	t.Run("should use default tenant without tenants file", func(t *testing.T) {
+		app, tenantID := newApp(t, tenantsetup.DefaultRegistry())
 
-		app.Put("/persons/:id", handler.UpdatePerson)
+		resp, err := app.Test(request(nil))
 
-		bodyBytes, err := json.Marshal(updateData)
 		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, tenant.Default, *tenantID)
+	})
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := app.Test(req)
-
-		require.NoError(t, err)
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	registry, err := tenantsetup.NewRegistry([]tenantsetup.Tenant{
+		{ID: "acme", APIKeys: []string{"acme-key"}},
+		{ID: "globex", APIKeys: []string{"globex-key"}, Quota: tenantsetup.Quota{RequestsPerMinute: 1}},
+	}, false)
+	require.NoError(t, err)
 
-		var returnedPerson entities.Person
-		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
-		require.NoError(t, err)
+	t.Run("should resolve tenant by API key", func(t *testing.T) {
+		app, tenantID := newApp(t, registry)
 
-		assert.Equal(t, personID, returnedPerson.ID)
-		assert.Equal(t, "Jane", returnedPerson.Name)
-		assert.Equal(t, "Smith", returnedPerson.Surname)
-		assert.Equal(t, patronymic, *returnedPerson.Patronymic)
-		assert.Equal(t, age, *returnedPerson.Age)
-		assert.Equal(t, gender, *returnedPerson.Gender)
-		assert.Equal(t, genderProb, *returnedPerson.GenderProbability)
-		assert.Equal(t, nationality, *returnedPerson.Nationality)
-		assert.Equal(t, nationProb, *returnedPerson.NationalityProbability)
+		resp, err := app.Test(request(map[string]string{handlers.HeaderAPIKey: "acme-key"}))
 
-		mockRepo.AssertExpectations(t)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "acme", *tenantID)
 	})
 
-	t.Run("should use existing person as template for update", func(t *testing.T) {
-		app, mockRepo, handler := setupTest()
-		testPerson := createTestPerson()
-		personID := testPerson.ID
+	t.Run("should reject missing or unknown API key", func(t *testing.T) {
+		app, _ := newApp(t, registry)
 
-		mockRepo.On("ExistsByID", mock.Anything, personID).Return(true, nil)
+		for _, headers := range []map[string]string{
+			nil,
+			{handlers.HeaderAPIKey: "unknown"},
+			{handlers.HeaderTenantID: "acme"},
+		} {
+			resp, err := app.Test(request(headers))
 
-		updateData := map[string]interface{}{
-			"name":    "Updated",
-			"surname": testPerson.Surname,
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
 		}
+	})
 
-		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
-			return p.ID == personID &&
-				p.Name == "Updated" &&
-				p.Surname == testPerson.Surname
-		})).Return(nil)
+	t.Run("should resolve tenant by trusted header", func(t *testing.T) {
+		trusted, err := tenantsetup.NewRegistry([]tenantsetup.Tenant{{ID: "acme"}}, true)
+		require.NoError(t, err)
+		app, tenantID := newApp(t, trusted)
 
-		app.Put("/persons/:id", handler.UpdatePerson)
+		resp, err := app.Test(request(map[string]string{handlers.HeaderTenantID: "acme"}))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "acme", *tenantID)
 
-		bodyBytes, err := json.Marshal(updateData)
+		resp, err = app.Test(request(map[string]string{handlers.HeaderTenantID: "globex"}))
 		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
 
-		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String(), bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := app.Test(req)
+	t.Run("should reject requests over tenant quota", func(t *testing.T) {
+		app, _ := newApp(t, registry)
 
+		resp, err := app.Test(request(map[string]string{handlers.HeaderAPIKey: "globex-key"}))
 		require.NoError(t, err)
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
 
-		var returnedPerson entities.Person
-		err = json.NewDecoder(resp.Body).Decode(&returnedPerson)
+		resp, err = app.Test(request(map[string]string{handlers.HeaderAPIKey: "globex-key"}))
 		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get(fiber.HeaderRetryAfter))
 
-		assert.Equal(t, personID, returnedPerson.ID)
-		assert.Equal(t, "Updated", returnedPerson.Name)
-		assert.Equal(t, testPerson.Surname, returnedPerson.Surname)
+		resp, err = app.Test(request(map[string]string{handlers.HeaderAPIKey: "acme-key"}))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode, "quota is tracked per tenant")
+	})
 
-		mockRepo.AssertExpectations(t)
+	t.Run("should reject duplicate API keys", func(t *testing.T) {
+		_, err := tenantsetup.NewRegistry([]tenantsetup.Tenant{
+			{ID: "acme", APIKeys: []string{"shared"}},
+			{ID: "globex", APIKeys: []string{"shared"}},
+		}, false)
+
+		require.ErrorIs(t, err, tenantsetup.ErrInvalidTenants)
 	})
 }
 
@@ -1862,7 +4100,7 @@ func TestDeletePerson(t *testing.T) {
 		app, mockRepo, handler := setupTest()
 		personID := uuid.New()
 
-		mockRepo.On("DeletePerson", mock.Anything, personID).Return(nil)
+		mockRepo.On("DeletePerson", mock.Anything, personID, 0).Return(nil)
 
 		app.Delete("/persons/:id", handler.DeletePerson)
 
@@ -1908,7 +4146,7 @@ func TestDeletePerson(t *testing.T) {
 		personID := uuid.New()
 		notFoundErr := errors.New("person not found: " + personID.String())
 
-		mockRepo.On("DeletePerson", mock.Anything, personID).Return(notFoundErr)
+		mockRepo.On("DeletePerson", mock.Anything, personID, 0).Return(notFoundErr)
 
 		app.Delete("/persons/:id", func(c fiber.Ctx) error {
 			err := handler.DeletePerson(c)
@@ -1939,7 +4177,7 @@ func TestDeletePerson(t *testing.T) {
 		personID := uuid.New()
 		dbErr := errors.New("database connection error")
 
-		mockRepo.On("DeletePerson", mock.Anything, personID).Return(dbErr)
+		mockRepo.On("DeletePerson", mock.Anything, personID, 0).Return(dbErr)
 
 		app.Delete("/persons/:id", func(c fiber.Ctx) error {
 			err := handler.DeletePerson(c)
@@ -1976,7 +4214,7 @@ func TestDeletePerson(t *testing.T) {
 		personID := uuid.New()
 		notFoundErr := errors.New("person not found: " + personID.String())
 
-		mockPersonRepository.On("DeletePerson", mock.Anything, personID).Return(notFoundErr)
+		mockPersonRepository.On("DeletePerson", mock.Anything, personID, 0).Return(notFoundErr)
 
 		mockPeopleRepositories := &MockPeopleRepositories{
 			mockPersonRepository: mockPersonRepository,
@@ -2020,7 +4258,7 @@ func TestDeletePerson(t *testing.T) {
 		personID := uuid.New()
 		dbErr := errors.New("database error")
 
-		mockPersonRepository.On("DeletePerson", mock.Anything, personID).Return(dbErr)
+		mockPersonRepository.On("DeletePerson", mock.Anything, personID, 0).Return(dbErr)
 
 		mockPeopleRepositories := &MockPeopleRepositories{
 			mockPersonRepository: mockPersonRepository,
@@ -2263,6 +4501,42 @@ func TestEnrichPerson(t *testing.T) {
 		mockNationalityService.AssertExpectations(t)
 	})
 
+	t.Run("should keep values written while external services were queried", func(t *testing.T) {
+		app, mockRepo, mockAgeService, mockGenderService, mockNationalityService, handler := setupTest()
+
+		person := createPersonWithoutEnrichment()
+		personID := person.ID
+
+		// Пока запрашивались внешние сервисы, пол персоны был задан вручную.
+		current := *person
+		manualGender := "female"
+		current.Gender = &manualGender
+		current.Version = person.Version + 1
+
+		mockRepo.On("GetByID", mock.Anything, personID).Return(person, nil).Once()
+		mockRepo.On("GetByID", mock.Anything, personID).Return(&current, nil).Once()
+		mockRepo.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(p *entities.Person) bool {
+			return p.Version == current.Version &&
+				*p.Age == 30 &&
+				*p.Gender == manualGender &&
+				p.GenderProbability == nil &&
+				*p.Nationality == "RU"
+		})).Return(nil)
+
+		mockAgeService.On("GetAgeByName", mock.Anything, person.Name).Return(30, 0.9, nil)
+		mockGenderService.On("GetGenderByName", mock.Anything, person.Name).Return("male", 0.95, nil)
+		mockNationalityService.On("GetNationalityByName", mock.Anything, person.Name).Return("RU", 0.8, nil)
+
+		app.Put("/persons/:id/enrich", handler.EnrichPerson)
+
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String()+"/enrich", nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("should skip enrichment for fields that already have values", func(t *testing.T) {
 		app, mockRepo, mockAgeService, mockGenderService, mockNationalityService, handler := setupTest()
 
@@ -2361,6 +4635,79 @@ func TestEnrichPerson(t *testing.T) {
 		mockNationalityService.AssertExpectations(t)
 	})
 
+	t.Run("should return 429 when tenant enrichment quota is exceeded", func(t *testing.T) {
+		app, mockRepo, mockAgeService, mockGenderService, mockNationalityService, _ := setupTest()
+		person := createPersonWithoutEnrichment()
+		personID := person.ID
+
+		mockRepo.On("GetByID", mock.Anything, personID).Return(person, nil)
+
+		handler := handlers.NewPersonHandler(&MockAPI{
+			mockPeopleServices: &MockPeopleServices{
+				mockAgeService:         mockAgeService,
+				mockGenderService:      mockGenderService,
+				mockNationalityService: mockNationalityService,
+			},
+			reserveErr: &api.QuotaError{Tenant: "acme", RetryAfter: 90*time.Second + time.Millisecond},
+		}, &MockRepositories{
+			mockPeopleRepositories: &MockPeopleRepositories{mockPersonRepository: mockRepo},
+		})
+		app.Put("/persons/:id/enrich", func(c fiber.Ctx) error {
+			err := handler.EnrichPerson(c)
+			if errors.Is(err, api.ErrTenantQuotaReached) {
+				// Обработчик уже отправил ответ с ошибкой.
+				return nil
+			}
+			return err
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String()+"/enrich", nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "91", resp.Header.Get(fiber.HeaderRetryAfter))
+
+		mockRepo.AssertNotCalled(t, "UpdatePerson", mock.Anything, mock.Anything)
+		mockAgeService.AssertNotCalled(t, "GetAgeByName", mock.Anything, mock.Anything)
+		mockGenderService.AssertNotCalled(t, "GetGenderByName", mock.Anything, mock.Anything)
+		mockNationalityService.AssertNotCalled(t, "GetNationalityByName", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not update person when providers are disabled", func(t *testing.T) {
+		app, mockRepo, mockAgeService, mockGenderService, mockNationalityService, handler := setupTest()
+		person := createPersonWithoutEnrichment()
+		person.Version = 3
+		personID := person.ID
+
+		mockRepo.On("GetByID", mock.Anything, personID).Return(person, nil)
+		mockAgeService.On("GetAgeByName", mock.Anything, person.Name).
+			Return(0, 0.0, fmt.Errorf("%w: age", api.ErrProviderDisabled))
+		mockGenderService.On("GetGenderByName", mock.Anything, person.Name).
+			Return("", 0.0, fmt.Errorf("%w: gender", api.ErrProviderDisabled))
+		mockNationalityService.On("GetNationalityByName", mock.Anything, person.Name).
+			Return("", 0.0, fmt.Errorf("%w: nationality", api.ErrProviderDisabled))
+
+		app.Put("/persons/:id/enrich", handler.EnrichPerson)
+
+		req := httptest.NewRequest(http.MethodPut, "/persons/"+personID.String()+"/enrich", nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get(fiber.HeaderETag))
+
+		var respPerson entities.Person
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&respPerson))
+		assert.Equal(t, 3, respPerson.Version)
+		assert.Nil(t, respPerson.Age)
+
+		mockRepo.AssertNotCalled(t, "UpdatePerson", mock.Anything, mock.Anything)
+		mockAgeService.AssertExpectations(t)
+		mockGenderService.AssertExpectations(t)
+		mockNationalityService.AssertExpectations(t)
+	})
+
 	t.Run("should return 500 when UpdatePerson fails", func(t *testing.T) {
 		app, mockRepo, mockAgeService, mockGenderService, mockNationalityService, handler := setupTest()
 		person := createPersonWithoutEnrichment()
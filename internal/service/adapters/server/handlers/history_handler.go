@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidVersion возникает, когда версия персоны в пути запроса некорректна.
+var ErrInvalidVersion = errors.New("invalid person version")
+
+// GetPersonHistory godoc
+// @Summary Get person change history
+// @Description Get the change history of a person, newest version first. Each entry contains before/after snapshots,
+// @Description the list of changed fields, the actor (X-Actor header) and the request id (X-Request-ID header)
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param id path string true "Person UUID" format(uuid)
+// @Param limit query int false "Page size limit" default(10) minimum(1)
+// @Param offset query int false "Page offset" default(0) minimum(0)
+// @Success 200 {object} map[string]interface{} "Successfully retrieved person history"
+// @Failure 400 {object} map[string]string "Bad request - Invalid UUID"
+// @Failure 404 {object} map[string]string "Person not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/{id}/history [get]
+func (h *PersonHandler) GetPersonHistory(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	idParam := ctx.Params("id")
+
+	logger.Debug(requestCtx, "handling get person history request", zap.String("id", idParam))
+
+	personID, err := uuid.Parse(idParam)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid UUID format", fmt.Errorf("invalid UUID format: %w", err))
+	}
+
+	limit, offset := paginationParams(ctx)
+
+	// История удаленной персоны остается доступной до ее окончательной очистки.
+	exists, err := h.repositories.People().Person().ExistsByID(personrepo.WithDeleted(requestCtx), personID)
+	if err != nil {
+		logger.Error(requestCtx, "failed to check if person exists", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to check if person exists", fmt.Errorf("failed to check if person exists: %w", err))
+	}
+	if !exists {
+		return sendError(ctx, fiber.StatusNotFound, "Person not found", fmt.Errorf("%w", ErrPersonNotFound))
+	}
+
+	entries, total, err := h.repositories.People().History().GetHistory(requestCtx, personID, offset, limit)
+	if err != nil {
+		logger.Error(requestCtx, "failed to get person history", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to retrieve person history", fmt.Errorf("failed to get person history: %w", err))
+	}
+
+	if err := ctx.JSON(fiber.Map{
+		"data":   entries,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// RevertPerson godoc
+// @Summary Revert person to a previous version
+// @Description Restore the person data recorded in the history entry of the given version.
+// @Description The change goes through the regular update path and is recorded in the history as a new version
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param id path string true "Person UUID" format(uuid)
+// @Param version path int true "Person version to revert to" minimum(1)
+// @Param If-Match header string false "Expected current person version (ETag)"
+// @Success 200 {object} entities.Person "Successfully reverted person"
+// @Header 200 {string} ETag "New person version"
+// @Failure 400 {object} map[string]string "Bad request - Invalid UUID or version"
+// @Failure 404 {object} map[string]string "Person or history entry not found"
+// @Failure 409 {object} map[string]string "Person was modified concurrently during revert"
+// @Failure 412 {object} map[string]string "Person version does not match If-Match or the person does not exist"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/{id}/history/{version}/revert [post]
+func (h *PersonHandler) RevertPerson(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	idParam := ctx.Params("id")
+	versionParam := ctx.Params("version")
+
+	logger.Debug(requestCtx, "handling revert person request",
+		zap.String("id", idParam),
+		zap.String("version", versionParam))
+
+	personID, err := uuid.Parse(idParam)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid UUID format", fmt.Errorf("invalid UUID format: %w", err))
+	}
+
+	version, err := strconv.Atoi(versionParam)
+	if err != nil || version <= 0 {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid version", fmt.Errorf("%w: %q", ErrInvalidVersion, versionParam))
+	}
+
+	match, err := ifMatchVersion(ctx)
+	if err != nil {
+		return sendPreconditionError(ctx, err)
+	}
+
+	entry, err := h.repositories.People().History().GetByVersion(requestCtx, personID, version)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return sendError(ctx, fiber.StatusNotFound, "History entry not found", fmt.Errorf("history entry not found: %w", err))
+		}
+		logger.Error(requestCtx, "failed to get person history entry", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to get person history", fmt.Errorf("failed to get person history entry: %w", err))
+	}
+	if entry.After == nil {
+		return sendError(ctx, fiber.StatusNotFound, "History entry not found", fmt.Errorf("history entry has no snapshot: version %d", version))
+	}
+
+	// Чтение текущего состояния и запись снимка выполняются в одной транзакции, поэтому изменение персоны
+	// между ними приводит к повтору транзакции, а не к ошибке.
+	var person *entities.Person
+	revertCtx := historyrepo.WithOperation(requestCtx, historyrepo.OperationRevert)
+	err = h.repositories.WithTx(requestCtx, func(tx repo.Repositories) error {
+		current, err := tx.People().Person().GetByID(requestCtx, personID)
+		if err != nil {
+			return err
+		}
+		if match.version != 0 && current.Version != match.version {
+			return fmt.Errorf("%w: expected version %d, actual %d", personrepo.ErrVersionConflict, match.version, current.Version)
+		}
+
+		snapshot := entry.After
+		current.Name = snapshot.Name
+		current.Surname = snapshot.Surname
+		current.Patronymic = snapshot.Patronymic
+		current.Age = snapshot.Age
+		current.Gender = snapshot.Gender
+		current.GenderProbability = snapshot.GenderProbability
+		current.Nationality = snapshot.Nationality
+		current.NationalityProbability = snapshot.NationalityProbability
+
+		if err := tx.People().Person().UpdatePerson(revertCtx, current); err != nil {
+			return err
+		}
+		person = current
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, personrepo.ErrVersionConflict):
+			status := fiber.StatusConflict
+			if match.present {
+				status = fiber.StatusPreconditionFailed
+			}
+			return sendError(ctx, status, "Person has been modified", fmt.Errorf("version conflict: %w", err))
+		case strings.Contains(err.Error(), "not found"):
+			return sendMissingTarget(ctx, match, "Person not found", fmt.Errorf("person not found: %w", err))
+		}
+		logger.Error(requestCtx, "failed to revert person", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to revert person", fmt.Errorf("failed to revert person: %w", err))
+	}
+
+	setETag(ctx, person.Version)
+	if err := ctx.JSON(person); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/imports"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// mimeTextCSV - тип содержимого отчета об ошибках импорта.
+const mimeTextCSV = "text/csv; charset=utf-8"
+
+// ErrInvalidImportRequest возникает при некорректных параметрах запроса импорта.
+var ErrInvalidImportRequest = errors.New("invalid import request")
+
+// ImportHandler обрабатывает HTTP-запросы импорта персон из файлов.
+type ImportHandler struct {
+	imports      *imports.Service
+	repositories repo.Repositories
+}
+
+// NewImportHandler создает новый обработчик импорта персон.
+func NewImportHandler(api api.API, repositories repo.Repositories) *ImportHandler {
+	return &ImportHandler{
+		imports:      imports.NewService(api, repositories),
+		repositories: repositories,
+	}
+}
+
+// CreateImport godoc
+// @Summary Import persons from a file
+// @Description Import persons from an uploaded CSV (with a header row) or NDJSON file. Columns are copied to the person fields
+// @Description with the same name unless mapping assigns them explicitly, e.g. {"Фамилия": "surname", "Имя": "name"}.
+// @Description Invalid rows are skipped and reported; with dry_run nothing is saved. The per-row error report is available at /imports/{id}/report
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or NDJSON file"
+// @Param format formData string false "File format, detected from the file name or content type by default" Enums(csv, ndjson)
+// @Param mapping formData string false "JSON object mapping file columns to person fields"
+// @Param delimiter formData string false "CSV field delimiter" default(,)
+// @Param dry_run formData bool false "Validate rows without saving persons" default(false)
+// @Param enrich formData bool false "Enrich imported persons in the background" default(false)
+// @Success 201 {object} entities.PersonImport "Import result"
+// @Failure 400 {object} map[string]string "Bad request - Missing file, malformed file or invalid options"
+// @Failure 413 {object} map[string]string "Too many records"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /imports [post]
+func (h *ImportHandler) CreateImport(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	logger.Debug(requestCtx, "handling create import request")
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "File is required",
+			fmt.Errorf("%w: %w", ErrInvalidImportRequest, err))
+	}
+
+	opts := imports.Options{
+		Format:   ctx.FormValue("format"),
+		FileName: fileHeader.Filename,
+	}
+	if opts.Format == "" {
+		opts.Format = imports.DetectFormat(fileHeader.Filename, fileHeader.Header.Get(fiber.HeaderContentType))
+	}
+
+	opts.Mapping, err = imports.ParseMapping(ctx.FormValue("mapping"))
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid mapping parameter", err)
+	}
+
+	if delimiter := ctx.FormValue("delimiter"); delimiter != "" {
+		if utf8.RuneCountInString(delimiter) != 1 {
+			return sendError(ctx, fiber.StatusBadRequest, "Invalid delimiter parameter",
+				fmt.Errorf("%w: delimiter %q", ErrInvalidImportRequest, delimiter))
+		}
+		opts.Delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	}
+
+	for name, target := range map[string]*bool{"dry_run": &opts.DryRun, "enrich": &opts.Enrich} {
+		value := ctx.FormValue(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return sendError(ctx, fiber.StatusBadRequest, "Invalid "+name+" parameter",
+				fmt.Errorf("%w: %s %q", ErrInvalidImportRequest, name, value))
+		}
+		*target = parsed
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Error(requestCtx, "failed to open uploaded file", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to read file",
+			fmt.Errorf("failed to open uploaded file: %w", err))
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Warn(requestCtx, "failed to close uploaded file", zap.Error(err))
+		}
+	}()
+
+	result, err := h.imports.Import(requestCtx, file, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, imports.ErrTooManyRecords):
+			return sendError(ctx, fiber.StatusRequestEntityTooLarge,
+				fmt.Sprintf("At most %d records are allowed per import", imports.MaxRecords), err)
+		case errors.Is(err, imports.ErrInvalidOptions), errors.Is(err, imports.ErrMalformedFile):
+			return sendError(ctx, fiber.StatusBadRequest, err.Error(), err)
+		}
+		logger.Error(requestCtx, "failed to import persons", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to import persons",
+			fmt.Errorf("failed to import persons: %w", err))
+	}
+
+	ctx.Location("/api/v1/imports/" + result.ID.String())
+	if err := ctx.Status(fiber.StatusCreated).JSON(result); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// GetImport godoc
+// @Summary Get import result
+// @Description Get the summary of a previous import
+// @Tags imports
+// @Produce json
+// @Param id path string true "Import ID" format(uuid)
+// @Success 200 {object} entities.PersonImport "Import result"
+// @Failure 400 {object} map[string]string "Bad request - Invalid UUID format"
+// @Failure 404 {object} map[string]string "Import not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /imports/{id} [get]
+func (h *ImportHandler) GetImport(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	idParam := ctx.Params("id")
+
+	logger.Debug(requestCtx, "handling get import request", zap.String("id", idParam))
+
+	importID, err := uuid.Parse(idParam)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid UUID format", fmt.Errorf("invalid UUID format: %w", err))
+	}
+
+	result, err := h.repositories.People().Imports().GetImport(requestCtx, importID)
+	if err != nil {
+		return sendImportError(ctx, err)
+	}
+
+	if err := ctx.JSON(result); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// GetImportReport godoc
+// @Summary Download import error report
+// @Description Download the per-row error report of an import as CSV with the columns row, field and error
+// @Tags imports
+// @Produce text/csv
+// @Param id path string true "Import ID" format(uuid)
+// @Success 200 {string} string "CSV error report"
+// @Failure 400 {object} map[string]string "Bad request - Invalid UUID format"
+// @Failure 404 {object} map[string]string "Import not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /imports/{id}/report [get]
+func (h *ImportHandler) GetImportReport(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	idParam := ctx.Params("id")
+
+	logger.Debug(requestCtx, "handling get import report request", zap.String("id", idParam))
+
+	importID, err := uuid.Parse(idParam)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid UUID format", fmt.Errorf("invalid UUID format: %w", err))
+	}
+
+	result, err := h.repositories.People().Imports().GetImport(requestCtx, importID)
+	if err != nil {
+		return sendImportError(ctx, err)
+	}
+
+	var report bytes.Buffer
+	if err := imports.WriteReport(&report, result); err != nil {
+		logger.Error(requestCtx, "failed to build import report", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to build import report",
+			fmt.Errorf("failed to build import report: %w", err))
+	}
+
+	ctx.Attachment("import-" + importID.String() + "-errors.csv")
+	ctx.Set(fiber.HeaderContentType, mimeTextCSV)
+	if err := ctx.Send(report.Bytes()); err != nil {
+		return fmt.Errorf("failed to send import report: %w", err)
+	}
+	return nil
+}
+
+// sendImportError отправляет ответ на ошибку получения результата импорта.
+func sendImportError(ctx fiber.Ctx, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return sendError(ctx, fiber.StatusNotFound, "Import not found", fmt.Errorf("import not found: %w", err))
+	}
+	logger.Error(ctx.Context(), "failed to get import", zap.Error(err))
+	return sendError(ctx, fiber.StatusInternalServerError, "Failed to get import",
+		fmt.Errorf("failed to get import: %w", err))
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/merge"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxDuplicatesLimit ограничивает количество групп дубликатов в ответе.
+const maxDuplicatesLimit = 100
+
+// ErrInvalidMinScore возникает, когда минимальная оценка сходства не является числом от 0 до 1.
+var ErrInvalidMinScore = errors.New("invalid min_score")
+
+// MergeRequest описывает запрос объединения персон.
+type MergeRequest struct {
+	// SurvivorID - персона, которая останется после объединения.
+	SurvivorID uuid.UUID `json:"survivor_id"`
+	// IDs - персоны, поглощаемые survivor.
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// FindDuplicates godoc
+// @Summary Find likely duplicate persons
+// @Description Group persons that likely describe the same human: the same name, surname and patronymic in a different case,
+// @Description transliteration (matching phonetic keys) or with typos (trigram similarity of the full name).
+// @Description Persons linked by a chain of pairs with score >= min_score form one cluster; the cluster score is its best pair score
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param min_score query number false "Minimum pair similarity score" default(0.7) minimum(0) maximum(1)
+// @Param limit query int false "Maximum number of clusters" default(10) minimum(1) maximum(100)
+// @Success 200 {object} map[string]interface{} "Duplicate clusters by descending score"
+// @Failure 400 {object} map[string]string "Bad request - Invalid min_score"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/duplicates [get]
+func (h *PersonHandler) FindDuplicates(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	logger.Debug(requestCtx, "handling find duplicates request")
+
+	minScore := merge.DefaultMinScore
+	if minScoreStr := ctx.Query("min_score"); minScoreStr != "" {
+		var err error
+		minScore, err = strconv.ParseFloat(minScoreStr, 64)
+		if err != nil || minScore < 0 || minScore > 1 {
+			return sendError(ctx, fiber.StatusBadRequest, "Parameter min_score must be a number between 0 and 1",
+				fmt.Errorf("%w: %q", ErrInvalidMinScore, minScoreStr))
+		}
+	}
+
+	limit, _ := paginationParams(ctx)
+	limit = min(limit, maxDuplicatesLimit)
+
+	clusters, err := h.merges.FindDuplicates(requestCtx, minScore, limit)
+	if err != nil {
+		logger.Error(requestCtx, "failed to find duplicates", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to find duplicates", fmt.Errorf("failed to find duplicates: %w", err))
+	}
+
+	if err := ctx.JSON(fiber.Map{
+		"data":      clusters,
+		"min_score": minScore,
+		"limit":     limit,
+	}); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// MergePersons godoc
+// @Summary Merge persons
+// @Description Merge persons into the survivor. Empty survivor fields are filled from the merged persons; a value entered manually
+// @Description wins over an enriched one, among enriched gender and nationality the higher probability wins, otherwise the survivor
+// @Description and then the most recently updated person win. Merged persons are soft deleted, lookups of their ids redirect to the survivor,
+// @Description and the merge is recorded in the history of every person involved
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param request body MergeRequest true "Survivor and persons to merge into it"
+// @Param If-Match header string false "Expected survivor version (ETag)"
+// @Success 200 {object} entities.Person "Merged survivor"
+// @Header 200 {string} ETag "New survivor version"
+// @Failure 400 {object} map[string]string "Bad request - Invalid body or set of persons"
+// @Failure 404 {object} map[string]string "Person not found"
+// @Failure 412 {object} map[string]string "Survivor version does not match If-Match"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/merge [post]
+func (h *PersonHandler) MergePersons(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	logger.Debug(requestCtx, "handling merge persons request")
+
+	match, err := ifMatchVersion(ctx)
+	if err != nil {
+		return sendPreconditionError(ctx, err)
+	}
+
+	var request MergeRequest
+	if err := ctx.Bind().Body(&request); err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid request body", fmt.Errorf("invalid request body: %w", err))
+	}
+	if request.SurvivorID == uuid.Nil {
+		return sendError(ctx, fiber.StatusBadRequest, "survivor_id is required",
+			fmt.Errorf("%w: survivor_id is required", personrepo.ErrInvalidMerge))
+	}
+
+	survivor, err := h.merges.Merge(requestCtx, request.SurvivorID, request.IDs, match.version)
+	if err != nil {
+		switch {
+		case errors.Is(err, personrepo.ErrInvalidMerge):
+			return sendError(ctx, fiber.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, personrepo.ErrVersionConflict):
+			return sendError(ctx, fiber.StatusPreconditionFailed, "Person has been modified", fmt.Errorf("version conflict: %w", err))
+		case strings.Contains(err.Error(), "not found"):
+			return sendError(ctx, fiber.StatusNotFound, "Person not found", fmt.Errorf("person not found: %w", err))
+		}
+		logger.Error(requestCtx, "failed to merge persons", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to merge persons", fmt.Errorf("failed to merge persons: %w", err))
+	}
+
+	setETag(ctx, survivor.Version)
+	if err := ctx.JSON(survivor); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// redirectMerged перенаправляет запрос персоны, объединенной с другой, на персону, которая ее поглотила.
+// Возвращает: признак отправленного перенаправления, ошибка.
+func (h *PersonHandler) redirectMerged(ctx fiber.Ctx, personID uuid.UUID) (bool, error) {
+	target, err := h.repositories.People().Person().MergedInto(ctx.Context(), personID)
+	if err != nil || target == uuid.Nil {
+		return false, err
+	}
+
+	location := strings.Replace(ctx.OriginalURL(), ctx.Params("id"), target.String(), 1)
+	ctx.Set(fiber.HeaderLocation, location)
+	if err := ctx.Status(fiber.StatusPermanentRedirect).JSON(fiber.Map{
+		"error":       "Person has been merged",
+		"merged_into": target,
+	}); err != nil {
+		return true, fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/gofiber/fiber/v3"
+)
+
+// dateLayout формат даты без времени, допустимый в фильтрах по времени наряду с RFC 3339.
+const dateLayout = "2006-01-02"
+
+// maxFilterValues ограничивает количество значений в одном текстовом фильтре.
+const maxFilterValues = 50
+
+// textFilterSuffixes перечисляет суффиксы ключей текстовых фильтров, включая ключ без суффикса.
+var textFilterSuffixes = []string{
+	"",
+	personrepo.FilterSuffixExact,
+	personrepo.FilterSuffixPrefix,
+	personrepo.FilterSuffixContains,
+	personrepo.FilterSuffixNot,
+}
+
+// parseListFilter разбирает параметры фильтрации и сортировки списка персон, общие для списка и выгрузки.
+// Возвращает: фильтр, сообщение для ответа 400 при ошибке, ошибка.
+func parseListFilter(ctx fiber.Ctx) (map[string]any, string, error) {
+	filter := make(map[string]any)
+	if err := parseTextFilters(ctx, filter); err != nil {
+		return nil, err.Error(), fmt.Errorf("invalid filter: %w", err)
+	}
+
+	if ageStr := ctx.Query("age"); ageStr != "" {
+		if age, err := strconv.Atoi(ageStr); err == nil {
+			filter["age"] = age
+		}
+	}
+
+	if phoneticStr := ctx.Query("phonetic"); phoneticStr != "" {
+		usePhonetic, err := strconv.ParseBool(phoneticStr)
+		if err != nil {
+			return nil, "Invalid phonetic parameter", fmt.Errorf("invalid phonetic: %w", err)
+		}
+		if usePhonetic {
+			filter[personrepo.FilterPhonetic] = true
+		}
+	}
+
+	if err := parseRangeFilters(ctx, filter); err != nil {
+		return nil, err.Error(), fmt.Errorf("invalid filter: %w", err)
+	}
+
+	if sortSpec := ctx.Query("sort"); sortSpec != "" {
+		sort, err := personrepo.ParseSort(sortSpec)
+		if err != nil {
+			return nil, "Invalid sort parameter: " + err.Error(), fmt.Errorf("invalid sort: %w", err)
+		}
+		filter[personrepo.FilterSort] = sort
+	}
+
+	return filter, "", nil
+}
+
+// parseTextFilters добавляет в фильтр текстовые условия из параметров запроса.
+// Значения через запятую передаются списком, одиночное значение ключа без суффикса — строкой.
+// Возвращает ошибку, оборачивающую personrepo.ErrInvalidFilter, если параметр некорректен.
+func parseTextFilters(ctx fiber.Ctx, filter map[string]any) error {
+	for column := range personrepo.TextColumns {
+		for _, suffix := range textFilterSuffixes {
+			key := column + suffix
+			value := ctx.Query(key)
+			if value == "" {
+				continue
+			}
+			if suffix == "" && !strings.Contains(value, ",") {
+				filter[key] = value
+				continue
+			}
+
+			var values []string
+			for _, part := range strings.Split(value, ",") {
+				if part = strings.TrimSpace(part); part != "" {
+					values = append(values, part)
+				}
+			}
+			if len(values) == 0 || len(values) > maxFilterValues {
+				return fmt.Errorf("%w: %s must contain from 1 to %d comma-separated values", personrepo.ErrInvalidFilter, key, maxFilterValues)
+			}
+			filter[key] = values
+		}
+	}
+	return nil
+}
+
+// parseRangeFilters добавляет в фильтр условия диапазонов и наличия значений из параметров запроса.
+// Возвращает ошибку, оборачивающую personrepo.ErrInvalidFilter, если параметр некорректен.
+func parseRangeFilters(ctx fiber.Ctx, filter map[string]any) error {
+	for _, key := range []string{personrepo.FilterAgeMin, personrepo.FilterAgeMax} {
+		value := ctx.Query(key)
+		if value == "" {
+			continue
+		}
+		age, err := strconv.Atoi(value)
+		if err != nil || age < 0 {
+			return fmt.Errorf("%w: %s must be a non-negative integer", personrepo.ErrInvalidFilter, key)
+		}
+		filter[key] = age
+	}
+
+	if minAge, ok := filter[personrepo.FilterAgeMin].(int); ok {
+		if maxAge, ok := filter[personrepo.FilterAgeMax].(int); ok && minAge > maxAge {
+			return fmt.Errorf("%w: age_min cannot be greater than age_max", personrepo.ErrInvalidFilter)
+		}
+	}
+
+	for _, key := range []string{personrepo.FilterGenderProbabilityMin, personrepo.FilterNationalityProbabilityMin} {
+		value := ctx.Query(key)
+		if value == "" {
+			continue
+		}
+		probability, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(probability) || probability < 0 || probability > 1 {
+			return fmt.Errorf("%w: %s must be a number between 0 and 1", personrepo.ErrInvalidFilter, key)
+		}
+		filter[key] = probability
+	}
+
+	for _, key := range []string{personrepo.FilterCreatedAfter, personrepo.FilterCreatedBefore, personrepo.FilterUpdatedAfter} {
+		value := ctx.Query(key)
+		if value == "" {
+			continue
+		}
+		moment, err := parseFilterTime(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be an RFC 3339 timestamp or a YYYY-MM-DD date", personrepo.ErrInvalidFilter, key)
+		}
+		filter[key] = moment
+	}
+
+	if after, ok := filter[personrepo.FilterCreatedAfter].(time.Time); ok {
+		if before, ok := filter[personrepo.FilterCreatedBefore].(time.Time); ok && !after.Before(before) {
+			return fmt.Errorf("%w: created_after must be earlier than created_before", personrepo.ErrInvalidFilter)
+		}
+	}
+
+	missing, err := parseColumnList(ctx, personrepo.FilterMissing)
+	if err != nil {
+		return err
+	}
+	present, err := parseColumnList(ctx, personrepo.FilterPresent)
+	if err != nil {
+		return err
+	}
+	for _, column := range missing {
+		for _, other := range present {
+			if column == other {
+				return fmt.Errorf("%w: column %q cannot be both missing and present", personrepo.ErrInvalidFilter, column)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		filter[personrepo.FilterMissing] = missing
+	}
+	if len(present) > 0 {
+		filter[personrepo.FilterPresent] = present
+	}
+
+	return nil
+}
+
+// parseFilterTime разбирает время в формате RFC 3339 или дату в формате YYYY-MM-DD (начало суток в UTC).
+func parseFilterTime(value string) (time.Time, error) {
+	if moment, err := time.Parse(time.RFC3339, value); err == nil {
+		return moment.UTC(), nil
+	}
+	moment, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse time %q: %w", value, err)
+	}
+	return moment, nil
+}
+
+// parseColumnList разбирает список колонок через запятую и проверяет, что все они могут быть не заполнены.
+func parseColumnList(ctx fiber.Ctx, key string) ([]string, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	columns := make([]string, 0, len(parts))
+	for _, part := range parts {
+		column := strings.TrimSpace(part)
+		if !personrepo.NullableColumns[column] {
+			return nil, fmt.Errorf("%w: %s: unknown or required column %q", personrepo.ErrInvalidFilter, key, column)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
@@ -8,8 +8,12 @@ import (
 	"strings"
 
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/enrich"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/merge"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -18,7 +22,7 @@ import (
 
 // Ошибки, которые могут возникнуть при работе с персонами.
 var (
-	ErrNameSurnameRequired = errors.New("name and surname are required")
+	ErrNameSurnameRequired = entities.ErrNameSurnameRequired
 	ErrPersonNotFound      = errors.New("person not found")
 )
 
@@ -26,6 +30,7 @@ var (
 type PersonHandler struct {
 	api          api.API
 	repositories repo.Repositories
+	merges       *merge.Service
 }
 
 // NewPersonHandler создает новый обработчик для работы с персонами.
@@ -33,58 +38,91 @@ func NewPersonHandler(api api.API, repositories repo.Repositories) *PersonHandle
 	return &PersonHandler{
 		api:          api,
 		repositories: repositories,
+		merges:       merge.NewService(repositories),
 	}
 }
 
 // GetPersons godoc
 // @Summary Get list of persons
-// @Description Get a list of persons with filtering and pagination
+// @Description Get a list of persons with filtering, sorting and pagination. By default persons are ordered by creation time (newest first).
+// @Description Pass next_cursor from the previous page as cursor (with the same sort) for stable keyset pagination.
+// @Description Text filters (name, surname, patronymic, gender, nationality) accept the suffixes _exact, _prefix, _contains and _not to choose the match mode
 // @Tags persons
 // @Accept json
 // @Produce json
 // @Param limit query int false "Page size limit" default(10) minimum(1)
-// @Param offset query int false "Page offset" default(0) minimum(0)
-// @Param name query string false "Filter by name"
-// @Param surname query string false "Filter by surname"
-// @Param patronymic query string false "Filter by patronymic"
-// @Param gender query string false "Filter by gender"
-// @Param nationality query string false "Filter by nationality"
+// @Param offset query int false "Page offset, ignored when cursor is set" default(0) minimum(0)
+// @Param sort query string false "Comma-separated sort columns, prefix - for descending: name, surname, age, gender_probability, nationality_probability, created_at, updated_at" example(surname,-age)
+// @Param cursor query string false "Opaque cursor from next_cursor of the previous page"
+// @Param skip_count query bool false "Skip counting the total number of persons" default(false)
+// @Param name query string false "Filter by name substring, comma-separated values are combined with OR"
+// @Param surname query string false "Filter by surname substring, comma-separated values are combined with OR"
+// @Param name_exact query string false "Comma-separated exact names (case-insensitive)"
+// @Param name_prefix query string false "Comma-separated name prefixes"
+// @Param name_contains query string false "Comma-separated name substrings"
+// @Param phonetic query bool false "Match name and surname by phonetic keys instead of substring" default(false)
+// @Param patronymic query string false "Filter by patronymic substring, comma-separated values are combined with OR"
+// @Param gender query string false "Comma-separated genders (exact match)" example(male,female)
+// @Param nationality query string false "Comma-separated nationality codes (exact match)" example(RU,UA,BY)
+// @Param nationality_not query string false "Comma-separated nationality codes to exclude"
 // @Param age query int false "Filter by age"
+// @Param age_min query int false "Minimum age (inclusive)" minimum(0)
+// @Param age_max query int false "Maximum age (inclusive)" minimum(0)
+// @Param gender_probability_min query number false "Minimum gender probability (inclusive)" minimum(0) maximum(1)
+// @Param nationality_probability_min query number false "Minimum nationality probability (inclusive)" minimum(0) maximum(1)
+// @Param created_after query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_after query string false "Updated at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param missing query string false "Comma-separated columns that must be empty: patronymic, age, gender, gender_probability, nationality, nationality_probability"
+// @Param present query string false "Comma-separated columns that must be filled"
+// @Param include_deleted query bool false "Include soft-deleted persons (admin only)"
+// @Param X-Admin-Token header string false "Admin token"
 // @Success 200 {object} map[string]interface{} "Successfully retrieved persons list"
+// @Failure 400 {object} map[string]string "Bad request - Invalid filter, include_deleted, sort, cursor, skip_count or phonetic"
+// @Failure 403 {object} map[string]string "Admin privileges required"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /persons [get]
 func (h *PersonHandler) GetPersons(ctx fiber.Ctx) error {
 	requestCtx := ctx.Context()
 	logger.Debug(requestCtx, "handling get persons request")
 
-	// Извлечение параметров пагинации
-	limitStr := ctx.Query("limit", "10")
-	offsetStr := ctx.Query("offset", "0")
+	readCtx, err := readContext(ctx)
+	if err != nil {
+		return sendReadContextError(ctx, err)
+	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 10
+	limit, offset := paginationParams(ctx)
+
+	filter, message, err := parseListFilter(ctx)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, message, err)
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+	sort := personrepo.DefaultSort
+	if fields, ok := filter[personrepo.FilterSort].([]personrepo.SortField); ok {
+		sort = fields
 	}
 
-	filter := make(map[string]any)
-	for _, field := range []string{"name", "surname", "patronymic", "gender", "nationality"} {
-		if value := ctx.Query(field); value != "" {
-			filter[field] = value
+	if token := ctx.Query("cursor"); token != "" {
+		cursor, err := personrepo.DecodeCursor(token, sort)
+		if err != nil {
+			return sendError(ctx, fiber.StatusBadRequest, "Invalid cursor", fmt.Errorf("invalid cursor: %w", err))
 		}
+		filter[personrepo.FilterCursor] = cursor
+		offset = 0
 	}
 
-	if ageStr := ctx.Query("age"); ageStr != "" {
-		if age, err := strconv.Atoi(ageStr); err == nil {
-			filter["age"] = age
+	if skipCountStr := ctx.Query("skip_count"); skipCountStr != "" {
+		skipCount, err := strconv.ParseBool(skipCountStr)
+		if err != nil {
+			return sendError(ctx, fiber.StatusBadRequest, "Invalid skip_count parameter", fmt.Errorf("invalid skip_count: %w", err))
+		}
+		if skipCount {
+			filter[personrepo.FilterSkipCount] = true
 		}
 	}
 
-	persons, total, err := h.repositories.People().Person().GetPersons(requestCtx, filter, offset, limit)
+	persons, total, err := h.repositories.People().Person().GetPersons(readCtx, filter, offset, limit)
 	if err != nil {
 		logger.Error(requestCtx, "failed to get persons", zap.Error(err))
 		if err := ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -95,12 +133,20 @@ func (h *PersonHandler) GetPersons(ctx fiber.Ctx) error {
 		return fmt.Errorf("failed to get persons: %w", err)
 	}
 
-	if err := ctx.JSON(fiber.Map{
+	response := fiber.Map{
 		"data":   persons,
-		"total":  total,
 		"limit":  limit,
 		"offset": offset,
-	}); err != nil {
+	}
+	if total >= 0 {
+		response["total"] = total
+	}
+	// Полная страница означает, что за ней могут быть еще записи.
+	if len(persons) > 0 && len(persons) == limit {
+		response["next_cursor"] = personrepo.CursorAfter(persons[len(persons)-1], sort).Encode()
+	}
+
+	if err := ctx.JSON(response); err != nil {
 		return fmt.Errorf("failed to send JSON response: %w", err)
 	}
 	return nil
@@ -113,8 +159,13 @@ func (h *PersonHandler) GetPersons(ctx fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Person UUID" format(uuid)
+// @Param include_deleted query bool false "Include soft-deleted persons (admin only)"
+// @Param X-Admin-Token header string false "Admin token"
 // @Success 200 {object} entities.Person "Successfully retrieved person"
+// @Header 200 {string} ETag "Current person version"
 // @Failure 400 {object} map[string]string "Bad request - Invalid UUID"
+// @Failure 403 {object} map[string]string "Admin privileges required"
+// @Failure 308 {object} map[string]string "Person has been merged, Location points to the survivor"
 // @Failure 404 {object} map[string]string "Person not found"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /persons/{id} [get]
@@ -134,9 +185,22 @@ func (h *PersonHandler) GetPersonByID(ctx fiber.Ctx) error {
 		return fmt.Errorf("invalid UUID format: %w", err)
 	}
 
-	person, err := h.repositories.People().Person().GetByID(requestCtx, personID)
+	readCtx, err := readContext(ctx)
+	if err != nil {
+		return sendReadContextError(ctx, err)
+	}
+
+	person, err := h.repositories.People().Person().GetByID(readCtx, personID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
+			redirected, err := h.redirectMerged(ctx, personID)
+			if redirected {
+				return err
+			}
+			if err != nil {
+				logger.Error(requestCtx, "failed to get merge target", zap.Error(err))
+				return sendError(ctx, fiber.StatusInternalServerError, "Failed to get person", fmt.Errorf("failed to get merge target: %w", err))
+			}
 			if err := ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Person not found",
 			}); err != nil {
@@ -152,6 +216,7 @@ func (h *PersonHandler) GetPersonByID(ctx fiber.Ctx) error {
 		return fmt.Errorf("failed to get person: %w", err)
 	}
 
+	setETag(ctx, person.Version)
 	if err := ctx.JSON(person); err != nil {
 		return fmt.Errorf("failed to send JSON response: %w", err)
 	}
@@ -206,6 +271,7 @@ func (h *PersonHandler) CreatePerson(ctx fiber.Ctx) error {
 		return fmt.Errorf("failed to create person: %w", err)
 	}
 
+	setETag(ctx, person.Version)
 	if err := ctx.Status(fiber.StatusCreated).JSON(person); err != nil {
 		return fmt.Errorf("failed to send JSON response: %w", err)
 	}
@@ -220,9 +286,12 @@ func (h *PersonHandler) CreatePerson(ctx fiber.Ctx) error {
 // @Produce json
 // @Param id path string true "Person UUID" format(uuid)
 // @Param person body entities.Person true "Person data to update"
+// @Param If-Match header string false "Expected person version (ETag)"
 // @Success 200 {object} entities.Person "Successfully updated person"
+// @Header 200 {string} ETag "New person version"
 // @Failure 400 {object} map[string]string "Bad request - Invalid input"
 // @Failure 404 {object} map[string]string "Person not found"
+// @Failure 412 {object} map[string]string "Person version does not match If-Match or the person does not exist"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /persons/{id} [put]
 func (h *PersonHandler) UpdatePerson(ctx fiber.Ctx) error {
@@ -241,6 +310,11 @@ func (h *PersonHandler) UpdatePerson(ctx fiber.Ctx) error {
 		return fmt.Errorf("invalid UUID format: %w", err)
 	}
 
+	match, err := ifMatchVersion(ctx)
+	if err != nil {
+		return sendPreconditionError(ctx, err)
+	}
+
 	exists, err := h.repositories.People().Person().ExistsByID(requestCtx, personID)
 	if err != nil {
 		logger.Error(requestCtx, "failed to check if person exists", zap.Error(err))
@@ -253,12 +327,7 @@ func (h *PersonHandler) UpdatePerson(ctx fiber.Ctx) error {
 	}
 
 	if !exists {
-		if err := ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Person not found",
-		}); err != nil {
-			return fmt.Errorf("failed to send JSON response: %w", err)
-		}
-		return fmt.Errorf("%w", ErrPersonNotFound)
+		return sendMissingTarget(ctx, match, "Person not found", fmt.Errorf("%w", ErrPersonNotFound))
 	}
 
 	var person entities.Person
@@ -272,6 +341,7 @@ func (h *PersonHandler) UpdatePerson(ctx fiber.Ctx) error {
 	}
 
 	person.ID = personID
+	person.Version = match.version
 
 	if person.Name == "" || person.Surname == "" {
 		if err := ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -282,7 +352,19 @@ func (h *PersonHandler) UpdatePerson(ctx fiber.Ctx) error {
 		return fmt.Errorf("%w", ErrNameSurnameRequired)
 	}
 
-	if err := h.repositories.People().Person().UpdatePerson(requestCtx, &person); err != nil {
+	// UpdatePerson записывает в person новую версию, поэтому каждая попытка транзакции обновляет свою копию.
+	err = h.repositories.WithTx(requestCtx, func(tx repo.Repositories) error {
+		updated := person
+		if err := tx.People().Person().UpdatePerson(requestCtx, &updated); err != nil {
+			return err
+		}
+		person = updated
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, personrepo.ErrVersionConflict) {
+			return sendError(ctx, fiber.StatusPreconditionFailed, "Person has been modified", fmt.Errorf("version conflict: %w", err))
+		}
 		logger.Error(requestCtx, "failed to update person", zap.Error(err))
 		if err := ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update person",
@@ -292,6 +374,7 @@ func (h *PersonHandler) UpdatePerson(ctx fiber.Ctx) error {
 		return fmt.Errorf("failed to update person: %w", err)
 	}
 
+	setETag(ctx, person.Version)
 	if err := ctx.JSON(person); err != nil {
 		return fmt.Errorf("failed to send JSON response: %w", err)
 	}
@@ -300,14 +383,16 @@ func (h *PersonHandler) UpdatePerson(ctx fiber.Ctx) error {
 
 // DeletePerson godoc
 // @Summary Delete person
-// @Description Delete a person by UUID
+// @Description Soft delete a person by UUID. The person can be restored until it is purged by the retention job
 // @Tags persons
 // @Accept json
 // @Produce json
 // @Param id path string true "Person UUID" format(uuid)
+// @Param If-Match header string false "Expected person version (ETag)"
 // @Success 204 "Successfully deleted"
 // @Failure 400 {object} map[string]string "Bad request - Invalid UUID"
 // @Failure 404 {object} map[string]string "Person not found"
+// @Failure 412 {object} map[string]string "Person version does not match If-Match or the person does not exist"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /persons/{id} [delete]
 func (h *PersonHandler) DeletePerson(ctx fiber.Ctx) error {
@@ -326,14 +411,17 @@ func (h *PersonHandler) DeletePerson(ctx fiber.Ctx) error {
 		return fmt.Errorf("invalid UUID format: %w", err)
 	}
 
-	if err := h.repositories.People().Person().DeletePerson(requestCtx, personID); err != nil {
+	match, err := ifMatchVersion(ctx)
+	if err != nil {
+		return sendPreconditionError(ctx, err)
+	}
+
+	if err := h.repositories.People().Person().DeletePerson(requestCtx, personID, match.version); err != nil {
+		if errors.Is(err, personrepo.ErrVersionConflict) {
+			return sendError(ctx, fiber.StatusPreconditionFailed, "Person has been modified", fmt.Errorf("version conflict: %w", err))
+		}
 		if strings.Contains(err.Error(), "not found") {
-			if err := ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Person not found",
-			}); err != nil {
-				return fmt.Errorf("failed to send JSON response: %w", err)
-			}
-			return fmt.Errorf("person not found: %w", err)
+			return sendMissingTarget(ctx, match, "Person not found", fmt.Errorf("person not found: %w", err))
 		}
 		logger.Error(requestCtx, "failed to delete person", zap.Error(err))
 		if err := ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -350,6 +438,50 @@ func (h *PersonHandler) DeletePerson(ctx fiber.Ctx) error {
 	return nil
 }
 
+// RestorePerson godoc
+// @Summary Restore person
+// @Description Restore a soft-deleted person by UUID
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param id path string true "Person UUID" format(uuid)
+// @Success 200 {object} entities.Person "Successfully restored person"
+// @Header 200 {string} ETag "New person version"
+// @Failure 400 {object} map[string]string "Bad request - Invalid UUID"
+// @Failure 404 {object} map[string]string "Person not found"
+// @Failure 409 {object} map[string]string "Person is not deleted"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/{id}/restore [post]
+func (h *PersonHandler) RestorePerson(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	idParam := ctx.Params("id")
+
+	logger.Debug(requestCtx, "handling restore person request", zap.String("id", idParam))
+
+	personID, err := uuid.Parse(idParam)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid UUID format", fmt.Errorf("invalid UUID format: %w", err))
+	}
+
+	person, err := h.repositories.People().Person().RestorePerson(requestCtx, personID)
+	if err != nil {
+		if errors.Is(err, personrepo.ErrPersonNotDeleted) {
+			return sendError(ctx, fiber.StatusConflict, "Person is not deleted", fmt.Errorf("person is not deleted: %w", err))
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return sendError(ctx, fiber.StatusNotFound, "Person not found", fmt.Errorf("person not found: %w", err))
+		}
+		logger.Error(requestCtx, "failed to restore person", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to restore person", fmt.Errorf("failed to restore person: %w", err))
+	}
+
+	setETag(ctx, person.Version)
+	if err := ctx.JSON(person); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
 // EnrichPerson godoc
 // @Summary Enrich person data
 // @Description Enrich person with age, gender, and nationality data from external APIs
@@ -357,9 +489,14 @@ func (h *PersonHandler) DeletePerson(ctx fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Person UUID" format(uuid)
+// @Param If-Match header string false "Expected person version (ETag)"
 // @Success 200 {object} entities.Person "Successfully enriched person"
+// @Header 200 {string} ETag "New person version"
 // @Failure 400 {object} map[string]string "Bad request - Invalid UUID"
 // @Failure 404 {object} map[string]string "Person not found"
+// @Failure 412 {object} map[string]string "Person version does not match If-Match or the person does not exist"
+// @Failure 429 {object} map[string]string "Tenant enrichment quota exceeded"
+// @Header 429 {string} Retry-After "Seconds until the quota resets"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /persons/{id}/enrich [post]
 func (h *PersonHandler) EnrichPerson(ctx fiber.Ctx) error {
@@ -378,15 +515,15 @@ func (h *PersonHandler) EnrichPerson(ctx fiber.Ctx) error {
 		return fmt.Errorf("invalid UUID format: %w", err)
 	}
 
+	match, err := ifMatchVersion(ctx)
+	if err != nil {
+		return sendPreconditionError(ctx, err)
+	}
+
 	person, err := h.repositories.People().Person().GetByID(requestCtx, personID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			if err := ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Person not found",
-			}); err != nil {
-				return fmt.Errorf("failed to send JSON response: %w", err)
-			}
-			return fmt.Errorf("person not found: %w", err)
+			return sendMissingTarget(ctx, match, "Person not found", fmt.Errorf("person not found: %w", err))
 		}
 		logger.Error(requestCtx, "failed to get person", zap.Error(err))
 		if err := ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -397,49 +534,47 @@ func (h *PersonHandler) EnrichPerson(ctx fiber.Ctx) error {
 		return fmt.Errorf("failed to get person: %w", err)
 	}
 
-	if person.Age == nil {
-		ageService := h.api.People().Age()
-		age, probability, err := ageService.GetAgeByName(requestCtx, person.Name)
-		if err == nil {
-			person.Age = &age
-			logger.Debug(requestCtx, "enriched with age data",
-				zap.Int("age", age),
-				zap.Float64("probability", probability))
-		} else {
-			logger.Warn(requestCtx, "failed to get age data", zap.Error(err))
-		}
+	if match.version != 0 && person.Version != match.version {
+		return sendError(ctx, fiber.StatusPreconditionFailed, "Person has been modified",
+			fmt.Errorf("%w: expected version %d, actual %d", personrepo.ErrVersionConflict, match.version, person.Version))
 	}
 
-	if person.Gender == nil {
-		genderService := h.api.People().Gender()
-		gender, probability, err := genderService.GetGenderByName(requestCtx, person.Name)
-		if err == nil {
-			person.Gender = &gender
-			person.GenderProbability = &probability
-			logger.Debug(requestCtx, "enriched with gender data",
-				zap.String("gender", gender),
-				zap.Float64("probability", probability))
-		} else {
-			logger.Warn(requestCtx, "failed to get gender data", zap.Error(err))
+	enrichment, err := enrich.Fetch(requestCtx, h.api, person)
+	if err != nil {
+		if errors.Is(err, api.ErrTenantQuotaReached) {
+			return sendQuotaExceeded(ctx, "Enrichment quota exceeded", err)
 		}
+		logger.Error(requestCtx, "failed to get enrichment data", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to get enrichment data", err)
 	}
 
-	if person.Nationality == nil {
-		nationalityService := h.api.People().Nationality()
-		nationality, probability, err := nationalityService.GetNationalityByName(requestCtx, person.Name)
-		if err == nil {
-			person.Nationality = &nationality
-			person.NationalityProbability = &probability
-			logger.Debug(requestCtx, "enriched with nationality data",
-				zap.String("nationality", nationality),
-				zap.Float64("probability", probability))
-		} else {
-			logger.Warn(requestCtx, "failed to get nationality data", zap.Error(err))
+	// Внешние сервисы отвечают долго, поэтому запрашиваются вне транзакции. Полученные данные дополняют
+	// текущее состояние персоны и не перезаписывают изменения, сделанные во время обогащения.
+	enrichCtx := historyrepo.WithOperation(requestCtx, historyrepo.OperationEnrich)
+	err = h.repositories.WithTx(requestCtx, func(tx repo.Repositories) error {
+		current, err := tx.People().Person().GetByID(requestCtx, personID)
+		if err != nil {
+			return err
 		}
-	}
-
-	err = h.repositories.People().Person().UpdatePerson(requestCtx, person)
+		if match.version != 0 && current.Version != match.version {
+			return fmt.Errorf("%w: expected version %d, actual %d", personrepo.ErrVersionConflict, match.version, current.Version)
+		}
+		// Без новых данных персона не изменяется: версия и история остаются прежними.
+		if enrichment.ApplyTo(current) {
+			if err := tx.People().Person().UpdatePerson(enrichCtx, current); err != nil {
+				return err
+			}
+		}
+		person = current
+		return nil
+	})
 	if err != nil {
+		switch {
+		case errors.Is(err, personrepo.ErrVersionConflict):
+			return sendError(ctx, fiber.StatusPreconditionFailed, "Person has been modified", fmt.Errorf("version conflict: %w", err))
+		case strings.Contains(err.Error(), "not found"):
+			return sendMissingTarget(ctx, match, "Person not found", fmt.Errorf("person not found: %w", err))
+		}
 		logger.Error(requestCtx, "failed to save enriched data", zap.Error(err))
 		if err := ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to save enriched data",
@@ -449,8 +584,35 @@ func (h *PersonHandler) EnrichPerson(ctx fiber.Ctx) error {
 		return fmt.Errorf("failed to save enriched data: %w", err)
 	}
 
+	setETag(ctx, person.Version)
 	if err := ctx.JSON(person); err != nil {
 		return fmt.Errorf("failed to send JSON response: %w", err)
 	}
 	return nil
 }
+
+// sendError отправляет JSON-ответ с ошибкой и возвращает исходную ошибку обработчику.
+func sendError(ctx fiber.Ctx, status int, message string, cause error) error {
+	if err := ctx.Status(status).JSON(fiber.Map{
+		"error": message,
+	}); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return cause
+}
+
+// paginationParams извлекает параметры пагинации limit и offset, подставляя значения по умолчанию
+// для отсутствующих или некорректных параметров.
+func paginationParams(ctx fiber.Ctx) (int, int) {
+	limit, err := strconv.Atoi(ctx.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(ctx.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/jsonpatch"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Ошибки, которые могут возникнуть при частичном обновлении персоны.
+var (
+	ErrUnsupportedPatchType = errors.New("unsupported patch media type")
+	ErrImmutableField       = errors.New("field cannot be modified")
+	ErrInvalidFieldValue    = errors.New("invalid field value")
+)
+
+// patchFieldKind описывает тип значения изменяемого поля персоны.
+type patchFieldKind int
+
+const (
+	patchFieldRequiredString patchFieldKind = iota
+	patchFieldString
+	patchFieldInteger
+	patchFieldProbability
+)
+
+// patchableFields содержит поля персоны, доступные для частичного обновления.
+var patchableFields = map[string]patchFieldKind{
+	"name":                    patchFieldRequiredString,
+	"surname":                 patchFieldRequiredString,
+	"patronymic":              patchFieldString,
+	"age":                     patchFieldInteger,
+	"gender":                  patchFieldString,
+	"gender_probability":      patchFieldProbability,
+	"nationality":             patchFieldString,
+	"nationality_probability": patchFieldProbability,
+}
+
+// PatchPerson godoc
+// @Summary Partially update person
+// @Description Partially update a person with JSON Merge Patch (RFC 7396, application/merge-patch+json or application/json)
+// @Description or JSON Patch (RFC 6902, application/json-patch+json). Only supplied fields are changed, explicit null clears a field.
+// @Tags persons
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "Person UUID" format(uuid)
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string false "Expected person version (ETag)"
+// @Success 200 {object} entities.Person "Successfully patched person"
+// @Header 200 {string} ETag "New person version"
+// @Failure 400 {object} map[string]string "Bad request - Invalid patch or field value"
+// @Failure 404 {object} map[string]string "Person not found"
+// @Failure 409 {object} map[string]string "JSON Patch test operation failed"
+// @Failure 412 {object} map[string]string "Person version does not match If-Match or the person does not exist"
+// @Failure 415 {object} map[string]string "Unsupported patch media type"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/{id} [patch]
+func (h *PersonHandler) PatchPerson(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	idParam := ctx.Params("id")
+
+	logger.Debug(requestCtx, "handling patch person request", zap.String("id", idParam))
+
+	personID, err := uuid.Parse(idParam)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid UUID format", fmt.Errorf("invalid UUID format: %w", err))
+	}
+
+	match, err := ifMatchVersion(ctx)
+	if err != nil {
+		return sendPreconditionError(ctx, err)
+	}
+
+	apply, err := patchFunc(ctx.Get(fiber.HeaderContentType), ctx.Body())
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedPatchType) {
+			return sendError(ctx, fiber.StatusUnsupportedMediaType, "Unsupported patch media type", err)
+		}
+		return sendError(ctx, fiber.StatusBadRequest, "Invalid patch document", fmt.Errorf("invalid patch document: %w", err))
+	}
+
+	// Патч применяется к состоянию персоны, прочитанному в той же транзакции, что и запись. Ожидаемой
+	// версией служит прочитанная версия, поэтому проверка test и выбор измененных полей не могут
+	// опираться на устаревшее состояние и перезаписать параллельное изменение.
+	// rejected и rejectedMessage описывают ошибку самого патча, о которой сообщается клиенту с кодом 400.
+	var (
+		person          *entities.Person
+		rejected        error
+		rejectedMessage string
+	)
+	err = h.repositories.WithTx(requestCtx, func(tx repo.Repositories) error {
+		rejected = nil
+		current, err := tx.People().Person().GetByID(requestCtx, personID)
+		if err != nil {
+			return err
+		}
+		if match.version != 0 && current.Version != match.version {
+			return fmt.Errorf("%w: expected version %d, actual %d", personrepo.ErrVersionConflict, match.version, current.Version)
+		}
+
+		original, err := json.Marshal(current)
+		if err != nil {
+			return fmt.Errorf("failed to marshal person: %w", err)
+		}
+		patched, err := apply(original)
+		if err != nil {
+			if !errors.Is(err, jsonpatch.ErrTestFailed) {
+				rejected, rejectedMessage = fmt.Errorf("failed to apply patch: %w", err), "Failed to apply patch: "+err.Error()
+			}
+			return err
+		}
+		fields, err := changedPersonFields(original, patched)
+		if err != nil {
+			rejected, rejectedMessage = fmt.Errorf("invalid patch: %w", err), err.Error()
+			return err
+		}
+
+		if len(fields) == 0 {
+			person = current
+			return nil
+		}
+		updated, err := tx.People().Person().PatchPerson(requestCtx, personID, fields, current.Version)
+		if err != nil {
+			return err
+		}
+		person = updated
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, jsonpatch.ErrTestFailed):
+			return sendError(ctx, fiber.StatusConflict, "Patch test operation failed", fmt.Errorf("patch test failed: %w", err))
+		case rejected != nil:
+			return sendError(ctx, fiber.StatusBadRequest, rejectedMessage, rejected)
+		case errors.Is(err, personrepo.ErrVersionConflict):
+			return sendError(ctx, fiber.StatusPreconditionFailed, "Person has been modified", fmt.Errorf("version conflict: %w", err))
+		case strings.Contains(err.Error(), "not found"):
+			return sendMissingTarget(ctx, match, "Person not found", fmt.Errorf("person not found: %w", err))
+		}
+		logger.Error(requestCtx, "failed to patch person", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to patch person", fmt.Errorf("failed to patch person: %w", err))
+	}
+
+	setETag(ctx, person.Version)
+	if err := ctx.JSON(person); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// patchFunc выбирает способ применения патча по типу содержимого запроса.
+func patchFunc(contentType string, body []byte) (func(doc []byte) ([]byte, error), error) {
+	mediaType := jsonpatch.ContentTypeMergePatch
+	if contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnsupportedPatchType, err)
+		}
+		mediaType = parsed
+	}
+
+	switch mediaType {
+	case jsonpatch.ContentTypeJSONPatch:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode JSON patch: %w", err)
+		}
+		return patch.Apply, nil
+	case jsonpatch.ContentTypeMergePatch, fiber.MIMEApplicationJSON:
+		var patch map[string]json.RawMessage
+		if err := json.Unmarshal(body, &patch); err != nil {
+			return nil, fmt.Errorf("merge patch must be a JSON object: %w", err)
+		}
+		return func(doc []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(doc, body)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPatchType, mediaType)
+	}
+}
+
+// changedPersonFields сравнивает исходный и измененный документы персоны и возвращает
+// значения измененных колонок. Удаленное поле соответствует значению nil.
+func changedPersonFields(original, patched []byte) (map[string]any, error) {
+	var before, after map[string]any
+	if err := json.Unmarshal(original, &before); err != nil {
+		return nil, fmt.Errorf("failed to decode person: %w", err)
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, fmt.Errorf("%w: patched document must be an object", ErrInvalidFieldValue)
+	}
+
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			if _, patchable := patchableFields[field]; !patchable {
+				return nil, fmt.Errorf("%w: unknown field %q", ErrImmutableField, field)
+			}
+		}
+	}
+
+	fields := make(map[string]any)
+	for field, newValue := range after {
+		if jsonpatch.Equal(before[field], newValue) {
+			continue
+		}
+		kind, ok := patchableFields[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrImmutableField, field)
+		}
+		value, err := convertPatchValue(field, kind, newValue)
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = value
+	}
+
+	for field := range before {
+		if _, ok := after[field]; ok {
+			continue
+		}
+		kind, ok := patchableFields[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrImmutableField, field)
+		}
+		if kind == patchFieldRequiredString {
+			return nil, fmt.Errorf("%w: %q cannot be null", ErrInvalidFieldValue, field)
+		}
+		fields[field] = nil
+	}
+
+	return fields, nil
+}
+
+// convertPatchValue проверяет значение поля и приводит его к типу колонки.
+func convertPatchValue(field string, kind patchFieldKind, value any) (any, error) {
+	if value == nil {
+		if kind == patchFieldRequiredString {
+			return nil, fmt.Errorf("%w: %q cannot be null", ErrInvalidFieldValue, field)
+		}
+		return nil, nil
+	}
+
+	switch kind {
+	case patchFieldRequiredString, patchFieldString:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q must be a string", ErrInvalidFieldValue, field)
+		}
+		if kind == patchFieldRequiredString && strings.TrimSpace(str) == "" {
+			return nil, fmt.Errorf("%w: %q cannot be empty", ErrInvalidFieldValue, field)
+		}
+		return str, nil
+	case patchFieldInteger:
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) || number < 0 || number > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: %q must be a non-negative integer", ErrInvalidFieldValue, field)
+		}
+		return int(number), nil
+	case patchFieldProbability:
+		number, ok := value.(float64)
+		if !ok || number < 0 || number > 1 {
+			return nil, fmt.Errorf("%w: %q must be a number between 0 and 1", ErrInvalidFieldValue, field)
+		}
+		return number, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrImmutableField, field)
+	}
+}
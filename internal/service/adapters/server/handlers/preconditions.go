@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ErrInvalidIfMatch возникает, когда заголовок If-Match не может быть разобран.
+var ErrInvalidIfMatch = errors.New("invalid If-Match header")
+
+// formatETag формирует сильный ETag по версии персоны.
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// setETag добавляет в ответ заголовок ETag с версией персоны.
+func setETag(ctx fiber.Ctx, version int) {
+	ctx.Set(fiber.HeaderETag, formatETag(version))
+}
+
+// precondition описывает условие заголовка If-Match.
+type precondition struct {
+	// version ожидаемая версия персоны; 0 означает, что версия не проверяется.
+	version int
+	// present сообщает, что запрос передал заголовок If-Match.
+	present bool
+	// any сообщает, что передан If-Match: *, которому соответствует персона в любой версии,
+	// но не отсутствующая персона.
+	any bool
+}
+
+// ifMatchVersion разбирает заголовок If-Match в условие на версию персоны.
+// Поддерживается один сильный ETag; слабые ETag по RFC 9110 не проходят строгое сравнение.
+func ifMatchVersion(ctx fiber.Ctx) (precondition, error) {
+	header := strings.TrimSpace(ctx.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return precondition{}, nil
+	}
+	if header == "*" {
+		return precondition{present: true, any: true}, nil
+	}
+	invalid := precondition{present: true}
+	if strings.Contains(header, ",") {
+		return invalid, fmt.Errorf("%w: multiple entity tags are not supported", ErrInvalidIfMatch)
+	}
+	if strings.HasPrefix(header, "W/") {
+		return invalid, fmt.Errorf("%w: weak entity tags cannot be used for If-Match", ErrInvalidIfMatch)
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return invalid, fmt.Errorf("%w: %q", ErrInvalidIfMatch, header)
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return invalid, fmt.Errorf("%w: %q", ErrInvalidIfMatch, header)
+	}
+	return precondition{version: version, present: true}, nil
+}
+
+// sendPreconditionError отвечает на некорректный заголовок If-Match.
+// Слабые и множественные ETag не совпадают с текущей версией, поэтому для них возвращается 412.
+func sendPreconditionError(ctx fiber.Ctx, err error) error {
+	return sendError(ctx, fiber.StatusPreconditionFailed, "Precondition failed: "+err.Error(), err)
+}
+
+// sendMissingTarget отвечает на запрос к отсутствующей персоне. По RFC 9110 If-Match, в том числе "*",
+// не выполняется для отсутствующего ресурса, поэтому при переданном заголовке возвращается 412, а не 404.
+func sendMissingTarget(ctx fiber.Ctx, match precondition, message string, err error) error {
+	if match.present {
+		return sendError(ctx, fiber.StatusPreconditionFailed, "Precondition failed: "+message, err)
+	}
+	return sendError(ctx, fiber.StatusNotFound, message, err)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+// Ограничения поискового запроса.
+const (
+	maxSearchQueryLength = 100
+	maxSearchLimit       = 100
+)
+
+// ErrInvalidSearchQuery возникает, когда поисковый запрос пуст или слишком длинный.
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// SearchPersons godoc
+// @Summary Fuzzy search persons
+// @Description Typo-tolerant search by name, surname and patronymic using trigram similarity.
+// @Description Results are ranked by the similarity score (0..1), which is returned with every person.
+// @Description With phonetic=true name and surname are matched by phonetic keys (Soundex, Double Metaphone, Russian metaphone) and the score is the share of matched query keys
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query" maxlength(100)
+// @Param limit query int false "Maximum number of results" default(10) minimum(1) maximum(100)
+// @Param phonetic query bool false "Match by phonetic keys instead of trigram similarity" default(false)
+// @Param include_deleted query bool false "Include soft-deleted persons (admin only)"
+// @Param X-Admin-Token header string false "Admin token"
+// @Success 200 {object} map[string]interface{} "Successfully found persons"
+// @Failure 400 {object} map[string]string "Bad request - Empty or too long query, invalid phonetic"
+// @Failure 403 {object} map[string]string "Admin privileges required"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/search [get]
+func (h *PersonHandler) SearchPersons(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	query := strings.TrimSpace(ctx.Query("q"))
+
+	logger.Debug(requestCtx, "handling search persons request", zap.String("query", query))
+
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return sendError(ctx, fiber.StatusBadRequest,
+			fmt.Sprintf("Query parameter q is required and must not exceed %d characters", maxSearchQueryLength),
+			fmt.Errorf("%w: %q", ErrInvalidSearchQuery, query))
+	}
+
+	usePhonetic := false
+	if phoneticStr := ctx.Query("phonetic"); phoneticStr != "" {
+		var err error
+		if usePhonetic, err = strconv.ParseBool(phoneticStr); err != nil {
+			return sendError(ctx, fiber.StatusBadRequest, "Invalid phonetic parameter", fmt.Errorf("invalid phonetic: %w", err))
+		}
+	}
+
+	readCtx, err := readContext(ctx)
+	if err != nil {
+		return sendReadContextError(ctx, err)
+	}
+
+	limit, _ := paginationParams(ctx)
+	limit = min(limit, maxSearchLimit)
+
+	results, err := h.repositories.People().Person().SearchPersons(readCtx, query, limit, usePhonetic)
+	if err != nil {
+		logger.Error(requestCtx, "failed to search persons", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to search persons", fmt.Errorf("failed to search persons: %w", err))
+	}
+
+	if err := ctx.JSON(fiber.Map{
+		"data":     results,
+		"query":    query,
+		"limit":    limit,
+		"phonetic": usePhonetic,
+	}); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
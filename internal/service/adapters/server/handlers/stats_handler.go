@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+// Параметры статистики по умолчанию и их допустимые пределы.
+const (
+	defaultTopNationalities = 10
+	maxTopNationalities     = 100
+	defaultAgeBucketWidth   = 10
+	maxAgeBucketWidth       = 100
+)
+
+// ErrInvalidStatsParameter возникает, когда параметр статистики не является числом в допустимом диапазоне.
+var ErrInvalidStatsParameter = errors.New("invalid stats parameter")
+
+// GetPersonStats godoc
+// @Summary Get person statistics
+// @Description Aggregate persons matching the list filters: counts by gender, the most frequent nationalities,
+// @Description an age histogram, the number and share of persons with each enrichable field filled and average probabilities.
+// @Description All aggregates are computed by the database on one snapshot
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param top query int false "Number of most frequent nationalities" default(10) minimum(1) maximum(100)
+// @Param age_bucket query int false "Age histogram bucket width in years" default(10) minimum(1) maximum(100)
+// @Param name query string false "Filter by name substring, comma-separated values are combined with OR"
+// @Param surname query string false "Filter by surname substring, comma-separated values are combined with OR"
+// @Param phonetic query bool false "Match name and surname by phonetic keys instead of substring" default(false)
+// @Param patronymic query string false "Filter by patronymic substring, comma-separated values are combined with OR"
+// @Param gender query string false "Comma-separated genders (exact match)" example(male,female)
+// @Param nationality query string false "Comma-separated nationality codes (exact match)" example(RU,UA,BY)
+// @Param age query int false "Filter by age"
+// @Param age_min query int false "Minimum age (inclusive)" minimum(0)
+// @Param age_max query int false "Maximum age (inclusive)" minimum(0)
+// @Param created_after query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param missing query string false "Comma-separated columns that must be empty"
+// @Param present query string false "Comma-separated columns that must be filled"
+// @Param include_deleted query bool false "Include soft-deleted persons (admin only)"
+// @Param X-Admin-Token header string false "Admin token"
+// @Success 200 {object} entities.PersonStats "Person statistics"
+// @Failure 400 {object} map[string]string "Bad request - Invalid top, age_bucket or filter"
+// @Failure 403 {object} map[string]string "Admin privileges required"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /persons/stats [get]
+func (h *PersonHandler) GetPersonStats(ctx fiber.Ctx) error {
+	requestCtx := ctx.Context()
+	logger.Debug(requestCtx, "handling get person stats request")
+
+	readCtx, err := readContext(ctx)
+	if err != nil {
+		return sendReadContextError(ctx, err)
+	}
+
+	filter, message, err := parseListFilter(ctx)
+	if err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, message, err)
+	}
+
+	opts := personrepo.StatsOptions{}
+	if opts.TopNationalities, err = statsParam(ctx, "top", defaultTopNationalities, maxTopNationalities); err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, err.Error(), err)
+	}
+	if opts.AgeBucketWidth, err = statsParam(ctx, "age_bucket", defaultAgeBucketWidth, maxAgeBucketWidth); err != nil {
+		return sendError(ctx, fiber.StatusBadRequest, err.Error(), err)
+	}
+
+	stats, err := h.repositories.People().Person().GetStats(readCtx, filter, opts)
+	if err != nil {
+		logger.Error(requestCtx, "failed to get person stats", zap.Error(err))
+		return sendError(ctx, fiber.StatusInternalServerError, "Failed to get person stats", fmt.Errorf("failed to get person stats: %w", err))
+	}
+
+	if err := ctx.JSON(stats); err != nil {
+		return fmt.Errorf("failed to send JSON response: %w", err)
+	}
+	return nil
+}
+
+// statsParam читает целочисленный параметр статистики от 1 до maxValue или возвращает значение по умолчанию.
+func statsParam(ctx fiber.Ctx, name string, defaultValue, maxValue int) (int, error) {
+	valueStr := ctx.Query(name)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 1 || value > maxValue {
+		return 0, fmt.Errorf("%w: %s must be an integer between 1 and %d", ErrInvalidStatsParameter, name, maxValue)
+	}
+	return value, nil
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
+	tenantsetup "github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/quota"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Заголовки, по которым определяется арендатор запроса.
+const (
+	HeaderAPIKey   = "X-API-Key"
+	HeaderTenantID = "X-Tenant-ID"
+)
+
+// Ошибки определения арендатора и его квоты запросов.
+var (
+	ErrTenantRequired = errors.New("tenant credentials required")
+	ErrUnknownTenant  = errors.New("unknown tenant")
+	ErrQuotaExceeded  = errors.New("tenant request quota exceeded")
+)
+
+// TenantMiddleware определяет арендатора запроса по API-ключу из заголовка X-API-Key,
+// а если это разрешено настройками - по заголовку X-Tenant-ID, и добавляет его в контекст запроса.
+// Все запросы к хранилищу выполняются от имени этого арендатора. Запросы сверх квоты арендатора
+// отклоняются с кодом 429. Без файла арендаторов все запросы относятся к арендатору по умолчанию.
+func TenantMiddleware(registry *tenantsetup.Registry) fiber.Handler {
+	limiters := make(map[string]*quota.Limiter)
+	for _, t := range registry.Tenants() {
+		limiters[t.ID] = quota.NewLimiter(t.Quota.RequestsPerMinute, time.Minute)
+	}
+
+	return func(ctx fiber.Ctx) error {
+		t, err := resolveTenant(ctx, registry)
+		if err != nil {
+			return sendError(ctx, fiber.StatusUnauthorized, "Valid API key required", err)
+		}
+
+		if allowed, retryAfter := limiters[t.ID].Allow(t.ID); !allowed {
+			setRetryAfter(ctx, retryAfter)
+			return sendError(ctx, fiber.StatusTooManyRequests, "Request quota exceeded",
+				fmt.Errorf("%w: tenant %q", ErrQuotaExceeded, t.ID))
+		}
+
+		ctx.SetContext(tenant.WithID(ctx.Context(), t.ID))
+		return ctx.Next()
+	}
+}
+
+// sendQuotaExceeded отвечает кодом 429 на исчерпанную квоту обогащений арендатора,
+// сообщая в Retry-After время до ее восстановления.
+func sendQuotaExceeded(ctx fiber.Ctx, message string, cause error) error {
+	var quotaErr *api.QuotaError
+	if errors.As(cause, &quotaErr) {
+		setRetryAfter(ctx, quotaErr.RetryAfter)
+	}
+	return sendError(ctx, fiber.StatusTooManyRequests, message, cause)
+}
+
+// setRetryAfter устанавливает заголовок Retry-After в целых секундах с округлением вверх.
+func setRetryAfter(ctx fiber.Ctx, retryAfter time.Duration) {
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+}
+
+// resolveTenant возвращает арендатора запроса.
+func resolveTenant(ctx fiber.Ctx, registry *tenantsetup.Registry) (*tenantsetup.Tenant, error) {
+	if !registry.Configured() {
+		if t, ok := registry.Get(tenant.Default); ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, tenant.Default)
+	}
+
+	if key := ctx.Get(HeaderAPIKey); key != "" {
+		t, ok := registry.ByAPIKey(key)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid API key", ErrUnknownTenant)
+		}
+		return t, nil
+	}
+
+	if id := ctx.Get(HeaderTenantID); id != "" && registry.TrustHeader() {
+		t, ok := registry.Get(id)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, id)
+		}
+		return t, nil
+	}
+
+	return nil, ErrTenantRequired
+}
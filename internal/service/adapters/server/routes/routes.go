@@ -11,19 +11,43 @@ import (
 // Setup настраивает маршруты HTTP-сервера.
 func Setup(app *fiber.App, api api.API, repositories repo.Repositories) {
 	personHandler := handlers.NewPersonHandler(api, repositories)
+	importHandler := handlers.NewImportHandler(api, repositories)
 
 	// Группа для API версии 1.
 	v1 := app.Group("/api/v1")
 
 	// Маршруты для работы с персонами.
 	persons := v1.Group("/persons")
-	persons.Get("/", personHandler.GetPersons)         // Получение списка с фильтрами и пагинацией.
-	persons.Get("/:id", personHandler.GetPersonByID)   // Получение по ID.
-	persons.Post("/", personHandler.CreatePerson)      // Создание новой персоны.
-	persons.Put("/:id", personHandler.UpdatePerson)    // Обновление персоны.
-	persons.Patch("/:id", personHandler.UpdatePerson)  // Частичное обновление персоны.
-	persons.Delete("/:id", personHandler.DeletePerson) // Удаление персоны.
+	persons.Get("/", personHandler.GetPersons)               // Получение списка с фильтрами и пагинацией.
+	persons.Get("/search", personHandler.SearchPersons)      // Нечеткий поиск (регистрируется до /:id).
+	persons.Get("/export", personHandler.ExportPersons)      // Потоковая выгрузка в CSV, NDJSON или Parquet.
+	persons.Get("/duplicates", personHandler.FindDuplicates) // Группы вероятных дубликатов.
+	persons.Get("/stats", personHandler.GetPersonStats)      // Агрегированная статистика по фильтрам списка.
+	persons.Get("/:id", personHandler.GetPersonByID)         // Получение по ID.
+	persons.Post("/", personHandler.CreatePerson)            // Создание новой персоны.
+	persons.Post("/bulk", personHandler.BulkCreatePersons)   // Массовое создание персон (JSON-массив или NDJSON).
+	persons.Post("/merge", personHandler.MergePersons)       // Объединение дубликатов.
+	persons.Put("/:id", personHandler.UpdatePerson)          // Обновление персоны.
+	persons.Patch("/:id", personHandler.PatchPerson)         // Частичное обновление персоны (JSON Merge Patch / JSON Patch).
+	persons.Delete("/:id", personHandler.DeletePerson)       // Мягкое удаление персоны.
+
+	// Маршруты для персон, связанных с идентификаторами внешних систем.
+	persons.Get("/by-external/:source/:externalId", personHandler.GetPersonByExternalID)
+	persons.Put("/by-external/:source/:externalId", personHandler.UpsertPersonByExternalID)
+
+	// Маршрут для восстановления мягко удаленной персоны.
+	persons.Post("/:id/restore", personHandler.RestorePerson)
+
+	// Маршруты для истории изменений персоны.
+	persons.Get("/:id/history", personHandler.GetPersonHistory)
+	persons.Post("/:id/history/:version/revert", personHandler.RevertPerson)
 
 	// Маршрут для обогащения данных персоны.
 	persons.Post("/:id/enrich", personHandler.EnrichPerson)
+
+	// Маршруты для импорта персон из файлов CSV и NDJSON.
+	imports := v1.Group("/imports")
+	imports.Post("/", importHandler.CreateImport)             // Загрузка файла (multipart/form-data).
+	imports.Get("/:id", importHandler.GetImport)              // Итоги импорта.
+	imports.Get("/:id/report", importHandler.GetImportReport) // Построчный отчет об ошибках в CSV.
 }
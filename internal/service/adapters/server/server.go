@@ -4,16 +4,18 @@ package server
 import (
 	"context"
 	"fmt"
+	"os"
 
+	"github.com/flexer2006/case-person-enrichment-go/docs/swagger"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/server/handlers"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/server/routes"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/server"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
 	"github.com/gofiber/fiber/v3"
 	"go.uber.org/zap"
-
-	_ "github.com/flexer2006/case-person-enrichment-go/docs/swagger"
 )
 
 // @title Person Enrichment API
@@ -31,8 +33,8 @@ type Server struct {
 	config server.Config
 }
 
-// New создает новый экземпляр HTTP-сервера.
-func New(config server.Config, api api.API, repositories repo.Repositories) *Server {
+// New создает новый экземпляр HTTP-сервера. Запросы к API выполняются от имени арендаторов из tenants.
+func New(config server.Config, api api.API, repositories repo.Repositories, tenants *tenant.Registry) *Server {
 	app := fiber.New(fiber.Config{
 		ReadTimeout:  config.ReadTimeout,
 		WriteTimeout: config.WriteTimeout,
@@ -45,14 +47,24 @@ func New(config server.Config, api api.API, repositories repo.Repositories) *Ser
 		return r.To("/swagger/swagger.html")
 	})
 
+	assets := fiber.SendFile{FS: swagger.Assets()}
+	if config.SwaggerDir != "" {
+		assets.FS = os.DirFS(config.SwaggerDir)
+	}
+
 	app.Get("/swagger/swagger.html", func(c fiber.Ctx) error {
-		return c.SendFile("./docs/swagger/swagger.html")
+		return c.SendFile("swagger.html", assets)
 	})
 
 	app.Get("/swagger/swagger.json", func(c fiber.Ctx) error {
-		return c.SendFile("./docs/swagger/swagger.json")
+		return c.SendFile("swagger.json", assets)
 	})
 
+	app.Use(handlers.AuditMiddleware())
+	app.Use(handlers.TenantMiddleware(tenants))
+	app.Use(handlers.ReadYourWritesMiddleware())
+	app.Use(handlers.AdminMiddleware(config.AdminToken))
+
 	routes.Setup(app, api, repositories)
 
 	return &Server{
@@ -2,16 +2,25 @@ package server_test
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/server"
 	apipeople "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
 	repopeople "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
 	serverconfig "github.com/flexer2006/case-person-enrichment-go/internal/service/setup/server"
+	tenantconfig "github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockAPI struct {
@@ -23,6 +32,11 @@ func (m *MockAPI) People() apipeople.Services {
 	return args.Get(0).(apipeople.Services)
 }
 
+func (m *MockAPI) ReserveEnrichment(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 type MockPeopleServices struct {
 	mock.Mock
 }
@@ -56,6 +70,11 @@ func (m *MockRepositories) People() repopeople.Repositories {
 	return args.Get(0).(repopeople.Repositories)
 }
 
+// WithTx выполняет fn без транзакции: моки не хранят данные.
+func (m *MockRepositories) WithTx(_ context.Context, fn func(tx repo.Repositories) error) error {
+	return fn(m)
+}
+
 type MockPeopleRepositories struct {
 	mock.Mock
 }
@@ -89,7 +108,7 @@ func TestNew(t *testing.T) {
 	mockRepositories := new(MockRepositories)
 	mockRepositories.On("People").Return(mockPeopleRepositories).Maybe()
 
-	s := server.New(config, mockAPI, mockRepositories)
+	s := server.New(config, mockAPI, mockRepositories, tenantconfig.DefaultRegistry())
 
 	assert.NotNil(t, s, "Server instance should not be nil")
 	assert.Equal(t, config, s.GetConfig(), "Server should store the provided config")
@@ -113,7 +132,7 @@ func TestNewWithEmptyConfig(t *testing.T) {
 	mockRepositories := new(MockRepositories)
 	mockRepositories.On("People").Return(mockPeopleRepositories).Maybe()
 
-	s := server.New(config, mockAPI, mockRepositories)
+	s := server.New(config, mockAPI, mockRepositories, tenantconfig.DefaultRegistry())
 
 	assert.NotNil(t, s, "Server instance should not be nil")
 	assert.Equal(t, config, s.GetConfig(), "Server should store the provided config")
@@ -156,7 +175,7 @@ func TestServerStart(t *testing.T) {
 	mockRepositories := new(MockRepositories)
 	mockRepositories.On("People").Return(mockPeopleRepositories).Maybe()
 
-	s := server.New(config, mockAPI, mockRepositories)
+	s := server.New(config, mockAPI, mockRepositories, tenantconfig.DefaultRegistry())
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -203,7 +222,7 @@ func TestServerStartWithEmptyConfig(t *testing.T) {
 	mockRepositories := new(MockRepositories)
 	mockRepositories.On("People").Return(mockPeopleRepositories).Maybe()
 
-	s := server.New(config, mockAPI, mockRepositories)
+	s := server.New(config, mockAPI, mockRepositories, tenantconfig.DefaultRegistry())
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -253,7 +272,7 @@ func TestStop(t *testing.T) {
 	mockRepositories := new(MockRepositories)
 	mockRepositories.On("People").Return(mockPeopleRepositories).Maybe()
 
-	s := server.New(config, mockAPI, mockRepositories)
+	s := server.New(config, mockAPI, mockRepositories, tenantconfig.DefaultRegistry())
 
 	ctx := context.Background()
 
@@ -285,7 +304,7 @@ func TestStopWithCanceledContext(t *testing.T) {
 	mockRepositories := new(MockRepositories)
 	mockRepositories.On("People").Return(mockPeopleRepositories).Maybe()
 
-	s := server.New(config, mockAPI, mockRepositories)
+	s := server.New(config, mockAPI, mockRepositories, tenantconfig.DefaultRegistry())
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -298,3 +317,77 @@ func TestStopWithCanceledContext(t *testing.T) {
 		t.Log("No error returned with canceled context, which is acceptable in some cases")
 	}
 }
+
+// startSwaggerServer запускает сервер на свободном порту и возвращает его адрес.
+func startSwaggerServer(t *testing.T, config serverconfig.Config) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	config.Host = "127.0.0.1"
+	config.Port = listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	mockRepositories := new(MockRepositories)
+	mockRepositories.On("People").Return(new(MockPeopleRepositories)).Maybe()
+	s := server.New(config, new(MockAPI), mockRepositories, tenantconfig.DefaultRegistry())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, s.Start(ctx))
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, s.Stop(context.Background()))
+		cancel()
+		<-done
+	})
+
+	address := fmt.Sprintf("http://%s:%d", config.Host, config.Port)
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(address)
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+	return address
+}
+
+func getBody(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestSwaggerServedFromEmbeddedAssets(t *testing.T) {
+	// Встроенные файлы не зависят от рабочего каталога.
+	t.Chdir(t.TempDir())
+	address := startSwaggerServer(t, serverconfig.Config{})
+
+	status, body := getBody(t, address+"/swagger/swagger.json")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"swagger"`)
+
+	status, body = getBody(t, address+"/swagger/swagger.html")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "/swagger/swagger.json")
+}
+
+func TestSwaggerServedFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "swagger.json"), []byte(`{"custom": true}`), 0o600))
+	address := startSwaggerServer(t, serverconfig.Config{SwaggerDir: dir})
+
+	status, body := getBody(t, address+"/swagger/swagger.json")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"custom": true}`, body)
+}
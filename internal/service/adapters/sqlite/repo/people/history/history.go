@@ -0,0 +1,193 @@
+// Package history содержит реализацию репозитория истории изменений персон с использованием SQLite.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrHistoryNotFound возникает, когда запись истории не найдена.
+var ErrHistoryNotFound = errors.New("person history not found")
+
+// historyColumns перечисляет колонки таблицы person_history в порядке сканирования scanHistory.
+const historyColumns = `id, person_id, version, operation, before, after, changed_fields, related_ids, actor, request_id, created_at`
+
+// Проверка реализации интерфейса.
+var _ history.Repository = (*Repository)(nil)
+
+// Repository реализует интерфейс history.Repository
+// с использованием SQLite в качестве хранилища.
+type Repository struct {
+	db sqlite.Provider
+	// tx задан у копии репозитория, привязанной к транзакции методом WithTx.
+	tx *sql.Tx
+}
+
+// NewRepository создает новый экземпляр репозитория истории изменений персон.
+func NewRepository(db sqlite.Provider) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// WithTx возвращает копию репозитория, выполняющую запросы в транзакции tx.
+func (r *Repository) WithTx(tx *sql.Tx) *Repository {
+	return &Repository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// querier возвращает транзакцию, к которой привязан репозиторий, или пул соединений.
+func (r *Repository) querier() sqlite.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.DB()
+}
+
+// GetHistory получает историю изменений персоны, начиная с последней версии.
+func (r *Repository) GetHistory(ctx context.Context, personID uuid.UUID, offset, limit int) ([]*entities.PersonHistory, int, error) {
+	logger.Debug(ctx, "getting person history",
+		zap.String("id", personID.String()),
+		zap.Int("offset", offset),
+		zap.Int("limit", limit))
+
+	var total int
+	err := r.querier().QueryRowContext(ctx, `SELECT COUNT(*) FROM person_history WHERE person_id = $1`, personID).Scan(&total)
+	if err != nil {
+		logger.Error(ctx, "failed to count person history", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count person history: %w", err)
+	}
+
+	if total == 0 {
+		return []*entities.PersonHistory{}, 0, nil
+	}
+
+	query := `
+        SELECT ` + historyColumns + `
+        FROM person_history
+        WHERE person_id = $1
+        ORDER BY version DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	rows, err := r.querier().QueryContext(ctx, query, personID, limit, offset)
+	if err != nil {
+		logger.Error(ctx, "failed to query person history", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to query person history: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn(ctx, "failed to close rows", zap.Error(err))
+		}
+	}()
+
+	entries := make([]*entities.PersonHistory, 0, limit)
+	for rows.Next() {
+		entry, err := scanHistory(rows)
+		if err != nil {
+			logger.Error(ctx, "failed to scan person history row", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan person history row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return nil, 0, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	return entries, total, nil
+}
+
+// GetByVersion получает запись истории, в результате которой персона получила указанную версию.
+func (r *Repository) GetByVersion(ctx context.Context, personID uuid.UUID, version int) (*entities.PersonHistory, error) {
+	logger.Debug(ctx, "getting person history entry",
+		zap.String("id", personID.String()),
+		zap.Int("version", version))
+
+	query := `
+        SELECT ` + historyColumns + `
+        FROM person_history
+        WHERE person_id = $1 AND version = $2
+    `
+
+	entry, err := scanHistory(r.querier().QueryRowContext(ctx, query, personID, version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id %s, version %d", ErrHistoryNotFound, personID, version)
+		}
+		logger.Error(ctx, "failed to get person history entry", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person history entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// rowScanner описывает строку результата, общую для *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanHistory считывает строку с колонками historyColumns в запись истории.
+// Состояния персоны, измененные колонки и связанные персоны хранятся в JSON.
+func scanHistory(row rowScanner) (*entities.PersonHistory, error) {
+	var entry entities.PersonHistory
+	var before, relatedIDs sql.NullString
+	var after, changedFields string
+	var actor sql.NullString
+	var requestID sql.NullString
+	var createdAt int64
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.PersonID,
+		&entry.Version,
+		&entry.Operation,
+		&before,
+		&after,
+		&changedFields,
+		&relatedIDs,
+		&actor,
+		&requestID,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan person history: %w", err)
+	}
+
+	if before.Valid {
+		if err := json.Unmarshal([]byte(before.String), &entry.Before); err != nil {
+			return nil, fmt.Errorf("failed to decode person state: %w", err)
+		}
+	}
+	if err := json.Unmarshal([]byte(after), &entry.After); err != nil {
+		return nil, fmt.Errorf("failed to decode person state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(changedFields), &entry.ChangedFields); err != nil {
+		return nil, fmt.Errorf("failed to decode changed fields: %w", err)
+	}
+	if relatedIDs.Valid {
+		if err := json.Unmarshal([]byte(relatedIDs.String), &entry.RelatedIDs); err != nil {
+			return nil, fmt.Errorf("failed to decode related ids: %w", err)
+		}
+	}
+
+	entry.Actor = actor.String
+	entry.RequestID = requestID.String
+	entry.CreatedAt = time.UnixMicro(createdAt).UTC()
+
+	return &entry, nil
+}
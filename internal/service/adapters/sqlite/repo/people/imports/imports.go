@@ -0,0 +1,154 @@
+// Package imports содержит реализацию репозитория результатов импорта персон с использованием SQLite.
+package imports
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrImportNotFound возникает, когда результат импорта не найден.
+var ErrImportNotFound = errors.New("person import not found")
+
+// Проверка реализации интерфейса.
+var _ imports.Repository = (*Repository)(nil)
+
+// Repository реализует интерфейс imports.Repository
+// с использованием SQLite в качестве хранилища.
+type Repository struct {
+	db sqlite.Provider
+	// tx задан у копии репозитория, привязанной к транзакции методом WithTx.
+	tx *sql.Tx
+}
+
+// NewRepository создает новый экземпляр репозитория результатов импорта персон.
+func NewRepository(db sqlite.Provider) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// WithTx возвращает копию репозитория, выполняющую запросы в транзакции tx.
+func (r *Repository) WithTx(tx *sql.Tx) *Repository {
+	return &Repository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// querier возвращает транзакцию, к которой привязан репозиторий, или пул соединений.
+func (r *Repository) querier() sqlite.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.DB()
+}
+
+// CreateImport сохраняет результат импорта.
+func (r *Repository) CreateImport(ctx context.Context, result *entities.PersonImport) error {
+	logger.Debug(ctx, "saving person import",
+		zap.String("id", result.ID.String()),
+		zap.Int("total", result.Total),
+		zap.Int("failed", result.Failed))
+
+	if result.ID == uuid.Nil {
+		result.ID = uuid.New()
+	}
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.UnixMicro(time.Now().UnixMicro()).UTC()
+	}
+	rowErrors := result.Errors
+	if rowErrors == nil {
+		rowErrors = []entities.PersonImportError{}
+	}
+	encodedErrors, err := json.Marshal(rowErrors)
+	if err != nil {
+		return fmt.Errorf("failed to encode import errors: %w", err)
+	}
+
+	query := `
+        INSERT INTO person_imports (
+            id, format, file_name, dry_run, enrich, total, valid, imported, failed, errors, actor, created_at
+        ) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12)
+    `
+
+	_, err = r.querier().ExecContext(ctx, query,
+		result.ID,
+		result.Format,
+		result.FileName,
+		result.DryRun,
+		result.Enrich,
+		result.Total,
+		result.Valid,
+		result.Imported,
+		result.Failed,
+		string(encodedErrors),
+		result.Actor,
+		result.CreatedAt.UnixMicro(),
+	)
+	if err != nil {
+		logger.Error(ctx, "failed to save person import", zap.Error(err))
+		return fmt.Errorf("failed to save person import: %w", err)
+	}
+
+	return nil
+}
+
+// GetImport получает результат импорта по идентификатору вместе с построчными ошибками.
+func (r *Repository) GetImport(ctx context.Context, id uuid.UUID) (*entities.PersonImport, error) {
+	logger.Debug(ctx, "getting person import", zap.String("id", id.String()))
+
+	query := `
+        SELECT id, format, file_name, dry_run, enrich, total, valid, imported, failed, errors, actor, created_at
+        FROM person_imports
+        WHERE id = $1
+    `
+
+	var result entities.PersonImport
+	var fileName sql.NullString
+	var actor sql.NullString
+	var rowErrors string
+	var createdAt int64
+
+	err := r.querier().QueryRowContext(ctx, query, id).Scan(
+		&result.ID,
+		&result.Format,
+		&fileName,
+		&result.DryRun,
+		&result.Enrich,
+		&result.Total,
+		&result.Valid,
+		&result.Imported,
+		&result.Failed,
+		&rowErrors,
+		&actor,
+		&createdAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: id %s", ErrImportNotFound, id)
+		}
+		logger.Error(ctx, "failed to get person import", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person import: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(rowErrors), &result.Errors); err != nil {
+		logger.Error(ctx, "failed to decode import errors", zap.Error(err))
+		return nil, fmt.Errorf("failed to decode import errors: %w", err)
+	}
+	result.FileName = fileName.String
+	result.Actor = actor.String
+	result.CreatedAt = time.UnixMicro(createdAt).UTC()
+
+	return &result, nil
+}
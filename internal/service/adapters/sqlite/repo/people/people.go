@@ -0,0 +1,58 @@
+// Package people содержит реализацию репозиториев для работы с данными о людях.
+package people
+
+import (
+	"database/sql"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/sqlite/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/sqlite/repo/people/imports"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/sqlite/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	importsrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+)
+
+// Проверка реализации интерфейса.
+var _ people.Repositories = (*Repositories)(nil)
+
+// Repositories реализует интерфейс people.Repositories для SQLite.
+type Repositories struct {
+	personRepo  *person.Repository
+	historyRepo *history.Repository
+	importsRepo *imports.Repository
+}
+
+// NewRepositories создает новый экземпляр репозиториев для работы с данными о людях.
+func NewRepositories(db sqlite.Provider) *Repositories {
+	return &Repositories{
+		personRepo:  person.NewRepository(db),
+		historyRepo: history.NewRepository(db),
+		importsRepo: imports.NewRepository(db),
+	}
+}
+
+// WithTx возвращает копию репозиториев, выполняющих запросы в транзакции tx.
+func (r *Repositories) WithTx(tx *sql.Tx) *Repositories {
+	return &Repositories{
+		personRepo:  r.personRepo.WithTx(tx),
+		historyRepo: r.historyRepo.WithTx(tx),
+		importsRepo: r.importsRepo.WithTx(tx),
+	}
+}
+
+// Person возвращает репозиторий для работы с персонами.
+func (r *Repositories) Person() personrepo.Repository {
+	return r.personRepo
+}
+
+// History возвращает репозиторий истории изменений персон.
+func (r *Repositories) History() historyrepo.Repository {
+	return r.historyRepo
+}
+
+// Imports возвращает репозиторий результатов импорта персон.
+func (r *Repositories) Imports() importsrepo.Repository {
+	return r.importsRepo
+}
@@ -0,0 +1,44 @@
+package person
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/trigram"
+	"modernc.org/sqlite"
+)
+
+// Функции SQL, которых нет во встроенном SQLite. Драйвер добавляет их в каждое новое соединение,
+// поэтому регистрация выполняется до открытия базы данных.
+func init() {
+	must(sqlite.RegisterDeterministicScalarFunction("ulower", 1, ulower))
+	must(sqlite.RegisterDeterministicScalarFunction("similarity", 2, similarity))
+}
+
+func must(err error) {
+	if err != nil {
+		panic(fmt.Sprintf("failed to register sqlite function: %v", err))
+	}
+}
+
+// ulower приводит строку к нижнему регистру с учетом Unicode; встроенная lower SQLite
+// меняет регистр только у латиницы, а сравнение без учета регистра должно работать и для кириллицы.
+func ulower(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	value, ok := args[0].(string)
+	if !ok {
+		return args[0], nil
+	}
+	return strings.ToLower(value), nil
+}
+
+// similarity вычисляет триграммное сходство строк так же, как функция similarity расширения pg_trgm.
+// Для NULL возвращается NULL.
+func similarity(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	a, okA := args[0].(string)
+	b, okB := args[1].(string)
+	if !okA || !okB {
+		return nil, nil
+	}
+	return trigram.Similarity(a, b), nil
+}
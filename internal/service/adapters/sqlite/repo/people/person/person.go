@@ -0,0 +1,1512 @@
+// Package person содержит реализацию репозитория для персон с использованием SQLite.
+package person
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/phonetic"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/trigram"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Ошибки, связанные с работой с персоны.
+var (
+	ErrPersonNotFound      = errors.New("person not found")
+	ErrPersonAlreadyExists = errors.New("person already exists")
+	ErrUnknownColumn       = errors.New("unknown person column")
+)
+
+// personColumns перечисляет колонки таблицы persons в порядке сканирования scanPerson.
+const personColumns = `id, name, surname, patronymic, age, gender, gender_probability,
+               nationality, nationality_probability, created_at, updated_at, version, deleted_at`
+
+// patchableColumns содержит колонки, которые допускается изменять частичным обновлением.
+var patchableColumns = map[string]bool{
+	"name":                    true,
+	"surname":                 true,
+	"patronymic":              true,
+	"age":                     true,
+	"gender":                  true,
+	"gender_probability":      true,
+	"nationality":             true,
+	"nationality_probability": true,
+}
+
+// rangeConditions сопоставляет фильтры диапазонов с условиями; %d заменяется номером параметра.
+var rangeConditions = map[string]string{
+	person.FilterAgeMin:                    "age >= $%d",
+	person.FilterAgeMax:                    "age <= $%d",
+	person.FilterGenderProbabilityMin:      "gender_probability >= $%d",
+	person.FilterNationalityProbabilityMin: "nationality_probability >= $%d",
+	person.FilterCreatedAfter:              "created_at >= $%d",
+	person.FilterCreatedBefore:             "created_at < $%d",
+	person.FilterUpdatedAfter:              "updated_at >= $%d",
+}
+
+// Проверка реализации интерфейса.
+var _ person.Repository = (*Repository)(nil)
+
+// Repository реализует интерфейс person.Repository с использованием SQLite в качестве хранилища.
+// Фонетические ключи хранятся в JSON-массивах и заполняются при каждой записи, поэтому
+// заполнение ключей для старых записей (person.PhoneticIndexer) не требуется.
+type Repository struct {
+	db sqlite.Provider
+	// tx задан у копии репозитория, привязанной к транзакции методом WithTx.
+	tx *sql.Tx
+}
+
+// NewRepository создает новый экземпляр репозитория персон.
+func NewRepository(db sqlite.Provider) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// WithTx возвращает копию репозитория, выполняющую запросы в транзакции tx.
+func (r *Repository) WithTx(tx *sql.Tx) *Repository {
+	return &Repository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// querier возвращает транзакцию, к которой привязан репозиторий, или пул соединений.
+func (r *Repository) querier() sqlite.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.DB()
+}
+
+// GetByID получает персону по идентификатору.
+func (r *Repository) GetByID(ctx context.Context, personID uuid.UUID) (*entities.Person, error) {
+	logger.Debug(ctx, "getting person by ID", zap.String("id", personID.String()))
+
+	query := `
+        SELECT ` + personColumns + `
+        FROM persons
+        WHERE id = $1` + notDeletedCondition(ctx)
+
+	person, err := scanPerson(r.querier().QueryRowContext(ctx, query, personID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Debug(ctx, "person not found", zap.String("id", personID.String()))
+			return nil, fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+		}
+		logger.Error(ctx, "failed to get person by ID", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	return person, nil
+}
+
+// GetPersons получает список персон с фильтрацией и пагинацией.
+func (r *Repository) GetPersons(ctx context.Context, filter map[string]any, offset, limit int) ([]*entities.Person, int, error) {
+	logger.Debug(ctx, "getting persons with filter",
+		zap.Any("filter", filter),
+		zap.Int("offset", offset),
+		zap.Int("limit", limit))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := -1
+	if !selection.skipCount {
+		// Запрос общего количества записей.
+		countQuery := `SELECT COUNT(*) FROM persons WHERE ` + selection.where
+		err := r.querier().QueryRowContext(ctx, countQuery, selection.args...).Scan(&total)
+		if err != nil {
+			logger.Error(ctx, "failed to count persons", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to count persons: %w", err)
+		}
+
+		if total == 0 {
+			return []*entities.Person{}, 0, nil
+		}
+	}
+
+	dataQuery, args, err := selection.orderedQuery()
+	if err != nil {
+		return nil, 0, err
+	}
+	if selection.cursor != nil {
+		offset = 0
+	}
+	dataQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	persons := make([]*entities.Person, 0, limit)
+	err = queryPersons(ctx, r.querier(), dataQuery, args, func(p *entities.Person) error {
+		persons = append(persons, p)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return persons, total, nil
+}
+
+// StreamPersons читает персоны, отобранные фильтром GetPersons, одним запросом и передает их в fn по одной.
+// В режиме WAL запрос читает согласованный снимок данных и не блокирует запись.
+func (r *Repository) StreamPersons(ctx context.Context, filter map[string]any, fn func(*entities.Person) error) error {
+	logger.Debug(ctx, "streaming persons with filter", zap.Any("filter", filter))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return err
+	}
+	query, args, err := selection.orderedQuery()
+	if err != nil {
+		return err
+	}
+
+	return queryPersons(ctx, r.querier(), query, args, fn)
+}
+
+// queryPersons выполняет запрос с колонками personColumns и передает прочитанные персоны в fn.
+// Ошибка fn прерывает чтение и возвращается без изменений.
+func queryPersons(ctx context.Context, q sqlite.Querier, query string, args []any, fn func(*entities.Person) error) error {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(ctx, "failed to query persons", zap.Error(err))
+		return fmt.Errorf("failed to query persons: %w", err)
+	}
+	defer closeRows(ctx, rows)
+
+	for rows.Next() {
+		person, err := scanPerson(rows)
+		if err != nil {
+			logger.Error(ctx, "failed to scan person row", zap.Error(err))
+			return fmt.Errorf("failed to scan person row: %w", err)
+		}
+		if err := fn(person); err != nil {
+			return err
+		}
+	}
+
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+	return nil
+}
+
+// selection описывает выборку персон, разобранную из фильтра GetPersons.
+type selection struct {
+	// where содержит условие отбора с параметрами args.
+	where      string
+	args       []any
+	cursor     *person.Cursor
+	sortFields []person.SortField
+	skipCount  bool
+}
+
+// parseFilter разбирает фильтр GetPersons в условия отбора, сортировку и курсор
+// по тем же правилам, что и PostgreSQL-реализация.
+func parseFilter(ctx context.Context, filter map[string]any) (*selection, error) {
+	var args []any
+	argNum := 1
+	conditions := []string{"1=1"}
+	result := &selection{sortFields: person.DefaultSort}
+	usePhonetic, _ := filter[person.FilterPhonetic].(bool)
+
+	if deleted := notDeletedCondition(ctx); deleted != "" {
+		conditions = append(conditions, strings.TrimPrefix(deleted, " AND "))
+	}
+
+	for field, value := range filter {
+		switch field {
+		case "age":
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", field, argNum))
+			args = append(args, value)
+		case person.FilterAgeMin, person.FilterAgeMax,
+			person.FilterGenderProbabilityMin, person.FilterNationalityProbabilityMin,
+			person.FilterCreatedAfter, person.FilterCreatedBefore, person.FilterUpdatedAfter:
+			condition := rangeConditions[field]
+			conditions = append(conditions, fmt.Sprintf(condition, argNum))
+			args = append(args, columnValue(value))
+		case person.FilterMissing, person.FilterPresent:
+			columns, ok := value.([]string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a list of columns", person.ErrInvalidFilter, field)
+			}
+			check := " IS NULL"
+			if field == person.FilterPresent {
+				check = " IS NOT NULL"
+			}
+			for _, column := range columns {
+				if !person.NullableColumns[column] {
+					return nil, fmt.Errorf("%w: %s: unknown column %q", person.ErrInvalidFilter, field, column)
+				}
+				conditions = append(conditions, column+check)
+			}
+			continue
+		case person.FilterIDs:
+			ids, ok := value.([]uuid.UUID)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a list of ids", person.ErrInvalidFilter, field)
+			}
+			conditions = append(conditions, fmt.Sprintf("id IN (SELECT value FROM json_each($%d))", argNum))
+			args = append(args, jsonArray(ids))
+		case person.FilterCursor:
+			position, ok := value.(person.Cursor)
+			if !ok {
+				return nil, fmt.Errorf("%w: unexpected type %T", person.ErrInvalidCursor, value)
+			}
+			result.cursor = &position
+			continue
+		case person.FilterSort:
+			fields, ok := value.([]person.SortField)
+			if !ok || len(fields) == 0 {
+				return nil, fmt.Errorf("%w: unexpected value %v", person.ErrInvalidSort, value)
+			}
+			for _, field := range fields {
+				if !person.SortableColumns[field.Column] {
+					return nil, fmt.Errorf("%w: unknown column %q", person.ErrInvalidSort, field.Column)
+				}
+			}
+			result.sortFields = fields
+			continue
+		case person.FilterSkipCount:
+			result.skipCount, _ = value.(bool)
+			continue
+		case person.FilterPhonetic:
+			continue
+		default:
+			textFilter, ok := person.ParseTextFilterKey(field)
+			if !ok {
+				logger.Warn(ctx, "ignoring unknown filter field", zap.String("field", field))
+				continue
+			}
+			values, err := filterValues(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", person.ErrInvalidFilter, field, err)
+			}
+			if usePhonetic && field == textFilter.Column && (field == "name" || field == "surname") {
+				var keys []string
+				for _, value := range values {
+					keys = append(keys, phonetic.Keys(value)...)
+				}
+				conditions = append(conditions, overlaps(field+"_phonetic", fmt.Sprintf("$%d", argNum)))
+				args = append(args, jsonArray(keys))
+			} else {
+				condition, patterns := textCondition(textFilter, values, argNum)
+				conditions = append(conditions, condition)
+				args = append(args, patterns...)
+				argNum += len(patterns)
+				continue
+			}
+		}
+		argNum++
+	}
+
+	result.where = strings.Join(conditions, " AND ")
+	result.args = args
+	return result, nil
+}
+
+// orderedQuery возвращает запрос выборки с условием курсора и ORDER BY, но без LIMIT и OFFSET.
+func (s *selection) orderedQuery() (string, []any, error) {
+	query := `SELECT ` + personColumns + ` FROM persons WHERE ` + s.where
+	args := slices.Clone(s.args)
+
+	// Курсор задает позицию по тому же ключу, что и сортировка, поэтому вставка новых
+	// записей во время постраничного обхода не приводит к пропускам и повторам.
+	if s.cursor != nil {
+		if len(s.cursor.Values) != len(s.sortFields) {
+			return "", nil, fmt.Errorf("%w: cursor does not match sort", person.ErrInvalidCursor)
+		}
+		condition, cursorArgs := keysetCondition(s.sortFields, *s.cursor, len(args)+1)
+		query += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	return query + " ORDER BY " + orderByClause(s.sortFields), args, nil
+}
+
+// likeEscaper экранирует специальные символы шаблонов LIKE в значениях фильтров.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// textCondition строит условие текстового фильтра без учета регистра с параметрами, начиная с argNum.
+// Несколько значений объединяются через OR; исключение также пропускает пустые значения.
+// Возвращает: условие и шаблоны для параметров.
+func textCondition(filter person.TextFilter, values []string, argNum int) (string, []any) {
+	patterns := make([]any, 0, len(values))
+	alternatives := make([]string, 0, len(values))
+	for i, value := range values {
+		pattern := likeEscaper.Replace(strings.ToLower(value))
+		switch filter.Mode {
+		case person.MatchPrefix:
+			pattern += "%"
+		case person.MatchContains:
+			pattern = "%" + pattern + "%"
+		case person.MatchExact:
+		}
+		patterns = append(patterns, pattern)
+		alternatives = append(alternatives, fmt.Sprintf(`ulower(%s) LIKE $%d ESCAPE '\'`, filter.Column, argNum+i))
+	}
+
+	condition := "(" + strings.Join(alternatives, " OR ") + ")"
+	if filter.Negate {
+		return fmt.Sprintf("(%s IS NULL OR NOT %s)", filter.Column, condition), patterns
+	}
+	return condition, patterns
+}
+
+// filterValues приводит значение текстового фильтра (string или []string) к непустому списку.
+func filterValues(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		if len(v) == 0 {
+			return nil, errors.New("empty list of values")
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unexpected type %T", value)
+	}
+}
+
+// overlaps возвращает условие наличия общего элемента у двух JSON-массивов (аналог && для массивов PostgreSQL).
+func overlaps(a, b string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) AS x, json_each(%s) AS y WHERE x.value = y.value)", a, b)
+}
+
+// SearchPersons выполняет нечеткий поиск персон по триграммному сходству с именем, фамилией
+// и отчеством, вычисляемому функцией similarity так же, как в pg_trgm. Оценка равна наибольшему
+// сходству среди этих колонок. В фонетическом режиме оценка равна доле ключей запроса,
+// совпавших с ключами имени и фамилии.
+func (r *Repository) SearchPersons(ctx context.Context, query string, limit int, usePhonetic bool) ([]*entities.PersonSearchResult, error) {
+	logger.Debug(ctx, "searching persons",
+		zap.String("query", query),
+		zap.Int("limit", limit),
+		zap.Bool("phonetic", usePhonetic))
+
+	// Индексов для триграмм в SQLite нет, поэтому сходство вычисляется для каждой строки.
+	sqlQuery := `
+        SELECT ` + personColumns + `,
+               MAX(similarity(name, $1), similarity(surname, $1), COALESCE(similarity(patronymic, $1), 0)) AS score
+        FROM persons
+        WHERE (similarity(name, $1) >= $3 OR similarity(surname, $1) >= $3 OR similarity(patronymic, $1) >= $3)` + notDeletedCondition(ctx) + `
+        ORDER BY score DESC, id
+        LIMIT $2
+    `
+	args := []any{query, limit, trigram.Threshold}
+
+	if usePhonetic {
+		keys := phonetic.Keys(query)
+		if len(keys) == 0 {
+			return []*entities.PersonSearchResult{}, nil
+		}
+		sqlQuery = `
+        SELECT ` + personColumns + `,
+               CAST((SELECT COUNT(DISTINCT k.value)
+                     FROM (SELECT value FROM json_each(name_phonetic) UNION ALL SELECT value FROM json_each(surname_phonetic)) AS k
+                     WHERE k.value IN (SELECT value FROM json_each($1))) AS REAL) / $3 AS score
+        FROM persons
+        WHERE (` + overlaps("name_phonetic", "$1") + ` OR ` + overlaps("surname_phonetic", "$1") + `)` + notDeletedCondition(ctx) + `
+        ORDER BY score DESC, id
+        LIMIT $2
+    `
+		args = []any{jsonArray(keys), limit, len(keys)}
+	}
+
+	rows, err := r.querier().QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		logger.Error(ctx, "failed to search persons", zap.Error(err))
+		return nil, fmt.Errorf("failed to search persons: %w", err)
+	}
+	defer closeRows(ctx, rows)
+
+	results := make([]*entities.PersonSearchResult, 0, limit)
+	for rows.Next() {
+		var score float64
+		found, err := scanPerson(rows, &score)
+		if err != nil {
+			logger.Error(ctx, "failed to scan search result", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, &entities.PersonSearchResult{Person: *found, Score: score})
+	}
+
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return nil, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	return results, nil
+}
+
+// UpsertByExternalID создает или обновляет персону, связанную с внешним идентификатором.
+// Связь вставляется первой через INSERT ... ON CONFLICT и возвращает уже связанную персону;
+// транзакции SQLite сразу берут блокировку записи, поэтому параллельные вызовы не создают дубликатов.
+// Внешний ключ связи проверяется при фиксации, что позволяет вставить персону после связи.
+func (r *Repository) UpsertByExternalID(ctx context.Context, source, externalID string, input *entities.Person) (bool, error) {
+	logger.Debug(ctx, "upserting person by external id",
+		zap.String("source", source),
+		zap.String("external_id", externalID))
+
+	linkQuery := `
+        INSERT INTO person_external_ids (source, external_id, person_id, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (source, external_id) DO UPDATE SET source = excluded.source
+        RETURNING person_id`
+
+	newID := uuid.New()
+	created := false
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var personID uuid.UUID
+		if err := tx.QueryRowContext(ctx, linkQuery, source, externalID, newID, toMicros(time.Now())).Scan(&personID); err != nil {
+			return fmt.Errorf("failed to link external id: %w", err)
+		}
+
+		if personID == newID {
+			if input.Version != 0 {
+				return fmt.Errorf("%w: external id %s/%s is not linked, expected version %d",
+					person.ErrVersionConflict, source, externalID, input.Version)
+			}
+			now := now()
+			candidate := *input
+			candidate.ID = newID
+			candidate.CreatedAt = now
+			candidate.UpdatedAt = now
+			candidate.Version = 1
+			candidate.DeletedAt = nil
+
+			inserted, err := insertPerson(ctx, tx, &candidate)
+			if err != nil {
+				return err
+			}
+			if err := insertHistory(ctx, tx, historyrepo.Operation(ctx, historyrepo.OperationCreate), nil, inserted); err != nil {
+				return err
+			}
+			*input = *inserted
+			created = true
+			return nil
+		}
+
+		before, err := lockPerson(ctx, tx, personID, input.Version)
+		if err != nil {
+			return err
+		}
+		candidate := *input
+		candidate.ID = personID
+		updated, err := writePerson(ctx, tx, &candidate)
+		if err != nil {
+			return err
+		}
+		if err := insertHistory(ctx, tx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, updated); err != nil {
+			return err
+		}
+		*input = *updated
+		return nil
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return false, err
+		}
+		logger.Error(ctx, "failed to upsert person by external id", zap.Error(err))
+		return false, fmt.Errorf("failed to upsert person by external id: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByExternalID получает неудаленную персону по внешнему идентификатору.
+func (r *Repository) GetByExternalID(ctx context.Context, source, externalID string) (*entities.Person, error) {
+	logger.Debug(ctx, "getting person by external id",
+		zap.String("source", source),
+		zap.String("external_id", externalID))
+
+	query := `
+        SELECT ` + prefixedPersonColumns("p") + `
+        FROM person_external_ids e
+        JOIN persons p ON p.id = e.person_id
+        WHERE e.source = $1 AND e.external_id = $2 AND p.deleted_at IS NULL`
+
+	person, err := scanPerson(r.querier().QueryRowContext(ctx, query, source, externalID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: external id %s/%s", ErrPersonNotFound, source, externalID)
+		}
+		logger.Error(ctx, "failed to get person by external id", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	return person, nil
+}
+
+// CreatePerson создает новую персону.
+func (r *Repository) CreatePerson(ctx context.Context, person *entities.Person) error {
+	logger.Debug(ctx, "creating new person", zap.String("name", person.Name), zap.String("surname", person.Surname))
+
+	if person.ID == uuid.Nil {
+		person.ID = uuid.New()
+	}
+
+	now := now()
+	person.CreatedAt = now
+	person.UpdatedAt = now
+	person.Version = 1
+
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		created, err := insertPerson(ctx, tx, person)
+		if err != nil {
+			return err
+		}
+		if err := insertHistory(ctx, tx, historyrepo.OperationCreate, nil, created); err != nil {
+			return err
+		}
+		*person = *created
+		return nil
+	})
+
+	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			logger.Error(ctx, "person with this ID already exists",
+				zap.String("id", person.ID.String()),
+				zap.Error(err))
+			return fmt.Errorf("%w: ID %s", ErrPersonAlreadyExists, person.ID)
+		}
+		logger.Error(ctx, "failed to create person", zap.Error(err))
+		return fmt.Errorf("failed to create person: %w", err)
+	}
+
+	return nil
+}
+
+// CreatePersons создает персоны в одной транзакции вместе с записями истории.
+func (r *Repository) CreatePersons(ctx context.Context, persons []*entities.Person) error {
+	logger.Debug(ctx, "creating persons in bulk", zap.Int("count", len(persons)))
+
+	if len(persons) == 0 {
+		return nil
+	}
+
+	now := now()
+	for _, person := range persons {
+		if person.ID == uuid.Nil {
+			person.ID = uuid.New()
+		}
+		person.CreatedAt = now
+		person.UpdatedAt = now
+		person.Version = 1
+		person.DeletedAt = nil
+	}
+
+	operation := historyrepo.Operation(ctx, historyrepo.OperationCreate)
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, person := range persons {
+			if _, err := insertPerson(ctx, tx, person); err != nil {
+				return fmt.Errorf("failed to insert person %s: %w", person.ID, err)
+			}
+			if err := insertHistory(ctx, tx, operation, nil, person); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			logger.Error(ctx, "person with this ID already exists", zap.Error(err))
+			return fmt.Errorf("%w: %w", ErrPersonAlreadyExists, err)
+		}
+		logger.Error(ctx, "failed to create persons", zap.Error(err))
+		return fmt.Errorf("failed to create persons: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePerson обновляет существующую персону.
+func (r *Repository) UpdatePerson(ctx context.Context, person *entities.Person) error {
+	logger.Debug(ctx, "updating person", zap.String("id", person.ID.String()))
+
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := lockPerson(ctx, tx, person.ID, person.Version)
+		if err != nil {
+			return err
+		}
+
+		updated, err := writePerson(ctx, tx, person)
+		if err != nil {
+			return err
+		}
+		if err := insertHistory(ctx, tx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, updated); err != nil {
+			return err
+		}
+		*person = *updated
+		return nil
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return err
+		}
+		logger.Error(ctx, "failed to update person", zap.Error(err))
+		return fmt.Errorf("failed to update person: %w", err)
+	}
+
+	return nil
+}
+
+// PatchPerson частично обновляет персону, изменяя только переданные колонки.
+func (r *Repository) PatchPerson(ctx context.Context, personID uuid.UUID, fields map[string]any, version int) (*entities.Person, error) {
+	logger.Debug(ctx, "patching person",
+		zap.String("id", personID.String()),
+		zap.Any("fields", fields),
+		zap.Int("version", version))
+
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		if !patchableColumns[column] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	args := []any{personID}
+	assignments := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		args = append(args, fields[column])
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+		// Фонетические ключи пересчитываются вместе с именем и фамилией.
+		if column == "name" || column == "surname" {
+			value, _ := fields[column].(string)
+			args = append(args, phoneticKeys(value))
+			assignments = append(assignments, fmt.Sprintf("%s_phonetic = $%d", column, len(args)))
+		}
+	}
+	args = append(args, toMicros(time.Now()))
+	assignments = append(assignments, fmt.Sprintf("updated_at = $%d", len(args)), "version = version + 1")
+
+	query := `
+        UPDATE persons
+        SET ` + strings.Join(assignments, ", ") + `
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	var patched *entities.Person
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := lockPerson(ctx, tx, personID, version)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			patched = before
+			return nil
+		}
+
+		patched, err = scanPerson(tx.QueryRowContext(ctx, query, args...))
+		if err != nil {
+			return err
+		}
+		return insertHistory(ctx, tx, historyrepo.Operation(ctx, historyrepo.OperationUpdate), before, patched)
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return nil, err
+		}
+		logger.Error(ctx, "failed to patch person", zap.Error(err))
+		return nil, fmt.Errorf("failed to patch person: %w", err)
+	}
+
+	return patched, nil
+}
+
+// DeletePerson мягко удаляет персону по идентификатору, устанавливая deleted_at.
+// Запись остается в таблице до восстановления или очистки через PurgeDeleted.
+func (r *Repository) DeletePerson(ctx context.Context, personID uuid.UUID, version int) error {
+	logger.Debug(ctx, "deleting person", zap.String("id", personID.String()), zap.Int("version", version))
+
+	query := `
+        UPDATE persons
+        SET deleted_at = $2, updated_at = $2, version = version + 1
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := lockPerson(ctx, tx, personID, version)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := scanPerson(tx.QueryRowContext(ctx, query, personID, toMicros(time.Now())))
+		if err != nil {
+			return err
+		}
+		return insertHistory(ctx, tx, historyrepo.OperationDelete, before, deleted)
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return err
+		}
+		logger.Error(ctx, "failed to delete person", zap.Error(err))
+		return fmt.Errorf("failed to delete person: %w", err)
+	}
+
+	return nil
+}
+
+// RestorePerson восстанавливает мягко удаленную персону.
+func (r *Repository) RestorePerson(ctx context.Context, personID uuid.UUID) (*entities.Person, error) {
+	logger.Debug(ctx, "restoring person", zap.String("id", personID.String()))
+
+	query := `
+        UPDATE persons
+        SET deleted_at = NULL, merged_into = NULL, updated_at = $2, version = version + 1
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	var restored *entities.Person
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := scanPerson(tx.QueryRowContext(ctx, `
+        SELECT `+personColumns+`
+        FROM persons
+        WHERE id = $1`, personID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+			}
+			return err
+		}
+		if before.DeletedAt == nil {
+			return fmt.Errorf("%w: id %s", person.ErrPersonNotDeleted, personID)
+		}
+
+		restored, err = scanPerson(tx.QueryRowContext(ctx, query, personID, toMicros(time.Now())))
+		if err != nil {
+			return err
+		}
+		return insertHistory(ctx, tx, historyrepo.OperationRestore, before, restored)
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return nil, err
+		}
+		logger.Error(ctx, "failed to restore person", zap.Error(err))
+		return nil, fmt.Errorf("failed to restore person: %w", err)
+	}
+
+	return restored, nil
+}
+
+// FindDuplicatePairs находит пары похожих неудаленных персон. Кандидаты отбираются по общим
+// фонетическим ключам фамилии или триграммному сходству фамилий, после чего пара оценивается
+// так же, как в PostgreSQL-реализации:
+//   - 1 - имя, фамилия и отчество совпадают без учета регистра;
+//   - 0.9 - совпадают фонетические ключи имени и фамилии (транслитерация), а отчество
+//     совпадает или не заполнено, 0.75 - при разных отчествах;
+//   - иначе - триграммное сходство полного имени (опечатки).
+//
+// Берется наибольшая из оценок.
+func (r *Repository) FindDuplicatePairs(ctx context.Context, minScore float64, limit int) ([]entities.PersonDuplicatePair, error) {
+	logger.Debug(ctx, "finding duplicate persons",
+		zap.Float64("min_score", minScore),
+		zap.Int("limit", limit))
+
+	query := `
+        SELECT person_id, duplicate_id, score
+        FROM (
+            SELECT a.id AS person_id, b.id AS duplicate_id,
+                   CAST(CASE
+                        WHEN ulower(a.name) = ulower(b.name) AND ulower(a.surname) = ulower(b.surname)
+                             AND ulower(COALESCE(a.patronymic, '')) = ulower(COALESCE(b.patronymic, '')) THEN 1
+                        ELSE MAX(
+                            similarity(a.name || ' ' || a.surname || COALESCE(' ' || a.patronymic, ''),
+                                       b.name || ' ' || b.surname || COALESCE(' ' || b.patronymic, '')),
+                            CASE
+                                WHEN NOT (` + overlaps("a.name_phonetic", "b.name_phonetic") + `
+                                          AND ` + overlaps("a.surname_phonetic", "b.surname_phonetic") + `) THEN 0
+                                WHEN a.patronymic IS NULL OR b.patronymic IS NULL
+                                     OR ulower(a.patronymic) = ulower(b.patronymic) THEN 0.9
+                                ELSE 0.75
+                            END)
+                    END AS REAL) AS score
+            FROM persons a
+            JOIN persons b ON a.id < b.id
+                AND (` + overlaps("a.surname_phonetic", "b.surname_phonetic") + ` OR similarity(a.surname, b.surname) >= $3)
+            WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL
+        ) AS pairs
+        WHERE score >= $1
+        ORDER BY score DESC, person_id, duplicate_id
+        LIMIT $2`
+
+	rows, err := r.querier().QueryContext(ctx, query, minScore, limit, trigram.Threshold)
+	if err != nil {
+		logger.Error(ctx, "failed to find duplicate persons", zap.Error(err))
+		return nil, fmt.Errorf("failed to find duplicate persons: %w", err)
+	}
+	defer closeRows(ctx, rows)
+
+	pairs := make([]entities.PersonDuplicatePair, 0)
+	for rows.Next() {
+		var pair entities.PersonDuplicatePair
+		if err := rows.Scan(&pair.PersonID, &pair.DuplicateID, &pair.Score); err != nil {
+			logger.Error(ctx, "failed to scan duplicate pair", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan duplicate pair: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if rows.Err() != nil {
+		logger.Error(ctx, "error iterating through rows", zap.Error(rows.Err()))
+		return nil, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+
+	return pairs, nil
+}
+
+// MergePersons объединяет персоны mergedIDs с персоной survivorID в одной транзакции.
+// Ссылки на поглощенные персоны из ранее выполненных объединений и их внешние идентификаторы
+// перенаправляются на survivor.
+func (r *Repository) MergePersons(ctx context.Context, survivorID uuid.UUID, mergedIDs []uuid.UUID, version int, resolve person.MergeResolver) (*entities.Person, error) {
+	logger.Debug(ctx, "merging persons",
+		zap.String("survivor", survivorID.String()),
+		zap.Int("merged", len(mergedIDs)),
+		zap.Int("version", version))
+
+	if len(mergedIDs) == 0 || slices.Contains(mergedIDs, survivorID) {
+		return nil, fmt.Errorf("%w: merged persons must not be empty or contain the survivor", person.ErrInvalidMerge)
+	}
+
+	deleteQuery := `
+        UPDATE persons
+        SET deleted_at = $2, updated_at = $2, merged_into = $3, version = version + 1
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	var survivor *entities.Person
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		locked, err := lockPersons(ctx, tx, append([]uuid.UUID{survivorID}, mergedIDs...))
+		if err != nil {
+			return err
+		}
+		before := locked[survivorID]
+		if version != 0 && before.Version != version {
+			return fmt.Errorf("%w: id %s, expected version %d, actual %d",
+				person.ErrVersionConflict, survivorID, version, before.Version)
+		}
+
+		merged := make([]*entities.Person, 0, len(mergedIDs))
+		for _, id := range mergedIDs {
+			merged = append(merged, locked[id])
+		}
+
+		enriched, err := enrichedFields(ctx, tx, append([]uuid.UUID{survivorID}, mergedIDs...))
+		if err != nil {
+			return err
+		}
+
+		resolved := resolve(before, merged, enriched)
+		resolved.ID = survivorID
+		survivor, err = writePerson(ctx, tx, resolved)
+		if err != nil {
+			return err
+		}
+		if err := insertHistory(ctx, tx, historyrepo.OperationMerge, before, survivor, mergedIDs...); err != nil {
+			return err
+		}
+
+		now := toMicros(time.Now())
+		for _, previous := range merged {
+			deleted, err := scanPerson(tx.QueryRowContext(ctx, deleteQuery, previous.ID, now, survivorID))
+			if err != nil {
+				return err
+			}
+			if err := insertHistory(ctx, tx, historyrepo.OperationMerge, previous, deleted, survivorID); err != nil {
+				return err
+			}
+		}
+
+		ids := jsonArray(mergedIDs)
+		if _, err := tx.ExecContext(ctx, `UPDATE persons SET merged_into = $1 WHERE merged_into IN (SELECT value FROM json_each($2))`, survivorID, ids); err != nil {
+			return fmt.Errorf("failed to redirect merged persons: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE person_external_ids SET person_id = $1 WHERE person_id IN (SELECT value FROM json_each($2))`, survivorID, ids); err != nil {
+			return fmt.Errorf("failed to move external ids: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		if isExpectedError(err) {
+			return nil, err
+		}
+		logger.Error(ctx, "failed to merge persons", zap.Error(err))
+		return nil, fmt.Errorf("failed to merge persons: %w", err)
+	}
+
+	return survivor, nil
+}
+
+// MergedInto возвращает персону, с которой была объединена персона, или uuid.Nil.
+func (r *Repository) MergedInto(ctx context.Context, personID uuid.UUID) (uuid.UUID, error) {
+	logger.Debug(ctx, "getting merge target", zap.String("id", personID.String()))
+
+	var target uuid.NullUUID
+	err := r.querier().QueryRowContext(ctx, `SELECT merged_into FROM persons WHERE id = $1`, personID).Scan(&target)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, nil
+		}
+		logger.Error(ctx, "failed to get merge target", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("failed to get merge target: %w", err)
+	}
+
+	return target.UUID, nil
+}
+
+// PurgeDeleted безвозвратно удаляет персоны, мягко удаленные раньше deletedBefore.
+func (r *Repository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	logger.Debug(ctx, "purging deleted persons", zap.Time("deleted_before", deletedBefore))
+
+	query := `DELETE FROM persons WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.querier().ExecContext(ctx, query, toMicros(deletedBefore))
+	if err != nil {
+		logger.Error(ctx, "failed to purge deleted persons", zap.Error(err))
+		return 0, fmt.Errorf("failed to purge deleted persons: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purged persons: %w", err)
+	}
+	return count, nil
+}
+
+// ExistsByID проверяет существование персоны по идентификатору.
+func (r *Repository) ExistsByID(ctx context.Context, personID uuid.UUID) (bool, error) {
+	logger.Debug(ctx, "checking if person exists", zap.String("id", personID.String()))
+
+	query := `SELECT EXISTS(SELECT 1 FROM persons WHERE id = $1` + notDeletedCondition(ctx) + `)`
+
+	var exists bool
+	err := r.querier().QueryRowContext(ctx, query, personID).Scan(&exists)
+	if err != nil {
+		logger.Error(ctx, "failed to check if person exists", zap.Error(err))
+		return false, fmt.Errorf("failed to check if person exists: %w", err)
+	}
+
+	return exists, nil
+}
+
+// inTx выполняет fn в транзакции и фиксирует ее, если fn завершилась без ошибки.
+// Внутри WithTx fn выполняется на точке сохранения, и ошибка fn откатывает только ее изменения.
+func (r *Repository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx == nil {
+		return sqlite.RunInTx(ctx, r.db.DB(), fn)
+	}
+	return sqlite.RunInSavepoint(ctx, r.tx, fn)
+}
+
+// lockPerson читает неудаленную персону в пишущей транзакции и проверяет ожидаемую версию.
+// Транзакции SQLite начинаются с блокировки записи, поэтому строку не изменит никто другой.
+// Версия 0 отключает проверку.
+func lockPerson(ctx context.Context, tx *sql.Tx, personID uuid.UUID, version int) (*entities.Person, error) {
+	query := `
+        SELECT ` + personColumns + `
+        FROM persons
+        WHERE id = $1 AND deleted_at IS NULL`
+
+	current, err := scanPerson(tx.QueryRowContext(ctx, query, personID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Debug(ctx, "person not found for modification", zap.String("id", personID.String()))
+			return nil, fmt.Errorf("%w: id %s", ErrPersonNotFound, personID)
+		}
+		return nil, err
+	}
+
+	if version != 0 && current.Version != version {
+		logger.Debug(ctx, "person version conflict",
+			zap.String("id", personID.String()),
+			zap.Int("expected", version),
+			zap.Int("actual", current.Version))
+		return nil, fmt.Errorf("%w: id %s, expected version %d, actual %d",
+			person.ErrVersionConflict, personID, version, current.Version)
+	}
+
+	return current, nil
+}
+
+// lockPersons читает неудаленные персоны в пишущей транзакции.
+// Возвращает: персоны по идентификаторам, ErrPersonNotFound, если какой-либо персоны нет.
+func lockPersons(ctx context.Context, tx *sql.Tx, ids []uuid.UUID) (map[uuid.UUID]*entities.Person, error) {
+	query := `
+        SELECT ` + personColumns + `
+        FROM persons
+        WHERE id IN (SELECT value FROM json_each($1)) AND deleted_at IS NULL`
+
+	locked := make(map[uuid.UUID]*entities.Person, len(ids))
+	err := queryPersons(ctx, tx, query, []any{jsonArray(ids)}, func(p *entities.Person) error {
+		locked[p.ID] = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if _, ok := locked[id]; !ok {
+			logger.Debug(ctx, "person not found for modification", zap.String("id", id.String()))
+			return nil, fmt.Errorf("%w: id %s", ErrPersonNotFound, id)
+		}
+	}
+	return locked, nil
+}
+
+// insertPerson вставляет персону со всеми колонками, включая время создания и версию.
+// Возвращает: сохраненное состояние персоны, ошибка.
+func insertPerson(ctx context.Context, tx *sql.Tx, person *entities.Person) (*entities.Person, error) {
+	query := `
+        INSERT INTO persons (
+            id, name, surname, patronymic, age, gender, gender_probability,
+            nationality, nationality_probability, created_at, updated_at, version,
+            name_phonetic, surname_phonetic
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+        RETURNING ` + personColumns
+
+	return scanPerson(tx.QueryRowContext(ctx, query,
+		person.ID,
+		person.Name,
+		person.Surname,
+		person.Patronymic,
+		person.Age,
+		person.Gender,
+		person.GenderProbability,
+		person.Nationality,
+		person.NationalityProbability,
+		toMicros(person.CreatedAt),
+		toMicros(person.UpdatedAt),
+		person.Version,
+		phoneticKeys(person.Name),
+		phoneticKeys(person.Surname),
+	))
+}
+
+// writePerson записывает все изменяемые колонки персоны, увеличивая версию.
+// Возвращает: новое состояние персоны, ошибка.
+func writePerson(ctx context.Context, tx *sql.Tx, person *entities.Person) (*entities.Person, error) {
+	query := `
+        UPDATE persons
+        SET name = $2, surname = $3, patronymic = $4, age = $5,
+            gender = $6, gender_probability = $7, nationality = $8,
+            nationality_probability = $9, updated_at = $10, version = version + 1,
+            name_phonetic = $11, surname_phonetic = $12
+        WHERE id = $1
+        RETURNING ` + personColumns
+
+	return scanPerson(tx.QueryRowContext(ctx, query,
+		person.ID,
+		person.Name,
+		person.Surname,
+		person.Patronymic,
+		person.Age,
+		person.Gender,
+		person.GenderProbability,
+		person.Nationality,
+		person.NationalityProbability,
+		toMicros(time.Now()),
+		phoneticKeys(person.Name),
+		phoneticKeys(person.Surname),
+	))
+}
+
+// enrichedFields возвращает для каждой персоны колонки, последнее изменение которых
+// по истории было сделано обогащением.
+func enrichedFields(ctx context.Context, tx *sql.Tx, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	query := `
+        SELECT person_id, field
+        FROM (
+            SELECT h.person_id, f.value AS field, h.operation,
+                   ROW_NUMBER() OVER (PARTITION BY h.person_id, f.value ORDER BY h.version DESC) AS position
+            FROM person_history h, json_each(h.changed_fields) f
+            WHERE h.person_id IN (SELECT value FROM json_each($1))
+        ) AS latest
+        WHERE position = 1 AND operation = $2`
+
+	rows, err := tx.QueryContext(ctx, query, jsonArray(ids), historyrepo.OperationEnrich)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query enriched fields: %w", err)
+	}
+	defer closeRows(ctx, rows)
+
+	enriched := make(map[uuid.UUID][]string, len(ids))
+	for rows.Next() {
+		var personID uuid.UUID
+		var field string
+		if err := rows.Scan(&personID, &field); err != nil {
+			return nil, fmt.Errorf("failed to scan enriched field: %w", err)
+		}
+		enriched[personID] = append(enriched[personID], field)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating through rows: %w", rows.Err())
+	}
+	return enriched, nil
+}
+
+// insertHistory записывает изменение персоны в person_history.
+// Инициатор и идентификатор запроса берутся из контекста; related перечисляет
+// другие персоны, участвовавшие в операции.
+func insertHistory(ctx context.Context, tx *sql.Tx, operation string, before, after *entities.Person, related ...uuid.UUID) error {
+	query := `
+        INSERT INTO person_history (
+            person_id, version, operation, before, after, changed_fields, related_ids, actor, request_id, created_at
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `
+
+	requestID, _ := logger.RequestID(ctx)
+
+	var beforeJSON, relatedJSON *string
+	if before != nil {
+		value := jsonArray(before)
+		beforeJSON = &value
+	}
+	if len(related) > 0 {
+		value := jsonArray(related)
+		relatedJSON = &value
+	}
+
+	_, err := tx.ExecContext(ctx, query,
+		after.ID,
+		after.Version,
+		operation,
+		beforeJSON,
+		jsonArray(after),
+		jsonArray(changedFields(before, after)),
+		relatedJSON,
+		nullIfEmpty(historyrepo.Actor(ctx)),
+		nullIfEmpty(requestID),
+		toMicros(time.Now()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write person history: %w", err)
+	}
+	return nil
+}
+
+// changedFields возвращает колонки, значения которых отличаются в двух состояниях персоны.
+// Для созданной персоны (before == nil) возвращаются все заполненные колонки.
+func changedFields(before, after *entities.Person) []string {
+	if before == nil {
+		before = &entities.Person{}
+	}
+
+	candidates := []struct {
+		column string
+		equal  bool
+	}{
+		{"name", before.Name == after.Name},
+		{"surname", before.Surname == after.Surname},
+		{"patronymic", equalPtr(before.Patronymic, after.Patronymic)},
+		{"age", equalPtr(before.Age, after.Age)},
+		{"gender", equalPtr(before.Gender, after.Gender)},
+		{"gender_probability", equalPtr(before.GenderProbability, after.GenderProbability)},
+		{"nationality", equalPtr(before.Nationality, after.Nationality)},
+		{"nationality_probability", equalPtr(before.NationalityProbability, after.NationalityProbability)},
+		{"deleted_at", (before.DeletedAt == nil) == (after.DeletedAt == nil)},
+	}
+
+	changed := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !candidate.equal {
+			changed = append(changed, candidate.column)
+		}
+	}
+	return changed
+}
+
+// equalPtr сравнивает значения по указателям, считая равными два nil.
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// nullIfEmpty возвращает nil для пустой строки, чтобы сохранить в колонке NULL.
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// isExpectedError сообщает, является ли ошибка ожидаемым результатом операции,
+// который не нужно логировать как сбой хранилища.
+func isExpectedError(err error) bool {
+	return errors.Is(err, ErrPersonNotFound) ||
+		errors.Is(err, person.ErrVersionConflict) ||
+		errors.Is(err, person.ErrPersonNotDeleted) ||
+		errors.Is(err, person.ErrInvalidMerge)
+}
+
+// orderByClause формирует ORDER BY по колонкам сортировки с идентификатором в качестве
+// последнего ключа, чтобы порядок строк с одинаковыми значениями был детерминированным.
+// Идентификатор сортируется в направлении первой колонки, чтобы сортировка по одной колонке
+// в любом направлении читалась индексом (колонка, id) прямым или обратным проходом.
+// NULL упорядочивается как в PostgreSQL: в конце при ASC и в начале при DESC.
+func orderByClause(sortFields []person.SortField) string {
+	parts := make([]string, 0, len(sortFields)+1)
+	for _, field := range sortFields {
+		nulls := " NULLS LAST"
+		if field.Desc {
+			nulls = " NULLS FIRST"
+		}
+		parts = append(parts, field.Column+direction(field.Desc)+nulls)
+	}
+	parts = append(parts, "id"+direction(sortFields[0].Desc))
+	return strings.Join(parts, ", ")
+}
+
+// direction возвращает направление сортировки для ORDER BY.
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// keysetCondition строит условие отбора строк, следующих за курсором в порядке orderByClause.
+// Номера параметров начинаются с argNum. NULL считается значением больше любого другого
+// (в конце при ASC и в начале при DESC), что учитывается в сравнении.
+func keysetCondition(sortFields []person.SortField, cursor person.Cursor, argNum int) (string, []any) {
+	keys := append(append([]person.SortField{}, sortFields...),
+		person.SortField{Column: "id", Desc: sortFields[0].Desc})
+	values := append(append([]any{}, cursor.Values...), cursor.ID)
+
+	var args []any
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			args = append(args, columnValue(value))
+			placeholders[i] = fmt.Sprintf("$%d", argNum+len(args)-1)
+		}
+	}
+
+	alternatives := make([]string, 0, len(keys))
+	for i, key := range keys {
+		after := keyAfter(key, values[i], placeholders[i])
+		if after == "" {
+			continue
+		}
+
+		parts := make([]string, 0, i+1)
+		for j := range i {
+			if values[j] == nil {
+				parts = append(parts, keys[j].Column+" IS NULL")
+			} else {
+				parts = append(parts, fmt.Sprintf("%s = %s", keys[j].Column, placeholders[j]))
+			}
+		}
+		parts = append(parts, after)
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(alternatives) == 0 {
+		return "FALSE", args
+	}
+
+	condition := "(" + strings.Join(alternatives, " OR ") + ")"
+	if bound := leadingBound(keys[0], values[0], placeholders[0]); bound != "" {
+		condition = "(" + bound + " AND " + condition + ")"
+	}
+	return condition, args
+}
+
+// leadingBound возвращает избыточное условие на первую колонку сортировки. Оно не меняет
+// результат keysetCondition, но позволяет начать чтение индекса с позиции курсора,
+// а не перебирать строки с начала. Пустая строка означает, что ограничения нет.
+func leadingBound(key person.SortField, value any, placeholder string) string {
+	switch {
+	case value == nil && key.Desc:
+		return ""
+	case value == nil:
+		return key.Column + " IS NULL"
+	case key.Desc:
+		return fmt.Sprintf("%s <= %s", key.Column, placeholder)
+	case person.NullableColumns[key.Column]:
+		return fmt.Sprintf("(%s >= %s OR %s IS NULL)", key.Column, placeholder, key.Column)
+	default:
+		return fmt.Sprintf("%s >= %s", key.Column, placeholder)
+	}
+}
+
+// keyAfter возвращает условие, при котором значение колонки следует за значением курсора.
+// Пустая строка означает, что таких значений нет.
+func keyAfter(key person.SortField, value any, placeholder string) string {
+	switch {
+	case value == nil && key.Desc:
+		return key.Column + " IS NOT NULL"
+	case value == nil:
+		return ""
+	case key.Desc:
+		return fmt.Sprintf("%s < %s", key.Column, placeholder)
+	case person.NullableColumns[key.Column]:
+		return fmt.Sprintf("(%s > %s OR %s IS NULL)", key.Column, placeholder, key.Column)
+	default:
+		return fmt.Sprintf("%s > %s", key.Column, placeholder)
+	}
+}
+
+// notDeletedCondition возвращает условие, исключающее мягко удаленные персоны,
+// если контекст не запрашивает их явно через person.WithDeleted.
+func notDeletedCondition(ctx context.Context) string {
+	if person.IncludeDeleted(ctx) {
+		return ""
+	}
+	return " AND deleted_at IS NULL"
+}
+
+// prefixedPersonColumns возвращает personColumns с псевдонимом таблицы для запросов с соединениями.
+func prefixedPersonColumns(alias string) string {
+	columns := strings.Split(personColumns, ",")
+	for i, column := range columns {
+		columns[i] = alias + "." + strings.TrimSpace(column)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// now возвращает текущее время с точностью хранения колонок времени.
+func now() time.Time {
+	return time.UnixMicro(toMicros(time.Now())).UTC()
+}
+
+// toMicros переводит время в микросекунды Unix, в которых хранятся колонки времени.
+func toMicros(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// fromMicros восстанавливает время UTC из микросекунд Unix.
+func fromMicros(micros int64) time.Time {
+	return time.UnixMicro(micros).UTC()
+}
+
+// columnValue приводит значение фильтра или курсора к представлению колонки в SQLite.
+func columnValue(value any) any {
+	if t, ok := value.(time.Time); ok {
+		return toMicros(t)
+	}
+	return value
+}
+
+// jsonArray кодирует значение в JSON для колонок-массивов и параметров json_each.
+// Значения, которые передаются сюда, всегда кодируются без ошибок.
+func jsonArray(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode json: %v", err))
+	}
+	return string(data)
+}
+
+// phoneticKeys возвращает фонетические ключи строки в виде JSON-массива.
+func phoneticKeys(value string) string {
+	keys := phonetic.Keys(value)
+	if keys == nil {
+		keys = []string{}
+	}
+	return jsonArray(keys)
+}
+
+// closeRows закрывает строки результата, записывая ошибку закрытия в лог.
+func closeRows(ctx context.Context, rows *sql.Rows) {
+	if err := rows.Close(); err != nil {
+		logger.Warn(ctx, "failed to close rows", zap.Error(err))
+	}
+}
+
+// rowScanner описывает строку результата, общую для *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPerson считывает строку с колонками personColumns в сущность персоны.
+// Значения дополнительных колонок, следующих за personColumns, считываются в extra.
+func scanPerson(row rowScanner, extra ...any) (*entities.Person, error) {
+	var person entities.Person
+	var patronymic sql.NullString
+	var age sql.NullInt32
+	var gender sql.NullString
+	var genderProb sql.NullFloat64
+	var nationality sql.NullString
+	var nationalityProb sql.NullFloat64
+	var createdAt, updatedAt int64
+	var deletedAt sql.NullInt64
+
+	dest := []any{
+		&person.ID,
+		&person.Name,
+		&person.Surname,
+		&patronymic,
+		&age,
+		&gender,
+		&genderProb,
+		&nationality,
+		&nationalityProb,
+		&createdAt,
+		&updatedAt,
+		&person.Version,
+		&deletedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan person: %w", err)
+	}
+
+	// Конвертируем nullable поля
+	if patronymic.Valid {
+		person.Patronymic = &patronymic.String
+	}
+	if age.Valid {
+		ageVal := int(age.Int32)
+		person.Age = &ageVal
+	}
+	if gender.Valid {
+		person.Gender = &gender.String
+	}
+	if genderProb.Valid {
+		person.GenderProbability = &genderProb.Float64
+	}
+	if nationality.Valid {
+		person.Nationality = &nationality.String
+	}
+	if nationalityProb.Valid {
+		person.NationalityProbability = &nationalityProb.Float64
+	}
+	person.CreatedAt = fromMicros(createdAt)
+	person.UpdatedAt = fromMicros(updatedAt)
+	if deletedAt.Valid {
+		deleted := fromMicros(deletedAt.Int64)
+		person.DeletedAt = &deleted
+	}
+
+	return &person, nil
+}
@@ -0,0 +1,32 @@
+package person_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/sqlite/repo/people/person"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person/persontest"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRepository прогоняет общий набор тестов репозитория на отдельной базе SQLite для каждого подтеста.
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	migrator := migrate.NewAdapter(migrate.Config{Path: "../../../../../../../migrations/sqlite"})
+
+	persontest.RunRepositoryTests(t, func(t *testing.T) personrepo.Repository {
+		config := sqlite.Config{Path: filepath.Join(t.TempDir(), "persons.db"), BusyTimeout: 5 * time.Second}
+		require.NoError(t, migrator.Up(ctx, config.MigrateURL()))
+
+		db, err := sqlite.New(ctx, config)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close(ctx) })
+
+		return person.NewRepository(db)
+	})
+}
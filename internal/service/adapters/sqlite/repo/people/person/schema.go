@@ -0,0 +1,66 @@
+package person
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// schemaColumns перечисляет таблицы и колонки, к которым обращаются запросы репозитория.
+var schemaColumns = map[string][]string{
+	"persons": append(strings.Fields(strings.ReplaceAll(personColumns, ",", " ")),
+		"merged_into", "name_phonetic", "surname_phonetic"),
+	"person_history": {
+		"id", "person_id", "version", "operation", "before", "after", "changed_fields",
+		"related_ids", "actor", "request_id", "created_at",
+	},
+	"person_external_ids": {"source", "external_id", "person_id", "created_at"},
+}
+
+// CheckSchema проверяет, что в базе данных есть все колонки, используемые репозиторием.
+func (r *Repository) CheckSchema(ctx context.Context) error {
+	rows, err := r.querier().QueryContext(ctx, `
+        SELECT t.name || '.' || c.name
+        FROM sqlite_master t, pragma_table_info(t.name) c
+        WHERE t.type = 'table'`)
+	if err != nil {
+		logger.Error(ctx, "failed to read database schema", zap.Error(err))
+		return fmt.Errorf("failed to read database schema: %w", err)
+	}
+	defer closeRows(ctx, rows)
+
+	var existing []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("failed to scan database schema: %w", err)
+		}
+		existing = append(existing, name)
+	}
+	if rows.Err() != nil {
+		logger.Error(ctx, "failed to read database schema", zap.Error(rows.Err()))
+		return fmt.Errorf("failed to read database schema: %w", rows.Err())
+	}
+
+	var missing []string
+	for table, columns := range schemaColumns {
+		for _, column := range columns {
+			if name := table + "." + column; !slices.Contains(existing, name) {
+				missing = append(missing, name)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	slices.Sort(missing)
+	logger.Error(ctx, "database schema is missing columns used by the person repository",
+		zap.Strings("columns", missing))
+	return fmt.Errorf("%w: missing columns %s", person.ErrSchemaDrift, strings.Join(missing, ", "))
+}
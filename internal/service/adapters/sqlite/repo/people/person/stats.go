@@ -0,0 +1,177 @@
+package person
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// coverageColumns перечисляет колонки, заполненность которых считает GetStats, в порядке сканирования.
+var coverageColumns = []string{
+	"patronymic",
+	"age",
+	"gender",
+	"gender_probability",
+	"nationality",
+	"nationality_probability",
+}
+
+// GetStats вычисляет статистику по персонам, отобранным фильтром GetPersons.
+// Все агрегаты считаются в SQLite в одной читающей транзакции на общем снимке данных.
+func (r *Repository) GetStats(ctx context.Context, filter map[string]any, opts person.StatsOptions) (*entities.PersonStats, error) {
+	logger.Debug(ctx, "getting person stats",
+		zap.Any("filter", filter),
+		zap.Int("top_nationalities", opts.TopNationalities),
+		zap.Int("age_bucket_width", opts.AgeBucketWidth))
+
+	selection, err := parseFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats *entities.PersonStats
+	err = r.readInTx(ctx, func(q sqlite.Querier) error {
+		var err error
+		stats, err = readStats(ctx, q, selection.where, selection.args, opts)
+		return err
+	})
+	if err != nil {
+		logger.Error(ctx, "failed to get person stats", zap.Error(err))
+		return nil, fmt.Errorf("failed to get person stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// readStats выполняет запросы GetStats по условию where с параметрами args.
+func readStats(ctx context.Context, q sqlite.Querier, where string, args []any, opts person.StatsOptions) (*entities.PersonStats, error) {
+	stats := &entities.PersonStats{AgeBucketWidth: opts.AgeBucketWidth}
+	next := len(args) + 1
+
+	filled := make([]int, len(coverageColumns))
+	dest := []any{&stats.Total}
+	for i := range filled {
+		dest = append(dest, &filled[i])
+	}
+	dest = append(dest, &stats.AverageGenderProbability, &stats.AverageNationalityProbability)
+	err := q.QueryRowContext(ctx, `
+        SELECT COUNT(*), COUNT(patronymic), COUNT(age), COUNT(gender), COUNT(gender_probability),
+               COUNT(nationality), COUNT(nationality_probability),
+               AVG(gender_probability), AVG(nationality_probability)
+        FROM persons
+        WHERE `+where, args...).Scan(dest...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan totals: %w", err)
+	}
+
+	stats.Coverage = make([]entities.PersonStatsFieldCoverage, len(coverageColumns))
+	for i, column := range coverageColumns {
+		stats.Coverage[i] = entities.PersonStatsFieldCoverage{Field: column, Filled: filled[i]}
+		if stats.Total > 0 {
+			stats.Coverage[i].Ratio = float64(filled[i]) / float64(stats.Total)
+		}
+	}
+
+	stats.Gender, err = readValueCounts(ctx, q, `
+        SELECT gender, COUNT(*)
+        FROM persons
+        WHERE `+where+` AND gender IS NOT NULL
+        GROUP BY gender
+        ORDER BY COUNT(*) DESC, gender`, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gender counts: %w", err)
+	}
+
+	stats.Nationality, err = readValueCounts(ctx, q, fmt.Sprintf(`
+        SELECT nationality, COUNT(*)
+        FROM persons
+        WHERE %s AND nationality IS NOT NULL
+        GROUP BY nationality
+        ORDER BY COUNT(*) DESC, nationality
+        LIMIT $%d`, where, next), slices.Concat(args, []any{opts.TopNationalities}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nationality counts: %w", err)
+	}
+	// Заполненность национальности равна сумме по всем значениям, поэтому остаток не требует отдельного запроса.
+	stats.NationalityOther = filled[slices.Index(coverageColumns, "nationality")]
+	for _, count := range stats.Nationality {
+		stats.NationalityOther -= count.Count
+	}
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`
+        SELECT age / $%[2]d * $%[2]d AS bucket, COUNT(*)
+        FROM persons
+        WHERE %[1]s AND age IS NOT NULL
+        GROUP BY bucket
+        ORDER BY bucket`, where, next), slices.Concat(args, []any{opts.AgeBucketWidth})...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query age histogram: %w", err)
+	}
+	defer closeRows(ctx, rows)
+
+	stats.Age = make([]entities.PersonStatsAgeBucket, 0)
+	for rows.Next() {
+		var bucket entities.PersonStatsAgeBucket
+		if err := rows.Scan(&bucket.From, &bucket.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan age bucket: %w", err)
+		}
+		bucket.To = bucket.From + opts.AgeBucketWidth - 1
+		stats.Age = append(stats.Age, bucket)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read age histogram: %w", rows.Err())
+	}
+
+	return stats, nil
+}
+
+// readValueCounts выполняет запрос, возвращающий строки (значение, количество).
+func readValueCounts(ctx context.Context, q sqlite.Querier, query string, args []any) ([]entities.PersonStatsValueCount, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query counts: %w", err)
+	}
+	defer closeRows(ctx, rows)
+
+	counts := make([]entities.PersonStatsValueCount, 0)
+	for rows.Next() {
+		var count entities.PersonStatsValueCount
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read counts: %w", rows.Err())
+	}
+	return counts, nil
+}
+
+// readInTx выполняет fn на согласованном снимке данных: в читающей транзакции, которая
+// не берет блокировку записи, или в транзакции WithTx.
+func (r *Repository) readInTx(ctx context.Context, fn func(q sqlite.Querier) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.DB().BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// Транзакция только читает данные, поэтому всегда откатывается.
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.Warn(ctx, "failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	return fn(tx)
+}
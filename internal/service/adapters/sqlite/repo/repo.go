@@ -0,0 +1,52 @@
+// Package repo содержит реализацию репозиториев с использованием SQLite.
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/sqlite/repo/people"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	peoplerepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+)
+
+// Проверка реализации интерфейса.
+var _ repo.Repositories = (*Repositories)(nil)
+
+// Repositories реализует интерфейс repo.Repositories для SQLite.
+type Repositories struct {
+	db         sqlite.Provider
+	peopleRepo *people.Repositories
+	// inTx означает, что репозитории привязаны к транзакции WithTx.
+	inTx bool
+}
+
+// NewRepositories создает новый экземпляр репозиториев с SQLite.
+func NewRepositories(db sqlite.Provider) *Repositories {
+	return &Repositories{
+		db:         db,
+		peopleRepo: people.NewRepositories(db),
+	}
+}
+
+// People возвращает репозитории для работы с данными о людях.
+func (r *Repositories) People() peoplerepo.Repositories {
+	return r.peopleRepo
+}
+
+// WithTx выполняет fn в пишущей транзакции. SQLite выполняет пишущие транзакции по одной,
+// поэтому fn видит неизменный снимок данных и конфликтов, требующих повтора, не возникает.
+func (r *Repositories) WithTx(ctx context.Context, fn func(tx repo.Repositories) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+
+	return sqlite.RunInTx(ctx, r.db.DB(), func(tx *sql.Tx) error {
+		return fn(&Repositories{
+			db:         r.db,
+			peopleRepo: r.peopleRepo.WithTx(tx),
+			inTx:       true,
+		})
+	})
+}
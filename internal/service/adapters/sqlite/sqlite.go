@@ -0,0 +1,59 @@
+// Package sqlite предоставляет адаптеры SQLite для приложения.
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/sqlite/repo"
+	repoports "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Adapter представляет основной адаптер SQLite для приложения.
+type Adapter struct {
+	db           sqlite.Provider
+	repositories repoports.Repositories
+}
+
+// NewSQLiteAdapter создает новый адаптер SQLite со всеми репозиториями.
+func NewSQLiteAdapter(db sqlite.Provider) *Adapter {
+	return &Adapter{
+		db:           db,
+		repositories: repo.NewRepositories(db),
+	}
+}
+
+// Repositories возвращает все репозитории для приложения.
+func (a *Adapter) Repositories() repoports.Repositories {
+	return a.repositories
+}
+
+// DB возвращает провайдер базы данных SQLite.
+func (a *Adapter) DB() sqlite.Provider {
+	return a.db
+}
+
+// Close закрывает базу данных SQLite.
+func (a *Adapter) Close(ctx context.Context) {
+	logger.Info(ctx, "closing SQLite adapter")
+	a.db.Close(ctx)
+}
+
+// Ping проверяет доступность базы данных.
+func (a *Adapter) Ping(ctx context.Context) error {
+	logger.Debug(ctx, "pinging SQLite database")
+	err := a.db.Ping(ctx)
+	if err != nil {
+		logger.Error(ctx, "failed to ping SQLite database", zap.Error(err))
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// DSN возвращает строку подключения к базе данных.
+func (a *Adapter) DSN() string {
+	return a.db.GetDSN()
+}
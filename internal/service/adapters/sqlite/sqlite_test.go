@@ -0,0 +1,117 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	adapter "github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/sqlite"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	"github.com/flexer2006/case-person-enrichment-go/migrations"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate/migratetest"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepositories открывает новую базу SQLite во временном каталоге и применяет миграции.
+func newRepositories(t *testing.T) repo.Repositories {
+	t.Helper()
+	ctx := context.Background()
+
+	config := sqlite.Config{Path: filepath.Join(t.TempDir(), "persons.db"), BusyTimeout: 5 * time.Second}
+	require.NoError(t, migrate.NewAdapter(migrate.Config{Path: "../../../../migrations/sqlite"}).Up(ctx, config.MigrateURL()))
+
+	db, err := sqlite.New(ctx, config)
+	require.NoError(t, err)
+	a := adapter.NewSQLiteAdapter(db)
+	t.Cleanup(func() { a.Close(ctx) })
+
+	return a.Repositories()
+}
+
+func TestWithTxRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repositories := newRepositories(t)
+	errAbort := errors.New("abort")
+
+	var created *entities.Person
+	err := repositories.WithTx(ctx, func(tx repo.Repositories) error {
+		created = &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+		if err := tx.People().Person().CreatePerson(ctx, created); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	exists, err := repositories.People().Person().ExistsByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = repositories.WithTx(ctx, func(tx repo.Repositories) error {
+		return tx.People().Person().CreatePerson(ctx, created)
+	})
+	require.NoError(t, err)
+
+	exists, err = repositories.People().Person().ExistsByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestHistoryRecordsChanges(t *testing.T) {
+	ctx := context.Background()
+	repositories := newRepositories(t)
+
+	p := &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+	require.NoError(t, repositories.People().Person().CreatePerson(ctx, p))
+	_, err := repositories.People().Person().PatchPerson(ctx, p.ID, map[string]any{"age": 30}, 0)
+	require.NoError(t, err)
+
+	history, total, err := repositories.People().History().GetHistory(ctx, p.ID, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	assert.Equal(t, 2, history[0].Version)
+	assert.Equal(t, []string{"age"}, history[0].ChangedFields)
+
+	first, err := repositories.People().History().GetByVersion(ctx, p.ID, 1)
+	require.NoError(t, err)
+	require.NotNil(t, first.After)
+	assert.Nil(t, first.After.Age)
+}
+
+func TestImportsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repositories := newRepositories(t)
+
+	result := &entities.PersonImport{
+		Format: "csv",
+		Total:  2,
+		Valid:  1,
+		Failed: 1,
+		Errors: []entities.PersonImportError{{Row: 3, Field: "age", Message: "invalid age"}},
+	}
+	require.NoError(t, repositories.People().Imports().CreateImport(ctx, result))
+
+	stored, err := repositories.People().Imports().GetImport(ctx, result.ID)
+	require.NoError(t, err)
+	assert.Equal(t, result.Errors, stored.Errors)
+	assert.Empty(t, stored.FileName)
+	assert.True(t, result.CreatedAt.Equal(stored.CreatedAt))
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	config := sqlite.Config{Path: filepath.Join(t.TempDir(), "persons.db"), BusyTimeout: 5 * time.Second}
+	db, err := sqlite.New(ctx, config)
+	require.NoError(t, err)
+	defer db.Close(ctx)
+
+	assets, err := migrations.SQLite()
+	require.NoError(t, err)
+	migratetest.RoundTrip(t, config.MigrateURL(), migrate.Config{FS: assets}, db.Schema)
+}
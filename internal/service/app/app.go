@@ -3,93 +3,210 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/enrichment"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/memory"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/postgres"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/server"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/adapters/sqlite"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/enrich"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/person"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
 	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/storage"
+	tenantsetup "github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
 	pgadapter "github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	sqliteadapter "github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
 	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// Ошибки инициализации приложения.
+var (
+	// ErrUnknownStorageDriver возвращается, если STORAGE_DRIVER содержит неподдерживаемое значение.
+	ErrUnknownStorageDriver = errors.New("unknown storage driver")
+	// ErrTenantsUnsupported возвращается, если арендаторы настроены для хранилища без разделения данных.
+	ErrTenantsUnsupported = errors.New("storage driver does not isolate tenants")
+	// ErrRowSecurityBypassed возвращается, если роль PostgreSQL обходит политики разделения арендаторов.
+	ErrRowSecurityBypassed = errors.New("database role bypasses row-level security")
+)
+
 // Application представляет основное приложение, объединяющее все компоненты.
 type Application struct {
 	config        *setup.Config
 	db            *pgadapter.Database
 	pgAdapter     *postgres.Adapter
+	sqliteAdapter *sqlite.Adapter
 	apiAdapter    api.API
 	repositories  repo.Repositories
+	tenants       *tenantsetup.Registry
 	httpServer    *server.Server
 	personService person.Service
+	retentionJob  *RetentionJob
 }
 
 // NewApplication создает новый экземпляр приложения с указанной конфигурацией.
 func NewApplication(ctx context.Context, config *setup.Config) (*Application, error) {
 	logger.Info(ctx, "initializing application")
 
-	dbConfig := pgadapter.Config{
-		Host:     config.Postgres.Host,
-		Port:     config.Postgres.Port,
-		User:     config.Postgres.User,
-		Password: config.Postgres.Password,
-		Database: config.Postgres.Database,
-		SSLMode:  config.Postgres.SSLMode,
-		MinConns: config.Postgres.PoolMinConns,
-		MaxConns: config.Postgres.PoolMaxConns,
+	app := &Application{config: config}
+
+	tenants, err := config.Tenants.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenants: %w", err)
+	}
+	// Данные арендаторов разделяют только политики защиты строк PostgreSQL.
+	if tenants.Isolated() && config.Storage.Driver != storage.DriverPostgres {
+		return nil, fmt.Errorf("%w: %q", ErrTenantsUnsupported, config.Storage.Driver)
+	}
+	app.tenants = tenants
+
+	switch config.Storage.Driver {
+	case storage.DriverPostgres:
+		if err := app.initPostgres(ctx); err != nil {
+			return nil, err
+		}
+		app.repositories = app.pgAdapter.Repositories()
+	case storage.DriverSQLite:
+		if err := app.initSQLite(ctx); err != nil {
+			return nil, err
+		}
+		app.repositories = app.sqliteAdapter.Repositories()
+	case storage.DriverMemory:
+		logger.Warn(ctx, "using in-memory storage, data will be lost on restart")
+		app.repositories = memory.NewRepositories()
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStorageDriver, config.Storage.Driver)
 	}
 
+	// Схема проверяется и при выключенных автоматических миграциях: без нужных колонок
+	// запросы репозитория будут завершаться ошибками уже после запуска.
+	if checker, ok := app.repositories.People().Person().(personrepo.SchemaChecker); ok {
+		if err := checker.CheckSchema(ctx); err != nil {
+			app.closeStorage(ctx)
+			return nil, fmt.Errorf("failed to check database schema: %w", err)
+		}
+	}
+
+	apiAdapter := enrichment.NewTenantEnrichment(enrichment.NewDefaultEnrichment().People(), tenants)
+
+	app.apiAdapter = apiAdapter
+	app.personService = NewPersonService(app.repositories, apiAdapter)
+	app.httpServer = server.New(config.Server, apiAdapter, app.repositories, tenants)
+	app.retentionJob = NewRetentionJob(app.repositories.People().Person(), config.Retention, tenants.IDs()...)
+
+	logger.Info(ctx, "application initialized successfully",
+		zap.String("storage_driver", config.Storage.Driver),
+		zap.Strings("tenants", tenants.IDs()))
+	return app, nil
+}
+
+// initPostgres подключается к PostgreSQL и применяет миграции.
+func (a *Application) initPostgres(ctx context.Context) error {
 	// Создание базы данных
-	database, err := pgadapter.New(ctx, dbConfig)
+	database, err := pgadapter.New(ctx, a.config.Postgres.ToConfig())
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
+		return fmt.Errorf("failed to initialize database: %w", err)
 	}
 
-	if config.Migrations.Path != "" {
-		migrateConfig := migrate.Config{
-			Path: config.Migrations.Path,
+	if err := a.prepareSchema(ctx, a.config.Migrations.Postgres(), database.GetDSN()); err != nil {
+		database.Close(ctx)
+		return err
+	}
+
+	if a.tenants.Isolated() {
+		bypass, err := database.BypassesRowSecurity(ctx)
+		if err != nil {
+			database.Close(ctx)
+			return err
 		}
-		migrator := migrate.NewAdapter(migrateConfig)
-		if err := migrator.Up(ctx, database.GetDSN()); err != nil {
-			return nil, fmt.Errorf("failed to apply migrations: %w", err)
+		if bypass {
+			database.Close(ctx)
+			logger.Error(ctx, "database role is a superuser or has BYPASSRLS, tenants would not be isolated",
+				zap.String("user", a.config.Postgres.User))
+			return fmt.Errorf("%w: user %q", ErrRowSecurityBypassed, a.config.Postgres.User)
 		}
 	}
 
-	pgAdapter := postgres.NewPostgresAdapter(database)
+	a.db = database
+	a.pgAdapter = postgres.NewPostgresAdapter(database)
+	return nil
+}
+
+// initSQLite открывает базу данных SQLite и применяет миграции для SQLite.
+func (a *Application) initSQLite(ctx context.Context) error {
+	database, err := sqliteadapter.New(ctx, a.config.SQLite.ToConfig())
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
 
-	apiAdapter := enrichment.NewDefaultEnrichment()
+	source, err := a.config.Migrations.SQLite()
+	if err != nil {
+		database.Close(ctx)
+		return err
+	}
+	if err := a.prepareSchema(ctx, source, database.GetDSN()); err != nil {
+		database.Close(ctx)
+		return err
+	}
 
-	personSvc := NewPersonService(pgAdapter.Repositories(), apiAdapter)
+	a.sqliteAdapter = sqlite.NewSQLiteAdapter(database)
+	return nil
+}
 
-	httpServer := server.New(config.Server, apiAdapter, pgAdapter.Repositories())
+// prepareSchema проверяет схему базы данных по миграциям source и, если включено MIGRATIONS_AUTO,
+// применяет новые миграции. Схема, оставшаяся "грязной" после неудачной миграции,
+// не позволяет запустить сервис до исправления командой migrate force.
+func (a *Application) prepareSchema(ctx context.Context, source migrate.Config, dsn string) error {
+	migrator := migrate.NewAdapter(source)
 
-	app := &Application{
-		config:        config,
-		db:            database,
-		pgAdapter:     pgAdapter,
-		apiAdapter:    apiAdapter,
-		repositories:  pgAdapter.Repositories(),
-		httpServer:    httpServer,
-		personService: personSvc,
+	version, dirty, err := migrator.Version(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to check database schema: %w", err)
+	}
+	if dirty {
+		logger.Error(ctx, "database schema is dirty, fix it and run migrate force", zap.Int("version", version))
+		return fmt.Errorf("%w: version %d", migrate.ErrDirtyDatabase, version)
 	}
 
-	logger.Info(ctx, "application initialized successfully")
-	return app, nil
+	if !a.config.Migrations.Auto {
+		if version == migrate.NoVersion {
+			logger.Warn(ctx, "automatic migrations are disabled and no migrations are applied")
+		} else {
+			logger.Info(ctx, "automatic migrations are disabled", zap.Int("version", version))
+		}
+		return nil
+	}
+	if err := migrator.Up(ctx, dsn); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
 }
 
 // Start запускает все сервисы приложения.
 func (a *Application) Start(ctx context.Context) error {
 	logger.Info(ctx, "starting application")
 
+	go a.retentionJob.Run(ctx)
+	go func() {
+		for _, id := range a.tenants.IDs() {
+			tenantCtx := tenant.WithID(ctx, id)
+			if _, err := BackfillPhoneticKeys(tenantCtx, a.repositories.People().Person()); err != nil {
+				logger.Error(tenantCtx, "failed to backfill phonetic keys", zap.String("tenant", id), zap.Error(err))
+			}
+		}
+	}()
+
 	if err := a.httpServer.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
 	}
@@ -114,17 +231,32 @@ func (a *Application) Stop(ctx context.Context) error {
 		logger.Error(ctx, "error stopping HTTP server", zap.Error(err))
 	}
 
-	a.pgAdapter.Close(ctx)
+	a.closeStorage(ctx)
 
 	logger.Info(ctx, "application stopped")
 	return nil
 }
 
+// closeStorage закрывает соединения с базой данных хранилища.
+func (a *Application) closeStorage(ctx context.Context) {
+	if a.pgAdapter != nil {
+		a.pgAdapter.Close(ctx)
+	}
+	if a.sqliteAdapter != nil {
+		a.sqliteAdapter.Close(ctx)
+	}
+}
+
 // Repositories возвращает репозитории приложения.
 func (a *Application) Repositories() repo.Repositories {
 	return a.repositories
 }
 
+// Tenants возвращает арендаторов приложения.
+func (a *Application) Tenants() *tenantsetup.Registry {
+	return a.tenants
+}
+
 // API возвращает API-адаптер приложения.
 func (a *Application) API() api.API {
 	return a.apiAdapter
@@ -138,15 +270,17 @@ func (a *Application) PersonService() person.Service {
 // NewPersonService создает новый сервис для работы с персонами.
 func NewPersonService(repositories repo.Repositories, apiAdapter api.API) person.Service {
 	return &personServiceImpl{
-		repository: repositories.People().Person(),
-		apiAdapter: apiAdapter,
+		repositories: repositories,
+		repository:   repositories.People().Person(),
+		apiAdapter:   apiAdapter,
 	}
 }
 
 // personServiceImpl реализует интерфейс PersonService.
 type personServiceImpl struct {
-	repository personrepo.Repository
-	apiAdapter api.API
+	repositories repo.Repositories
+	repository   personrepo.Repository
+	apiAdapter   api.API
 }
 
 // Реализация методов PersonService...
@@ -178,7 +312,16 @@ func (s *personServiceImpl) CreatePerson(ctx context.Context, person *entities.P
 
 // UpdatePerson обновляет существующую персону.
 func (s *personServiceImpl) UpdatePerson(ctx context.Context, person *entities.Person) error {
-	if err := s.repository.UpdatePerson(ctx, person); err != nil {
+	// UpdatePerson записывает в персону новую версию, поэтому каждая попытка транзакции обновляет свою копию.
+	err := s.repositories.WithTx(ctx, func(tx repo.Repositories) error {
+		updated := *person
+		if err := tx.People().Person().UpdatePerson(ctx, &updated); err != nil {
+			return err
+		}
+		*person = updated
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update person: %w", err)
 	}
 	return nil
@@ -186,7 +329,7 @@ func (s *personServiceImpl) UpdatePerson(ctx context.Context, person *entities.P
 
 // DeletePerson удаляет персону по идентификатору.
 func (s *personServiceImpl) DeletePerson(ctx context.Context, id uuid.UUID) error {
-	if err := s.repository.DeletePerson(ctx, id); err != nil {
+	if err := s.repository.DeletePerson(ctx, id, 0); err != nil {
 		return fmt.Errorf("failed to delete person: %w", err)
 	}
 	return nil
@@ -201,40 +344,26 @@ func (s *personServiceImpl) EnrichPerson(ctx context.Context, id uuid.UUID) (*en
 		return nil, fmt.Errorf("failed to get person: %w", err)
 	}
 
-	if person.Age == nil {
-		ageService := s.apiAdapter.People().Age()
-		age, _, err := ageService.GetAgeByName(ctx, person.Name)
-		if err == nil {
-			person.Age = &age
-		} else {
-			logger.Warn(ctx, "failed to enrich with age data", zap.Error(err))
-		}
+	enrichment, err := enrich.Fetch(ctx, s.apiAdapter, person)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enrich person: %w", err)
 	}
 
-	// Обогащение данными о поле
-	if person.Gender == nil {
-		genderService := s.apiAdapter.People().Gender()
-		gender, probability, err := genderService.GetGenderByName(ctx, person.Name)
-		if err == nil {
-			person.Gender = &gender
-			person.GenderProbability = &probability
-		} else {
-			logger.Warn(ctx, "failed to enrich with gender data", zap.Error(err))
+	// Данные внешних сервисов дополняют состояние персоны, прочитанное в транзакции записи.
+	enrichCtx := historyrepo.WithOperation(ctx, historyrepo.OperationEnrich)
+	err = s.repositories.WithTx(ctx, func(tx repo.Repositories) error {
+		current, err := tx.People().Person().GetByID(ctx, id)
+		if err != nil {
+			return err
 		}
-	}
-
-	if person.Nationality == nil {
-		nationalityService := s.apiAdapter.People().Nationality()
-		nationality, probability, err := nationalityService.GetNationalityByName(ctx, person.Name)
-		if err == nil {
-			person.Nationality = &nationality
-			person.NationalityProbability = &probability
-		} else {
-			logger.Warn(ctx, "failed to enrich with nationality data", zap.Error(err))
+		if enrichment.ApplyTo(current) {
+			if err := tx.People().Person().UpdatePerson(enrichCtx, current); err != nil {
+				return err
+			}
 		}
-	}
-
-	err = s.repository.UpdatePerson(ctx, person)
+		person = current
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to save enriched person data: %w", err)
 	}
@@ -3,7 +3,10 @@ package app_test
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/app"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
@@ -12,8 +15,19 @@ import (
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/gender"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/nationality"
 	personapi "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
 	repopeople "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	importsrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
 	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/data"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/graceful"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/migration"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/storage"
+	tenantsetup "github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
+	sqliteadapter "github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -29,6 +43,11 @@ func (m *mockAPIAdapter) People() people.Services {
 	return args.Get(0).(people.Services)
 }
 
+func (m *mockAPIAdapter) ReserveEnrichment(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 type mockRepositories struct {
 	mock.Mock
 }
@@ -38,6 +57,11 @@ func (m *mockRepositories) People() repopeople.Repositories {
 	return args.Get(0).(repopeople.Repositories)
 }
 
+// WithTx выполняет fn без транзакции: моки не хранят данные.
+func (m *mockRepositories) WithTx(_ context.Context, fn func(tx repo.Repositories) error) error {
+	return fn(m)
+}
+
 type mockPeopleRepositories struct {
 	mock.Mock
 }
@@ -47,6 +71,16 @@ func (m *mockPeopleRepositories) Person() personrepo.Repository {
 	return args.Get(0).(personrepo.Repository)
 }
 
+func (m *mockPeopleRepositories) History() historyrepo.Repository {
+	args := m.Called()
+	return args.Get(0).(historyrepo.Repository)
+}
+
+func (m *mockPeopleRepositories) Imports() importsrepo.Repository {
+	args := m.Called()
+	return args.Get(0).(importsrepo.Repository)
+}
+
 type mockPersonRepository struct {
 	mock.Mock
 }
@@ -67,21 +101,102 @@ func (m *mockPersonRepository) GetPersons(ctx context.Context, filter map[string
 	return args.Get(0).([]*entities.Person), args.Int(1), args.Error(2)
 }
 
+func (m *mockPersonRepository) StreamPersons(ctx context.Context, filter map[string]any, fn func(*entities.Person) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *mockPersonRepository) FindDuplicatePairs(ctx context.Context, minScore float64, limit int) ([]entities.PersonDuplicatePair, error) {
+	args := m.Called(ctx, minScore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.PersonDuplicatePair), args.Error(1)
+}
+
+func (m *mockPersonRepository) MergePersons(ctx context.Context, survivorID uuid.UUID, mergedIDs []uuid.UUID, version int, resolve personrepo.MergeResolver) (*entities.Person, error) {
+	args := m.Called(ctx, survivorID, mergedIDs, version, resolve)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Person), args.Error(1)
+}
+
+func (m *mockPersonRepository) MergedInto(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *mockPersonRepository) UpsertByExternalID(ctx context.Context, source, externalID string, person *entities.Person) (bool, error) {
+	args := m.Called(ctx, source, externalID, person)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockPersonRepository) GetByExternalID(ctx context.Context, source, externalID string) (*entities.Person, error) {
+	args := m.Called(ctx, source, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Person), args.Error(1)
+}
+
+func (m *mockPersonRepository) GetStats(ctx context.Context, filter map[string]any, opts personrepo.StatsOptions) (*entities.PersonStats, error) {
+	args := m.Called(ctx, filter, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PersonStats), args.Error(1)
+}
+
 func (m *mockPersonRepository) CreatePerson(ctx context.Context, person *entities.Person) error {
 	args := m.Called(ctx, person)
 	return args.Error(0)
 }
 
+func (m *mockPersonRepository) CreatePersons(ctx context.Context, persons []*entities.Person) error {
+	args := m.Called(ctx, persons)
+	return args.Error(0)
+}
+
 func (m *mockPersonRepository) UpdatePerson(ctx context.Context, person *entities.Person) error {
 	args := m.Called(ctx, person)
 	return args.Error(0)
 }
 
-func (m *mockPersonRepository) DeletePerson(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *mockPersonRepository) PatchPerson(ctx context.Context, id uuid.UUID, fields map[string]any, version int) (*entities.Person, error) {
+	args := m.Called(ctx, id, fields, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Person), args.Error(1)
+}
+
+func (m *mockPersonRepository) DeletePerson(ctx context.Context, id uuid.UUID, version int) error {
+	args := m.Called(ctx, id, version)
 	return args.Error(0)
 }
 
+func (m *mockPersonRepository) SearchPersons(ctx context.Context, query string, limit int, phonetic bool) ([]*entities.PersonSearchResult, error) {
+	args := m.Called(ctx, query, limit, phonetic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.PersonSearchResult), args.Error(1)
+}
+
+func (m *mockPersonRepository) RestorePerson(ctx context.Context, id uuid.UUID) (*entities.Person, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Person), args.Error(1)
+}
+
+func (m *mockPersonRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *mockPersonRepository) ExistsByID(ctx context.Context, id uuid.UUID) (bool, error) {
 	args := m.Called(ctx, id)
 	return args.Bool(0), args.Error(1)
@@ -279,7 +394,7 @@ func TestPersonServiceDeletePerson(t *testing.T) {
 
 	peopleRepo.On("Person").Return(personRepo)
 	repositories.On("People").Return(peopleRepo)
-	personRepo.On("DeletePerson", mock.Anything, id).Return(nil)
+	personRepo.On("DeletePerson", mock.Anything, id, 0).Return(nil)
 
 	service := app.NewPersonService(repositories, apiAdapter)
 	err := service.DeletePerson(ctx, id)
@@ -321,6 +436,7 @@ func TestPersonServiceEnrichPerson(t *testing.T) {
 	peopleRepo.On("Person").Return(personRepo)
 	repositories.On("People").Return(peopleRepo)
 	apiAdapter.On("People").Return(peopleServices)
+	apiAdapter.On("ReserveEnrichment", mock.Anything).Return(nil)
 
 	peopleServices.On("Person").Return(personAPIService)
 	peopleServices.On("Age").Return(ageService)
@@ -379,6 +495,7 @@ func TestPersonServiceEnrichPersonPartialFailure(t *testing.T) {
 	peopleRepo.On("Person").Return(personRepo)
 	repositories.On("People").Return(peopleRepo)
 	apiAdapter.On("People").Return(peopleServices)
+	apiAdapter.On("ReserveEnrichment", mock.Anything).Return(nil)
 
 	peopleServices.On("Person").Return(personAPIService)
 	peopleServices.On("Age").Return(ageService)
@@ -417,3 +534,156 @@ func TestPersonServiceEnrichPersonPartialFailure(t *testing.T) {
 	genderService.AssertExpectations(t)
 	nationalityService.AssertExpectations(t)
 }
+
+func TestNewApplicationWithMemoryStorage(t *testing.T) {
+	ctx := context.Background()
+	config := &setup.Config{Storage: storage.Config{Driver: storage.DriverMemory}}
+
+	application, err := app.NewApplication(ctx, config)
+	require.NoError(t, err)
+
+	person := &entities.Person{Name: "John", Surname: "Doe"}
+	require.NoError(t, application.Repositories().People().Person().CreatePerson(ctx, person))
+
+	found, err := application.PersonService().GetByID(ctx, person.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Doe", found.Surname)
+}
+
+func TestNewApplicationWithSQLiteStorage(t *testing.T) {
+	ctx := context.Background()
+	config := &setup.Config{
+		Storage:    storage.Config{Driver: storage.DriverSQLite},
+		SQLite:     data.SQLiteConfig{Path: filepath.Join(t.TempDir(), "db", "persons.db"), BusyTimeout: time.Second},
+		Migrations: migration.Config{Auto: true},
+		Graceful:   graceful.Config{ShutdownTimeout: "1s"},
+	}
+
+	application, err := app.NewApplication(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, application.Stop(ctx)) })
+
+	person := &entities.Person{Name: "John", Surname: "Doe"}
+	require.NoError(t, application.Repositories().People().Person().CreatePerson(ctx, person))
+
+	found, err := application.PersonService().GetByID(ctx, person.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Doe", found.Surname)
+}
+
+func TestNewApplicationRefusesDirtySchema(t *testing.T) {
+	ctx := context.Background()
+	migrations := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(migrations, "000001_broken.up.sql"), []byte("SELECT * FROM missing;"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(migrations, "000001_broken.down.sql"), []byte(""), 0o600))
+
+	config := &setup.Config{
+		Storage:    storage.Config{Driver: storage.DriverSQLite},
+		SQLite:     data.SQLiteConfig{Path: filepath.Join(t.TempDir(), "persons.db"), BusyTimeout: time.Second},
+		Migrations: migration.Config{SQLitePath: migrations, Auto: true},
+	}
+
+	_, err := app.NewApplication(ctx, config)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, migrate.ErrDirtyDatabase)
+
+	// Неудачная миграция оставила схему "грязной": запуск отклоняется и без автоматических миграций.
+	config.Migrations.Auto = false
+	application, err := app.NewApplication(ctx, config)
+	require.ErrorIs(t, err, migrate.ErrDirtyDatabase)
+	assert.Nil(t, application)
+}
+
+func TestNewApplicationWithoutAutoMigrations(t *testing.T) {
+	ctx := context.Background()
+	config := &setup.Config{
+		Storage:  storage.Config{Driver: storage.DriverSQLite},
+		SQLite:   data.SQLiteConfig{Path: filepath.Join(t.TempDir(), "persons.db"), BusyTimeout: time.Second},
+		Graceful: graceful.Config{ShutdownTimeout: "1s"},
+	}
+
+	// Миграции не применялись, поэтому в базе нет таблиц персон.
+	_, err := app.NewApplication(ctx, config)
+	require.ErrorIs(t, err, personrepo.ErrSchemaDrift)
+
+	source, err := config.Migrations.SQLite()
+	require.NoError(t, err)
+	require.NoError(t, migrate.NewAdapter(source).Up(ctx, config.SQLite.ToConfig().MigrateURL()))
+	application, err := app.NewApplication(ctx, config)
+	require.NoError(t, err)
+	require.NoError(t, application.Stop(ctx))
+}
+
+func TestNewApplicationRefusesSchemaDrift(t *testing.T) {
+	ctx := context.Background()
+	config := &setup.Config{
+		Storage:    storage.Config{Driver: storage.DriverSQLite},
+		SQLite:     data.SQLiteConfig{Path: filepath.Join(t.TempDir(), "persons.db"), BusyTimeout: time.Second},
+		Migrations: migration.Config{Auto: true},
+	}
+	source, err := config.Migrations.SQLite()
+	require.NoError(t, err)
+	require.NoError(t, migrate.NewAdapter(source).Up(ctx, config.SQLite.ToConfig().MigrateURL()))
+
+	database, err := sqliteadapter.New(ctx, config.SQLite.ToConfig())
+	require.NoError(t, err)
+	_, err = database.DB().ExecContext(ctx, "ALTER TABLE persons DROP COLUMN name_phonetic")
+	require.NoError(t, err)
+	database.Close(ctx)
+
+	_, err = app.NewApplication(ctx, config)
+	require.ErrorIs(t, err, personrepo.ErrSchemaDrift)
+	assert.ErrorContains(t, err, "persons.name_phonetic")
+}
+
+func TestNewApplicationTenants(t *testing.T) {
+	ctx := context.Background()
+	writeTenants := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "tenants.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("refuses tenants on storage without isolation", func(t *testing.T) {
+		config := &setup.Config{
+			Storage: storage.Config{Driver: storage.DriverMemory},
+			Tenants: tenantsetup.Config{File: writeTenants(t, `{"tenants": [{"id": "acme"}, {"id": "globex"}]}`)},
+		}
+
+		application, err := app.NewApplication(ctx, config)
+		require.ErrorIs(t, err, app.ErrTenantsUnsupported)
+		assert.Nil(t, application)
+	})
+
+	t.Run("refuses invalid tenants file", func(t *testing.T) {
+		config := &setup.Config{
+			Storage: storage.Config{Driver: storage.DriverMemory},
+			Tenants: tenantsetup.Config{File: writeTenants(t, `{"tenants": [{"id": "Acme Corp"}]}`)},
+		}
+
+		_, err := app.NewApplication(ctx, config)
+		require.ErrorIs(t, err, tenantsetup.ErrInvalidTenants)
+	})
+
+	t.Run("accepts only the default tenant on any storage", func(t *testing.T) {
+		config := &setup.Config{
+			Storage:  storage.Config{Driver: storage.DriverMemory},
+			Graceful: graceful.Config{ShutdownTimeout: "1s"},
+			Tenants:  tenantsetup.Config{File: writeTenants(t, `{"tenants": [{"id": "default", "api_keys": ["secret"]}]}`)},
+		}
+
+		application, err := app.NewApplication(ctx, config)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"default"}, application.Tenants().IDs())
+		require.NoError(t, application.Stop(ctx))
+	})
+}
+
+func TestNewApplicationUnknownStorage(t *testing.T) {
+	config := &setup.Config{Storage: storage.Config{Driver: "mongo"}}
+
+	application, err := app.NewApplication(context.Background(), config)
+	require.ErrorIs(t, err, app.ErrUnknownStorageDriver)
+	assert.Nil(t, application)
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// phoneticBackfillBatchSize определяет число персон, обрабатываемых за один проход заполнения.
+const phoneticBackfillBatchSize = 500
+
+// BackfillPhoneticKeys вычисляет фонетические ключи для всех персон, сохраненных без них,
+// если хранилище поддерживает фонетический поиск. Возвращает: количество обновленных записей, ошибка.
+func BackfillPhoneticKeys(ctx context.Context, repository personrepo.Repository) (int, error) {
+	indexer, ok := repository.(personrepo.PhoneticIndexer)
+	if !ok {
+		return 0, nil
+	}
+
+	total := 0
+	for ctx.Err() == nil {
+		updated, err := indexer.BackfillPhoneticKeys(ctx, phoneticBackfillBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to backfill phonetic keys: %w", err)
+		}
+		total += updated
+		if updated < phoneticBackfillBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Info(ctx, "backfilled phonetic keys", zap.Int("count", total))
+	}
+	return total, nil
+}
@@ -0,0 +1,52 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPhoneticRepository struct {
+	mockPersonRepository
+}
+
+func (m *mockPhoneticRepository) BackfillPhoneticKeys(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(ctx, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+func TestBackfillPhoneticKeys(t *testing.T) {
+	t.Run("processes batches until a partial one", func(t *testing.T) {
+		mockRepo := new(mockPhoneticRepository)
+		mockRepo.On("BackfillPhoneticKeys", mock.Anything, 500).Return(500, nil).Once()
+		mockRepo.On("BackfillPhoneticKeys", mock.Anything, 500).Return(42, nil).Once()
+
+		total, err := app.BackfillPhoneticKeys(context.Background(), mockRepo)
+
+		require.NoError(t, err)
+		assert.Equal(t, 542, total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns repository error", func(t *testing.T) {
+		mockRepo := new(mockPhoneticRepository)
+		mockRepo.On("BackfillPhoneticKeys", mock.Anything, 500).Return(0, errors.New("db error"))
+
+		_, err := app.BackfillPhoneticKeys(context.Background(), mockRepo)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to backfill phonetic keys")
+	})
+
+	t.Run("skips repositories without phonetic keys", func(t *testing.T) {
+		total, err := app.BackfillPhoneticKeys(context.Background(), new(mockPersonRepository))
+
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/retention"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"go.uber.org/zap"
+)
+
+// RetentionJob периодически удаляет безвозвратно персоны, мягко удаленные дольше срока хранения.
+type RetentionJob struct {
+	repository personrepo.Repository
+	config     retention.Config
+	tenants    []string
+	now        func() time.Time
+}
+
+// NewRetentionJob создает задачу очистки мягко удаленных персон арендаторов tenants.
+// Без арендаторов очищаются персоны арендатора из контекста.
+func NewRetentionJob(repository personrepo.Repository, config retention.Config, tenants ...string) *RetentionJob {
+	return &RetentionJob{
+		repository: repository,
+		config:     config,
+		tenants:    tenants,
+		now:        time.Now,
+	}
+}
+
+// Run выполняет очистку сразу и затем с заданным интервалом до отмены контекста.
+// Если очистка отключена в конфигурации, метод сразу возвращает управление.
+func (j *RetentionJob) Run(ctx context.Context) {
+	if !j.config.Enabled() {
+		logger.Info(ctx, "soft-deleted persons purge is disabled")
+		return
+	}
+
+	logger.Info(ctx, "starting soft-deleted persons purge job",
+		zap.Int("purge_after_days", j.config.PurgeAfterDays),
+		zap.Duration("interval", j.config.Interval))
+
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.PurgeOnce(ctx); err != nil {
+			logger.Error(ctx, "failed to purge soft-deleted persons", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info(ctx, "soft-deleted persons purge job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce удаляет персоны, мягко удаленные раньше начала срока хранения, у каждого арендатора.
+// Возвращает: количество удаленных записей, ошибка.
+func (j *RetentionJob) PurgeOnce(ctx context.Context) (int64, error) {
+	if len(j.tenants) == 0 {
+		return j.purge(ctx)
+	}
+
+	var total int64
+	for _, id := range j.tenants {
+		purged, err := j.purge(tenant.WithID(ctx, id))
+		total += purged
+		if err != nil {
+			return total, fmt.Errorf("tenant %q: %w", id, err)
+		}
+	}
+	return total, nil
+}
+
+// purge удаляет мягко удаленные персоны арендатора из контекста.
+func (j *RetentionJob) purge(ctx context.Context) (int64, error) {
+	cutoff := j.now().UTC().Add(-j.config.RetentionPeriod())
+
+	purged, err := j.repository.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted persons: %w", err)
+	}
+
+	if purged > 0 {
+		id, _ := tenant.ID(ctx)
+		logger.Info(ctx, "purged soft-deleted persons",
+			zap.String("tenant", id),
+			zap.Int64("count", purged),
+			zap.Time("deleted_before", cutoff))
+	}
+	return purged, nil
+}
@@ -0,0 +1,108 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/app"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/retention"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionJobPurgeOnce(t *testing.T) {
+	t.Run("purges persons deleted before retention period", func(t *testing.T) {
+		mockRepo := new(mockPersonRepository)
+		job := app.NewRetentionJob(mockRepo, retention.Config{PurgeAfterDays: 30, Interval: time.Hour})
+
+		start := time.Now().UTC()
+		mockRepo.On("PurgeDeleted", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+			expected := start.Add(-30 * 24 * time.Hour)
+			return !cutoff.Before(expected) && cutoff.Sub(expected) < time.Minute
+		})).Return(int64(2), nil)
+
+		purged, err := job.PurgeOnce(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("purges persons of every tenant", func(t *testing.T) {
+		mockRepo := new(mockPersonRepository)
+		job := app.NewRetentionJob(mockRepo, retention.Config{PurgeAfterDays: 30, Interval: time.Hour}, "acme", "globex")
+
+		for id, count := range map[string]int64{"acme": 2, "globex": 3} {
+			mockRepo.On("PurgeDeleted", mock.MatchedBy(func(ctx context.Context) bool {
+				tenantID, _ := tenant.ID(ctx)
+				return tenantID == id
+			}), mock.Anything).Return(count, nil).Once()
+		}
+
+		purged, err := job.PurgeOnce(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), purged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns repository error", func(t *testing.T) {
+		mockRepo := new(mockPersonRepository)
+		job := app.NewRetentionJob(mockRepo, retention.Config{PurgeAfterDays: 1, Interval: time.Hour})
+
+		mockRepo.On("PurgeDeleted", mock.Anything, mock.Anything).Return(int64(0), errors.New("db error"))
+
+		_, err := job.PurgeOnce(context.Background())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to purge deleted persons")
+	})
+}
+
+func TestRetentionJobRun(t *testing.T) {
+	t.Run("does nothing when disabled", func(t *testing.T) {
+		mockRepo := new(mockPersonRepository)
+		job := app.NewRetentionJob(mockRepo, retention.Config{PurgeAfterDays: 0, Interval: time.Hour})
+
+		job.Run(context.Background())
+
+		mockRepo.AssertNotCalled(t, "PurgeDeleted", mock.Anything, mock.Anything)
+	})
+
+	t.Run("purges until context is canceled", func(t *testing.T) {
+		mockRepo := new(mockPersonRepository)
+		job := app.NewRetentionJob(mockRepo, retention.Config{PurgeAfterDays: 7, Interval: time.Millisecond})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		purged := make(chan struct{}, 1)
+		mockRepo.On("PurgeDeleted", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+			select {
+			case purged <- struct{}{}:
+			default:
+			}
+		}).Return(int64(0), nil)
+
+		done := make(chan struct{})
+		go func() {
+			job.Run(ctx)
+			close(done)
+		}()
+
+		select {
+		case <-purged:
+		case <-time.After(time.Second):
+			t.Fatal("purge was not executed")
+		}
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("retention job did not stop after context cancellation")
+		}
+	})
+}
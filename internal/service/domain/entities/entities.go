@@ -7,3 +7,42 @@ import (
 
 // Person представляет сущность человека в системе.
 type Person = person.Person
+
+// PersonDuplicatePair представляет пару вероятных дубликатов персоны.
+type PersonDuplicatePair = person.DuplicatePair
+
+// PersonDuplicateCluster представляет группу вероятных дубликатов персоны.
+type PersonDuplicateCluster = person.DuplicateCluster
+
+// PersonEnrichment представляет данные о персоне, полученные из внешних сервисов.
+type PersonEnrichment = person.Enrichment
+
+// PersonHistory представляет запись истории изменений персоны.
+type PersonHistory = person.History
+
+// PersonImport представляет результат импорта персон из файла.
+type PersonImport = person.Import
+
+// PersonImportError представляет ошибку обработки строки импортируемого файла.
+type PersonImportError = person.ImportError
+
+// PersonStats представляет агрегированные показатели по персонам.
+type PersonStats = person.Stats
+
+// PersonStatsValueCount представляет количество персон с одним значением колонки.
+type PersonStatsValueCount = person.ValueCount
+
+// PersonStatsAgeBucket представляет интервал гистограммы возраста.
+type PersonStatsAgeBucket = person.AgeBucket
+
+// PersonStatsFieldCoverage представляет заполненность колонки персоны.
+type PersonStatsFieldCoverage = person.FieldCoverage
+
+// PersonSearchResult представляет результат нечеткого поиска персон.
+type PersonSearchResult = person.SearchResult
+
+// Ошибки проверки персоны.
+var (
+	ErrInvalidPerson       = person.ErrInvalid
+	ErrNameSurnameRequired = person.ErrNameSurnameRequired
+)
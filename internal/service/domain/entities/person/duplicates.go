@@ -0,0 +1,18 @@
+package person
+
+import "github.com/google/uuid"
+
+// DuplicatePair представляет пару персон, которые, вероятно, описывают одного человека.
+type DuplicatePair struct {
+	PersonID    uuid.UUID `json:"person_id"`
+	DuplicateID uuid.UUID `json:"duplicate_id"`
+	Score       float64   `json:"score"`
+}
+
+// DuplicateCluster представляет группу вероятных дубликатов, связанных парами сходства.
+// Score равен наибольшей оценке сходства среди пар группы.
+type DuplicateCluster struct {
+	Score   float64         `json:"score"`
+	Persons []Person        `json:"persons"`
+	Pairs   []DuplicatePair `json:"pairs"`
+}
@@ -0,0 +1,32 @@
+package person
+
+// Enrichment содержит данные о персоне, полученные из внешних сервисов. Незаполненные поля не были получены.
+type Enrichment struct {
+	Age                    *int
+	Gender                 *string
+	GenderProbability      *float64
+	Nationality            *string
+	NationalityProbability *float64
+}
+
+// ApplyTo заполняет полученными данными колонки персоны, которые остаются пустыми;
+// значения, появившиеся у персоны после запроса к внешним сервисам, не перезаписываются.
+// Возвращает: признак изменения персоны.
+func (e Enrichment) ApplyTo(person *Person) bool {
+	changed := false
+	if person.Age == nil && e.Age != nil {
+		person.Age = e.Age
+		changed = true
+	}
+	if person.Gender == nil && e.Gender != nil {
+		person.Gender = e.Gender
+		person.GenderProbability = e.GenderProbability
+		changed = true
+	}
+	if person.Nationality == nil && e.Nationality != nil {
+		person.Nationality = e.Nationality
+		person.NationalityProbability = e.NationalityProbability
+		changed = true
+	}
+	return changed
+}
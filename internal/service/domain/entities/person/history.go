@@ -0,0 +1,23 @@
+package person
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// History представляет запись истории изменений персоны.
+type History struct {
+	ID            int64     `db:"id" json:"id"`
+	PersonID      uuid.UUID `db:"person_id" json:"person_id"`
+	Version       int       `db:"version" json:"version"`
+	Operation     string    `db:"operation" json:"operation"`
+	Before        *Person   `db:"before" json:"before,omitempty"`
+	After         *Person   `db:"after" json:"after,omitempty"`
+	ChangedFields []string  `db:"changed_fields" json:"changed_fields"`
+	// RelatedIDs содержит другие персоны, участвовавшие в операции (например, при объединении).
+	RelatedIDs []uuid.UUID `db:"related_ids" json:"related_ids,omitempty"`
+	Actor      string      `db:"actor" json:"actor,omitempty"`
+	RequestID  string      `db:"request_id" json:"request_id,omitempty"`
+	CreatedAt  time.Time   `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package person
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Import описывает результат импорта персон из файла.
+type Import struct {
+	ID       uuid.UUID `db:"id" json:"id"`
+	Format   string    `db:"format" json:"format"`
+	FileName string    `db:"file_name" json:"file_name,omitempty"`
+	DryRun   bool      `db:"dry_run" json:"dry_run"`
+	Enrich   bool      `db:"enrich" json:"enrich"`
+	// Total - количество строк данных в файле, Valid - прошедших проверку, Imported - сохраненных.
+	Total     int           `db:"total" json:"total"`
+	Valid     int           `db:"valid" json:"valid"`
+	Imported  int           `db:"imported" json:"imported"`
+	Failed    int           `db:"failed" json:"failed"`
+	Errors    []ImportError `db:"errors" json:"-"`
+	Actor     string        `db:"actor" json:"actor,omitempty"`
+	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+}
+
+// ImportError описывает ошибку обработки одной строки импортируемого файла.
+type ImportError struct {
+	// Row - номер строки файла, начиная с 1 (для CSV строка 1 - заголовок).
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"error"`
+}
@@ -0,0 +1,7 @@
+package person
+
+// SearchResult представляет персону, найденную нечетким поиском, и оценку сходства с запросом.
+type SearchResult struct {
+	Person
+	Score float64 `json:"score"`
+}
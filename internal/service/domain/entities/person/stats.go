@@ -0,0 +1,40 @@
+package person
+
+// Stats содержит агрегированные показатели по персонам, отобранным фильтром списка.
+type Stats struct {
+	Total int `json:"total"`
+	// Gender содержит количество персон по полу по убыванию количества.
+	Gender []ValueCount `json:"gender"`
+	// Nationality содержит самые частые национальности по убыванию количества.
+	Nationality []ValueCount `json:"nationality"`
+	// NationalityOther - количество персон с национальностью, не вошедшей в Nationality.
+	NationalityOther int `json:"nationality_other"`
+	// AgeBucketWidth - ширина интервала гистограммы возраста в годах.
+	AgeBucketWidth int `json:"age_bucket_width"`
+	// Age содержит непустые интервалы гистограммы возраста по возрастанию.
+	Age []AgeBucket `json:"age"`
+	// Coverage показывает, у скольких персон заполнена каждая колонка, заполняемая обогащением.
+	Coverage                      []FieldCoverage `json:"coverage"`
+	AverageGenderProbability      *float64        `json:"average_gender_probability"`
+	AverageNationalityProbability *float64        `json:"average_nationality_probability"`
+}
+
+// ValueCount представляет количество персон с одним значением колонки.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// AgeBucket представляет интервал гистограммы возраста [From, To] включительно.
+type AgeBucket struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+// FieldCoverage представляет заполненность колонки: количество и долю персон с непустым значением.
+type FieldCoverage struct {
+	Field  string  `json:"field"`
+	Filled int     `json:"filled"`
+	Ratio  float64 `json:"ratio"`
+}
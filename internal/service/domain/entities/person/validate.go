@@ -0,0 +1,59 @@
+package person
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ограничения длины полей персоны, соответствующие колонкам хранилища.
+const (
+	MaxNameLength        = 100
+	MaxGenderLength      = 10
+	MaxNationalityLength = 2
+)
+
+// Ошибки проверки персоны.
+var (
+	ErrInvalid             = errors.New("invalid person")
+	ErrNameSurnameRequired = errors.New("name and surname are required")
+)
+
+// Validate проверяет, что значения полей персоны помещаются в колонки хранилища.
+func (p *Person) Validate() error {
+	if strings.TrimSpace(p.Name) == "" || strings.TrimSpace(p.Surname) == "" {
+		return fmt.Errorf("%w: %w", ErrInvalid, ErrNameSurnameRequired)
+	}
+
+	lengths := []struct {
+		field string
+		value *string
+		limit int
+	}{
+		{"name", &p.Name, MaxNameLength},
+		{"surname", &p.Surname, MaxNameLength},
+		{"patronymic", p.Patronymic, MaxNameLength},
+		{"gender", p.Gender, MaxGenderLength},
+		{"nationality", p.Nationality, MaxNationalityLength},
+	}
+	for _, l := range lengths {
+		if l.value != nil && utf8.RuneCountInString(*l.value) > l.limit {
+			return fmt.Errorf("%w: %s must not exceed %d characters", ErrInvalid, l.field, l.limit)
+		}
+	}
+
+	if p.Age != nil && (*p.Age < 0 || *p.Age > math.MaxInt32) {
+		return fmt.Errorf("%w: age must be a non-negative integer", ErrInvalid)
+	}
+	for field, probability := range map[string]*float64{
+		"gender_probability":      p.GenderProbability,
+		"nationality_probability": p.NationalityProbability,
+	} {
+		if probability != nil && (*probability < 0 || *probability > 1) {
+			return fmt.Errorf("%w: %s must be a number between 0 and 1", ErrInvalid, field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package enrich запрашивает у внешних сервисов данные для обогащения персон.
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Fetch запрашивает у поставщиков значения, которых нет у персоны. Обогащение расходует одну
+// единицу квоты арендатора независимо от числа опрошенных поставщиков. Поставщики, отключенные
+// для арендатора, пропускаются, а сбои остальных записываются в журнал и не прерывают обогащение.
+// Возвращает: полученные данные, ошибку api.ErrTenantQuotaReached, если квота обогащений исчерпана.
+func Fetch(ctx context.Context, services api.API, person *entities.Person) (entities.PersonEnrichment, error) {
+	var enrichment entities.PersonEnrichment
+	if person.Age != nil && person.Gender != nil && person.Nationality != nil {
+		return enrichment, nil
+	}
+
+	if err := services.ReserveEnrichment(ctx); err != nil {
+		return enrichment, fmt.Errorf("failed to reserve enrichment: %w", err)
+	}
+
+	if person.Age == nil {
+		age, probability, err := services.People().Age().GetAgeByName(ctx, person.Name)
+		if err != nil {
+			skip(ctx, "age", err)
+		} else {
+			enrichment.Age = &age
+			logger.Debug(ctx, "enriched with age data",
+				zap.Int("age", age),
+				zap.Float64("probability", probability))
+		}
+	}
+
+	if person.Gender == nil {
+		gender, probability, err := services.People().Gender().GetGenderByName(ctx, person.Name)
+		if err != nil {
+			skip(ctx, "gender", err)
+		} else {
+			enrichment.Gender = &gender
+			enrichment.GenderProbability = &probability
+			logger.Debug(ctx, "enriched with gender data",
+				zap.String("gender", gender),
+				zap.Float64("probability", probability))
+		}
+	}
+
+	if person.Nationality == nil {
+		nationality, probability, err := services.People().Nationality().GetNationalityByName(ctx, person.Name)
+		if err != nil {
+			skip(ctx, "nationality", err)
+		} else {
+			enrichment.Nationality = &nationality
+			enrichment.NationalityProbability = &probability
+			logger.Debug(ctx, "enriched with nationality data",
+				zap.String("nationality", nationality),
+				zap.Float64("probability", probability))
+		}
+	}
+
+	return enrichment, nil
+}
+
+// skip записывает в журнал ошибку поставщика field, без данных которого продолжается обогащение.
+func skip(ctx context.Context, field string, err error) {
+	if errors.Is(err, api.ErrProviderDisabled) {
+		logger.Debug(ctx, "enrichment provider is disabled", zap.String("field", field))
+		return
+	}
+	logger.Warn(ctx, "failed to get "+field+" data", zap.Error(err))
+}
@@ -0,0 +1,109 @@
+package enrich_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/enrich"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/age"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/gender"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/nationality"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockServices struct {
+	people.Services
+	mock.Mock
+}
+
+func (m *mockServices) People() people.Services { return m }
+
+func (m *mockServices) ReserveEnrichment(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *mockServices) Age() age.Service { return m }
+
+func (m *mockServices) Gender() gender.Service { return m }
+
+func (m *mockServices) Nationality() nationality.Service { return m }
+
+func (m *mockServices) GetAgeByName(ctx context.Context, name string) (int, float64, error) {
+	args := m.Called(ctx, name)
+	return args.Int(0), args.Get(1).(float64), args.Error(2)
+}
+
+func (m *mockServices) GetGenderByName(ctx context.Context, name string) (string, float64, error) {
+	args := m.Called(ctx, name)
+	return args.String(0), args.Get(1).(float64), args.Error(2)
+}
+
+func (m *mockServices) GetNationalityByName(ctx context.Context, name string) (string, float64, error) {
+	args := m.Called(ctx, name)
+	return args.String(0), args.Get(1).(float64), args.Error(2)
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("requests only missing values", func(t *testing.T) {
+		services := new(mockServices)
+		age := 30
+		person := &entities.Person{Name: "Ivan", Age: &age}
+		services.On("ReserveEnrichment", ctx).Return(nil).Once()
+		services.On("GetGenderByName", ctx, "Ivan").Return("male", 0.9, nil)
+		services.On("GetNationalityByName", ctx, "Ivan").Return("RU", 0.8, nil)
+
+		enrichment, err := enrich.Fetch(ctx, services, person)
+		require.NoError(t, err)
+		assert.Nil(t, enrichment.Age)
+		assert.Equal(t, "male", *enrichment.Gender)
+		assert.Equal(t, "RU", *enrichment.Nationality)
+		services.AssertNotCalled(t, "GetAgeByName", mock.Anything, mock.Anything)
+	})
+
+	t.Run("skips disabled and failing providers", func(t *testing.T) {
+		services := new(mockServices)
+		person := &entities.Person{Name: "Ivan"}
+		services.On("ReserveEnrichment", ctx).Return(nil).Once()
+		services.On("GetAgeByName", ctx, "Ivan").Return(0, 0.0, fmt.Errorf("%w: age", api.ErrProviderDisabled))
+		services.On("GetGenderByName", ctx, "Ivan").Return("", 0.0, errors.New("timeout"))
+		services.On("GetNationalityByName", ctx, "Ivan").Return("RU", 0.8, nil)
+
+		enrichment, err := enrich.Fetch(ctx, services, person)
+		require.NoError(t, err)
+		assert.Nil(t, enrichment.Age)
+		assert.Nil(t, enrichment.Gender)
+		assert.Equal(t, "RU", *enrichment.Nationality)
+	})
+
+	t.Run("does not reserve quota for enriched person", func(t *testing.T) {
+		services := new(mockServices)
+		age, gender, nationality := 30, "male", "RU"
+		person := &entities.Person{Name: "Ivan", Age: &age, Gender: &gender, Nationality: &nationality}
+
+		enrichment, err := enrich.Fetch(ctx, services, person)
+		require.NoError(t, err)
+		assert.Equal(t, entities.PersonEnrichment{}, enrichment)
+		services.AssertNotCalled(t, "ReserveEnrichment", mock.Anything)
+	})
+
+	t.Run("does not query providers when tenant quota is exceeded", func(t *testing.T) {
+		services := new(mockServices)
+		person := &entities.Person{Name: "Ivan"}
+		services.On("ReserveEnrichment", ctx).Return(&api.QuotaError{Tenant: "acme"})
+
+		enrichment, err := enrich.Fetch(ctx, services, person)
+		require.ErrorIs(t, err, api.ErrTenantQuotaReached)
+		assert.Equal(t, entities.PersonEnrichment{}, enrichment)
+		services.AssertNotCalled(t, "GetAgeByName", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,133 @@
+// Package exports содержит потоковую выгрузку персон в файлы CSV, NDJSON и Parquet.
+package exports
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Форматы выгрузки.
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// Ошибки выгрузки персон.
+var (
+	ErrUnsupportedFormat = errors.New("unsupported export format")
+	ErrUnknownColumn     = errors.New("unknown export column")
+)
+
+// Options описывает параметры выгрузки.
+type Options struct {
+	// Format - формат файла: FormatCSV, FormatNDJSON или FormatParquet.
+	Format string
+	// Columns - выгружаемые колонки в порядке вывода; пустой список означает DefaultColumns.
+	Columns []string
+	// Filter - фильтр в формате GetPersons репозитория персон.
+	Filter map[string]any
+	// Gzip сжимает CSV и NDJSON целиком, а страницы Parquet - кодеком GZIP,
+	// чтобы файл оставался читаемым инструментами Parquet.
+	Gzip bool
+}
+
+// ParseColumns разбирает список колонок через запятую. Пустая строка означает DefaultColumns.
+func ParseColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return DefaultColumns, nil
+	}
+
+	var columns []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(Columns, name) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+		}
+		if slices.Contains(columns, name) {
+			continue
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
+
+// ContentType возвращает тип содержимого выгрузки.
+func ContentType(opts Options) string {
+	switch {
+	case opts.Format == FormatParquet:
+		return "application/vnd.apache.parquet"
+	case opts.Gzip:
+		return "application/gzip"
+	case opts.Format == FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// FileName возвращает имя файла выгрузки с расширением формата.
+func FileName(opts Options) string {
+	name := "persons." + opts.Format
+	if opts.Gzip && opts.Format != FormatParquet {
+		name += ".gz"
+	}
+	return name
+}
+
+// Export читает персоны, отобранные фильтром, потоком из репозитория и записывает их в w
+// в выбранном формате. Возвращает: количество выгруженных персон, ошибка.
+func Export(ctx context.Context, repository personrepo.Repository, w io.Writer, opts Options) (int, error) {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+
+	output := w
+	var compressor *gzip.Writer
+	if opts.Gzip && opts.Format != FormatParquet {
+		compressor = gzip.NewWriter(w)
+		output = compressor
+	}
+
+	writer, err := newWriter(output, opts.Format, columns, opts.Gzip)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = repository.StreamPersons(ctx, opts.Filter, func(person *entities.Person) error {
+		if err := writer.Write(person); err != nil {
+			return fmt.Errorf("failed to write person: %w", err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to export persons: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return count, fmt.Errorf("failed to finish export: %w", err)
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return count, fmt.Errorf("failed to finish compression: %w", err)
+		}
+	}
+
+	logger.Debug(ctx, "persons exported",
+		zap.String("format", opts.Format),
+		zap.Int("count", count))
+	return count, nil
+}
@@ -0,0 +1,168 @@
+package exports_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/exports"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamRepository отдает заранее заданные персоны через StreamPersons.
+type streamRepository struct {
+	personrepo.Repository
+	persons []*entities.Person
+	filter  map[string]any
+	err     error
+}
+
+func (r *streamRepository) StreamPersons(_ context.Context, filter map[string]any, fn func(*entities.Person) error) error {
+	r.filter = filter
+	for _, person := range r.persons {
+		if err := fn(person); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+func testPersons() []*entities.Person {
+	age := 35
+	gender := "male"
+	probability := 0.98
+	createdAt := time.Date(2025, 5, 1, 12, 30, 0, 0, time.UTC)
+	return []*entities.Person{
+		{
+			ID:                uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+			Name:              "Ivan",
+			Surname:           "Ivanov, Jr.",
+			Age:               &age,
+			Gender:            &gender,
+			GenderProbability: &probability,
+			CreatedAt:         createdAt,
+			UpdatedAt:         createdAt,
+			Version:           2,
+		},
+		{
+			ID:        uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"),
+			Name:      "Anna",
+			Surname:   "Petrova",
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+			Version:   1,
+		},
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Run("writes selected columns as CSV", func(t *testing.T) {
+		repository := &streamRepository{persons: testPersons()}
+		filter := map[string]any{"nationality": "RU"}
+		var output bytes.Buffer
+
+		count, err := exports.Export(context.Background(), repository, &output, exports.Options{
+			Format:  exports.FormatCSV,
+			Columns: []string{"surname", "age", "gender_probability", "created_at"},
+			Filter:  filter,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, filter, repository.filter)
+		assert.Equal(t, "surname,age,gender_probability,created_at\n"+
+			"\"Ivanov, Jr.\",35,0.98,2025-05-01T12:30:00Z\n"+
+			"Petrova,,,2025-05-01T12:30:00Z\n", output.String())
+	})
+
+	t.Run("writes gzip-compressed NDJSON", func(t *testing.T) {
+		repository := &streamRepository{persons: testPersons()}
+		var output bytes.Buffer
+
+		_, err := exports.Export(context.Background(), repository, &output, exports.Options{
+			Format:  exports.FormatNDJSON,
+			Columns: []string{"id", "name", "age"},
+			Gzip:    true,
+		})
+		require.NoError(t, err)
+
+		reader, err := gzip.NewReader(&output)
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"550e8400-e29b-41d4-a716-446655440000","name":"Ivan","age":35}`+"\n"+
+			`{"id":"550e8400-e29b-41d4-a716-446655440001","name":"Anna","age":null}`+"\n", string(content))
+	})
+
+	t.Run("writes Parquet readable by parquet readers", func(t *testing.T) {
+		repository := &streamRepository{persons: testPersons()}
+		var output bytes.Buffer
+
+		count, err := exports.Export(context.Background(), repository, &output, exports.Options{
+			Format:  exports.FormatParquet,
+			Columns: []string{"surname", "age", "id", "created_at"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		type row struct {
+			ID        string    `parquet:"id"`
+			Surname   string    `parquet:"surname"`
+			Age       *int64    `parquet:"age,optional"`
+			CreatedAt time.Time `parquet:"created_at,timestamp(microsecond)"`
+		}
+		rows, err := parquet.Read[row](bytes.NewReader(output.Bytes()), int64(output.Len()))
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", rows[0].ID)
+		assert.Equal(t, "Ivanov, Jr.", rows[0].Surname)
+		require.NotNil(t, rows[0].Age)
+		assert.Equal(t, int64(35), *rows[0].Age)
+		assert.True(t, rows[0].CreatedAt.Equal(time.Date(2025, 5, 1, 12, 30, 0, 0, time.UTC)))
+		assert.Nil(t, rows[1].Age)
+	})
+
+	t.Run("returns repository error", func(t *testing.T) {
+		repository := &streamRepository{err: errors.New("db error")}
+
+		_, err := exports.Export(context.Background(), repository, io.Discard, exports.Options{Format: exports.FormatCSV})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to export persons")
+	})
+
+	t.Run("rejects unknown format", func(t *testing.T) {
+		_, err := exports.Export(context.Background(), &streamRepository{}, io.Discard, exports.Options{Format: "xlsx"})
+
+		require.ErrorIs(t, err, exports.ErrUnsupportedFormat)
+	})
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := exports.ParseColumns("")
+	require.NoError(t, err)
+	assert.Equal(t, exports.DefaultColumns, columns)
+	assert.NotContains(t, columns, "deleted_at")
+
+	columns, err = exports.ParseColumns(" name, surname ,name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "surname"}, columns)
+
+	_, err = exports.ParseColumns("name,phonetic")
+	require.ErrorIs(t, err, exports.ErrUnknownColumn)
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "persons.csv.gz", exports.FileName(exports.Options{Format: exports.FormatCSV, Gzip: true}))
+	assert.Equal(t, "persons.parquet", exports.FileName(exports.Options{Format: exports.FormatParquet, Gzip: true}))
+	assert.Equal(t, "application/gzip", exports.ContentType(exports.Options{Format: exports.FormatNDJSON, Gzip: true}))
+}
@@ -0,0 +1,283 @@
+package exports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRowGroupSize ограничивает число строк в группе Parquet, которую писатель держит в памяти.
+const parquetRowGroupSize = 10000
+
+// column описывает выгружаемую колонку: тип в схеме Parquet и значение поля персоны.
+type column struct {
+	name     string
+	node     parquet.Node
+	nullable bool
+	value    func(person *entities.Person) any
+}
+
+// columns перечисляет все выгружаемые колонки в порядке по умолчанию.
+var columns = []column{
+	{"id", parquet.String(), false, func(p *entities.Person) any { return p.ID }},
+	{"name", parquet.String(), false, func(p *entities.Person) any { return p.Name }},
+	{"surname", parquet.String(), false, func(p *entities.Person) any { return p.Surname }},
+	{"patronymic", parquet.String(), true, func(p *entities.Person) any { return deref(p.Patronymic) }},
+	{"age", parquet.Int(64), true, func(p *entities.Person) any { return deref(p.Age) }},
+	{"gender", parquet.String(), true, func(p *entities.Person) any { return deref(p.Gender) }},
+	{"gender_probability", parquet.Leaf(parquet.DoubleType), true, func(p *entities.Person) any { return deref(p.GenderProbability) }},
+	{"nationality", parquet.String(), true, func(p *entities.Person) any { return deref(p.Nationality) }},
+	{"nationality_probability", parquet.Leaf(parquet.DoubleType), true, func(p *entities.Person) any { return deref(p.NationalityProbability) }},
+	{"created_at", parquet.Timestamp(parquet.Microsecond), false, func(p *entities.Person) any { return p.CreatedAt }},
+	{"updated_at", parquet.Timestamp(parquet.Microsecond), false, func(p *entities.Person) any { return p.UpdatedAt }},
+	{"version", parquet.Int(64), false, func(p *entities.Person) any { return p.Version }},
+	{"deleted_at", parquet.Timestamp(parquet.Microsecond), true, func(p *entities.Person) any { return deref(p.DeletedAt) }},
+}
+
+// Columns перечисляет имена всех выгружаемых колонок.
+var Columns = func() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}()
+
+// DefaultColumns перечисляет колонки, выгружаемые по умолчанию: все, кроме deleted_at.
+var DefaultColumns = slices.DeleteFunc(slices.Clone(Columns), func(name string) bool { return name == "deleted_at" })
+
+// rowWriter записывает персоны в файл выгрузки.
+type rowWriter interface {
+	// Write записывает одну персону.
+	Write(person *entities.Person) error
+	// Close дописывает буферизованные данные; исходный поток не закрывается.
+	Close() error
+}
+
+// newWriter создает писатель выгрузки для формата и набора колонок.
+func newWriter(w io.Writer, format string, names []string, gzip bool) (rowWriter, error) {
+	selected := make([]column, 0, len(names))
+	for _, name := range names {
+		index := slices.Index(Columns, name)
+		if index < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+		}
+		selected = append(selected, columns[index])
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, selected)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: w, columns: selected}, nil
+	case FormatParquet:
+		return newParquetWriter(w, selected, gzip), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// csvWriter записывает персоны в CSV с заголовком из имен колонок.
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []column
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, selected []column) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	header := make([]string, len(selected))
+	for i, c := range selected {
+		header[i] = c.name
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	return &csvWriter{writer: writer, columns: selected, record: make([]string, len(selected))}, nil
+}
+
+// Write записывает персону строкой CSV; отсутствующие значения записываются пустыми.
+func (w *csvWriter) Write(person *entities.Person) error {
+	for i, c := range w.columns {
+		w.record[i] = formatValue(c.value(person))
+	}
+	if err := w.writer.Write(w.record); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	return nil
+}
+
+// Close дописывает буфер CSV.
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV: %w", err)
+	}
+	return nil
+}
+
+// ndjsonWriter записывает каждую персону JSON-объектом на отдельной строке с колонками в заданном порядке.
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []column
+	line    []byte
+}
+
+// Write записывает персону строкой NDJSON; отсутствующие значения записываются как null.
+func (w *ndjsonWriter) Write(person *entities.Person) error {
+	w.line = append(w.line[:0], '{')
+	for i, c := range w.columns {
+		if i > 0 {
+			w.line = append(w.line, ',')
+		}
+		w.line = strconv.AppendQuote(w.line, c.name)
+		w.line = append(w.line, ':')
+
+		value, err := json.Marshal(c.value(person))
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", c.name, err)
+		}
+		w.line = append(w.line, value...)
+	}
+	w.line = append(w.line, '}', '\n')
+
+	if _, err := w.w.Write(w.line); err != nil {
+		return fmt.Errorf("failed to write NDJSON row: %w", err)
+	}
+	return nil
+}
+
+// Close ничего не делает: строки NDJSON записываются сразу.
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
+// parquetWriter записывает персоны в Parquet, сбрасывая группу строк каждые parquetRowGroupSize персон.
+type parquetWriter struct {
+	writer  *parquet.Writer
+	columns []column
+	// leaves содержит номер листовой колонки схемы для каждой выбранной колонки.
+	leaves  []int
+	row     parquet.Row
+	pending int
+}
+
+func newParquetWriter(w io.Writer, selected []column, gzip bool) *parquetWriter {
+	group := make(parquet.Group, len(selected))
+	for _, c := range selected {
+		node := c.node
+		if c.nullable {
+			node = parquet.Optional(node)
+		}
+		group[c.name] = node
+	}
+	schema := parquet.NewSchema("person", group)
+
+	// Поля группы упорядочены в схеме по имени, поэтому номера листовых колонок
+	// берутся из схемы, а не из порядка выбора.
+	leaves := make([]int, len(selected))
+	for i, c := range selected {
+		leaves[i] = slices.IndexFunc(schema.Columns(), func(path []string) bool {
+			return len(path) == 1 && path[0] == c.name
+		})
+	}
+
+	codec := parquet.Compression(&parquet.Snappy)
+	if gzip {
+		codec = parquet.Compression(&parquet.Gzip)
+	}
+
+	return &parquetWriter{
+		writer:  parquet.NewWriter(w, schema, codec),
+		columns: selected,
+		leaves:  leaves,
+		row:     make(parquet.Row, len(selected)),
+	}
+}
+
+// Write добавляет персону в текущую группу строк Parquet.
+func (w *parquetWriter) Write(person *entities.Person) error {
+	for i, c := range w.columns {
+		value := parquetValue(c.value(person))
+		definitionLevel := 0
+		if c.nullable && !value.IsNull() {
+			definitionLevel = 1
+		}
+		w.row[w.leaves[i]] = value.Level(0, definitionLevel, w.leaves[i])
+	}
+
+	if _, err := w.writer.WriteRows([]parquet.Row{w.row}); err != nil {
+		return fmt.Errorf("failed to write Parquet row: %w", err)
+	}
+
+	w.pending++
+	if w.pending == parquetRowGroupSize {
+		w.pending = 0
+		if err := w.writer.Flush(); err != nil {
+			return fmt.Errorf("failed to flush Parquet row group: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close дописывает последнюю группу строк и метаданные файла Parquet.
+func (w *parquetWriter) Close() error {
+	if err := w.writer.Close(); err != nil {
+		return fmt.Errorf("failed to close Parquet writer: %w", err)
+	}
+	return nil
+}
+
+// deref возвращает значение по указателю или nil для пустого указателя.
+func deref[T any](value *T) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// formatValue форматирует значение колонки для CSV.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case uuid.UUID:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parquetValue преобразует значение колонки в значение Parquet.
+func parquetValue(value any) parquet.Value {
+	switch v := value.(type) {
+	case string:
+		return parquet.ByteArrayValue([]byte(v))
+	case uuid.UUID:
+		return parquet.ByteArrayValue([]byte(v.String()))
+	case int:
+		return parquet.Int64Value(int64(v))
+	case float64:
+		return parquet.DoubleValue(v)
+	case time.Time:
+		return parquet.Int64Value(v.UnixMicro())
+	default:
+		return parquet.Value{}
+	}
+}
@@ -0,0 +1,298 @@
+// Package imports содержит сервис импорта персон из файлов CSV и NDJSON.
+package imports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/enrich"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Форматы импортируемых файлов.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Ограничения импорта.
+const (
+	// MaxRecords ограничивает количество строк данных в одном файле.
+	MaxRecords = 100000
+	// chunkSize определяет число персон, сохраняемых в одной транзакции.
+	chunkSize = 1000
+)
+
+// Ошибки импорта персон.
+var (
+	ErrInvalidOptions = errors.New("invalid import options")
+	ErrMalformedFile  = errors.New("malformed import file")
+	ErrMalformedRow   = errors.New("malformed row")
+	ErrTooManyRecords = errors.New("too many records")
+)
+
+// Options описывает параметры импорта.
+type Options struct {
+	// Format - формат файла: FormatCSV или FormatNDJSON.
+	Format string
+	// FileName - исходное имя файла, сохраняемое в результате импорта.
+	FileName string
+	// Mapping сопоставляет колонку CSV (или ключ NDJSON) полю персоны. Колонки без сопоставления
+	// переносятся в одноименное поле персоны, если такое поле существует, остальные игнорируются.
+	Mapping map[string]string
+	// Delimiter - разделитель полей CSV, по умолчанию запятая.
+	Delimiter rune
+	// DryRun включает режим проверки без сохранения персон.
+	DryRun bool
+	// Enrich запускает обогащение импортированных персон в фоне.
+	Enrich bool
+}
+
+// Service импортирует персон из файлов и сохраняет построчный отчет об ошибках.
+type Service struct {
+	api          api.API
+	repositories repo.Repositories
+	enrichments  sync.WaitGroup
+}
+
+// NewService создает новый сервис импорта персон.
+func NewService(api api.API, repositories repo.Repositories) *Service {
+	return &Service{
+		api:          api,
+		repositories: repositories,
+	}
+}
+
+// DetectFormat определяет формат файла по расширению имени или типу содержимого.
+// Возвращает пустую строку, если формат определить не удалось.
+func DetectFormat(fileName, contentType string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return FormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"), strings.HasPrefix(contentType, "application/ndjson"):
+		return FormatNDJSON
+	}
+	return ""
+}
+
+// Import разбирает файл, проверяет каждую строку и, если это не пробный запуск, сохраняет корректные
+// строки частями. Ошибка строки не отменяет импорт остальных строк и попадает в отчет.
+// Результат сохраняется, чтобы отчет об ошибках можно было получить позже.
+func (s *Service) Import(ctx context.Context, r io.Reader, opts Options) (*entities.PersonImport, error) {
+	if err := validateOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	logger.Info(ctx, "importing persons",
+		zap.String("format", opts.Format),
+		zap.String("file_name", opts.FileName),
+		zap.Bool("dry_run", opts.DryRun),
+		zap.Bool("enrich", opts.Enrich))
+
+	var records []record
+	var err error
+	if opts.Format == FormatCSV {
+		records, err = readCSV(r, opts)
+	} else {
+		records, err = readNDJSON(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.PersonImport{
+		ID:        uuid.New(),
+		Format:    opts.Format,
+		FileName:  opts.FileName,
+		DryRun:    opts.DryRun,
+		Enrich:    opts.Enrich && !opts.DryRun,
+		Total:     len(records),
+		Errors:    []entities.PersonImportError{},
+		Actor:     historyrepo.Actor(ctx),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	mapped := make(map[string]bool, len(opts.Mapping))
+	for _, field := range opts.Mapping {
+		mapped[field] = true
+	}
+
+	valid := make([]*entities.Person, 0, len(records))
+	rows := make([]int, 0, len(records))
+	seen := make(map[uuid.UUID]int, len(records))
+	for _, rec := range records {
+		if rec.err != nil {
+			result.Errors = append(result.Errors, *rec.err)
+			continue
+		}
+
+		person, rowErr := toPerson(rec, opts.Mapping, mapped)
+		if rowErr == nil && person.ID != uuid.Nil {
+			if first, ok := seen[person.ID]; ok {
+				rowErr = &entities.PersonImportError{
+					Row: rec.row, Field: "id", Message: fmt.Sprintf("duplicate id of row %d", first),
+				}
+			} else {
+				seen[person.ID] = rec.row
+			}
+		}
+		if rowErr != nil {
+			result.Errors = append(result.Errors, *rowErr)
+			continue
+		}
+
+		valid = append(valid, person)
+		rows = append(rows, rec.row)
+	}
+	result.Valid = len(valid)
+
+	var imported []*entities.Person
+	if !opts.DryRun {
+		imported = s.save(ctx, valid, rows, result)
+		result.Imported = len(imported)
+	}
+
+	slices.SortStableFunc(result.Errors, func(a, b entities.PersonImportError) int {
+		return a.Row - b.Row
+	})
+	result.Failed = len(result.Errors)
+
+	if err := s.repositories.People().Imports().CreateImport(ctx, result); err != nil {
+		return nil, fmt.Errorf("failed to save import result: %w", err)
+	}
+
+	logger.Info(ctx, "persons import finished",
+		zap.String("id", result.ID.String()),
+		zap.Int("total", result.Total),
+		zap.Int("imported", result.Imported),
+		zap.Int("failed", result.Failed))
+
+	if result.Enrich && len(imported) > 0 {
+		s.enrichments.Add(1)
+		go func() {
+			defer s.enrichments.Done()
+			s.enrich(context.WithoutCancel(ctx), result.ID, imported)
+		}()
+	}
+
+	return result, nil
+}
+
+// Wait ожидает завершения обогащения, запущенного предыдущими импортами.
+func (s *Service) Wait() {
+	s.enrichments.Wait()
+}
+
+// save сохраняет корректные строки частями и добавляет в результат ошибки частей, которые не удалось сохранить.
+// Возвращает: сохраненные персоны.
+func (s *Service) save(ctx context.Context, persons []*entities.Person, rows []int, result *entities.PersonImport) []*entities.Person {
+	importCtx := historyrepo.WithOperation(ctx, historyrepo.OperationImport)
+	repository := s.repositories.People().Person()
+
+	imported := make([]*entities.Person, 0, len(persons))
+	for start := 0; start < len(persons); start += chunkSize {
+		end := min(start+chunkSize, len(persons))
+		chunk := persons[start:end]
+
+		if err := repository.CreatePersons(importCtx, chunk); err != nil {
+			logger.Error(ctx, "failed to import persons chunk",
+				zap.Int("offset", start),
+				zap.Int("count", len(chunk)),
+				zap.Error(err))
+
+			message := "failed to save person"
+			if strings.Contains(err.Error(), "already exists") {
+				message = "person with the same ID already exists in this chunk"
+			}
+			for _, row := range rows[start:end] {
+				result.Errors = append(result.Errors, entities.PersonImportError{Row: row, Message: message})
+			}
+			continue
+		}
+		imported = append(imported, chunk...)
+	}
+	return imported
+}
+
+// enrich дополняет импортированных персон возрастом, полом и национальностью из внешних сервисов.
+// Ошибка обогащения одной персоны не прерывает обработку остальных, пока не исчерпана квота арендатора.
+func (s *Service) enrich(ctx context.Context, importID uuid.UUID, persons []*entities.Person) {
+	enrichCtx := historyrepo.WithOperation(ctx, historyrepo.OperationEnrich)
+	repository := s.repositories.People().Person()
+
+	enriched := 0
+	for _, person := range persons {
+		enrichment, err := enrich.Fetch(ctx, s.api, person)
+		if err != nil {
+			// Квота арендатора не восстановится до конца импорта, поэтому остальные персоны не обогащаются.
+			logger.Warn(ctx, "stopped enriching imported persons", zap.Error(err))
+			break
+		}
+		if !enrichment.ApplyTo(person) {
+			continue
+		}
+
+		// Персона могла измениться после импорта; в этом случае обогащение пропускается.
+		if err := repository.UpdatePerson(enrichCtx, person); err != nil {
+			if !errors.Is(err, personrepo.ErrVersionConflict) {
+				logger.Error(ctx, "failed to save enriched person data",
+					zap.String("id", person.ID.String()),
+					zap.Error(err))
+			}
+			continue
+		}
+		enriched++
+	}
+
+	logger.Info(ctx, "imported persons enriched",
+		zap.String("import_id", importID.String()),
+		zap.Int("enriched", enriched),
+		zap.Int("total", len(persons)))
+}
+
+// validateOptions проверяет параметры импорта и подставляет значения по умолчанию.
+func validateOptions(opts *Options) error {
+	if opts.Format != FormatCSV && opts.Format != FormatNDJSON {
+		return fmt.Errorf("%w: unsupported format %q", ErrInvalidOptions, opts.Format)
+	}
+
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	if opts.Delimiter == '"' || opts.Delimiter == '\r' || opts.Delimiter == '\n' {
+		return fmt.Errorf("%w: invalid delimiter %q", ErrInvalidOptions, opts.Delimiter)
+	}
+
+	targets := make(map[string]string, len(opts.Mapping))
+	for column, field := range opts.Mapping {
+		if !slices.Contains(Fields, field) {
+			return fmt.Errorf("%w: column %q is mapped to unknown field %q", ErrInvalidOptions, column, field)
+		}
+		if other, ok := targets[field]; ok {
+			return fmt.Errorf("%w: columns %q and %q are mapped to the same field %q", ErrInvalidOptions, other, column, field)
+		}
+		targets[field] = column
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package imports_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/imports"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/age"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/gender"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people/nationality"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	repopeople "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	importsrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPersonRepository реализует только методы, которые использует импорт.
+type mockPersonRepository struct {
+	personrepo.Repository
+	mock.Mock
+}
+
+func (m *mockPersonRepository) CreatePersons(ctx context.Context, persons []*entities.Person) error {
+	args := m.Called(ctx, persons)
+	return args.Error(0)
+}
+
+func (m *mockPersonRepository) UpdatePerson(ctx context.Context, person *entities.Person) error {
+	args := m.Called(ctx, person)
+	return args.Error(0)
+}
+
+type mockImportsRepository struct {
+	mock.Mock
+}
+
+func (m *mockImportsRepository) CreateImport(ctx context.Context, result *entities.PersonImport) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
+func (m *mockImportsRepository) GetImport(ctx context.Context, id uuid.UUID) (*entities.PersonImport, error) {
+	args := m.Called(ctx, id)
+	if result, ok := args.Get(0).(*entities.PersonImport); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockRepositories struct {
+	person  *mockPersonRepository
+	imports *mockImportsRepository
+}
+
+func (m *mockRepositories) People() repopeople.Repositories { return m }
+
+// WithTx выполняет fn без транзакции: моки не хранят данные.
+func (m *mockRepositories) WithTx(_ context.Context, fn func(tx repo.Repositories) error) error {
+	return fn(m)
+}
+
+func (m *mockRepositories) Person() personrepo.Repository { return m.person }
+
+func (m *mockRepositories) History() historyrepo.Repository { return nil }
+
+func (m *mockRepositories) Imports() importsrepo.Repository { return m.imports }
+
+type mockServices struct {
+	people.Services
+	mock.Mock
+}
+
+func (m *mockServices) People() people.Services { return m }
+
+func (m *mockServices) ReserveEnrichment(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *mockServices) Age() age.Service { return m }
+
+func (m *mockServices) Gender() gender.Service { return m }
+
+func (m *mockServices) Nationality() nationality.Service { return m }
+
+func (m *mockServices) GetAgeByName(ctx context.Context, name string) (int, float64, error) {
+	args := m.Called(ctx, name)
+	return args.Int(0), args.Get(1).(float64), args.Error(2)
+}
+
+func (m *mockServices) GetGenderByName(ctx context.Context, name string) (string, float64, error) {
+	args := m.Called(ctx, name)
+	return args.String(0), args.Get(1).(float64), args.Error(2)
+}
+
+func (m *mockServices) GetNationalityByName(ctx context.Context, name string) (string, float64, error) {
+	args := m.Called(ctx, name)
+	return args.String(0), args.Get(1).(float64), args.Error(2)
+}
+
+func newService() (*imports.Service, *mockRepositories, *mockServices) {
+	repositories := &mockRepositories{
+		person:  new(mockPersonRepository),
+		imports: new(mockImportsRepository),
+	}
+	services := new(mockServices)
+	return imports.NewService(services, repositories), repositories, services
+}
+
+func TestImport(t *testing.T) {
+	t.Run("dry run validates CSV rows with column mapping", func(t *testing.T) {
+		service, repositories, _ := newService()
+		repositories.imports.On("CreateImport", mock.Anything, mock.AnythingOfType("*person.Import")).Return(nil)
+
+		file := "\ufeffФамилия;Имя;age;comment\n" +
+			"Ivanov;Ivan;30;ok\n" +
+			"Petrov;;25;no name\n" +
+			"Sidorov;Petr;old;bad age\n"
+
+		result, err := service.Import(context.Background(), strings.NewReader(file), imports.Options{
+			Format:    imports.FormatCSV,
+			FileName:  "people.csv",
+			Mapping:   map[string]string{"Фамилия": "surname", "Имя": "name"},
+			Delimiter: ';',
+			DryRun:    true,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Total)
+		assert.Equal(t, 1, result.Valid)
+		assert.Zero(t, result.Imported)
+		assert.Equal(t, 2, result.Failed)
+		require.Len(t, result.Errors, 2)
+		assert.Equal(t, 3, result.Errors[0].Row)
+		assert.Contains(t, result.Errors[0].Message, "name and surname are required")
+		assert.Equal(t, entities.PersonImportError{Row: 4, Field: "age", Message: "must be an integer"}, result.Errors[1])
+		repositories.person.AssertNotCalled(t, "CreatePersons", mock.Anything, mock.Anything)
+		repositories.imports.AssertExpectations(t)
+	})
+
+	t.Run("imports valid NDJSON rows and reports the rest", func(t *testing.T) {
+		service, repositories, _ := newService()
+		duplicateID := uuid.New()
+		repositories.person.On("CreatePersons", mock.Anything, mock.MatchedBy(func(persons []*entities.Person) bool {
+			return len(persons) == 2 && persons[0].Surname == "Ivanov" && *persons[1].Age == 41
+		})).Return(nil)
+		repositories.imports.On("CreateImport", mock.Anything, mock.Anything).Return(nil)
+
+		file := `{"id":"` + duplicateID.String() + `","name":"Ivan","surname":"Ivanov","nationality":null}` + "\n" +
+			"\n" +
+			`{"name":"Anna","surname":"Petrova","age":41,"gender_probability":"0.9"}` + "\n" +
+			`{"name":"Oleg",` + "\n" +
+			`{"id":"` + duplicateID.String() + `","name":"Ivan","surname":"Ivanov"}` + "\n"
+
+		result, err := service.Import(context.Background(), strings.NewReader(file), imports.Options{
+			Format: imports.FormatNDJSON,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Total)
+		assert.Equal(t, 2, result.Imported)
+		require.Len(t, result.Errors, 2)
+		assert.Equal(t, 4, result.Errors[0].Row)
+		assert.Contains(t, result.Errors[0].Message, "invalid JSON object")
+		assert.Equal(t, entities.PersonImportError{Row: 5, Field: "id", Message: "duplicate id of row 1"}, result.Errors[1])
+		repositories.person.AssertExpectations(t)
+	})
+
+	t.Run("reports rows of a chunk that failed to save", func(t *testing.T) {
+		service, repositories, _ := newService()
+		repositories.person.On("CreatePersons", mock.Anything, mock.Anything).
+			Return(errors.New("person already exists"))
+		repositories.imports.On("CreateImport", mock.Anything, mock.Anything).Return(nil)
+
+		result, err := service.Import(context.Background(), strings.NewReader("name,surname\nIvan,Ivanov\n"), imports.Options{
+			Format: imports.FormatCSV,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Valid)
+		assert.Zero(t, result.Imported)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, 2, result.Errors[0].Row)
+		assert.Contains(t, result.Errors[0].Message, "already exists")
+	})
+
+	t.Run("enriches imported persons in the background", func(t *testing.T) {
+		service, repositories, services := newService()
+		repositories.person.On("CreatePersons", mock.Anything, mock.Anything).Return(nil)
+		repositories.imports.On("CreateImport", mock.Anything, mock.Anything).Return(nil)
+		services.On("ReserveEnrichment", mock.Anything).Return(nil)
+		services.On("GetAgeByName", mock.Anything, "Ivan").Return(42, 0.8, nil)
+		services.On("GetGenderByName", mock.Anything, "Ivan").Return("male", 0.99, nil)
+		services.On("GetNationalityByName", mock.Anything, "Ivan").Return("", 0.0, errors.New("api error"))
+		repositories.person.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(person *entities.Person) bool {
+			return *person.Age == 42 && *person.Gender == "male" && person.Nationality == nil
+		})).Return(nil)
+
+		result, err := service.Import(context.Background(), strings.NewReader("name,surname\nIvan,Ivanov\n"), imports.Options{
+			Format: imports.FormatCSV,
+			Enrich: true,
+		})
+		service.Wait()
+
+		require.NoError(t, err)
+		assert.True(t, result.Enrich)
+		repositories.person.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		service, _, _ := newService()
+
+		_, err := service.Import(context.Background(), strings.NewReader("name,surname\n"), imports.Options{Format: "xlsx"})
+		require.ErrorIs(t, err, imports.ErrInvalidOptions)
+
+		_, err = service.Import(context.Background(), strings.NewReader("name,surname\n"), imports.Options{
+			Format:  imports.FormatCSV,
+			Mapping: map[string]string{"name": "first_name"},
+		})
+		require.ErrorIs(t, err, imports.ErrInvalidOptions)
+
+		_, err = service.Import(context.Background(), strings.NewReader("name,surname\nIvan,Ivanov\n"), imports.Options{
+			Format:  imports.FormatCSV,
+			Mapping: map[string]string{"Фамилия": "surname"},
+		})
+		require.ErrorIs(t, err, imports.ErrInvalidOptions)
+	})
+
+	t.Run("rejects files without records", func(t *testing.T) {
+		service, _, _ := newService()
+
+		_, err := service.Import(context.Background(), strings.NewReader("name,surname\n"), imports.Options{Format: imports.FormatCSV})
+		require.ErrorIs(t, err, imports.ErrMalformedFile)
+
+		_, err = service.Import(context.Background(), strings.NewReader("\n\n"), imports.Options{Format: imports.FormatNDJSON})
+		require.ErrorIs(t, err, imports.ErrMalformedFile)
+	})
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, imports.FormatCSV, imports.DetectFormat("people.CSV", ""))
+	assert.Equal(t, imports.FormatNDJSON, imports.DetectFormat("people.jsonl", ""))
+	assert.Equal(t, imports.FormatNDJSON, imports.DetectFormat("upload", "application/x-ndjson"))
+	assert.Empty(t, imports.DetectFormat("people.xlsx", "application/octet-stream"))
+}
+
+func TestWriteReport(t *testing.T) {
+	var report bytes.Buffer
+	err := imports.WriteReport(&report, &entities.PersonImport{
+		Errors: []entities.PersonImportError{
+			{Row: 3, Field: "age", Message: "must be an integer"},
+			{Row: 7, Message: "invalid person: name and surname are required"},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "row,field,error\n3,age,must be an integer\n7,,invalid person: name and surname are required\n", report.String())
+}
@@ -0,0 +1,249 @@
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/google/uuid"
+)
+
+// Fields перечисляет поля персоны, в которые можно перенести колонки файла.
+var Fields = []string{
+	"id", "name", "surname", "patronymic", "age",
+	"gender", "gender_probability", "nationality", "nationality_probability",
+}
+
+// maxLineLength ограничивает длину одной строки NDJSON.
+const maxLineLength = 1024 * 1024
+
+// record представляет одну строку файла: значения по именам колонок или ошибку разбора строки.
+type record struct {
+	row    int
+	values map[string]string
+	err    *entities.PersonImportError
+}
+
+// ParseMapping разбирает сопоставление колонок из JSON-объекта вида {"Фамилия": "surname"}.
+// Пустая строка означает отсутствие сопоставления.
+func ParseMapping(value string) (map[string]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var mapping map[string]string
+	if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+		return nil, fmt.Errorf("%w: mapping must be a JSON object of column names to fields: %w", ErrInvalidOptions, err)
+	}
+	return mapping, nil
+}
+
+// readCSV читает CSV-файл с заголовком. Строки, которые не удалось разобрать, возвращаются с ошибкой.
+func readCSV(r io.Reader, opts Options) ([]record, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Delimiter
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: file is empty", ErrMalformedFile)
+		}
+		return nil, fmt.Errorf("%w: failed to read header: %w", ErrMalformedFile, err)
+	}
+	if len(header) > 0 {
+		// Табличные редакторы добавляют BOM в начало файла в кодировке UTF-8.
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	for column := range opts.Mapping {
+		if !slices.Contains(header, column) {
+			return nil, fmt.Errorf("%w: mapped column %q is not present in the header", ErrInvalidOptions, column)
+		}
+	}
+
+	var records []record
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(records) == MaxRecords {
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManyRecords, MaxRecords)
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("%w: %w", ErrMalformedFile, err)
+			}
+			records = append(records, record{
+				row: parseErr.StartLine,
+				err: &entities.PersonImportError{Row: parseErr.StartLine, Message: parseErr.Err.Error()},
+			})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string, len(header))
+		for i, name := range header {
+			values[name] = fields[i]
+		}
+		records = append(records, record{row: line, values: values})
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no records", ErrMalformedFile)
+	}
+	return records, nil
+}
+
+// readNDJSON читает файл, каждая непустая строка которого содержит JSON-объект.
+// Числа, строки и null переносятся как текст; строки с некорректным JSON возвращаются с ошибкой.
+func readNDJSON(r io.Reader) ([]record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+
+	var records []record
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if line == 1 {
+			data = bytes.TrimPrefix(data, []byte("\ufeff"))
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if len(records) == MaxRecords {
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManyRecords, MaxRecords)
+		}
+
+		values, err := decodeObject(data)
+		if err != nil {
+			records = append(records, record{
+				row: line,
+				err: &entities.PersonImportError{Row: line, Message: err.Error()},
+			})
+			continue
+		}
+		records = append(records, record{row: line, values: values})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedFile, err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no records", ErrMalformedFile)
+	}
+	return records, nil
+}
+
+// decodeObject разбирает JSON-объект строки NDJSON в текстовые значения по ключам.
+func decodeObject(data []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON object: %w", ErrMalformedRow, err)
+	}
+	if object == nil {
+		return nil, fmt.Errorf("%w: line must be a JSON object", ErrMalformedRow)
+	}
+
+	values := make(map[string]string, len(object))
+	for key, value := range object {
+		switch v := value.(type) {
+		case nil:
+			values[key] = ""
+		case string:
+			values[key] = v
+		case json.Number:
+			values[key] = v.String()
+		default:
+			return nil, fmt.Errorf("%w: value of %q must be a string or a number", ErrMalformedRow, key)
+		}
+	}
+	return values, nil
+}
+
+// fieldOf возвращает поле персоны для колонки: поле из явного сопоставления либо одноименное поле,
+// если в него не сопоставлена другая колонка. Пустая строка означает, что колонка игнорируется.
+func fieldOf(column string, mapping map[string]string, mapped map[string]bool) string {
+	if field, ok := mapping[column]; ok {
+		return field
+	}
+	field := strings.ToLower(strings.TrimSpace(column))
+	if mapped[field] || !slices.Contains(Fields, field) {
+		return ""
+	}
+	return field
+}
+
+// toPerson переносит значения строки в поля персоны и проверяет результат.
+// mapped содержит поля, в которые явно сопоставлены колонки.
+func toPerson(rec record, mapping map[string]string, mapped map[string]bool) (*entities.Person, *entities.PersonImportError) {
+	fieldError := func(field, message string) *entities.PersonImportError {
+		return &entities.PersonImportError{Row: rec.row, Field: field, Message: message}
+	}
+
+	var person entities.Person
+	for column, raw := range rec.values {
+		field := fieldOf(column, mapping, mapped)
+		value := strings.TrimSpace(raw)
+		if value == "" {
+			continue
+		}
+
+		switch field {
+		case "id":
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return nil, fieldError(field, "invalid UUID format")
+			}
+			person.ID = id
+		case "name":
+			person.Name = value
+		case "surname":
+			person.Surname = value
+		case "patronymic":
+			person.Patronymic = &value
+		case "gender":
+			person.Gender = &value
+		case "nationality":
+			person.Nationality = &value
+		case "age":
+			age, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fieldError(field, "must be an integer")
+			}
+			person.Age = &age
+		case "gender_probability", "nationality_probability":
+			probability, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fieldError(field, "must be a number")
+			}
+			if field == "gender_probability" {
+				person.GenderProbability = &probability
+			} else {
+				person.NationalityProbability = &probability
+			}
+		}
+	}
+
+	if err := person.Validate(); err != nil {
+		return nil, fieldError("", err.Error())
+	}
+	return &person, nil
+}
@@ -0,0 +1,29 @@
+package imports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+)
+
+// WriteReport записывает построчный отчет об ошибках импорта в формате CSV с колонками row, field, error.
+func WriteReport(w io.Writer, result *entities.PersonImport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"row", "field", "error"}); err != nil {
+		return fmt.Errorf("failed to write report header: %w", err)
+	}
+	for _, rowErr := range result.Errors {
+		if err := writer.Write([]string{strconv.Itoa(rowErr.Row), rowErr.Field, rowErr.Message}); err != nil {
+			return fmt.Errorf("failed to write report row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Package merge содержит поиск вероятных дубликатов персон и объединение дубликатов в одну персону.
+package merge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Ограничения поиска дубликатов и объединения.
+const (
+	// DefaultMinScore - минимальная оценка сходства пары по умолчанию.
+	DefaultMinScore = 0.7
+	// MaxMergeSize ограничивает количество персон, поглощаемых одним объединением.
+	MaxMergeSize = 100
+	// maxPairs ограничивает количество пар сходства, из которых строятся группы дубликатов.
+	maxPairs = 5000
+)
+
+// Service ищет дубликаты персон и объединяет их.
+type Service struct {
+	repositories repo.Repositories
+}
+
+// NewService создает новый сервис дубликатов персон.
+func NewService(repositories repo.Repositories) *Service {
+	return &Service{repositories: repositories}
+}
+
+// FindDuplicates группирует вероятные дубликаты: персоны, связанные цепочкой пар с оценкой
+// сходства не ниже minScore, попадают в одну группу. Персоны группы упорядочены по времени создания.
+// Возвращает: не более limit групп по убыванию оценки, ошибка.
+func (s *Service) FindDuplicates(ctx context.Context, minScore float64, limit int) ([]*entities.PersonDuplicateCluster, error) {
+	repository := s.repositories.People().Person()
+
+	pairs, err := repository.FindDuplicatePairs(ctx, minScore, maxPairs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate pairs: %w", err)
+	}
+
+	clusters, members := clusterPairs(pairs)
+	if len(clusters) > limit {
+		clusters = clusters[:limit]
+	}
+	if len(clusters) == 0 {
+		return []*entities.PersonDuplicateCluster{}, nil
+	}
+
+	var ids []uuid.UUID
+	for _, cluster := range clusters {
+		ids = append(ids, members[cluster]...)
+	}
+
+	persons, _, err := repository.GetPersons(ctx, map[string]any{
+		personrepo.FilterIDs:       ids,
+		personrepo.FilterSkipCount: true,
+		personrepo.FilterSort:      []personrepo.SortField{{Column: "created_at"}},
+	}, 0, len(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get duplicate persons: %w", err)
+	}
+
+	clusterOf := make(map[uuid.UUID]*entities.PersonDuplicateCluster, len(ids))
+	for _, cluster := range clusters {
+		for _, id := range members[cluster] {
+			clusterOf[id] = cluster
+		}
+	}
+	for _, person := range persons {
+		if cluster, ok := clusterOf[person.ID]; ok {
+			cluster.Persons = append(cluster.Persons, *person)
+		}
+	}
+
+	// Персоны, удаленные между запросами, пропускаются вместе с группами, где не осталось пары.
+	result := make([]*entities.PersonDuplicateCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if len(cluster.Persons) > 1 {
+			result = append(result, cluster)
+		}
+	}
+
+	logger.Debug(ctx, "duplicate persons found",
+		zap.Int("pairs", len(pairs)),
+		zap.Int("clusters", len(result)))
+	return result, nil
+}
+
+// Merge объединяет персоны ids с персоной survivorID по правилам Resolve. Идентификатор survivor
+// и повторы в ids пропускаются. version - ожидаемая версия survivor (0 отключает проверку).
+// Возвращает: обновленную персону survivor, ошибка.
+func (s *Service) Merge(ctx context.Context, survivorID uuid.UUID, ids []uuid.UUID, version int) (*entities.Person, error) {
+	mergedIDs := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id != survivorID && !slices.Contains(mergedIDs, id) {
+			mergedIDs = append(mergedIDs, id)
+		}
+	}
+	if len(mergedIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one person other than the survivor is required", personrepo.ErrInvalidMerge)
+	}
+	if len(mergedIDs) > MaxMergeSize {
+		return nil, fmt.Errorf("%w: at most %d persons can be merged at once", personrepo.ErrInvalidMerge, MaxMergeSize)
+	}
+
+	survivor, err := s.repositories.People().Person().MergePersons(ctx, survivorID, mergedIDs, version, Resolve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge persons: %w", err)
+	}
+
+	logger.Info(ctx, "persons merged",
+		zap.String("survivor", survivorID.String()),
+		zap.Int("merged", len(mergedIDs)))
+	return survivor, nil
+}
+
+// clusterPairs объединяет пары в группы связности. Пары упорядочены по убыванию оценки, поэтому
+// группы создаются в порядке своей лучшей пары и получают ее оценку.
+// Возвращает: группы без персон и идентификаторы персон каждой группы.
+func clusterPairs(pairs []entities.PersonDuplicatePair) ([]*entities.PersonDuplicateCluster, map[*entities.PersonDuplicateCluster][]uuid.UUID) {
+	parent := make(map[uuid.UUID]uuid.UUID)
+	var find func(id uuid.UUID) uuid.UUID
+	find = func(id uuid.UUID) uuid.UUID {
+		root, ok := parent[id]
+		if !ok || root == id {
+			parent[id] = id
+			return id
+		}
+		root = find(root)
+		parent[id] = root
+		return root
+	}
+	for _, pair := range pairs {
+		parent[find(pair.PersonID)] = find(pair.DuplicateID)
+	}
+
+	var clusters []*entities.PersonDuplicateCluster
+	byRoot := make(map[uuid.UUID]*entities.PersonDuplicateCluster)
+	members := make(map[*entities.PersonDuplicateCluster][]uuid.UUID)
+	for _, pair := range pairs {
+		root := find(pair.PersonID)
+		cluster, ok := byRoot[root]
+		if !ok {
+			cluster = &entities.PersonDuplicateCluster{Score: pair.Score}
+			byRoot[root] = cluster
+			clusters = append(clusters, cluster)
+		}
+		cluster.Pairs = append(cluster.Pairs, pair)
+		for _, id := range []uuid.UUID{pair.PersonID, pair.DuplicateID} {
+			if !slices.Contains(members[cluster], id) {
+				members[cluster] = append(members[cluster], id)
+			}
+		}
+	}
+	return clusters, members
+}
@@ -0,0 +1,232 @@
+package merge_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/services/merge"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo"
+	repopeople "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	importsrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
+	personrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPersonRepository реализует только методы, которые использует сервис дубликатов.
+type mockPersonRepository struct {
+	personrepo.Repository
+	mock.Mock
+}
+
+func (m *mockPersonRepository) FindDuplicatePairs(ctx context.Context, minScore float64, limit int) ([]entities.PersonDuplicatePair, error) {
+	args := m.Called(ctx, minScore, limit)
+	if pairs, ok := args.Get(0).([]entities.PersonDuplicatePair); ok {
+		return pairs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockPersonRepository) GetPersons(ctx context.Context, filter map[string]any, offset, limit int) ([]*entities.Person, int, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if persons, ok := args.Get(0).([]*entities.Person); ok {
+		return persons, args.Int(1), args.Error(2)
+	}
+	return nil, args.Int(1), args.Error(2)
+}
+
+func (m *mockPersonRepository) MergePersons(ctx context.Context, survivorID uuid.UUID, mergedIDs []uuid.UUID, version int, resolve personrepo.MergeResolver) (*entities.Person, error) {
+	args := m.Called(ctx, survivorID, mergedIDs, version)
+	if resolve == nil {
+		return nil, errors.New("resolver is required")
+	}
+	if person, ok := args.Get(0).(*entities.Person); ok {
+		return person, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockRepositories struct {
+	person *mockPersonRepository
+}
+
+func (m *mockRepositories) People() repopeople.Repositories { return m }
+
+// WithTx выполняет fn без транзакции: моки не хранят данные.
+func (m *mockRepositories) WithTx(_ context.Context, fn func(tx repo.Repositories) error) error {
+	return fn(m)
+}
+
+func (m *mockRepositories) Person() personrepo.Repository { return m.person }
+
+func (m *mockRepositories) History() historyrepo.Repository { return nil }
+
+func (m *mockRepositories) Imports() importsrepo.Repository { return nil }
+
+func newService() (*merge.Service, *mockPersonRepository) {
+	repository := new(mockPersonRepository)
+	return merge.NewService(&mockRepositories{person: repository}), repository
+}
+
+func TestFindDuplicates(t *testing.T) {
+	ids := make([]uuid.UUID, 5)
+	persons := make([]*entities.Person, 5)
+	for i := range ids {
+		ids[i] = uuid.New()
+		persons[i] = &entities.Person{ID: ids[i], Name: "Ivan", Surname: "Ivanov"}
+	}
+	pairs := []entities.PersonDuplicatePair{
+		{PersonID: ids[0], DuplicateID: ids[1], Score: 1},
+		{PersonID: ids[2], DuplicateID: ids[3], Score: 0.9},
+		{PersonID: ids[1], DuplicateID: ids[4], Score: 0.8},
+	}
+
+	t.Run("groups pairs linked by a common person", func(t *testing.T) {
+		service, repository := newService()
+		repository.On("FindDuplicatePairs", mock.Anything, 0.7, mock.Anything).Return(pairs, nil)
+		repository.On("GetPersons", mock.Anything, mock.MatchedBy(func(filter map[string]any) bool {
+			filterIDs, _ := filter[personrepo.FilterIDs].([]uuid.UUID)
+			return len(filterIDs) == 5
+		}), 0, 5).Return(persons, -1, nil)
+
+		clusters, err := service.FindDuplicates(context.Background(), 0.7, 10)
+
+		require.NoError(t, err)
+		require.Len(t, clusters, 2)
+		assert.InDelta(t, 1, clusters[0].Score, 1e-9)
+		assert.Len(t, clusters[0].Persons, 3)
+		assert.Len(t, clusters[0].Pairs, 2)
+		assert.InDelta(t, 0.9, clusters[1].Score, 1e-9)
+		assert.Len(t, clusters[1].Persons, 2)
+	})
+
+	t.Run("limits clusters and skips persons deleted meanwhile", func(t *testing.T) {
+		service, repository := newService()
+		repository.On("FindDuplicatePairs", mock.Anything, 0.7, mock.Anything).Return(pairs, nil)
+		repository.On("GetPersons", mock.Anything, mock.Anything, 0, 3).
+			Return([]*entities.Person{persons[0], persons[4]}, -1, nil)
+
+		clusters, err := service.FindDuplicates(context.Background(), 0.7, 1)
+
+		require.NoError(t, err)
+		require.Len(t, clusters, 1)
+		assert.Equal(t, []entities.Person{*persons[0], *persons[4]}, clusters[0].Persons)
+	})
+
+	t.Run("returns empty list without pairs", func(t *testing.T) {
+		service, repository := newService()
+		repository.On("FindDuplicatePairs", mock.Anything, 0.9, mock.Anything).Return([]entities.PersonDuplicatePair{}, nil)
+
+		clusters, err := service.FindDuplicates(context.Background(), 0.9, 10)
+
+		require.NoError(t, err)
+		assert.Empty(t, clusters)
+		repository.AssertNotCalled(t, "GetPersons", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	survivorID := uuid.New()
+	otherID := uuid.New()
+
+	t.Run("passes unique merged ids to the repository", func(t *testing.T) {
+		service, repository := newService()
+		survivor := &entities.Person{ID: survivorID, Version: 3}
+		repository.On("MergePersons", mock.Anything, survivorID, []uuid.UUID{otherID}, 2).Return(survivor, nil)
+
+		merged, err := service.Merge(context.Background(), survivorID, []uuid.UUID{otherID, survivorID, otherID}, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, survivor, merged)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("rejects merge without other persons", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.Merge(context.Background(), survivorID, []uuid.UUID{survivorID}, 0)
+
+		require.ErrorIs(t, err, personrepo.ErrInvalidMerge)
+	})
+
+	t.Run("rejects too many persons", func(t *testing.T) {
+		service, _ := newService()
+		ids := make([]uuid.UUID, merge.MaxMergeSize+1)
+		for i := range ids {
+			ids[i] = uuid.New()
+		}
+
+		_, err := service.Merge(context.Background(), survivorID, ids, 0)
+
+		require.ErrorIs(t, err, personrepo.ErrInvalidMerge)
+	})
+}
+
+func TestResolve(t *testing.T) {
+	ptr := func(value string) *string { return &value }
+	age := func(value int) *int { return &value }
+	probability := func(value float64) *float64 { return &value }
+	updatedAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	survivor := &entities.Person{
+		ID:                     uuid.New(),
+		Name:                   "Dmitriy",
+		Surname:                "Ivanov",
+		Age:                    age(40),
+		Gender:                 ptr("male"),
+		GenderProbability:      probability(0.7),
+		Nationality:            ptr("UA"),
+		NationalityProbability: probability(0.4),
+		Version:                3,
+		UpdatedAt:              updatedAt,
+	}
+	older := &entities.Person{
+		ID:                     uuid.New(),
+		Name:                   "Дмитрий",
+		Surname:                "Иванов",
+		Patronymic:             ptr("Петрович"),
+		Age:                    age(41),
+		Gender:                 ptr("female"),
+		GenderProbability:      probability(0.99),
+		Nationality:            ptr("RU"),
+		NationalityProbability: probability(0.3),
+		UpdatedAt:              updatedAt.Add(-time.Hour),
+	}
+	newer := &entities.Person{
+		ID:                     uuid.New(),
+		Name:                   "Dmitry",
+		Surname:                "Ivanov",
+		Patronymic:             ptr("Petrovich"),
+		Nationality:            ptr("BY"),
+		NationalityProbability: probability(0.2),
+		UpdatedAt:              updatedAt.Add(time.Hour),
+	}
+	enriched := map[uuid.UUID][]string{
+		survivor.ID: {"age", "gender", "gender_probability", "nationality", "nationality_probability"},
+		older.ID:    {"gender", "gender_probability", "nationality", "nationality_probability"},
+	}
+
+	result := merge.Resolve(survivor, []*entities.Person{older, newer}, enriched)
+
+	assert.Equal(t, "Dmitriy", result.Name)
+	assert.Equal(t, "Ivanov", result.Surname)
+	assert.Equal(t, 3, result.Version)
+	// Отчество заполняется у персоны, обновленной позже.
+	assert.Equal(t, "Petrovich", *result.Patronymic)
+	// Возраст, введенный вручную, предпочитается обогащенному.
+	assert.Equal(t, 41, *result.Age)
+	// Среди обогащенных значений пол выбирается по большей вероятности вместе с ней.
+	assert.Equal(t, "female", *result.Gender)
+	assert.InDelta(t, 0.99, *result.GenderProbability, 1e-9)
+	// Национальность, введенная вручную, побеждает значения с большей вероятностью.
+	assert.Equal(t, "BY", *result.Nationality)
+	assert.InDelta(t, 0.2, *result.NationalityProbability, 1e-9)
+	// Исходная персона не изменяется.
+	assert.Equal(t, 40, *survivor.Age)
+}
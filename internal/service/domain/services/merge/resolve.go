@@ -0,0 +1,91 @@
+package merge
+
+import (
+	"slices"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/google/uuid"
+)
+
+// Resolve вычисляет состояние персоны после объединения. Имя и фамилия остаются от survivor,
+// остальные колонки берутся у первой персоны, где они заполнены, по правилам:
+//   - значение, введенное вручную, предпочитается значению из обогащения;
+//   - среди значений из обогащения пол и национальность выбираются по большей вероятности;
+//   - при прочих равных побеждает survivor, затем персона, обновленная позже.
+//
+// Пол и национальность переносятся вместе со своей вероятностью.
+// Resolve соответствует сигнатуре person.MergeResolver.
+func Resolve(survivor *entities.Person, merged []*entities.Person, enriched map[uuid.UUID][]string) *entities.Person {
+	others := slices.Clone(merged)
+	slices.SortStableFunc(others, func(a, b *entities.Person) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	r := resolver{
+		candidates: append([]*entities.Person{survivor}, others...),
+		enriched:   enriched,
+	}
+
+	result := *survivor
+	if source := r.pick("patronymic", func(p *entities.Person) bool { return p.Patronymic != nil }, nil); source != nil {
+		result.Patronymic = source.Patronymic
+	}
+	if source := r.pick("age", func(p *entities.Person) bool { return p.Age != nil }, nil); source != nil {
+		result.Age = source.Age
+	}
+	if source := r.pick("gender", func(p *entities.Person) bool { return p.Gender != nil },
+		func(p *entities.Person) *float64 { return p.GenderProbability }); source != nil {
+		result.Gender = source.Gender
+		result.GenderProbability = source.GenderProbability
+	}
+	if source := r.pick("nationality", func(p *entities.Person) bool { return p.Nationality != nil },
+		func(p *entities.Person) *float64 { return p.NationalityProbability }); source != nil {
+		result.Nationality = source.Nationality
+		result.NationalityProbability = source.NationalityProbability
+	}
+	return &result
+}
+
+// resolver выбирает источник значения колонки среди объединяемых персон.
+type resolver struct {
+	// candidates упорядочены по приоритету: survivor, затем остальные по убыванию updated_at.
+	candidates []*entities.Person
+	enriched   map[uuid.UUID][]string
+}
+
+// pick возвращает персону, значение колонки которой попадет в результат, или nil,
+// если колонка не заполнена ни у одной персоны. probability задается для колонок,
+// значения которых обогащение возвращает с вероятностью.
+func (r resolver) pick(column string, has func(*entities.Person) bool, probability func(*entities.Person) *float64) *entities.Person {
+	var best *entities.Person
+	for _, candidate := range r.candidates {
+		if !has(candidate) {
+			continue
+		}
+		if best == nil || r.better(candidate, best, column, probability) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// better сообщает, предпочтительнее ли значение колонки candidate значению current,
+// которое стоит раньше в порядке приоритета.
+func (r resolver) better(candidate, current *entities.Person, column string, probability func(*entities.Person) *float64) bool {
+	candidateManual := !slices.Contains(r.enriched[candidate.ID], column)
+	currentManual := !slices.Contains(r.enriched[current.ID], column)
+	if candidateManual != currentManual {
+		return candidateManual
+	}
+	if candidateManual || probability == nil {
+		return false
+	}
+	return valueOrZero(probability(candidate)) > valueOrZero(probability(current))
+}
+
+// valueOrZero возвращает значение по указателю или 0 для пустого указателя.
+func valueOrZero(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -2,11 +2,46 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/api/people"
 )
 
+// Ошибки обращения к поставщикам обогащения.
+var (
+	// ErrProviderDisabled возникает при обращении к поставщику, отключенному для арендатора.
+	ErrProviderDisabled = errors.New("enrichment provider is disabled for tenant")
+	// ErrTenantQuotaReached возникает, когда суточная квота обогащений арендатора исчерпана.
+	ErrTenantQuotaReached = errors.New("tenant enrichment quota exceeded")
+)
+
+// QuotaError сообщает об исчерпанной квоте обогащений арендатора и времени до ее восстановления.
+// Проверка errors.Is(err, ErrTenantQuotaReached) для нее истинна.
+type QuotaError struct {
+	Tenant     string
+	RetryAfter time.Duration
+}
+
+// Error возвращает описание ошибки.
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: tenant %q", ErrTenantQuotaReached, e.Tenant)
+}
+
+// Unwrap возвращает ErrTenantQuotaReached.
+func (e *QuotaError) Unwrap() error {
+	return ErrTenantQuotaReached
+}
+
 // API объединяет все сервисные интерфейсы приложения.
 type API interface {
 	// People возвращает интерфейсы для работы с данными о людях.
 	People() people.Services
+
+	// ReserveEnrichment учитывает одно обогащение персоны в суточной квоте арендатора из контекста.
+	// Вызывается один раз перед обращениями к поставщикам, которые сами квоту не расходуют.
+	// Возвращает: QuotaError, если квота исчерпана.
+	ReserveEnrichment(ctx context.Context) error
 }
@@ -0,0 +1,63 @@
+// Package history определяет интерфейс репозитория истории изменений персон.
+package history
+
+import (
+	"context"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/google/uuid"
+)
+
+// Операции, фиксируемые в истории изменений персоны.
+const (
+	OperationCreate  = "create"
+	OperationUpdate  = "update"
+	OperationDelete  = "delete"
+	OperationRestore = "restore"
+	OperationEnrich  = "enrich"
+	OperationRevert  = "revert"
+	OperationImport  = "import"
+	OperationMerge   = "merge"
+)
+
+// Ключи контекста для метаданных записи истории.
+type (
+	actorKey     struct{}
+	operationKey struct{}
+)
+
+// WithActor возвращает контекст с инициатором изменения, который попадет в историю.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor возвращает инициатора изменения из контекста.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithOperation возвращает контекст, в котором изменение персоны записывается в историю
+// под указанной операцией вместо операции по умолчанию (например, enrich вместо update).
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// Operation возвращает операцию из контекста или fallback, если она не задана.
+func Operation(ctx context.Context, fallback string) string {
+	if operation, ok := ctx.Value(operationKey{}).(string); ok && operation != "" {
+		return operation
+	}
+	return fallback
+}
+
+// Repository определяет методы чтения истории изменений персон.
+// Записи истории создаются репозиторием персон в той же транзакции, что и изменение.
+type Repository interface {
+	// GetHistory получает историю изменений персоны, начиная с последней версии.
+	// Возвращает: записи истории, общее количество записей, ошибка.
+	GetHistory(ctx context.Context, personID uuid.UUID, offset, limit int) ([]*entities.PersonHistory, int, error)
+
+	// GetByVersion получает запись истории, в результате которой персона получила указанную версию.
+	GetByVersion(ctx context.Context, personID uuid.UUID, version int) (*entities.PersonHistory, error)
+}
@@ -0,0 +1,18 @@
+// Package imports определяет интерфейс репозитория результатов импорта персон.
+package imports
+
+import (
+	"context"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/google/uuid"
+)
+
+// Repository определяет методы для сохранения результатов импорта персон вместе с отчетом об ошибках.
+type Repository interface {
+	// CreateImport сохраняет результат импорта.
+	CreateImport(ctx context.Context, result *entities.PersonImport) error
+
+	// GetImport получает результат импорта по идентификатору вместе с построчными ошибками.
+	GetImport(ctx context.Context, id uuid.UUID) (*entities.PersonImport, error)
+}
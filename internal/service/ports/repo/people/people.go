@@ -2,6 +2,8 @@
 package people
 
 import (
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/imports"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
 )
 
@@ -9,4 +11,10 @@ import (
 type Repositories interface {
 	// Person возвращает репозиторий для работы с персонами.
 	Person() person.Repository
+
+	// History возвращает репозиторий истории изменений персон.
+	History() history.Repository
+
+	// Imports возвращает репозиторий результатов импорта персон.
+	Imports() imports.Repository
 }
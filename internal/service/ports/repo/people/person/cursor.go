@@ -0,0 +1,110 @@
+package person
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	"github.com/google/uuid"
+)
+
+// Служебные ключи фильтра GetPersons, управляющие выборкой, а не условиями отбора.
+const (
+	// FilterCursor задает позицию, после которой продолжается выборка (значение типа Cursor).
+	// При наличии курсора смещение offset игнорируется.
+	FilterCursor = "cursor"
+	// FilterSkipCount отключает подсчет общего количества записей (значение типа bool).
+	// В этом случае GetPersons возвращает общее количество -1.
+	FilterSkipCount = "skip_count"
+)
+
+// ErrInvalidCursor возникает, когда токен курсора не может быть разобран
+// или выдан для другого порядка сортировки.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor описывает позицию в списке персон: значения колонок сортировки и идентификатор
+// последней полученной персоны. Идентификатор служит последним ключом сортировки.
+type Cursor struct {
+	Sort   string    `json:"s"`
+	Values []any     `json:"v"`
+	ID     uuid.UUID `json:"i"`
+}
+
+// CursorAfter возвращает курсор, указывающий на позицию сразу после персоны в заданном порядке.
+func CursorAfter(person *entities.Person, sort []SortField) Cursor {
+	values := make([]any, 0, len(sort))
+	for _, field := range sort {
+		values = append(values, SortValue(person, field.Column))
+	}
+	return Cursor{Sort: FormatSort(sort), Values: values, ID: person.ID}
+}
+
+// Encode кодирует курсор в непрозрачный токен, пригодный для передачи в URL.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает токен, полученный из Cursor.Encode, и проверяет,
+// что он выдан для того же порядка сортировки.
+func DecodeCursor(token string, sort []SortField) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if cursor.ID == uuid.Nil {
+		return Cursor{}, fmt.Errorf("%w: missing position", ErrInvalidCursor)
+	}
+	if cursor.Sort != FormatSort(sort) || len(cursor.Values) != len(sort) {
+		return Cursor{}, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidCursor, cursor.Sort)
+	}
+
+	for i, field := range sort {
+		value, err := decodeSortValue(field.Column, cursor.Values[i])
+		if err != nil {
+			return Cursor{}, err
+		}
+		cursor.Values[i] = value
+	}
+
+	return cursor, nil
+}
+
+// decodeSortValue восстанавливает тип значения колонки сортировки после разбора JSON.
+func decodeSortValue(column string, raw any) (any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	switch column {
+	case "name", "surname":
+		if value, ok := raw.(string); ok {
+			return value, nil
+		}
+	case "age":
+		if value, ok := raw.(float64); ok && value == float64(int(value)) {
+			return int(value), nil
+		}
+	case "gender_probability", "nationality_probability":
+		if value, ok := raw.(float64); ok {
+			return value, nil
+		}
+	case "created_at", "updated_at":
+		if value, ok := raw.(string); ok {
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err == nil {
+				return parsed, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: bad value for %q", ErrInvalidCursor, column)
+}
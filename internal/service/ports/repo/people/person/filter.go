@@ -0,0 +1,109 @@
+package person
+
+import (
+	"errors"
+	"strings"
+)
+
+// Ключи фильтров GetPersons по диапазонам значений.
+const (
+	// FilterAgeMin и FilterAgeMax ограничивают возраст включительно (значения типа int).
+	FilterAgeMin = "age_min"
+	FilterAgeMax = "age_max"
+	// FilterGenderProbabilityMin и FilterNationalityProbabilityMin задают минимальную
+	// вероятность включительно (значения типа float64).
+	FilterGenderProbabilityMin      = "gender_probability_min"
+	FilterNationalityProbabilityMin = "nationality_probability_min"
+	// FilterCreatedAfter, FilterCreatedBefore и FilterUpdatedAfter ограничивают время
+	// (значения типа time.Time); нижняя граница включается, верхняя нет.
+	FilterCreatedAfter  = "created_after"
+	FilterCreatedBefore = "created_before"
+	FilterUpdatedAfter  = "updated_after"
+)
+
+// Ключи фильтров GetPersons по наличию значений (значения типа []string с колонками из NullableColumns).
+const (
+	FilterMissing = "missing"
+	FilterPresent = "present"
+)
+
+// MatchMode задает способ сравнения текстового фильтра без учета регистра.
+type MatchMode string
+
+// Режимы сравнения текстовых фильтров.
+const (
+	MatchExact    MatchMode = "exact"
+	MatchPrefix   MatchMode = "prefix"
+	MatchContains MatchMode = "contains"
+)
+
+// Суффиксы ключей текстовых фильтров GetPersons. Ключ без суффикса («nationality») использует
+// режим колонки из TextColumns, ключ с суффиксом режима («name_prefix») задает режим явно,
+// а суффикс FilterSuffixNot исключает точные совпадения. Значение фильтра имеет тип string
+// или []string; несколько значений объединяются через ИЛИ (для исключения — через И).
+const (
+	FilterSuffixExact    = "_" + string(MatchExact)
+	FilterSuffixPrefix   = "_" + string(MatchPrefix)
+	FilterSuffixContains = "_" + string(MatchContains)
+	FilterSuffixNot      = "_not"
+)
+
+// TextColumns сопоставляет текстовые колонки с режимом сравнения по умолчанию:
+// имена ищутся по подстроке, а коды пола и национальности сравниваются точно.
+var TextColumns = map[string]MatchMode{
+	"name":        MatchContains,
+	"surname":     MatchContains,
+	"patronymic":  MatchContains,
+	"gender":      MatchExact,
+	"nationality": MatchExact,
+}
+
+// TextFilter описывает условие текстового фильтра, разобранное из ключа.
+type TextFilter struct {
+	Column string
+	Mode   MatchMode
+	Negate bool
+}
+
+// ParseTextFilterKey разбирает ключ текстового фильтра («name», «name_exact», «nationality_not»).
+// Возвращает false, если ключ не относится к текстовой колонке.
+func ParseTextFilterKey(key string) (TextFilter, bool) {
+	if mode, ok := TextColumns[key]; ok {
+		return TextFilter{Column: key, Mode: mode}, true
+	}
+
+	suffixes := []struct {
+		suffix string
+		filter TextFilter
+	}{
+		{FilterSuffixExact, TextFilter{Mode: MatchExact}},
+		{FilterSuffixPrefix, TextFilter{Mode: MatchPrefix}},
+		{FilterSuffixContains, TextFilter{Mode: MatchContains}},
+		{FilterSuffixNot, TextFilter{Mode: MatchExact, Negate: true}},
+	}
+	for _, s := range suffixes {
+		column, found := strings.CutSuffix(key, s.suffix)
+		if _, ok := TextColumns[column]; found && ok {
+			s.filter.Column = column
+			return s.filter, true
+		}
+	}
+	return TextFilter{}, false
+}
+
+// FilterPhonetic включает фонетическое сравнение (значение типа bool): фильтры name и surname
+// сопоставляются по фонетическим ключам вместо поиска подстроки.
+const FilterPhonetic = "phonetic"
+
+// ErrInvalidFilter возникает, когда значение фильтра имеет неверный тип или колонку.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// NullableColumns содержит колонки персоны, которые могут быть не заполнены.
+var NullableColumns = map[string]bool{
+	"patronymic":              true,
+	"age":                     true,
+	"gender":                  true,
+	"gender_probability":      true,
+	"nationality":             true,
+	"nationality_probability": true,
+}
@@ -3,29 +3,151 @@ package person
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
 	"github.com/google/uuid"
 )
 
+// Ошибки, общие для всех реализаций репозитория персон.
+var (
+	// ErrVersionConflict возвращается, когда версия записи в хранилище не совпадает с ожидаемой.
+	ErrVersionConflict = errors.New("person version conflict")
+	// ErrPersonNotDeleted возвращается при попытке восстановить персону, которая не была удалена.
+	ErrPersonNotDeleted = errors.New("person is not deleted")
+	// ErrInvalidMerge возвращается, когда набор объединяемых персон некорректен.
+	ErrInvalidMerge = errors.New("invalid merge")
+	// ErrSchemaDrift возвращается, когда в базе данных нет колонок, с которыми работает репозиторий.
+	ErrSchemaDrift = errors.New("database schema does not match the person repository")
+)
+
+// SchemaChecker реализуют репозитории, хранящие персон в базе данных со схемой.
+type SchemaChecker interface {
+	// CheckSchema проверяет, что в базе есть все таблицы и колонки, используемые репозиторием.
+	// Отсутствующие колонки перечисляются в ошибке ErrSchemaDrift.
+	CheckSchema(ctx context.Context) error
+}
+
+// FilterIDs ограничивает выборку GetPersons персонами с указанными идентификаторами (значение типа []uuid.UUID).
+const FilterIDs = "ids"
+
+// MergeResolver вычисляет состояние персоны, остающейся после объединения, по текущему состоянию
+// survivor и поглощаемых персон merged. enriched содержит для каждой персоны колонки, последнее
+// изменение которых было сделано обогащением, а не вручную.
+type MergeResolver func(survivor *entities.Person, merged []*entities.Person, enriched map[uuid.UUID][]string) *entities.Person
+
+// StatsOptions задает параметры статистики GetStats.
+type StatsOptions struct {
+	// TopNationalities - число самых частых национальностей в статистике.
+	TopNationalities int
+	// AgeBucketWidth - ширина интервала гистограммы возраста в годах.
+	AgeBucketWidth int
+}
+
+// includeDeletedKey ключ контекста, включающий мягко удаленные персоны в чтения репозитория.
+type includeDeletedKey struct{}
+
+// WithDeleted возвращает контекст, в котором GetByID, GetPersons и ExistsByID
+// учитывают мягко удаленные персоны.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// IncludeDeleted сообщает, нужно ли учитывать мягко удаленные персоны в чтениях.
+func IncludeDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
+
 // Repository определяет интерфейс для работы с хранилищем персон.
+// Методы изменения принимают ожидаемую версию записи: значение 0 отключает проверку,
+// иначе при несовпадении возвращается ErrVersionConflict.
 type Repository interface {
 	// GetByID получает персону по идентификатору.
+	// Мягко удаленные персоны не возвращаются, если контекст не создан через WithDeleted.
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Person, error)
 
 	// GetPersons получает список персон с фильтрацией и пагинацией.
-	// Возвращает: список персон, общее количество записей, ошибка.
+	// Персоны упорядочены по (created_at, id) по убыванию; вместо offset можно передать
+	// курсор в ключе FilterCursor, а ключ FilterSkipCount отключает подсчет общего количества.
+	// Возвращает: список персон, общее количество записей (-1, если подсчет отключен), ошибка.
 	GetPersons(ctx context.Context, filter map[string]any, offset, limit int) ([]*entities.Person, int, error)
 
+	// GetStats вычисляет статистику по персонам, отобранным фильтром GetPersons: количество по полу,
+	// самые частые национальности, гистограмму возраста, заполненность колонок обогащения и средние вероятности.
+	GetStats(ctx context.Context, filter map[string]any, opts StatsOptions) (*entities.PersonStats, error)
+
+	// StreamPersons передает в fn персоны, отобранные фильтром GetPersons, в порядке его сортировки,
+	// не загружая всю выборку в память. Ключи FilterSkipCount и пагинация не применяются.
+	// Ошибка fn прерывает чтение и возвращается вызывающему.
+	StreamPersons(ctx context.Context, filter map[string]any, fn func(*entities.Person) error) error
+
+	// SearchPersons выполняет нечеткий поиск персон по имени, фамилии и отчеству.
+	// При phonetic = true имя и фамилия сравниваются по фонетическим ключам.
+	// Возвращает: найденных персон по убыванию оценки сходства, ошибка.
+	SearchPersons(ctx context.Context, query string, limit int, phonetic bool) ([]*entities.PersonSearchResult, error)
+
+	// FindDuplicatePairs находит пары неудаленных персон, похожих по имени, фамилии и отчеству
+	// с учетом регистра, опечаток и транслитерации, с оценкой сходства не ниже minScore.
+	// Возвращает: не более limit пар по убыванию оценки, ошибка.
+	FindDuplicatePairs(ctx context.Context, minScore float64, limit int) ([]entities.PersonDuplicatePair, error)
+
+	// MergePersons объединяет персоны mergedIDs с персоной survivorID в одной транзакции.
+	// Новое состояние survivor вычисляется resolve; поглощенные персоны мягко удаляются
+	// и запоминают survivor для MergedInto, а их внешние идентификаторы переходят к survivor.
+	// Изменения всех персон записываются в историю.
+	// Ожидаемая версия version относится к survivor.
+	// Возвращает: обновленную персону survivor, ошибка.
+	MergePersons(ctx context.Context, survivorID uuid.UUID, mergedIDs []uuid.UUID, version int, resolve MergeResolver) (*entities.Person, error)
+
+	// MergedInto возвращает персону, с которой была объединена персона id,
+	// или uuid.Nil, если персона не объединялась.
+	MergedInto(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+
+	// UpsertByExternalID создает персону, связанную с внешним идентификатором externalID системы source,
+	// или обновляет уже связанную с ним персону. Ожидаемая версия берется из person.Version и проверяется
+	// только при обновлении; для новой связи ненулевая версия приводит к ErrVersionConflict.
+	// Новое состояние записывается в person. Возвращает: признак создания персоны, ошибка.
+	UpsertByExternalID(ctx context.Context, source, externalID string, person *entities.Person) (bool, error)
+
+	// GetByExternalID получает неудаленную персону по внешнему идентификатору externalID системы source.
+	GetByExternalID(ctx context.Context, source, externalID string) (*entities.Person, error)
+
 	// CreatePerson создает новую персону.
 	CreatePerson(ctx context.Context, person *entities.Person) error
 
+	// CreatePersons создает несколько персон одной операцией: либо все, либо ни одной.
+	// Идентификаторы, время создания и версия записываются в переданные персоны.
+	CreatePersons(ctx context.Context, persons []*entities.Person) error
+
 	// UpdatePerson обновляет существующую персону.
+	// Ожидаемая версия берется из person.Version, после обновления в нее записывается новая версия.
 	UpdatePerson(ctx context.Context, person *entities.Person) error
 
-	// DeletePerson удаляет персону по идентификатору.
-	DeletePerson(ctx context.Context, id uuid.UUID) error
+	// PatchPerson частично обновляет персону, изменяя только переданные колонки.
+	// Значение nil в fields очищает соответствующую колонку.
+	// Возвращает: обновленную персону, ошибка.
+	PatchPerson(ctx context.Context, id uuid.UUID, fields map[string]any, version int) (*entities.Person, error)
+
+	// DeletePerson мягко удаляет персону по идентификатору, устанавливая deleted_at.
+	DeletePerson(ctx context.Context, id uuid.UUID, version int) error
+
+	// RestorePerson восстанавливает мягко удаленную персону.
+	RestorePerson(ctx context.Context, id uuid.UUID) (*entities.Person, error)
+
+	// PurgeDeleted безвозвратно удаляет персоны, мягко удаленные раньше deletedBefore.
+	// Возвращает: количество удаленных записей, ошибка.
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
 
 	// ExistsByID проверяет существование персоны по идентификатору.
 	ExistsByID(ctx context.Context, id uuid.UUID) (bool, error)
 }
+
+// PhoneticIndexer реализуется хранилищами, которые хранят фонетические ключи имен
+// и умеют вычислять их для записей, созданных до появления ключей.
+type PhoneticIndexer interface {
+	// BackfillPhoneticKeys вычисляет фонетические ключи для записей без них, обрабатывая
+	// не более batchSize записей за раз. Возвращает: количество обновленных записей, ошибка.
+	BackfillPhoneticKeys(ctx context.Context, batchSize int) (int, error)
+}
@@ -0,0 +1,688 @@
+// Package persontest содержит общий набор тестов, которому должна соответствовать
+// любая реализация person.Repository.
+package persontest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+	historyrepo "github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/history"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people/person"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory создает пустой репозиторий для одного теста.
+type Factory func(t *testing.T) person.Repository
+
+// testContext возвращает контекст арендатора по умолчанию: хранилища с разделением данных
+// по арендаторам отклоняют запросы без арендатора в контексте.
+func testContext() context.Context {
+	return tenant.WithID(context.Background(), tenant.Default)
+}
+
+// RunRepositoryTests проверяет, что реализация person.Repository соблюдает контракт порта:
+// версии и мягкое удаление, фильтры, сортировку и курсоры списка, поиск, объединение,
+// внешние идентификаторы и статистику. newRepository вызывается для каждого подтеста.
+func RunRepositoryTests(t *testing.T, newRepository Factory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repository person.Repository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"CreatePersonsIsAtomic", testCreatePersonsIsAtomic},
+		{"UpdateChecksVersion", testUpdateChecksVersion},
+		{"PatchPerson", testPatchPerson},
+		{"DeleteAndRestore", testDeleteAndRestore},
+		{"PurgeDeleted", testPurgeDeleted},
+		{"Filters", testFilters},
+		{"InvalidFilters", testInvalidFilters},
+		{"SortAndCursor", testSortAndCursor},
+		{"StreamPersons", testStreamPersons},
+		{"SearchPersons", testSearchPersons},
+		{"FindDuplicatePairs", testFindDuplicatePairs},
+		{"MergePersons", testMergePersons},
+		{"ExternalIDs", testExternalIDs},
+		{"GetStats", testGetStats},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepository(t))
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+// seed создает набор персон, на котором проверяются фильтры, сортировка и статистика.
+// Вероятности имеют не более четырех знаков, как колонки DECIMAL(5,4) в PostgreSQL.
+func seed(t *testing.T, repository person.Repository) map[string]*entities.Person {
+	t.Helper()
+
+	persons := []*entities.Person{
+		{Name: "Ivan", Surname: "Ivanov", Patronymic: ptr("Ivanovich"), Age: ptr(30), Gender: ptr("male"),
+			GenderProbability: ptr(0.9), Nationality: ptr("RU"), NationalityProbability: ptr(0.5)},
+		{Name: "Anna", Surname: "Petrova", Age: ptr(25), Gender: ptr("female"),
+			GenderProbability: ptr(0.95), Nationality: ptr("UA"), NationalityProbability: ptr(0.4)},
+		{Name: "Petr", Surname: "Sidorov", Patronymic: ptr("Petrovich"),
+			Nationality: ptr("RU"), NationalityProbability: ptr(0.8)},
+		{Name: "Maria", Surname: "Ivanova", Age: ptr(41), Gender: ptr("female"), GenderProbability: ptr(0.7)},
+		{Name: "John", Surname: "Smith", Age: ptr(30), Gender: ptr("male"),
+			GenderProbability: ptr(0.6), Nationality: ptr("US"), NationalityProbability: ptr(0.9)},
+	}
+	require.NoError(t, repository.CreatePersons(testContext(), persons))
+
+	byName := make(map[string]*entities.Person, len(persons))
+	for _, p := range persons {
+		byName[p.Name] = p
+	}
+	return byName
+}
+
+// names возвращает отсортированные имена персон.
+func names(persons []*entities.Person) []string {
+	result := make([]string, 0, len(persons))
+	for _, p := range persons {
+		result = append(result, p.Name)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func ids(persons []*entities.Person) []uuid.UUID {
+	result := make([]uuid.UUID, 0, len(persons))
+	for _, p := range persons {
+		result = append(result, p.ID)
+	}
+	return result
+}
+
+func testCreateAndGet(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	created := &entities.Person{Name: "Ivan", Surname: "Ivanov", Patronymic: ptr("Ivanovich"), Age: ptr(30)}
+
+	require.NoError(t, repository.CreatePerson(ctx, created))
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	assert.Equal(t, 1, created.Version)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	found, err := repository.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.Name, found.Name)
+	assert.Equal(t, created.Patronymic, found.Patronymic)
+	assert.Equal(t, created.Age, found.Age)
+	assert.Nil(t, found.Gender)
+	assert.True(t, created.CreatedAt.Equal(found.CreatedAt))
+	assert.Nil(t, found.DeletedAt)
+
+	exists, err := repository.ExistsByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = repository.GetByID(ctx, uuid.New())
+	require.ErrorContains(t, err, "not found")
+
+	exists, err = repository.ExistsByID(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	duplicate := &entities.Person{ID: created.ID, Name: "Petr", Surname: "Petrov"}
+	require.ErrorContains(t, repository.CreatePerson(ctx, duplicate), "already exists")
+}
+
+func testCreatePersonsIsAtomic(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	existing := &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+	require.NoError(t, repository.CreatePerson(ctx, existing))
+
+	fresh := &entities.Person{Name: "Anna", Surname: "Petrova"}
+	err := repository.CreatePersons(ctx, []*entities.Person{fresh, {ID: existing.ID, Name: "Petr", Surname: "Petrov"}})
+	require.ErrorContains(t, err, "already exists")
+
+	exists, err := repository.ExistsByID(ctx, fresh.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, repository.CreatePersons(ctx, nil))
+}
+
+func testUpdateChecksVersion(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	p := &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+	require.NoError(t, repository.CreatePerson(ctx, p))
+
+	stale := *p
+	p.Age = ptr(31)
+	require.NoError(t, repository.UpdatePerson(ctx, p))
+	assert.Equal(t, 2, p.Version)
+	assert.Equal(t, ptr(31), p.Age)
+
+	stale.Age = ptr(40)
+	require.ErrorIs(t, repository.UpdatePerson(ctx, &stale), person.ErrVersionConflict)
+
+	unchecked := *p
+	unchecked.Version = 0
+	unchecked.Surname = "Petrov"
+	require.NoError(t, repository.UpdatePerson(ctx, &unchecked))
+	assert.Equal(t, 3, unchecked.Version)
+
+	found, err := repository.GetByID(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Petrov", found.Surname)
+	assert.Equal(t, ptr(31), found.Age)
+	assert.Equal(t, 3, found.Version)
+
+	missing := &entities.Person{ID: uuid.New(), Name: "Anna", Surname: "Petrova"}
+	require.ErrorContains(t, repository.UpdatePerson(ctx, missing), "not found")
+}
+
+func testPatchPerson(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	p := &entities.Person{Name: "Ivan", Surname: "Ivanov", Patronymic: ptr("Ivanovich"), Gender: ptr("male")}
+	require.NoError(t, repository.CreatePerson(ctx, p))
+
+	patched, err := repository.PatchPerson(ctx, p.ID, map[string]any{"age": 30, "patronymic": nil, "name": "Ivann"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, patched.Version)
+	assert.Equal(t, ptr(30), patched.Age)
+	assert.Nil(t, patched.Patronymic)
+	assert.Equal(t, "Ivann", patched.Name)
+	assert.Equal(t, ptr("male"), patched.Gender)
+
+	unchanged, err := repository.PatchPerson(ctx, p.ID, map[string]any{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, unchanged.Version)
+
+	_, err = repository.PatchPerson(ctx, p.ID, map[string]any{"age": 31}, 1)
+	require.ErrorIs(t, err, person.ErrVersionConflict)
+
+	_, err = repository.PatchPerson(ctx, p.ID, map[string]any{"version": 5}, 0)
+	require.Error(t, err)
+
+	_, err = repository.PatchPerson(ctx, uuid.New(), map[string]any{"age": 31}, 0)
+	require.ErrorContains(t, err, "not found")
+}
+
+func testDeleteAndRestore(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	p := &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+	require.NoError(t, repository.CreatePerson(ctx, p))
+
+	require.ErrorIs(t, repository.DeletePerson(ctx, p.ID, 5), person.ErrVersionConflict)
+	require.NoError(t, repository.DeletePerson(ctx, p.ID, 1))
+
+	_, err := repository.GetByID(ctx, p.ID)
+	require.ErrorContains(t, err, "not found")
+	exists, err := repository.ExistsByID(ctx, p.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	deleted, err := repository.GetByID(person.WithDeleted(ctx), p.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, deleted.DeletedAt)
+	assert.Equal(t, 2, deleted.Version)
+	exists, err = repository.ExistsByID(person.WithDeleted(ctx), p.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.ErrorContains(t, repository.DeletePerson(ctx, p.ID, 0), "not found")
+
+	restored, err := repository.RestorePerson(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, 3, restored.Version)
+
+	_, err = repository.RestorePerson(ctx, p.ID)
+	require.ErrorIs(t, err, person.ErrPersonNotDeleted)
+	_, err = repository.RestorePerson(ctx, uuid.New())
+	require.ErrorContains(t, err, "not found")
+}
+
+func testPurgeDeleted(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	kept := &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+	purged := &entities.Person{Name: "Anna", Surname: "Petrova"}
+	require.NoError(t, repository.CreatePersons(ctx, []*entities.Person{kept, purged}))
+	require.NoError(t, repository.DeletePerson(ctx, purged.ID, 0))
+
+	count, err := repository.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	count, err = repository.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = repository.GetByID(person.WithDeleted(ctx), purged.ID)
+	require.ErrorContains(t, err, "not found")
+	_, err = repository.GetByID(ctx, kept.ID)
+	require.NoError(t, err)
+}
+
+func testFilters(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	seeded := seed(t, repository)
+
+	deleted := &entities.Person{Name: "Deleted", Surname: "Ivanov", Age: ptr(30)}
+	require.NoError(t, repository.CreatePerson(ctx, deleted))
+	require.NoError(t, repository.DeletePerson(ctx, deleted.ID, 0))
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		filter   map[string]any
+		expected []string
+	}{
+		{"no filter", map[string]any{}, []string{"Anna", "Ivan", "John", "Maria", "Petr"}},
+		{"unknown key is ignored", map[string]any{"unknown": "value"}, []string{"Anna", "Ivan", "John", "Maria", "Petr"}},
+		{"name contains ignoring case", map[string]any{"name": "AN"}, []string{"Anna", "Ivan"}},
+		{"surname values are combined with or", map[string]any{"surname": []string{"ivanov", "smith"}}, []string{"Ivan", "John", "Maria"}},
+		{"surname exact", map[string]any{"surname_exact": "ivanov"}, []string{"Ivan"}},
+		{"surname prefix", map[string]any{"surname_prefix": "Iv"}, []string{"Ivan", "Maria"}},
+		{"like characters are literal", map[string]any{"surname": "Iv_nov"}, []string{}},
+		{"nationality exact by default", map[string]any{"nationality": "ru"}, []string{"Ivan", "Petr"}},
+		{"negation keeps empty values", map[string]any{"nationality_not": "RU"}, []string{"Anna", "John", "Maria"}},
+		{"negation of several values", map[string]any{"gender_not": []string{"male", "female"}}, []string{"Petr"}},
+		{"patronymic contains", map[string]any{"patronymic_contains": "VICH"}, []string{"Ivan", "Petr"}},
+		{"age", map[string]any{"age": 30}, []string{"Ivan", "John"}},
+		{"age range", map[string]any{person.FilterAgeMin: 26, person.FilterAgeMax: 41}, []string{"Ivan", "John", "Maria"}},
+		{"gender probability", map[string]any{person.FilterGenderProbabilityMin: 0.9}, []string{"Anna", "Ivan"}},
+		{"nationality probability", map[string]any{person.FilterNationalityProbabilityMin: 0.8}, []string{"John", "Petr"}},
+		{"missing", map[string]any{person.FilterMissing: []string{"age"}}, []string{"Petr"}},
+		{"present", map[string]any{person.FilterPresent: []string{"patronymic", "nationality"}}, []string{"Ivan", "Petr"}},
+		{"ids", map[string]any{person.FilterIDs: []uuid.UUID{seeded["Anna"].ID, seeded["John"].ID, deleted.ID}}, []string{"Anna", "John"}},
+		{"phonetic surname", map[string]any{"surname": "Smit", person.FilterPhonetic: true}, []string{"John"}},
+		{"created before", map[string]any{person.FilterCreatedBefore: now.Add(time.Hour)}, []string{"Anna", "Ivan", "John", "Maria", "Petr"}},
+		{"created after", map[string]any{person.FilterCreatedAfter: now.Add(time.Hour)}, []string{}},
+		{"updated after", map[string]any{person.FilterUpdatedAfter: now.Add(-time.Hour), "surname": "ova"}, []string{"Anna", "Maria"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			persons, total, err := repository.GetPersons(ctx, tt.filter, 0, 100)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, names(persons))
+			assert.Equal(t, len(tt.expected), total)
+		})
+	}
+
+	t.Run("deleted persons on request", func(t *testing.T) {
+		persons, total, err := repository.GetPersons(person.WithDeleted(ctx), map[string]any{"surname_exact": "Ivanov"}, 0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Deleted", "Ivan"}, names(persons))
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("offset and limit", func(t *testing.T) {
+		persons, total, err := repository.GetPersons(ctx, map[string]any{}, 3, 10)
+		require.NoError(t, err)
+		assert.Len(t, persons, 2)
+		assert.Equal(t, 5, total)
+
+		persons, _, err = repository.GetPersons(ctx, map[string]any{}, 10, 10)
+		require.NoError(t, err)
+		assert.Empty(t, persons)
+	})
+
+	t.Run("skip count", func(t *testing.T) {
+		persons, total, err := repository.GetPersons(ctx, map[string]any{person.FilterSkipCount: true}, 0, 2)
+		require.NoError(t, err)
+		assert.Len(t, persons, 2)
+		assert.Equal(t, -1, total)
+	})
+}
+
+func testInvalidFilters(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+
+	tests := []struct {
+		name     string
+		filter   map[string]any
+		expected error
+	}{
+		{"missing of a required column", map[string]any{person.FilterMissing: []string{"name"}}, person.ErrInvalidFilter},
+		{"present of a wrong type", map[string]any{person.FilterPresent: "age"}, person.ErrInvalidFilter},
+		{"text filter of a wrong type", map[string]any{"name": 5}, person.ErrInvalidFilter},
+		{"empty list of values", map[string]any{"name": []string{}}, person.ErrInvalidFilter},
+		{"ids of a wrong type", map[string]any{person.FilterIDs: []string{"id"}}, person.ErrInvalidFilter},
+		{"unknown sort column", map[string]any{person.FilterSort: []person.SortField{{Column: "patronymic"}}}, person.ErrInvalidSort},
+		{"cursor of a wrong type", map[string]any{person.FilterCursor: "token"}, person.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := repository.GetPersons(ctx, tt.filter, 0, 10)
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func testSortAndCursor(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	seed(t, repository)
+
+	sorts := []struct {
+		name  string
+		sort  []person.SortField
+		first string
+		last  string
+	}{
+		{"default", person.DefaultSort, "", ""},
+		{"age ascending puts empty last", []person.SortField{{Column: "age"}}, "Anna", "Petr"},
+		{"age descending puts empty first", []person.SortField{{Column: "age", Desc: true}}, "Petr", "Anna"},
+		{"surname", []person.SortField{{Column: "surname"}}, "Ivan", "John"},
+		{"several columns", []person.SortField{{Column: "gender_probability"}, {Column: "name", Desc: true}}, "John", "Petr"},
+		{"descending first column with ascending ties", []person.SortField{{Column: "age", Desc: true}, {Column: "name"}}, "Petr", "Anna"},
+		{"nationality probability descending", []person.SortField{{Column: "nationality_probability", Desc: true}}, "Maria", "Anna"},
+	}
+
+	for _, tt := range sorts {
+		t.Run(tt.name, func(t *testing.T) {
+			all, _, err := repository.GetPersons(ctx, map[string]any{person.FilterSort: tt.sort}, 0, 100)
+			require.NoError(t, err)
+			require.Len(t, all, 5)
+			if tt.first != "" {
+				assert.Equal(t, tt.first, all[0].Name)
+				assert.Equal(t, tt.last, all[len(all)-1].Name)
+			}
+
+			// Обход курсором через закодированный токен дает тот же порядок без пропусков и повторов;
+			// смещение при заданном курсоре игнорируется.
+			var walked []*entities.Person
+			filter := map[string]any{person.FilterSort: tt.sort, person.FilterSkipCount: true}
+			offset := 0
+			for range len(all) {
+				page, total, err := repository.GetPersons(ctx, filter, offset, 2)
+				require.NoError(t, err)
+				assert.Equal(t, -1, total)
+				if len(page) == 0 {
+					break
+				}
+				walked = append(walked, page...)
+
+				cursor, err := person.DecodeCursor(person.CursorAfter(page[len(page)-1], tt.sort).Encode(), tt.sort)
+				require.NoError(t, err)
+				filter[person.FilterCursor] = cursor
+				offset = 100
+			}
+			assert.Equal(t, ids(all), ids(walked))
+		})
+	}
+
+	t.Run("cursor for another sort", func(t *testing.T) {
+		filter := map[string]any{
+			person.FilterSort:   []person.SortField{{Column: "age"}},
+			person.FilterCursor: person.Cursor{Values: []any{}, ID: uuid.New()},
+		}
+		_, _, err := repository.GetPersons(ctx, filter, 0, 10)
+		require.ErrorIs(t, err, person.ErrInvalidCursor)
+	})
+}
+
+func testStreamPersons(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	seed(t, repository)
+	filter := map[string]any{"nationality_not": "US", person.FilterSort: []person.SortField{{Column: "surname"}}}
+
+	expected, _, err := repository.GetPersons(ctx, filter, 0, 100)
+	require.NoError(t, err)
+
+	var streamed []*entities.Person
+	err = repository.StreamPersons(ctx, filter, func(p *entities.Person) error {
+		streamed = append(streamed, p)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ids(expected), ids(streamed))
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = repository.StreamPersons(ctx, filter, func(*entities.Person) error {
+		calls++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func testSearchPersons(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	seeded := seed(t, repository)
+	require.NoError(t, repository.DeletePerson(ctx, seeded["Maria"].ID, 0))
+
+	results, err := repository.SearchPersons(ctx, "Ivanof", 10, false)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, seeded["Ivan"].ID, results[0].Person.ID)
+	assert.InDelta(t, 5.0/9.0, results[0].Score, 1e-6)
+
+	results, err = repository.SearchPersons(person.WithDeleted(ctx), "Ivanof", 10, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.GreaterOrEqual(t, results[0].Score, results[1].Score)
+
+	results, err = repository.SearchPersons(ctx, "Smit", 10, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, seeded["John"].ID, results[0].Person.ID)
+	assert.Positive(t, results[0].Score)
+	assert.LessOrEqual(t, results[0].Score, 1.0)
+
+	results, err = repository.SearchPersons(ctx, "!!!", 10, true)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	results, err = repository.SearchPersons(ctx, "Zzzyx", 10, false)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func testFindDuplicatePairs(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	seeded := seed(t, repository)
+
+	duplicate := &entities.Person{Name: "IVAN", Surname: "ivanov", Patronymic: ptr("ivanovich")}
+	require.NoError(t, repository.CreatePerson(ctx, duplicate))
+
+	pairs, err := repository.FindDuplicatePairs(ctx, 0.95, 10)
+	require.NoError(t, err)
+	require.Len(t, pairs, 1)
+	assert.InDelta(t, 1.0, pairs[0].Score, 1e-9)
+	assert.ElementsMatch(t, []uuid.UUID{seeded["Ivan"].ID, duplicate.ID}, []uuid.UUID{pairs[0].PersonID, pairs[0].DuplicateID})
+	assert.Negative(t, compareIDs(pairs[0].PersonID, pairs[0].DuplicateID))
+
+	pairs, err = repository.FindDuplicatePairs(ctx, 0, 100)
+	require.NoError(t, err)
+	for i := 1; i < len(pairs); i++ {
+		assert.GreaterOrEqual(t, pairs[i-1].Score, pairs[i].Score)
+	}
+
+	require.NoError(t, repository.DeletePerson(ctx, duplicate.ID, 0))
+	pairs, err = repository.FindDuplicatePairs(ctx, 0.95, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pairs)
+}
+
+// compareIDs сравнивает идентификаторы побайтно, как PostgreSQL сравнивает uuid.
+func compareIDs(a, b uuid.UUID) int {
+	return slices.Compare(a[:], b[:])
+}
+
+func testMergePersons(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	survivor := &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+	merged := &entities.Person{Name: "Ivan", Surname: "Ivanov", Patronymic: ptr("Ivanovich")}
+	earlier := &entities.Person{Name: "Ivan", Surname: "Ivanoff"}
+	require.NoError(t, repository.CreatePersons(ctx, []*entities.Person{survivor, merged, earlier}))
+
+	// Возраст поглощаемой персоны заполнен обогащением, что должно быть видно resolve.
+	enriched := *merged
+	enriched.Age = ptr(35)
+	require.NoError(t, repository.UpdatePerson(historyrepo.WithOperation(ctx, historyrepo.OperationEnrich), &enriched))
+
+	_, err := repository.UpsertByExternalID(ctx, "crm", "42", &entities.Person{Name: "Ivan", Surname: "Ivanov"})
+	require.NoError(t, err)
+	linked, err := repository.GetByExternalID(ctx, "crm", "42")
+	require.NoError(t, err)
+
+	_, err = repository.MergePersons(ctx, survivor.ID, nil, 0, nil)
+	require.ErrorIs(t, err, person.ErrInvalidMerge)
+	_, err = repository.MergePersons(ctx, survivor.ID, []uuid.UUID{survivor.ID}, 0, nil)
+	require.ErrorIs(t, err, person.ErrInvalidMerge)
+	_, err = repository.MergePersons(ctx, survivor.ID, []uuid.UUID{uuid.New()}, 0, nil)
+	require.ErrorContains(t, err, "not found")
+	_, err = repository.MergePersons(ctx, survivor.ID, []uuid.UUID{merged.ID}, 7, nil)
+	require.ErrorIs(t, err, person.ErrVersionConflict)
+
+	// Ранее объединенная персона должна перейти к survivor вместе с linked.
+	_, err = repository.MergePersons(ctx, linked.ID, []uuid.UUID{earlier.ID}, 0, func(s *entities.Person, _ []*entities.Person, _ map[uuid.UUID][]string) *entities.Person {
+		return s
+	})
+	require.NoError(t, err)
+
+	var enrichedFields map[uuid.UUID][]string
+	result, err := repository.MergePersons(ctx, survivor.ID, []uuid.UUID{merged.ID, linked.ID}, 1,
+		func(s *entities.Person, others []*entities.Person, fields map[uuid.UUID][]string) *entities.Person {
+			enrichedFields = fields
+			resolved := *s
+			resolved.Patronymic = others[0].Patronymic
+			resolved.Age = others[0].Age
+			return &resolved
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, survivor.ID, result.ID)
+	assert.Equal(t, 2, result.Version)
+	assert.Equal(t, ptr("Ivanovich"), result.Patronymic)
+	assert.Equal(t, ptr(35), result.Age)
+	assert.Equal(t, []string{"age"}, enrichedFields[merged.ID])
+	assert.Empty(t, enrichedFields[survivor.ID])
+
+	_, err = repository.GetByID(ctx, merged.ID)
+	require.ErrorContains(t, err, "not found")
+	deleted, err := repository.GetByID(person.WithDeleted(ctx), merged.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, deleted.DeletedAt)
+
+	for _, id := range []uuid.UUID{merged.ID, linked.ID, earlier.ID} {
+		target, err := repository.MergedInto(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, survivor.ID, target)
+	}
+	target, err := repository.MergedInto(ctx, survivor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, target)
+	target, err = repository.MergedInto(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, target)
+
+	found, err := repository.GetByExternalID(ctx, "crm", "42")
+	require.NoError(t, err)
+	assert.Equal(t, survivor.ID, found.ID)
+
+	restored, err := repository.RestorePerson(ctx, merged.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	target, err = repository.MergedInto(ctx, merged.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, target)
+}
+
+func testExternalIDs(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+
+	input := &entities.Person{Name: "Ivan", Surname: "Ivanov"}
+	created, err := repository.UpsertByExternalID(ctx, "crm", "1", input)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 1, input.Version)
+	personID := input.ID
+
+	found, err := repository.GetByExternalID(ctx, "crm", "1")
+	require.NoError(t, err)
+	assert.Equal(t, personID, found.ID)
+
+	update := &entities.Person{Name: "Ivan", Surname: "Petrov", Age: ptr(30), Version: 1}
+	created, err = repository.UpsertByExternalID(ctx, "crm", "1", update)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, personID, update.ID)
+	assert.Equal(t, 2, update.Version)
+	assert.Equal(t, "Petrov", update.Surname)
+
+	_, err = repository.UpsertByExternalID(ctx, "crm", "1", &entities.Person{Name: "Ivan", Surname: "Ivanov", Version: 1})
+	require.ErrorIs(t, err, person.ErrVersionConflict)
+	_, err = repository.UpsertByExternalID(ctx, "crm", "2", &entities.Person{Name: "Anna", Surname: "Petrova", Version: 1})
+	require.ErrorIs(t, err, person.ErrVersionConflict)
+
+	_, err = repository.GetByExternalID(ctx, "crm", "2")
+	require.ErrorContains(t, err, "not found")
+	_, err = repository.GetByExternalID(ctx, "erp", "1")
+	require.ErrorContains(t, err, "not found")
+
+	require.NoError(t, repository.DeletePerson(ctx, personID, 0))
+	_, err = repository.GetByExternalID(ctx, "crm", "1")
+	require.ErrorContains(t, err, "not found")
+	_, err = repository.UpsertByExternalID(ctx, "crm", "1", &entities.Person{Name: "Ivan", Surname: "Ivanov"})
+	require.ErrorContains(t, err, "not found")
+}
+
+func testGetStats(t *testing.T, repository person.Repository) {
+	ctx := testContext()
+	seed(t, repository)
+	opts := person.StatsOptions{TopNationalities: 2, AgeBucketWidth: 10}
+
+	stats, err := repository.GetStats(ctx, map[string]any{}, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, stats.Total)
+	assert.Equal(t, []entities.PersonStatsValueCount{{Value: "female", Count: 2}, {Value: "male", Count: 2}}, stats.Gender)
+	assert.Equal(t, []entities.PersonStatsValueCount{{Value: "RU", Count: 2}, {Value: "UA", Count: 1}}, stats.Nationality)
+	assert.Equal(t, 1, stats.NationalityOther)
+	assert.Equal(t, 10, stats.AgeBucketWidth)
+	assert.Equal(t, []entities.PersonStatsAgeBucket{
+		{From: 20, To: 29, Count: 1},
+		{From: 30, To: 39, Count: 2},
+		{From: 40, To: 49, Count: 1},
+	}, stats.Age)
+
+	coverage := make(map[string]int, len(stats.Coverage))
+	for _, field := range stats.Coverage {
+		coverage[field.Field] = field.Filled
+	}
+	assert.Equal(t, map[string]int{
+		"patronymic":              2,
+		"age":                     4,
+		"gender":                  4,
+		"gender_probability":      4,
+		"nationality":             4,
+		"nationality_probability": 4,
+	}, coverage)
+	require.NotNil(t, stats.AverageGenderProbability)
+	assert.InDelta(t, 0.7875, *stats.AverageGenderProbability, 1e-9)
+	require.NotNil(t, stats.AverageNationalityProbability)
+	assert.InDelta(t, 0.65, *stats.AverageNationalityProbability, 1e-9)
+
+	filtered, err := repository.GetStats(ctx, map[string]any{"gender": "female"}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, filtered.Total)
+	assert.Equal(t, []entities.PersonStatsValueCount{{Value: "UA", Count: 1}}, filtered.Nationality)
+
+	empty, err := repository.GetStats(ctx, map[string]any{"name": "Nobody"}, opts)
+	require.NoError(t, err)
+	assert.Zero(t, empty.Total)
+	assert.Empty(t, empty.Gender)
+	assert.Empty(t, empty.Age)
+	assert.Nil(t, empty.AverageGenderProbability)
+}
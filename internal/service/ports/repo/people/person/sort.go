@@ -0,0 +1,108 @@
+package person
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/domain/entities"
+)
+
+// FilterSort задает порядок сортировки GetPersons (значение типа []SortField).
+const FilterSort = "sort"
+
+// ErrInvalidSort возникает, когда параметр сортировки содержит неизвестные или повторяющиеся колонки.
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortableColumns содержит колонки, по которым допускается сортировка списка персон.
+var SortableColumns = map[string]bool{
+	"name":                    true,
+	"surname":                 true,
+	"age":                     true,
+	"gender_probability":      true,
+	"nationality_probability": true,
+	"created_at":              true,
+	"updated_at":              true,
+}
+
+// SortField описывает колонку сортировки и ее направление.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// DefaultSort порядок сортировки по умолчанию: сначала новые персоны.
+var DefaultSort = []SortField{{Column: "created_at", Desc: true}}
+
+// ParseSort разбирает параметр сортировки вида "surname,-age,created_at",
+// где префикс "-" означает сортировку по убыванию.
+func ParseSort(spec string) ([]SortField, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("%w: empty sort", ErrInvalidSort)
+	}
+
+	parts := strings.Split(spec, ",")
+	fields := make([]SortField, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		column := strings.TrimPrefix(part, "-")
+
+		if !SortableColumns[column] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidSort, column)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidSort, column)
+		}
+		seen[column] = true
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+
+	return fields, nil
+}
+
+// FormatSort возвращает каноническое представление порядка сортировки.
+func FormatSort(fields []SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Desc {
+			parts = append(parts, "-"+field.Column)
+		} else {
+			parts = append(parts, field.Column)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// SortValue возвращает значение колонки сортировки персоны; nil соответствует NULL.
+func SortValue(person *entities.Person, column string) any {
+	switch column {
+	case "name":
+		return person.Name
+	case "surname":
+		return person.Surname
+	case "age":
+		if person.Age == nil {
+			return nil
+		}
+		return *person.Age
+	case "gender_probability":
+		if person.GenderProbability == nil {
+			return nil
+		}
+		return *person.GenderProbability
+	case "nationality_probability":
+		if person.NationalityProbability == nil {
+			return nil
+		}
+		return *person.NationalityProbability
+	case "created_at":
+		return person.CreatedAt
+	case "updated_at":
+		return person.UpdatedAt
+	default:
+		return nil
+	}
+}
@@ -2,6 +2,8 @@
 package repo
 
 import (
+	"context"
+
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/ports/repo/people"
 )
 
@@ -9,4 +11,10 @@ import (
 type Repositories interface {
 	// People возвращает репозитории для работы с данными о людях.
 	People() people.Repositories
+
+	// WithTx выполняет fn как единицу работы: все репозитории tx выполняют запросы в одной транзакции,
+	// которая фиксируется, если fn завершилась без ошибки, и откатывается иначе.
+	// Транзакция, прерванная конфликтом сериализации, повторяется целиком, поэтому fn может быть вызвана
+	// несколько раз и не должна иметь побочных эффектов вне tx. Вызов WithTx у tx выполняет fn в той же транзакции.
+	WithTx(ctx context.Context, fn func(tx Repositories) error) error
 }
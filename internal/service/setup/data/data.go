@@ -2,24 +2,39 @@
 package data
 
 import (
+	"time"
+
 	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
 	"go.uber.org/zap"
 )
 
 // PostgresConfig содержит настройки для базы данных PostgreSQL.
 type PostgresConfig struct {
-	Host                string `env:"POSTGRES_HOST" env-default:"localhost"`
-	Port                int    `env:"POSTGRES_PORT" env-default:"5432"`
-	User                string `env:"POSTGRES_USER" env-default:"postgres"`
-	Password            string `env:"POSTGRES_PASSWORD" env-default:"postgres"`
-	Database            string `env:"POSTGRES_DB" env-default:"postgres"`
-	SSLMode             string `env:"POSTGRES_SSLMODE" env-default:"disable"`
-	PoolMinConns        int    `env:"PGX_POOL_MIN_CONNS" env-default:"2"`
-	PoolMaxConns        int    `env:"PGX_POOL_MAX_CONNS" env-default:"10"`
-	PoolMaxConnLifetime int    `env:"PGX_POOL_MAX_CONN_LIFETIME" env-default:"3600"`
-	PoolMaxConnIdleTime int    `env:"PGX_POOL_MAX_CONN_IDLE_TIME" env-default:"600"`
-	ConnectTimeout      string `env:"PGX_CONNECT_TIMEOUT" env-default:"10s"`
-	AcquireTimeout      string `env:"PGX_POOL_ACQUIRE_TIMEOUT" env-default:"30s"`
+	Host         string `env:"POSTGRES_HOST" env-default:"localhost"`
+	Port         int    `env:"POSTGRES_PORT" env-default:"5432"`
+	User         string `env:"POSTGRES_USER" env-default:"postgres"`
+	Password     string `env:"POSTGRES_PASSWORD" env-default:"postgres"`
+	Database     string `env:"POSTGRES_DB" env-default:"postgres"`
+	SSLMode      string `env:"POSTGRES_SSLMODE" env-default:"disable"`
+	PoolMinConns int    `env:"PGX_POOL_MIN_CONNS" env-default:"2"`
+	PoolMaxConns int    `env:"PGX_POOL_MAX_CONNS" env-default:"10"`
+	// PoolMaxConnLifetime и PoolMaxConnIdleTime задаются в секундах.
+	PoolMaxConnLifetime   int           `env:"PGX_POOL_MAX_CONN_LIFETIME" env-default:"3600"`
+	PoolMaxConnIdleTime   int           `env:"PGX_POOL_MAX_CONN_IDLE_TIME" env-default:"600"`
+	PoolHealthCheckPeriod time.Duration `env:"PGX_POOL_HEALTH_CHECK_PERIOD" env-default:"1m"`
+	ConnectTimeout        time.Duration `env:"PGX_CONNECT_TIMEOUT" env-default:"10s"`
+	AcquireTimeout        time.Duration `env:"PGX_POOL_ACQUIRE_TIMEOUT" env-default:"30s"`
+	// StatementTimeout задается в миллисекундах, как параметр statement_timeout; 0 - без ограничения.
+	StatementTimeout int    `env:"POSTGRES_STATEMENT_TIMEOUT" env-default:"0"`
+	ApplicationName  string `env:"POSTGRES_APPLICATION_NAME" env-default:"person-enrichment-service"`
+	// ConnectRetries - число повторных попыток подключения при запуске; пауза между ними
+	// начинается с ConnectRetryInterval и удваивается.
+	ConnectRetries       int           `env:"POSTGRES_CONNECT_RETRIES" env-default:"5"`
+	ConnectRetryInterval time.Duration `env:"POSTGRES_CONNECT_RETRY_INTERVAL" env-default:"1s"`
+	// ReplicaDSNs - строки подключения к репликам для чтения через запятую.
+	ReplicaDSNs          []string      `env:"POSTGRES_REPLICA_DSNS" env-separator:","`
+	ReplicaCheckInterval time.Duration `env:"POSTGRES_REPLICA_CHECK_INTERVAL" env-default:"5s"`
 }
 
 // LogFields реализует интерфейс LoggableConfig для PostgresConfig.
@@ -32,6 +47,17 @@ func (c *PostgresConfig) LogFields() []zap.Field {
 		zap.String("sslmode", c.SSLMode),
 		zap.Int("pool_min_conns", c.PoolMinConns),
 		zap.Int("pool_max_conns", c.PoolMaxConns),
+		zap.Int("pool_max_conn_lifetime", c.PoolMaxConnLifetime),
+		zap.Int("pool_max_conn_idle_time", c.PoolMaxConnIdleTime),
+		zap.Duration("pool_health_check_period", c.PoolHealthCheckPeriod),
+		zap.Duration("connect_timeout", c.ConnectTimeout),
+		zap.Duration("acquire_timeout", c.AcquireTimeout),
+		zap.Int("statement_timeout", c.StatementTimeout),
+		zap.String("application_name", c.ApplicationName),
+		zap.Int("connect_retries", c.ConnectRetries),
+		zap.Duration("connect_retry_interval", c.ConnectRetryInterval),
+		zap.Int("replicas", len(c.ReplicaDSNs)),
+		zap.Duration("replica_check_interval", c.ReplicaCheckInterval),
 	}
 }
 
@@ -46,5 +72,41 @@ func (c *PostgresConfig) ToConfig() postgres.Config {
 		SSLMode:  c.SSLMode,
 		MinConns: c.PoolMinConns,
 		MaxConns: c.PoolMaxConns,
+
+		MaxConnLifetime:   time.Duration(c.PoolMaxConnLifetime) * time.Second,
+		MaxConnIdleTime:   time.Duration(c.PoolMaxConnIdleTime) * time.Second,
+		HealthCheckPeriod: c.PoolHealthCheckPeriod,
+		ConnectTimeout:    c.ConnectTimeout,
+		AcquireTimeout:    c.AcquireTimeout,
+		StatementTimeout:  time.Duration(c.StatementTimeout) * time.Millisecond,
+		ApplicationName:   c.ApplicationName,
+
+		ConnectRetries:       c.ConnectRetries,
+		ConnectRetryInterval: c.ConnectRetryInterval,
+
+		ReplicaDSNs:          c.ReplicaDSNs,
+		ReplicaCheckInterval: c.ReplicaCheckInterval,
+	}
+}
+
+// SQLiteConfig содержит настройки для базы данных SQLite.
+type SQLiteConfig struct {
+	Path        string        `env:"SQLITE_PATH" env-default:"./data/persons.db"`
+	BusyTimeout time.Duration `env:"SQLITE_BUSY_TIMEOUT" env-default:"5s"`
+}
+
+// LogFields реализует интерфейс LoggableConfig для SQLiteConfig.
+func (c *SQLiteConfig) LogFields() []zap.Field {
+	return []zap.Field{
+		zap.String("path", c.Path),
+		zap.Duration("busy_timeout", c.BusyTimeout),
+	}
+}
+
+// ToConfig преобразует SQLiteConfig в sqlite.Config.
+func (c *SQLiteConfig) ToConfig() sqlite.Config {
+	return sqlite.Config{
+		Path:        c.Path,
+		BusyTimeout: c.BusyTimeout,
 	}
 }
@@ -2,17 +2,48 @@
 package migration
 
 import (
+	"github.com/flexer2006/case-person-enrichment-go/migrations"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
 	"go.uber.org/zap"
 )
 
 // Config содержит настройки для миграций базы данных.
+// Пустой каталог означает миграции, встроенные в бинарный файл.
 type Config struct {
-	Path string `env:"MIGRATIONS_DIR" env-default:"./migrations"`
+	Path string `env:"MIGRATIONS_DIR"`
+	// SQLitePath - каталог миграций для хранилища SQLite.
+	SQLitePath string `env:"SQLITE_MIGRATIONS_DIR"`
+	// Auto включает применение миграций при запуске сервиса; без него схема только проверяется.
+	Auto bool `env:"MIGRATIONS_AUTO" env-default:"true"`
+}
+
+// Postgres возвращает источник миграций для PostgreSQL.
+func (c *Config) Postgres() migrate.Config {
+	return migrate.Config{Path: c.Path, FS: migrations.Postgres()}
+}
+
+// SQLite возвращает источник миграций для SQLite.
+func (c *Config) SQLite() (migrate.Config, error) {
+	assets, err := migrations.SQLite()
+	if err != nil {
+		return migrate.Config{}, err
+	}
+	return migrate.Config{Path: c.SQLitePath, FS: assets}, nil
 }
 
 // LogFields реализует интерфейс LoggableConfig для Config.
 func (c *Config) LogFields() []zap.Field {
 	return []zap.Field{
-		zap.String("path", c.Path),
+		zap.String("path", sourceName(c.Path)),
+		zap.String("sqlite_path", sourceName(c.SQLitePath)),
+		zap.Bool("auto", c.Auto),
+	}
+}
+
+// sourceName возвращает каталог миграций для журнала или пометку о встроенных миграциях.
+func sourceName(path string) string {
+	if path == "" {
+		return "embedded"
 	}
+	return path
 }
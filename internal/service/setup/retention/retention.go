@@ -0,0 +1,34 @@
+// Package retention содержит настройки очистки мягко удаленных данных.
+package retention
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Config содержит настройки фоновой очистки мягко удаленных персон.
+type Config struct {
+	// PurgeAfterDays количество дней, после которых мягко удаленная персона удаляется безвозвратно.
+	// Значение 0 отключает очистку.
+	PurgeAfterDays int           `env:"RETENTION_PURGE_AFTER_DAYS" env-default:"30"`
+	Interval       time.Duration `env:"RETENTION_PURGE_INTERVAL" env-default:"1h"`
+}
+
+// Enabled сообщает, включена ли фоновая очистка.
+func (c *Config) Enabled() bool {
+	return c.PurgeAfterDays > 0 && c.Interval > 0
+}
+
+// RetentionPeriod возвращает срок хранения мягко удаленных персон.
+func (c *Config) RetentionPeriod() time.Duration {
+	return time.Duration(c.PurgeAfterDays) * 24 * time.Hour
+}
+
+// LogFields реализует интерфейс LoggableConfig для Config.
+func (c *Config) LogFields() []zap.Field {
+	return []zap.Field{
+		zap.Int("purge_after_days", c.PurgeAfterDays),
+		zap.Duration("purge_interval", c.Interval),
+	}
+}
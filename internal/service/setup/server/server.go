@@ -13,6 +13,12 @@ type Config struct {
 	Port         int           `env:"HTTP_PORT" env-default:"8080"`
 	ReadTimeout  time.Duration `env:"HTTP_READ_TIMEOUT" env-default:"5s"`
 	WriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT" env-default:"10s"`
+	// AdminToken токен администратора, передаваемый в заголовке X-Admin-Token.
+	// Пустое значение отключает административные возможности.
+	AdminToken string `env:"HTTP_ADMIN_TOKEN"`
+	// SwaggerDir каталог со страницей Swagger UI и спецификацией API.
+	// Пустое значение означает файлы, встроенные в бинарный файл.
+	SwaggerDir string `env:"SWAGGER_DIR"`
 }
 
 // LogFields реализует интерфейс для логирования и возвращает поля конфигурации
@@ -23,5 +29,7 @@ func (c *Config) LogFields() []zap.Field {
 		zap.Int("port", c.Port),
 		zap.Duration("read_timeout", c.ReadTimeout),
 		zap.Duration("write_timeout", c.WriteTimeout),
+		zap.Bool("admin_enabled", c.AdminToken != ""),
+		zap.String("swagger_dir", c.SwaggerDir),
 	}
 }
@@ -6,17 +6,24 @@ import (
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/graceful"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/logs"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/migration"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/retention"
 	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/server"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/storage"
+	"github.com/flexer2006/case-person-enrichment-go/internal/service/setup/tenant"
 	"go.uber.org/zap"
 )
 
 // Config представляет основную конфигурацию приложения.
 type Config struct {
 	Logger     logs.Config         `env-prefix:"LOGGER_"`
+	Storage    storage.Config      `env-prefix:""`
 	Postgres   data.PostgresConfig `env-prefix:""`
+	SQLite     data.SQLiteConfig   `env-prefix:""`
 	Migrations migration.Config    `env-prefix:""`
 	Graceful   graceful.Config
 	Server     server.Config `env-prefix:""`
+	Retention  retention.Config
+	Tenants    tenant.Config `env-prefix:""`
 }
 
 // LogFields реализует интерфейс LoggableConfig и возвращает поля конфигурации
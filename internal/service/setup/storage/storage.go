@@ -0,0 +1,27 @@
+// Package storage содержит настройки выбора хранилища данных.
+package storage
+
+import (
+	"go.uber.org/zap"
+)
+
+// Поддерживаемые драйверы хранилища.
+const (
+	DriverPostgres = "postgres"
+	// DriverMemory хранит данные в памяти процесса; они теряются при перезапуске.
+	DriverMemory = "memory"
+	// DriverSQLite хранит данные в файле SQLite и подходит для небольших развертываний на одном узле.
+	DriverSQLite = "sqlite"
+)
+
+// Config содержит настройки хранилища данных.
+type Config struct {
+	Driver string `env:"STORAGE_DRIVER" env-default:"postgres"`
+}
+
+// LogFields реализует интерфейс LoggableConfig для Config.
+func (c *Config) LogFields() []zap.Field {
+	return []zap.Field{
+		zap.String("driver", c.Driver),
+	}
+}
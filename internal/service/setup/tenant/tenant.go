@@ -0,0 +1,198 @@
+// Package tenant содержит настройки арендаторов сервиса.
+package tenant
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"go.uber.org/zap"
+)
+
+// Ошибки загрузки настроек арендаторов.
+var (
+	ErrInvalidTenants = errors.New("invalid tenants configuration")
+)
+
+// Config содержит настройки разделения данных по арендаторам.
+type Config struct {
+	// File путь к JSON-файлу со списком арендаторов. Пустое значение оставляет сервис
+	// с единственным арендатором по умолчанию, которому принадлежат все запросы.
+	File string `env:"TENANTS_FILE"`
+	// TrustHeader разрешает выбирать арендатора заголовком X-Tenant-ID без API-ключа.
+	// Включается, только если запросы приходят через шлюз, который сам проверяет арендатора.
+	TrustHeader bool `env:"TENANT_TRUST_HEADER" env-default:"false"`
+}
+
+// LogFields реализует интерфейс LoggableConfig для Config.
+func (c *Config) LogFields() []zap.Field {
+	return []zap.Field{
+		zap.String("tenants_file", c.File),
+		zap.Bool("trust_tenant_header", c.TrustHeader),
+	}
+}
+
+// Tenant описывает арендатора: его API-ключи, поставщиков обогащения и квоты.
+type Tenant struct {
+	ID         string     `json:"id"`
+	APIKeys    []string   `json:"api_keys"`
+	Enrichment Enrichment `json:"enrichment"`
+	Quota      Quota      `json:"quota"`
+}
+
+// Enrichment содержит настройки поставщиков обогащения арендатора.
+type Enrichment struct {
+	Age         Provider `json:"age"`
+	Gender      Provider `json:"gender"`
+	Nationality Provider `json:"nationality"`
+}
+
+// Provider переопределяет поставщика обогащения для арендатора.
+type Provider struct {
+	// BaseURL адрес API поставщика; пустое значение означает общий адрес по умолчанию.
+	BaseURL string `json:"base_url"`
+	// Disabled отключает обогащение этим поставщиком.
+	Disabled bool `json:"disabled"`
+}
+
+// Quota содержит ограничения арендатора; нулевое значение снимает ограничение.
+type Quota struct {
+	// RequestsPerMinute ограничивает число запросов к API в минуту.
+	RequestsPerMinute int `json:"requests_per_minute"`
+	// EnrichmentsPerDay ограничивает число обогащений персон в сутки; обогащение учитывается
+	// один раз независимо от числа опрошенных поставщиков.
+	EnrichmentsPerDay int `json:"enrichments_per_day"`
+}
+
+// Registry содержит арендаторов сервиса и позволяет найти арендатора по API-ключу.
+type Registry struct {
+	tenants     map[string]*Tenant
+	keys        map[[sha256.Size]byte]*Tenant
+	ids         []string
+	trustHeader bool
+	configured  bool
+}
+
+// file описывает содержимое файла арендаторов.
+type file struct {
+	Tenants []Tenant `json:"tenants"`
+}
+
+// Load загружает арендаторов из файла конфигурации. Без файла возвращается реестр
+// с единственным арендатором по умолчанию без ключей и квот.
+func (c *Config) Load() (*Registry, error) {
+	if c.File == "" {
+		return DefaultRegistry(), nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(c.File))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+	var content file
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTenants, err)
+	}
+	if len(content.Tenants) == 0 {
+		return nil, fmt.Errorf("%w: no tenants in %s", ErrInvalidTenants, c.File)
+	}
+
+	return NewRegistry(content.Tenants, c.TrustHeader)
+}
+
+// DefaultRegistry возвращает реестр с единственным арендатором по умолчанию, которому
+// принадлежат все запросы без API-ключа.
+func DefaultRegistry() *Registry {
+	t := &Tenant{ID: tenant.Default}
+	return &Registry{
+		tenants: map[string]*Tenant{t.ID: t},
+		keys:    make(map[[sha256.Size]byte]*Tenant),
+		ids:     []string{t.ID},
+	}
+}
+
+// NewRegistry создает реестр из списка арендаторов, проверяя идентификаторы и уникальность ключей.
+// Запросы к такому реестру должны предъявлять API-ключ арендатора.
+func NewRegistry(tenants []Tenant, trustHeader bool) (*Registry, error) {
+	registry := &Registry{
+		tenants:     make(map[string]*Tenant, len(tenants)),
+		keys:        make(map[[sha256.Size]byte]*Tenant),
+		trustHeader: trustHeader,
+		configured:  true,
+	}
+	for i := range tenants {
+		t := &tenants[i]
+		if err := tenant.Validate(t.ID); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTenants, err)
+		}
+		if _, ok := registry.tenants[t.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate tenant %q", ErrInvalidTenants, t.ID)
+		}
+		if t.Quota.RequestsPerMinute < 0 || t.Quota.EnrichmentsPerDay < 0 {
+			return nil, fmt.Errorf("%w: negative quota for tenant %q", ErrInvalidTenants, t.ID)
+		}
+		for _, key := range t.APIKeys {
+			if key == "" {
+				return nil, fmt.Errorf("%w: empty API key for tenant %q", ErrInvalidTenants, t.ID)
+			}
+			// Ключи хранятся хешами, чтобы время поиска не зависело от совпадения префикса ключа.
+			hash := sha256.Sum256([]byte(key))
+			if _, ok := registry.keys[hash]; ok {
+				return nil, fmt.Errorf("%w: API key of tenant %q is already used", ErrInvalidTenants, t.ID)
+			}
+			registry.keys[hash] = t
+		}
+		registry.tenants[t.ID] = t
+		registry.ids = append(registry.ids, t.ID)
+	}
+	slices.Sort(registry.ids)
+	return registry, nil
+}
+
+// Configured сообщает, настроены ли арендаторы. Запросы к реестру без настроенных арендаторов
+// не требуют API-ключа и относятся к арендатору по умолчанию.
+func (r *Registry) Configured() bool {
+	return r.configured
+}
+
+// Isolated сообщает, есть ли арендаторы, кроме арендатора по умолчанию, данные которых
+// нужно отделять друг от друга.
+func (r *Registry) Isolated() bool {
+	return slices.ContainsFunc(r.ids, func(id string) bool { return id != tenant.Default })
+}
+
+// TrustHeader сообщает, можно ли выбирать арендатора заголовком без API-ключа.
+func (r *Registry) TrustHeader() bool {
+	return r.trustHeader
+}
+
+// Get возвращает арендатора по идентификатору.
+func (r *Registry) Get(id string) (*Tenant, bool) {
+	t, ok := r.tenants[id]
+	return t, ok
+}
+
+// ByAPIKey возвращает арендатора, которому принадлежит API-ключ.
+func (r *Registry) ByAPIKey(key string) (*Tenant, bool) {
+	t, ok := r.keys[sha256.Sum256([]byte(key))]
+	return t, ok
+}
+
+// IDs возвращает отсортированные идентификаторы арендаторов.
+func (r *Registry) IDs() []string {
+	return slices.Clone(r.ids)
+}
+
+// Tenants возвращает арендаторов в порядке идентификаторов.
+func (r *Registry) Tenants() []*Tenant {
+	tenants := make([]*Tenant, 0, len(r.ids))
+	for _, id := range r.ids {
+		tenants = append(tenants, r.tenants[id])
+	}
+	return tenants
+}
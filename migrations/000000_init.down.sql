@@ -1,3 +1,3 @@
-DROP TRIGGER IF EXISTS update_people_updated_at ON people;
+DROP TRIGGER IF EXISTS update_persons_updated_at ON persons;
 DROP FUNCTION IF EXISTS update_updated_at_column();
-DROP TABLE IF EXISTS people;
\ No newline at end of file
+DROP TABLE IF EXISTS persons;
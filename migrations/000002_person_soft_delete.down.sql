@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_persons_deleted_at;
+
+ALTER TABLE persons DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS person_history (
+    id BIGSERIAL PRIMARY KEY,
+    person_id UUID NOT NULL REFERENCES persons(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    before JSONB,
+    after JSONB,
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    actor VARCHAR(255),
+    request_id VARCHAR(64),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT person_history_person_version_key UNIQUE (person_id, version)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_persons_updated_at_id;
+DROP INDEX IF EXISTS idx_persons_nationality_probability_id;
+DROP INDEX IF EXISTS idx_persons_gender_probability_id;
+DROP INDEX IF EXISTS idx_persons_age_id;
+DROP INDEX IF EXISTS idx_persons_surname_id;
+DROP INDEX IF EXISTS idx_persons_name_id;
@@ -0,0 +1,12 @@
+-- Индексы (колонка, id) покрывают сортировку по одной колонке в обоих направлениях:
+-- идентификатор в ORDER BY следует направлению первой колонки, поэтому "-age" читается
+-- обратным проходом по (age, id). Сортировка created_at покрыта индексом из 000004.
+-- Для сортировки по нескольким колонкам (например, "surname,-age") индекс первой колонки
+-- задает начальный порядок и позицию курсора, а остальные ключи досортировываются
+-- (Incremental Sort) внутри групп с одинаковым значением первой колонки.
+CREATE INDEX IF NOT EXISTS idx_persons_name_id ON persons(name, id);
+CREATE INDEX IF NOT EXISTS idx_persons_surname_id ON persons(surname, id);
+CREATE INDEX IF NOT EXISTS idx_persons_age_id ON persons(age, id);
+CREATE INDEX IF NOT EXISTS idx_persons_gender_probability_id ON persons(gender_probability, id);
+CREATE INDEX IF NOT EXISTS idx_persons_nationality_probability_id ON persons(nationality_probability, id);
+CREATE INDEX IF NOT EXISTS idx_persons_updated_at_id ON persons(updated_at, id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_persons_patronymic_trgm;
+DROP INDEX IF EXISTS idx_persons_surname_trgm;
+DROP INDEX IF EXISTS idx_persons_name_trgm;
@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_persons_name_trgm ON persons USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_persons_surname_trgm ON persons USING GIN (surname gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_persons_patronymic_trgm ON persons USING GIN (patronymic gin_trgm_ops);
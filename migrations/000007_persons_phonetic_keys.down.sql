@@ -0,0 +1,14 @@
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_persons_surname_phonetic;
+DROP INDEX IF EXISTS idx_persons_name_phonetic;
+
+ALTER TABLE persons
+    DROP COLUMN IF EXISTS surname_phonetic,
+    DROP COLUMN IF EXISTS name_phonetic;
@@ -0,0 +1,17 @@
+ALTER TABLE persons
+    ADD COLUMN IF NOT EXISTS name_phonetic TEXT[],
+    ADD COLUMN IF NOT EXISTS surname_phonetic TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_persons_name_phonetic ON persons USING GIN (name_phonetic);
+CREATE INDEX IF NOT EXISTS idx_persons_surname_phonetic ON persons USING GIN (surname_phonetic);
+
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (to_jsonb(NEW) - 'name_phonetic' - 'surname_phonetic') IS DISTINCT FROM
+       (to_jsonb(OLD) - 'name_phonetic' - 'surname_phonetic') THEN
+        NEW.updated_at = NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS person_imports (
+    id UUID PRIMARY KEY,
+    format VARCHAR(10) NOT NULL,
+    file_name VARCHAR(255),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    enrich BOOLEAN NOT NULL DEFAULT FALSE,
+    total INTEGER NOT NULL DEFAULT 0,
+    valid INTEGER NOT NULL DEFAULT 0,
+    imported INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]',
+    actor VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+ALTER TABLE person_history
+    DROP COLUMN IF EXISTS related_ids;
+
+DROP INDEX IF EXISTS idx_persons_merged_into;
+
+ALTER TABLE persons
+    DROP COLUMN IF EXISTS merged_into;
@@ -0,0 +1,7 @@
+ALTER TABLE persons
+    ADD COLUMN IF NOT EXISTS merged_into UUID REFERENCES persons(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_persons_merged_into ON persons (merged_into) WHERE merged_into IS NOT NULL;
+
+ALTER TABLE person_history
+    ADD COLUMN IF NOT EXISTS related_ids UUID[];
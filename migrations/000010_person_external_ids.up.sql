@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS person_external_ids (
+    source VARCHAR(50) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    person_id UUID NOT NULL REFERENCES persons(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (source, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_external_ids_person_id ON person_external_ids (person_id);
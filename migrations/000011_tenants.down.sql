@@ -0,0 +1,27 @@
+DROP POLICY IF EXISTS person_external_ids_tenant_isolation ON person_external_ids;
+ALTER TABLE person_external_ids NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE person_external_ids DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS person_imports_tenant_isolation ON person_imports;
+ALTER TABLE person_imports NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE person_imports DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS person_history_tenant_isolation ON person_history;
+ALTER TABLE person_history NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE person_history DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS persons_tenant_isolation ON persons;
+ALTER TABLE persons NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE persons DISABLE ROW LEVEL SECURITY;
+
+-- Без арендаторов внешние идентификаторы снова должны быть уникальны глобально; откат
+-- не выполнится, если разные арендаторы используют один и тот же внешний идентификатор.
+ALTER TABLE person_external_ids DROP CONSTRAINT person_external_ids_pkey;
+ALTER TABLE person_external_ids ADD PRIMARY KEY (source, external_id);
+
+DROP INDEX IF EXISTS idx_persons_tenant_id;
+
+ALTER TABLE person_external_ids DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE person_imports DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE person_history DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE persons DROP COLUMN IF EXISTS tenant_id;
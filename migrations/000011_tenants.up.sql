@@ -0,0 +1,44 @@
+-- Каждая таблица с данными арендатора содержит tenant_id и политику защиты строк по параметру сессии
+-- app.tenant_id, который сервис устанавливает в каждом соединении. Существующие строки относятся
+-- к арендатору по умолчанию, новые получают арендатора из сессии.
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE person_history ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE person_imports ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE person_external_ids ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+
+ALTER TABLE persons ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant_id', true);
+ALTER TABLE person_history ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant_id', true);
+ALTER TABLE person_imports ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant_id', true);
+ALTER TABLE person_external_ids ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant_id', true);
+
+CREATE INDEX IF NOT EXISTS idx_persons_tenant_id ON persons (tenant_id);
+
+-- Внешние идентификаторы уникальны в пределах арендатора.
+ALTER TABLE person_external_ids DROP CONSTRAINT person_external_ids_pkey;
+ALTER TABLE person_external_ids ADD PRIMARY KEY (tenant_id, source, external_id);
+
+-- FORCE применяет политики и к владельцу таблиц, от имени которого обычно подключается сервис.
+-- Пустой параметр означает соединение без арендатора: такие соединения не видят строк и не могут их записать.
+ALTER TABLE persons ENABLE ROW LEVEL SECURITY;
+ALTER TABLE persons FORCE ROW LEVEL SECURITY;
+CREATE POLICY persons_tenant_isolation ON persons
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), ''))
+    WITH CHECK (tenant_id = NULLIF(current_setting('app.tenant_id', true), ''));
+
+ALTER TABLE person_history ENABLE ROW LEVEL SECURITY;
+ALTER TABLE person_history FORCE ROW LEVEL SECURITY;
+CREATE POLICY person_history_tenant_isolation ON person_history
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), ''))
+    WITH CHECK (tenant_id = NULLIF(current_setting('app.tenant_id', true), ''));
+
+ALTER TABLE person_imports ENABLE ROW LEVEL SECURITY;
+ALTER TABLE person_imports FORCE ROW LEVEL SECURITY;
+CREATE POLICY person_imports_tenant_isolation ON person_imports
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), ''))
+    WITH CHECK (tenant_id = NULLIF(current_setting('app.tenant_id', true), ''));
+
+ALTER TABLE person_external_ids ENABLE ROW LEVEL SECURITY;
+ALTER TABLE person_external_ids FORCE ROW LEVEL SECURITY;
+CREATE POLICY person_external_ids_tenant_isolation ON person_external_ids
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), ''))
+    WITH CHECK (tenant_id = NULLIF(current_setting('app.tenant_id', true), ''));
@@ -0,0 +1,29 @@
+// Package migrations встраивает файлы миграций в бинарный файл сервиса, чтобы он не зависел
+// от рабочего каталога.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed *.sql
+var postgres embed.FS
+
+//go:embed sqlite/*.sql
+var sqlite embed.FS
+
+// Postgres возвращает встроенные миграции для PostgreSQL.
+func Postgres() fs.FS {
+	return postgres
+}
+
+// SQLite возвращает встроенные миграции для SQLite.
+func SQLite() (fs.FS, error) {
+	assets, err := fs.Sub(sqlite, "sqlite")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded sqlite migrations: %w", err)
+	}
+	return assets, nil
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS person_external_ids;
+DROP TABLE IF EXISTS person_imports;
+DROP TABLE IF EXISTS person_history;
+DROP TABLE IF EXISTS persons;
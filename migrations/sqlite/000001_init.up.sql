@@ -0,0 +1,71 @@
+-- Схема SQLite повторяет схему PostgreSQL из каталога migrations.
+-- Идентификаторы хранятся как текст UUID в нижнем регистре, поэтому порядок строк совпадает с порядком uuid;
+-- время хранится в микросекундах Unix UTC, массивы и документы - в JSON.
+CREATE TABLE IF NOT EXISTS persons (
+    id TEXT PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL,
+    surname TEXT NOT NULL,
+    patronymic TEXT,
+    age INTEGER,
+    gender TEXT,
+    gender_probability REAL,
+    nationality TEXT,
+    nationality_probability REAL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at INTEGER,
+    merged_into TEXT REFERENCES persons(id) ON DELETE SET NULL,
+    name_phonetic TEXT,
+    surname_phonetic TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_persons_created_at_id ON persons(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_persons_name_id ON persons(name, id);
+CREATE INDEX IF NOT EXISTS idx_persons_surname_id ON persons(surname, id);
+CREATE INDEX IF NOT EXISTS idx_persons_age_id ON persons(age, id);
+CREATE INDEX IF NOT EXISTS idx_persons_gender_probability_id ON persons(gender_probability, id);
+CREATE INDEX IF NOT EXISTS idx_persons_nationality_probability_id ON persons(nationality_probability, id);
+CREATE INDEX IF NOT EXISTS idx_persons_updated_at_id ON persons(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_persons_deleted_at ON persons(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_persons_merged_into ON persons(merged_into) WHERE merged_into IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS person_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    person_id TEXT NOT NULL REFERENCES persons(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    operation TEXT NOT NULL,
+    before TEXT,
+    after TEXT,
+    changed_fields TEXT NOT NULL DEFAULT '[]',
+    related_ids TEXT,
+    actor TEXT,
+    request_id TEXT,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT person_history_person_version_key UNIQUE (person_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS person_imports (
+    id TEXT PRIMARY KEY NOT NULL,
+    format TEXT NOT NULL,
+    file_name TEXT,
+    dry_run INTEGER NOT NULL DEFAULT 0,
+    enrich INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    valid INTEGER NOT NULL DEFAULT 0,
+    imported INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    errors TEXT NOT NULL DEFAULT '[]',
+    actor TEXT,
+    created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS person_external_ids (
+    source TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    person_id TEXT NOT NULL REFERENCES persons(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (source, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_external_ids_person_id ON person_external_ids(person_id);
@@ -0,0 +1,31 @@
+// Package consistency предоставляет отметку о записи в рамках запроса, по которой хранилище
+// с репликами решает, можно ли читать с реплики.
+package consistency
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// writeMarkerKey - ключ контекста для отметки о записи в рамках запроса.
+type writeMarkerKey struct{}
+
+// WithReadYourWrites возвращает контекст запроса, в котором отмечаются записи в основную базу.
+// После первой записи чтения с этим контекстом выполняются на основном сервере, поэтому запрос
+// видит свои изменения, даже если реплики от него отстают.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writeMarkerKey{}, new(atomic.Bool))
+}
+
+// MarkWritten отмечает, что запрос изменил данные. Без WithReadYourWrites вызов ничего не делает.
+func MarkWritten(ctx context.Context) {
+	if written, ok := ctx.Value(writeMarkerKey{}).(*atomic.Bool); ok {
+		written.Store(true)
+	}
+}
+
+// Written сообщает, изменял ли данные запрос с контекстом ctx.
+func Written(ctx context.Context) bool {
+	written, ok := ctx.Value(writeMarkerKey{}).(*atomic.Bool)
+	return ok && written.Load()
+}
@@ -0,0 +1,23 @@
+package consistency_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/consistency"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	ctx := context.Background()
+
+	consistency.MarkWritten(ctx)
+	assert.False(t, consistency.Written(ctx), "writes are tracked only in contexts prepared by WithReadYourWrites")
+
+	requestCtx := consistency.WithReadYourWrites(ctx)
+	assert.False(t, consistency.Written(requestCtx))
+	derivedCtx, cancel := context.WithCancel(requestCtx)
+	defer cancel()
+	consistency.MarkWritten(derivedCtx)
+	assert.True(t, consistency.Written(requestCtx), "a write in a derived context marks the whole request")
+}
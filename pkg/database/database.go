@@ -74,7 +74,7 @@ func NewWithDSN(ctx context.Context, dsn string, minConn, maxConn int, migration
 
 // Pool возвращает пул соединений с базой данных.
 func (d *Database) Pool() *pgxpool.Pool {
-	return d.provider.Pool()
+	return d.provider.WritePool()
 }
 
 // Close закрывает соединение с базой данных.
@@ -100,12 +100,13 @@ func (d *Database) RollbackMigrations(ctx context.Context) error {
 	return nil
 }
 
-// GetMigrationVersion возвращает текущую версию миграции и статус "грязный".
-func (d *Database) GetMigrationVersion(ctx context.Context) (uint, bool, error) {
+// GetMigrationVersion возвращает текущую версию миграции и статус "грязный";
+// migrate.NoVersion, если миграции не применялись.
+func (d *Database) GetMigrationVersion(ctx context.Context) (int, bool, error) {
 	dsn := d.provider.GetDSN()
 	version, dirty, err := d.migrator.Version(ctx, dsn)
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+		return migrate.NoVersion, false, fmt.Errorf("failed to get migration version: %w", err)
 	}
 	return version, dirty, nil
 }
@@ -10,8 +10,9 @@ type Provider interface {
 	Up(ctx context.Context, dsn string) error
 	// Down откатывает все миграции.
 	Down(ctx context.Context, dsn string) error
-	// Version возвращает текущую версию миграции и статус "грязный".
-	Version(ctx context.Context, dsn string) (uint, bool, error)
+	// Version возвращает текущую версию миграции и статус "грязный";
+	// NoVersion, если миграции не применялись.
+	Version(ctx context.Context, dsn string) (int, bool, error)
 }
 
 // Adapter адаптирует Migrator к интерфейсу Provider.
@@ -39,6 +40,31 @@ func (a *Adapter) Down(ctx context.Context, dsn string) error {
 }
 
 // Version реализует Provider.Version.
-func (a *Adapter) Version(ctx context.Context, dsn string) (uint, bool, error) {
+func (a *Adapter) Version(ctx context.Context, dsn string) (int, bool, error) {
 	return a.migrator.Version(ctx, dsn, a.config)
 }
+
+// Steps применяет n следующих миграций или откатывает -n последних.
+func (a *Adapter) Steps(ctx context.Context, dsn string, n int) error {
+	return a.migrator.Steps(ctx, dsn, n, a.config)
+}
+
+// Goto применяет или откатывает миграции до указанной версии.
+func (a *Adapter) Goto(ctx context.Context, dsn string, version uint) error {
+	return a.migrator.Goto(ctx, dsn, version, a.config)
+}
+
+// Force устанавливает версию схемы без выполнения миграций и снимает признак "грязный".
+func (a *Adapter) Force(ctx context.Context, dsn string, version int) error {
+	return a.migrator.Force(ctx, dsn, version, a.config)
+}
+
+// Status возвращает состояние схемы относительно доступных миграций.
+func (a *Adapter) Status(ctx context.Context, dsn string) (*Status, error) {
+	return a.migrator.Status(ctx, dsn, a.config)
+}
+
+// Verify проверяет обратимость миграций на пустой базе данных.
+func (a *Adapter) Verify(ctx context.Context, dsn string, schema SchemaFunc) error {
+	return a.migrator.Verify(ctx, dsn, schema, a.config)
+}
@@ -5,12 +5,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 
 	// Импортируем драйвер для работы с Postgres.
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	// Импортируем драйвер для работы с SQLite (modernc.org/sqlite, без CGO).
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
 	// Импортируем драйвер для чтения миграций из файлов.
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"go.uber.org/zap"
@@ -19,8 +27,23 @@ import (
 // ErrMigrationPathNotSpecified ошибка, возникающая, когда путь к миграциям не указан.
 var (
 	ErrMigrationPathNotSpecified = errors.New("migration path not specified")
+	// ErrDirtyDatabase возникает, когда предыдущая миграция завершилась с ошибкой и схема
+	// требует ручного исправления и команды force.
+	ErrDirtyDatabase = errors.New("database schema is dirty")
+	// ErrDatabaseNotEmpty возникает, когда проверка миграций запущена на базе с уже примененными миграциями.
+	ErrDatabaseNotEmpty = errors.New("database already has migrations applied")
+	// ErrSchemaMismatch возникает, когда откат или повторное применение миграции не восстанавливает схему.
+	ErrSchemaMismatch = errors.New("schema mismatch")
 )
 
+// NoVersion - версия схемы, в которой не применена ни одна миграция. Совпадает с аргументом -1
+// команды force; версия 0 обозначает примененную миграцию 000000.
+const NoVersion = -1
+
+// SchemaFunc возвращает описание схемы базы данных: по строке на объект (таблицу, колонку, индекс и т.п.)
+// без таблицы версий миграций. Описания сравниваются как множества строк.
+type SchemaFunc func(ctx context.Context) ([]string, error)
+
 type MigrateInstance interface {
 	Up() error
 	Down() error
@@ -33,6 +56,9 @@ type MigrateInstance interface {
 type Config struct {
 	// Путь к файлам миграций.
 	Path string
+	// FS - файловая система с миграциями, например встроенная в бинарный файл через embed.FS.
+	// Используется, если Path не задан.
+	FS fs.FS
 }
 
 // Migrator представляет сервис для выполнения миграций базы данных.
@@ -45,14 +71,7 @@ func NewMigrator() *Migrator {
 
 // Up выполняет все доступные миграции.
 func (m *Migrator) Up(ctx context.Context, dsn string, cfg ...Config) error {
-	var path string
-	if len(cfg) > 0 && cfg[0].Path != "" {
-		path = fmt.Sprintf("file://%s", cfg[0].Path)
-	} else {
-		return ErrMigrationPathNotSpecified
-	}
-
-	migrator, err := m.createMigrator(ctx, dsn, path)
+	migrator, err := m.createMigrator(ctx, dsn, cfg)
 	if err != nil {
 		return err
 	}
@@ -70,14 +89,7 @@ func (m *Migrator) Up(ctx context.Context, dsn string, cfg ...Config) error {
 
 // Down откатывает все миграции.
 func (m *Migrator) Down(ctx context.Context, dsn string, cfg ...Config) error {
-	var path string
-	if len(cfg) > 0 && cfg[0].Path != "" {
-		path = fmt.Sprintf("file://%s", cfg[0].Path)
-	} else {
-		return ErrMigrationPathNotSpecified
-	}
-
-	migrator, err := m.createMigrator(ctx, dsn, path)
+	migrator, err := m.createMigrator(ctx, dsn, cfg)
 	if err != nil {
 		return err
 	}
@@ -94,44 +106,30 @@ func (m *Migrator) Down(ctx context.Context, dsn string, cfg ...Config) error {
 }
 
 // Version возвращает текущую версию миграции и статус "грязный".
-func (m *Migrator) Version(ctx context.Context, dsn string, cfg ...Config) (uint, bool, error) {
-	var path string
-	if len(cfg) > 0 && cfg[0].Path != "" {
-		path = fmt.Sprintf("file://%s", cfg[0].Path)
-	} else {
-		return 0, false, ErrMigrationPathNotSpecified
-	}
-
-	migrator, err := m.createMigrator(ctx, dsn, path)
+// Для базы без примененных миграций возвращается NoVersion.
+func (m *Migrator) Version(ctx context.Context, dsn string, cfg ...Config) (int, bool, error) {
+	migrator, err := m.createMigrator(ctx, dsn, cfg)
 	if err != nil {
-		return 0, false, err
+		return NoVersion, false, err
 	}
 
 	defer m.closeMigrator(ctx, migrator)
 
 	version, dirty, err := migrator.Version()
-	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
-		logger.Error(ctx, "failed to get migration version", zap.Error(err))
-		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
-	}
-
 	if errors.Is(err, migrate.ErrNilVersion) {
-		return 0, false, nil
+		return NoVersion, false, nil
+	}
+	if err != nil {
+		logger.Error(ctx, "failed to get migration version", zap.Error(err))
+		return NoVersion, false, fmt.Errorf("failed to get migration version: %w", err)
 	}
 
-	return version, dirty, nil
+	return int(version), dirty, nil
 }
 
 // Force устанавливает определенную версию миграции принудительно.
 func (m *Migrator) Force(ctx context.Context, dsn string, version int, cfg ...Config) error {
-	var path string
-	if len(cfg) > 0 && cfg[0].Path != "" {
-		path = fmt.Sprintf("file://%s", cfg[0].Path)
-	} else {
-		return ErrMigrationPathNotSpecified
-	}
-
-	migrator, err := m.createMigrator(ctx, dsn, path)
+	migrator, err := m.createMigrator(ctx, dsn, cfg)
 	if err != nil {
 		return err
 	}
@@ -146,11 +144,238 @@ func (m *Migrator) Force(ctx context.Context, dsn string, version int, cfg ...Co
 	return nil
 }
 
+// Steps применяет n следующих миграций при положительном n или откатывает -n последних при отрицательном.
+func (m *Migrator) Steps(ctx context.Context, dsn string, n int, cfg ...Config) error {
+	migrator, err := m.createMigrator(ctx, dsn, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer m.closeMigrator(ctx, migrator)
+
+	if err := migrator.Steps(n); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		logger.Error(ctx, "failed to migrate steps", zap.Error(err), zap.Int("steps", n))
+		return fmt.Errorf("failed to migrate %d steps: %w", n, err)
+	}
+
+	logger.Info(ctx, "database migrations applied", zap.Int("steps", n))
+	return nil
+}
+
+// Goto применяет или откатывает миграции до указанной версии.
+func (m *Migrator) Goto(ctx context.Context, dsn string, version uint, cfg ...Config) error {
+	migrator, err := m.createMigrator(ctx, dsn, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer m.closeMigrator(ctx, migrator)
+
+	if err := migrator.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		logger.Error(ctx, "failed to migrate to version", zap.Error(err), zap.Uint("version", version))
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+
+	logger.Info(ctx, "database migrated to version", zap.Uint("version", version))
+	return nil
+}
+
+// Verify проверяет обратимость миграций на пустой базе данных: применяет миграции по одной,
+// запоминая схему после каждой, затем откатывает их по одной, сравнивая схему с предыдущей,
+// и применяет все снова, сравнивая итоговую схему с полученной в первый раз.
+// База остается в последней версии. Проверка изменяет схему, поэтому запускать ее следует
+// только на отдельной базе.
+func (m *Migrator) Verify(ctx context.Context, dsn string, schema SchemaFunc, cfg ...Config) error {
+	migrator, err := m.createMigrator(ctx, dsn, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer m.closeMigrator(ctx, migrator)
+
+	if version, _, err := migrator.Version(); !errors.Is(err, migrate.ErrNilVersion) {
+		if err != nil {
+			return fmt.Errorf("failed to get migration version: %w", err)
+		}
+		return fmt.Errorf("%w: version %d", ErrDatabaseNotEmpty, version)
+	}
+
+	versions, err := sourceVersions(cfg)
+	if err != nil {
+		return err
+	}
+
+	// snapshots[i] - схема после применения i первых миграций.
+	snapshots := make([][]string, 0, len(versions)+1)
+	snapshot, err := schema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	snapshots = append(snapshots, snapshot)
+
+	for _, version := range versions {
+		if err := migrator.Steps(1); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", version, err)
+		}
+		if snapshot, err = schema(ctx); err != nil {
+			return fmt.Errorf("failed to read schema after migration %d: %w", version, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if err := migrator.Steps(-1); err != nil {
+			return fmt.Errorf("failed to roll back migration %d: %w", versions[i], err)
+		}
+		if snapshot, err = schema(ctx); err != nil {
+			return fmt.Errorf("failed to read schema after rolling back migration %d: %w", versions[i], err)
+		}
+		if err := compareSchema(snapshots[i], snapshot); err != nil {
+			return fmt.Errorf("rolling back migration %d does not restore the schema: %w", versions[i], err)
+		}
+	}
+
+	if err := migrator.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to reapply migrations: %w", err)
+	}
+	if snapshot, err = schema(ctx); err != nil {
+		return fmt.Errorf("failed to read schema after reapplying migrations: %w", err)
+	}
+	if err := compareSchema(snapshots[len(versions)], snapshot); err != nil {
+		return fmt.Errorf("reapplying migrations does not reproduce the schema: %w", err)
+	}
+
+	logger.Info(ctx, "database migrations verified", zap.Int("migrations", len(versions)))
+	return nil
+}
+
+// compareSchema возвращает ErrSchemaMismatch с недостающими и лишними объектами got относительно want.
+func compareSchema(want, got []string) error {
+	var missing, unexpected []string
+	for _, object := range want {
+		if !slices.Contains(got, object) {
+			missing = append(missing, object)
+		}
+	}
+	for _, object := range got {
+		if !slices.Contains(want, object) {
+			unexpected = append(unexpected, object)
+		}
+	}
+	var details []string
+	if len(missing) > 0 {
+		details = append(details, fmt.Sprintf("missing %q", missing))
+	}
+	if len(unexpected) > 0 {
+		details = append(details, fmt.Sprintf("unexpected %q", unexpected))
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrSchemaMismatch, strings.Join(details, ", "))
+}
+
+// Status описывает состояние схемы базы данных относительно доступных миграций.
+type Status struct {
+	// Version - текущая версия схемы; NoVersion, если миграции не применялись.
+	Version int `json:"version"`
+	// Dirty означает, что миграция Version завершилась с ошибкой.
+	Dirty bool `json:"dirty"`
+	// Latest - версия последней доступной миграции.
+	Latest uint `json:"latest"`
+	// Pending - количество доступных миграций новее Version.
+	Pending int `json:"pending"`
+}
+
+// Status возвращает текущую версию схемы и количество еще не примененных миграций.
+func (m *Migrator) Status(ctx context.Context, dsn string, cfg ...Config) (*Status, error) {
+	version, dirty, err := m.Version(ctx, dsn, cfg...)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := sourceVersions(cfg)
+	if err != nil {
+		logger.Error(ctx, "failed to read migrations", zap.Error(err))
+		return nil, err
+	}
+
+	status := &Status{Version: version, Dirty: dirty}
+	for _, available := range versions {
+		status.Latest = available
+		if int(available) > version {
+			status.Pending++
+		}
+	}
+	return status, nil
+}
+
+// openSource открывает источник миграций из необязательной конфигурации: каталог Path или файловую систему FS.
+func openSource(cfg []Config) (source.Driver, error) {
+	switch {
+	case len(cfg) == 0:
+		return nil, ErrMigrationPathNotSpecified
+	case cfg[0].Path != "":
+		driver, err := source.Open(fmt.Sprintf("file://%s", cfg[0].Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open migrations source: %w", err)
+		}
+		return driver, nil
+	case cfg[0].FS != nil:
+		driver, err := iofs.New(cfg[0].FS, ".")
+		if err != nil {
+			return nil, fmt.Errorf("failed to open migrations source: %w", err)
+		}
+		return driver, nil
+	default:
+		return nil, ErrMigrationPathNotSpecified
+	}
+}
+
+// sourceVersions возвращает версии доступных миграций по возрастанию.
+func sourceVersions(cfg []Config) ([]uint, error) {
+	driver, err := openSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Источник только читает файлы, поэтому ошибка закрытия не влияет на результат.
+		_ = driver.Close()
+	}()
+
+	version, err := driver.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read first migration: %w", err)
+	}
+
+	versions := []uint{version}
+	for {
+		version, err = driver.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read next migration: %w", err)
+		}
+		versions = append(versions, version)
+	}
+}
+
 // createMigrator создает новый экземпляр мигратора.
-func (m *Migrator) createMigrator(ctx context.Context, dsn string, path string) (*migrate.Migrate, error) {
-	migrator, err := migrate.New(path, dsn)
+func (m *Migrator) createMigrator(ctx context.Context, dsn string, cfg []Config) (*migrate.Migrate, error) {
+	driver, err := openSource(cfg)
+	if err != nil {
+		logger.Error(ctx, "failed to open migrations source", zap.Error(err))
+		return nil, err
+	}
+
+	migrator, err := migrate.NewWithSourceInstance("migrations", driver, dsn)
 	if err != nil {
-		logger.Error(ctx, "failed to create migration instance", zap.Error(err), zap.String("path", path))
+		_ = driver.Close()
+		logger.Error(ctx, "failed to create migration instance", zap.Error(err))
 		return nil, fmt.Errorf("failed to create migration instance: %w", err)
 	}
 	return migrator, nil
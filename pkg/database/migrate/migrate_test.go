@@ -2,10 +2,17 @@ package migrate_test
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewMigrator(t *testing.T) {
@@ -48,3 +55,162 @@ func TestNewMigrator(t *testing.T) {
 		assert.NotErrorIs(t, err, migrate.ErrMigrationPathNotSpecified)
 	})
 }
+
+// writeMigrations создает в каталоге dir миграции с заданными SQL-командами up; миграции нумеруются с 1.
+func writeMigrations(t *testing.T, dir string, ups ...string) {
+	t.Helper()
+	for i, up := range ups {
+		name := filepath.Join(dir, fmt.Sprintf("%06d_step", i+1))
+		require.NoError(t, os.WriteFile(name+".up.sql", []byte(up), 0o600))
+		require.NoError(t, os.WriteFile(name+".down.sql", []byte(fmt.Sprintf("DROP TABLE IF EXISTS t%d;", i+1)), 0o600))
+	}
+}
+
+func TestMigratorStepsGotoAndStatus(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeMigrations(t, dir, "CREATE TABLE t1 (id INTEGER);", "CREATE TABLE t2 (id INTEGER);", "CREATE TABLE t3 (id INTEGER);")
+
+	adapter := migrate.NewAdapter(migrate.Config{Path: dir})
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+
+	status, err := adapter.Status(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, &migrate.Status{Version: migrate.NoVersion, Latest: 3, Pending: 3}, status)
+
+	require.NoError(t, adapter.Steps(ctx, dsn, 2))
+	status, err = adapter.Status(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, &migrate.Status{Version: 2, Latest: 3, Pending: 1}, status)
+
+	require.NoError(t, adapter.Steps(ctx, dsn, -1))
+	version, dirty, err := adapter.Version(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.False(t, dirty)
+
+	require.NoError(t, adapter.Goto(ctx, dsn, 3))
+	require.NoError(t, adapter.Goto(ctx, dsn, 3), "migrating to the current version is not an error")
+	status, err = adapter.Status(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, &migrate.Status{Version: 3, Latest: 3}, status)
+}
+
+func TestMigratorStatusOnEmptyDatabase(t *testing.T) {
+	ctx := context.Background()
+	// Как и в migrations, первая миграция имеет версию 0.
+	migrations := fstest.MapFS{
+		"000000_init.up.sql":   {Data: []byte("CREATE TABLE t0 (id INTEGER);")},
+		"000000_init.down.sql": {Data: []byte("DROP TABLE t0;")},
+		"000001_step.up.sql":   {Data: []byte("CREATE TABLE t1 (id INTEGER);")},
+		"000001_step.down.sql": {Data: []byte("DROP TABLE t1;")},
+	}
+
+	adapter := migrate.NewAdapter(migrate.Config{FS: migrations})
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+
+	version, dirty, err := adapter.Version(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, migrate.NoVersion, version)
+	assert.False(t, dirty)
+
+	status, err := adapter.Status(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, &migrate.Status{Version: migrate.NoVersion, Latest: 1, Pending: 2}, status)
+
+	require.NoError(t, adapter.Steps(ctx, dsn, 1))
+	status, err = adapter.Status(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, &migrate.Status{Version: 0, Latest: 1, Pending: 1}, status)
+}
+
+func TestMigratorForceClearsDirtyVersion(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeMigrations(t, dir, "CREATE TABLE t1 (id INTEGER);", "CREATE TABLE t2 (id INTEGER); SELECT * FROM missing;")
+
+	adapter := migrate.NewAdapter(migrate.Config{Path: dir})
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+
+	require.Error(t, adapter.Up(ctx, dsn))
+	version, dirty, err := adapter.Version(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.True(t, dirty)
+
+	require.NoError(t, adapter.Force(ctx, dsn, 1))
+	status, err := adapter.Status(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, &migrate.Status{Version: 1, Latest: 2, Pending: 1}, status)
+}
+
+func TestMigratorReadsMigrationsFromFS(t *testing.T) {
+	ctx := context.Background()
+	migrations := fstest.MapFS{
+		"000001_step.up.sql":   {Data: []byte("CREATE TABLE t1 (id INTEGER);")},
+		"000001_step.down.sql": {Data: []byte("DROP TABLE t1;")},
+	}
+
+	adapter := migrate.NewAdapter(migrate.Config{FS: migrations})
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+
+	require.NoError(t, adapter.Up(ctx, dsn))
+	status, err := adapter.Status(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, &migrate.Status{Version: 1, Latest: 1}, status)
+}
+
+func TestMigratorPrefersPathOverFS(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeMigrations(t, dir, "CREATE TABLE t1 (id INTEGER);", "CREATE TABLE t2 (id INTEGER);")
+
+	adapter := migrate.NewAdapter(migrate.Config{Path: dir, FS: fstest.MapFS{}})
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+
+	require.NoError(t, adapter.Up(ctx, dsn))
+	version, _, err := adapter.Version(ctx, dsn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+}
+
+func TestMigratorVerify(t *testing.T) {
+	ctx := context.Background()
+
+	open := func(t *testing.T) (string, migrate.SchemaFunc) {
+		t.Helper()
+		config := sqlite.Config{Path: filepath.Join(t.TempDir(), "test.db"), BusyTimeout: time.Second}
+		db, err := sqlite.New(ctx, config)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close(ctx) })
+		return config.MigrateURL(), db.Schema
+	}
+
+	t.Run("reversible migrations", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMigrations(t, dir, "CREATE TABLE t1 (id INTEGER);", "CREATE TABLE t2 (id INTEGER); CREATE INDEX idx_t2 ON t2(id);")
+		adapter := migrate.NewAdapter(migrate.Config{Path: dir})
+		dsn, schema := open(t)
+
+		require.NoError(t, adapter.Verify(ctx, dsn, schema))
+		version, dirty, err := adapter.Version(ctx, dsn)
+		require.NoError(t, err)
+		assert.Equal(t, 2, version)
+		assert.False(t, dirty)
+
+		require.ErrorIs(t, adapter.Verify(ctx, dsn, schema), migrate.ErrDatabaseNotEmpty)
+	})
+
+	t.Run("down migration leaves objects", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMigrations(t, dir, "CREATE TABLE t1 (id INTEGER);", "CREATE TABLE t2 (id INTEGER);")
+		// Откат второй миграции удаляет не ту таблицу.
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "000002_step.down.sql"), []byte("DROP TABLE IF EXISTS people;"), 0o600))
+		dsn, schema := open(t)
+
+		err := migrate.NewAdapter(migrate.Config{Path: dir}).Verify(ctx, dsn, schema)
+		require.ErrorIs(t, err, migrate.ErrSchemaMismatch)
+		assert.ErrorContains(t, err, "rolling back migration 2")
+		assert.ErrorContains(t, err, "table t2")
+	})
+}
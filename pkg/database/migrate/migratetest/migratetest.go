@@ -0,0 +1,19 @@
+// Package migratetest содержит помощники для проверки миграций в тестах.
+package migratetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/migrate"
+	"github.com/stretchr/testify/require"
+)
+
+// RoundTrip применяет миграции source к пустой базе dsn по одной, откатывает и применяет их снова.
+// Тест завершается с ошибкой, если откат какой-либо миграции не восстанавливает предыдущую схему
+// или повторное применение дает другую схему. schema читает схему той же базы.
+func RoundTrip(t testing.TB, dsn string, source migrate.Config, schema migrate.SchemaFunc) {
+	t.Helper()
+
+	require.NoError(t, migrate.NewAdapter(source).Verify(context.Background(), dsn, schema))
+}
@@ -8,8 +8,11 @@ import (
 
 // Provider определяет интерфейс для провайдера базы данных PostgreSQL.
 type Provider interface {
-	// Pool возвращает пул соединений с базой данных.
-	Pool() *pgxpool.Pool
+	// WritePool возвращает пул соединений с основным сервером для записей и транзакций.
+	WritePool() *pgxpool.Pool
+	// ReadPool возвращает пул соединений для чтений, не требующих свежих данных:
+	// с доступной репликой или с основным сервером, если доступных реплик нет.
+	ReadPool() *pgxpool.Pool
 	// Close закрывает соединение с базой данных.
 	Close(ctx context.Context)
 	// Ping проверяет доступность базы данных.
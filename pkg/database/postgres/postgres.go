@@ -5,7 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
+	"strconv"
 	"time"
 
 	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
@@ -18,6 +18,14 @@ var (
 	ErrInvalidConfiguration = errors.New("invalid database configuration: required fields missing")
 )
 
+// Значения настроек пула, используемые, если они не заданы в конфигурации.
+const (
+	DefaultConnectTimeout    = 5 * time.Second
+	DefaultHealthCheckPeriod = 1 * time.Minute
+	// maxConnectRetryInterval ограничивает рост паузы между попытками подключения при запуске.
+	maxConnectRetryInterval = 30 * time.Second
+)
+
 // Config содержит настройки для подключения к базе данных.
 type Config struct {
 	Host     string
@@ -28,6 +36,29 @@ type Config struct {
 	SSLMode  string
 	MinConns int
 	MaxConns int
+	// MaxConnLifetime - время жизни соединения, после которого пул его закрывает.
+	MaxConnLifetime time.Duration
+	// MaxConnIdleTime - время простоя, после которого пул закрывает соединение.
+	MaxConnIdleTime time.Duration
+	// HealthCheckPeriod - период проверки простаивающих соединений пула.
+	HealthCheckPeriod time.Duration
+	// ConnectTimeout ограничивает установку одного соединения.
+	ConnectTimeout time.Duration
+	// AcquireTimeout ограничивает ожидание соединения из пула при проверке подключения на запуске.
+	// pgxpool не ограничивает получение соединения сам, поэтому в запросах его ограничивает контекст.
+	AcquireTimeout time.Duration
+	// StatementTimeout - параметр statement_timeout сессии; ноль оставляет значение сервера.
+	StatementTimeout time.Duration
+	// ApplicationName - параметр application_name сессии, видимый в pg_stat_activity.
+	ApplicationName string
+	// ConnectRetries - число повторных попыток подключения при запуске, если сервер еще недоступен.
+	ConnectRetries int
+	// ConnectRetryInterval - пауза перед первой повторной попыткой; каждая следующая пауза вдвое длиннее.
+	ConnectRetryInterval time.Duration
+	// ReplicaDSNs - строки подключения к репликам для чтения; пустой список направляет все запросы на основной сервер.
+	ReplicaDSNs []string
+	// ReplicaCheckInterval - период проверки доступности реплик.
+	ReplicaCheckInterval time.Duration
 }
 
 // Validate проверяет конфигурацию на валидность.
@@ -44,10 +75,55 @@ func (c Config) DSN() string {
 		c.User, c.Password, c.Host, c.Port, c.Database, c.SSLMode)
 }
 
+// PoolConfig возвращает настройки пула соединений с основным сервером.
+func (c Config) PoolConfig() (*pgxpool.Config, error) {
+	return c.poolConfig(c.DSN())
+}
+
+// poolConfig возвращает настройки пула соединений с сервером dsn с параметрами пула из конфигурации.
+func (c Config) poolConfig(dsn string) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database configuration: %w", err)
+	}
+
+	if c.MinConns > 0 {
+		poolCfg.MinConns = clampConns(c.MinConns)
+	}
+	if c.MaxConns > 0 {
+		poolCfg.MaxConns = clampConns(c.MaxConns)
+	}
+	if c.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+
+	poolCfg.HealthCheckPeriod = DefaultHealthCheckPeriod
+	if c.HealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = c.HealthCheckPeriod
+	}
+	poolCfg.ConnConfig.ConnectTimeout = DefaultConnectTimeout
+	if c.ConnectTimeout > 0 {
+		poolCfg.ConnConfig.ConnectTimeout = c.ConnectTimeout
+	}
+
+	if c.StatementTimeout > 0 {
+		poolCfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+	if c.ApplicationName != "" {
+		poolCfg.ConnConfig.RuntimeParams["application_name"] = c.ApplicationName
+	}
+	bindTenants(poolCfg)
+	return poolCfg, nil
+}
+
 // Database представляет соединение с PostgreSQL.
 type Database struct {
-	pool   *pgxpool.Pool
-	config Config
+	pool     *pgxpool.Pool
+	replicas *Replicas
+	config   Config
 }
 
 // New создает новое соединение с базой данных PostgreSQL.
@@ -57,52 +133,21 @@ func New(ctx context.Context, config Config) (*Database, error) {
 		return nil, err
 	}
 
-	dsn := config.DSN()
-
 	logger.Info(ctx, "connecting to postgres database",
 		zap.String("host", config.Host),
 		zap.Int("port", config.Port),
 		zap.String("database", config.Database),
 		zap.String("user", config.User))
 
-	poolCfg, err := pgxpool.ParseConfig(dsn)
+	poolCfg, err := config.PoolConfig()
 	if err != nil {
 		logger.Error(ctx, "failed to parse database configuration", zap.Error(err))
-		return nil, fmt.Errorf("failed to parse database configuration: %w", err)
-	}
-
-	if config.MinConns > 0 {
-		if config.MinConns > math.MaxInt32 {
-			logger.Warn(ctx, "MinConns value exceeds maximum allowed value, setting to max int32")
-			poolCfg.MinConns = math.MaxInt32
-		} else {
-			poolCfg.MinConns = int32(config.MinConns)
-		}
-	}
-
-	if config.MaxConns > 0 {
-		if config.MaxConns > math.MaxInt32 {
-			logger.Warn(ctx, "MaxConns value exceeds maximum allowed value, setting to max int32")
-			poolCfg.MaxConns = math.MaxInt32
-		} else {
-			poolCfg.MaxConns = int32(config.MaxConns)
-		}
+		return nil, err
 	}
 
-	poolCfg.ConnConfig.ConnectTimeout = 5 * time.Second
-
-	poolCfg.HealthCheckPeriod = 1 * time.Minute
-
-	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	pool, err := connectWithRetries(ctx, poolCfg, config)
 	if err != nil {
-		logger.Error(ctx, "failed to create connection pool", zap.Error(err))
-		return nil, fmt.Errorf("failed to create connection pool: %w", err)
-	}
-
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		logger.Error(ctx, "failed to ping database", zap.Error(err))
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, err
 	}
 
 	logger.Info(ctx, "connected to postgres database",
@@ -110,9 +155,22 @@ func New(ctx context.Context, config Config) (*Database, error) {
 		zap.Int("port", config.Port),
 		zap.String("database", config.Database))
 
+	var replicas *Replicas
+	if len(config.ReplicaDSNs) > 0 {
+		replicas, err = NewReplicas(ctx, config.ReplicaDSNs, config)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		logger.Info(ctx, "connected to read replicas",
+			zap.Int("replicas", len(config.ReplicaDSNs)),
+			zap.Int("available", replicas.Healthy()))
+	}
+
 	return &Database{
-		pool:   pool,
-		config: config,
+		pool:     pool,
+		replicas: replicas,
+		config:   config,
 	}, nil
 }
 
@@ -120,67 +178,108 @@ func New(ctx context.Context, config Config) (*Database, error) {
 func NewWithDSN(ctx context.Context, dsn string, minConn, maxConn int) (*Database, error) {
 	logger.Info(ctx, "connecting to postgres database")
 
-	poolCfg, err := pgxpool.ParseConfig(dsn)
+	config := Config{
+		MinConns: minConn,
+		MaxConns: maxConn,
+	}
+
+	poolCfg, err := config.poolConfig(dsn)
 	if err != nil {
 		logger.Error(ctx, "failed to parse database configuration", zap.Error(err))
-		return nil, fmt.Errorf("failed to parse database configuration: %w", err)
+		return nil, err
 	}
 
-	if minConn > 0 {
-		if minConn > math.MaxInt32 {
-			logger.Warn(ctx, "minConn value exceeds maximum allowed value, setting to max int32")
-			poolCfg.MinConns = math.MaxInt32
-		} else {
-			poolCfg.MinConns = int32(minConn)
-		}
+	pool, err := connect(ctx, poolCfg, 0)
+	if err != nil {
+		logger.Error(ctx, "failed to connect to database", zap.Error(err))
+		return nil, err
 	}
 
-	if maxConn > 0 {
-		if maxConn > math.MaxInt32 {
-			logger.Warn(ctx, "maxConn value exceeds maximum allowed value, setting to max int32")
-			poolCfg.MaxConns = math.MaxInt32
-		} else {
-			poolCfg.MaxConns = int32(maxConn)
+	logger.Info(ctx, "connected to postgres database")
+
+	return &Database{
+		pool:   pool,
+		config: config,
+	}, nil
+}
+
+// connectWithRetries создает пул соединений и проверяет подключение. Пока сервер недоступен,
+// попытка повторяется до config.ConnectRetries раз с удваивающейся паузой, чтобы сервис
+// дождался PostgreSQL, запускаемого одновременно с ним.
+func connectWithRetries(ctx context.Context, poolCfg *pgxpool.Config, config Config) (*pgxpool.Pool, error) {
+	delay := config.ConnectRetryInterval
+	for attempt := 0; ; attempt++ {
+		pool, err := connect(ctx, poolCfg, config.AcquireTimeout)
+		if err == nil {
+			return pool, nil
+		}
+		if attempt >= config.ConnectRetries || delay <= 0 {
+			logger.Error(ctx, "failed to connect to database", zap.Error(err), zap.Int("attempts", attempt+1))
+			return nil, err
 		}
-	}
 
-	poolCfg.ConnConfig.ConnectTimeout = 5 * time.Second
+		logger.Warn(ctx, "database is not available, retrying",
+			zap.Error(err),
+			zap.Int("attempt", attempt+1),
+			zap.Int("retries", config.ConnectRetries),
+			zap.Duration("delay", delay))
 
-	poolCfg.HealthCheckPeriod = 1 * time.Minute
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to connect to database: %w", errors.Join(err, ctx.Err()))
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxConnectRetryInterval)
+	}
+}
 
+// connect создает пул соединений и проверяет подключение, ожидая соединение не дольше acquireTimeout.
+func connect(ctx context.Context, poolCfg *pgxpool.Config, acquireTimeout time.Duration) (*pgxpool.Pool, error) {
 	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
-		logger.Error(ctx, "failed to create connection pool", zap.Error(err))
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
+	pingCtx := ctx
+	if acquireTimeout > 0 {
+		var cancel context.CancelFunc
+		pingCtx, cancel = context.WithTimeout(ctx, acquireTimeout)
+		defer cancel()
+	}
+	if err := pool.Ping(pingCtx); err != nil {
 		pool.Close()
-		logger.Error(ctx, "failed to ping database", zap.Error(err))
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	return pool, nil
+}
 
-	logger.Info(ctx, "connected to postgres database")
-
-	config := Config{
-		MinConns: minConn,
-		MaxConns: maxConn,
-	}
+// Pool возвращает пул соединений с основным сервером.
+func (db *Database) Pool() *pgxpool.Pool {
+	return db.pool
+}
 
-	return &Database{
-		pool:   pool,
-		config: config,
-	}, nil
+// WritePool возвращает пул соединений с основным сервером, через который выполняются записи.
+func (db *Database) WritePool() *pgxpool.Pool {
+	return db.pool
 }
 
-// Pool возвращает пул соединений с базой данных.
-func (db *Database) Pool() *pgxpool.Pool {
+// ReadPool возвращает пул соединений с доступной репликой, а если реплик нет или все они
+// недоступны - с основным сервером.
+func (db *Database) ReadPool() *pgxpool.Pool {
+	if db.replicas != nil {
+		if pool := db.replicas.Pick(); pool != nil {
+			return pool
+		}
+	}
 	return db.pool
 }
 
 // Close закрывает соединение с базой данных.
 func (db *Database) Close(ctx context.Context) {
 	logger.Info(ctx, "closing postgres database connection")
+	if db.replicas != nil {
+		db.replicas.Close()
+	}
 	db.pool.Close()
 }
 
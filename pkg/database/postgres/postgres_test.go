@@ -1354,3 +1354,87 @@ func TestErrorHandling(t *testing.T) {
 		assert.Contains(t, err.Error(), "context")
 	})
 }
+
+func TestConfig_PoolConfig(t *testing.T) {
+	base := postgres.Config{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "postgres",
+		Password: "secret",
+		Database: "testdb",
+		SSLMode:  "disable",
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		poolCfg, err := base.PoolConfig()
+		require.NoError(t, err)
+
+		assert.Equal(t, postgres.DefaultConnectTimeout, poolCfg.ConnConfig.ConnectTimeout)
+		assert.Equal(t, postgres.DefaultHealthCheckPeriod, poolCfg.HealthCheckPeriod)
+		assert.NotContains(t, poolCfg.ConnConfig.RuntimeParams, "statement_timeout")
+		assert.NotContains(t, poolCfg.ConnConfig.RuntimeParams, "application_name")
+	})
+
+	t.Run("All settings", func(t *testing.T) {
+		config := base
+		config.MinConns = 3
+		config.MaxConns = 7
+		config.MaxConnLifetime = time.Hour
+		config.MaxConnIdleTime = 10 * time.Minute
+		config.HealthCheckPeriod = 30 * time.Second
+		config.ConnectTimeout = 10 * time.Second
+		config.StatementTimeout = time.Minute
+		config.ApplicationName = "person-enrichment-service"
+
+		poolCfg, err := config.PoolConfig()
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(3), poolCfg.MinConns)
+		assert.Equal(t, int32(7), poolCfg.MaxConns)
+		assert.Equal(t, time.Hour, poolCfg.MaxConnLifetime)
+		assert.Equal(t, 10*time.Minute, poolCfg.MaxConnIdleTime)
+		assert.Equal(t, 30*time.Second, poolCfg.HealthCheckPeriod)
+		assert.Equal(t, 10*time.Second, poolCfg.ConnConfig.ConnectTimeout)
+		assert.Equal(t, "60000", poolCfg.ConnConfig.RuntimeParams["statement_timeout"])
+		assert.Equal(t, "person-enrichment-service", poolCfg.ConnConfig.RuntimeParams["application_name"])
+	})
+}
+
+func TestNew_ConnectRetries(t *testing.T) {
+	// Порт 1 закрыт, поэтому каждая попытка подключения сразу завершается ошибкой.
+	config := postgres.Config{
+		Host:     "127.0.0.1",
+		Port:     1,
+		User:     "postgres",
+		Password: "postgres",
+		Database: "postgres",
+		SSLMode:  "disable",
+	}
+
+	t.Run("Retries with backoff", func(t *testing.T) {
+		config := config
+		config.ConnectRetries = 2
+		config.ConnectRetryInterval = 20 * time.Millisecond
+
+		start := time.Now()
+		db, err := postgres.New(context.Background(), config)
+		require.Error(t, err)
+		assert.Nil(t, db)
+		// Паузы 20 и 40 мс перед двумя повторными попытками.
+		assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+	})
+
+	t.Run("Stops when context is done", func(t *testing.T) {
+		config := config
+		config.ConnectRetries = 10
+		config.ConnectRetryInterval = time.Minute
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := postgres.New(ctx, config)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
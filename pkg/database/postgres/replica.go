@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// DefaultReplicaCheckInterval - период проверки доступности реплик, если он не задан в конфигурации.
+const DefaultReplicaCheckInterval = 5 * time.Second
+
+// replica - пул соединений с одной репликой и результат последней проверки ее доступности.
+type replica struct {
+	pool    *pgxpool.Pool
+	host    string
+	healthy atomic.Bool
+}
+
+// Replicas распределяет чтения между репликами для чтения по кругу, пропуская недоступные.
+// Доступность реплик проверяется в фоне с заданным периодом.
+type Replicas struct {
+	replicas []*replica
+	next     atomic.Uint64
+	cancel   context.CancelFunc
+	done     sync.WaitGroup
+}
+
+// NewReplicas создает пулы соединений с репликами по их DSN с параметрами пула из config
+// и запускает проверку доступности с периодом config.ReplicaCheckInterval.
+// Недоступная при запуске реплика не считается ошибкой: она используется, когда станет доступна.
+func NewReplicas(ctx context.Context, dsns []string, config Config) (*Replicas, error) {
+	checkInterval := config.ReplicaCheckInterval
+	if checkInterval <= 0 {
+		checkInterval = DefaultReplicaCheckInterval
+	}
+
+	set := &Replicas{}
+	for _, dsn := range dsns {
+		poolCfg, err := config.poolConfig(dsn)
+		if err != nil {
+			set.closePools()
+			logger.Error(ctx, "failed to parse replica configuration", zap.Error(err))
+			return nil, fmt.Errorf("failed to parse replica configuration: %w", err)
+		}
+
+		pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+		if err != nil {
+			set.closePools()
+			logger.Error(ctx, "failed to create replica connection pool", zap.Error(err))
+			return nil, fmt.Errorf("failed to create replica connection pool: %w", err)
+		}
+		set.replicas = append(set.replicas, &replica{pool: pool, host: poolCfg.ConnConfig.Host})
+	}
+
+	set.check(ctx, checkInterval)
+
+	// Проверка живет до Close и не зависит от отмены контекста запуска.
+	checkCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	set.cancel = cancel
+	set.done.Add(1)
+	go set.run(checkCtx, checkInterval)
+
+	return set, nil
+}
+
+// Pick возвращает пул очередной доступной реплики или nil, если доступных реплик нет.
+func (s *Replicas) Pick() *pgxpool.Pool {
+	count := uint64(len(s.replicas))
+	if count == 0 {
+		return nil
+	}
+
+	start := s.next.Add(1)
+	for i := range count {
+		r := s.replicas[(start+i)%count]
+		if r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return nil
+}
+
+// Healthy возвращает количество реплик, доступных по результатам последней проверки.
+func (s *Replicas) Healthy() int {
+	healthy := 0
+	for _, r := range s.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// Close останавливает проверку доступности и закрывает пулы соединений с репликами.
+func (s *Replicas) Close() {
+	s.cancel()
+	s.done.Wait()
+	s.closePools()
+}
+
+// closePools закрывает пулы соединений с репликами.
+func (s *Replicas) closePools() {
+	for _, r := range s.replicas {
+		r.pool.Close()
+	}
+}
+
+// run проверяет доступность реплик с периодом interval до отмены ctx.
+func (s *Replicas) run(ctx context.Context, interval time.Duration) {
+	defer s.done.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(ctx, interval)
+		}
+	}
+}
+
+// check проверяет доступность каждой реплики и записывает в лог изменения ее состояния.
+func (s *Replicas) check(ctx context.Context, timeout time.Duration) {
+	for _, r := range s.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := r.pool.Ping(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			logger.Info(ctx, "read replica is available", zap.String("host", r.host))
+		} else {
+			logger.Warn(ctx, "read replica is unavailable, reads fall back to other replicas or primary",
+				zap.String("host", r.host),
+				zap.Error(err))
+		}
+	}
+}
+
+// clampConns приводит положительное количество соединений к типу настроек пула.
+func clampConns(conns int) int32 {
+	if conns > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(conns)
+}
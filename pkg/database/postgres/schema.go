@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// schemaQuery описывает объекты текущей схемы: колонки, индексы, ограничения, триггеры, функции,
+// политики и включенную защиту на уровне строк.
+// Таблица версий golang-migrate и объекты расширений не включаются: расширения остаются в базе
+// после отката миграций, которые их создали.
+const schemaQuery = `
+    SELECT 'column ' || c.table_name || '.' || c.column_name || ' ' || c.data_type
+           || CASE WHEN c.is_nullable = 'NO' THEN ' not null' ELSE '' END
+           || COALESCE(' default ' || c.column_default, '')
+    FROM information_schema.columns c
+    WHERE c.table_schema = current_schema() AND c.table_name <> 'schema_migrations'
+    UNION ALL
+    SELECT 'index ' || i.indexname || ': ' || i.indexdef
+    FROM pg_indexes i
+    WHERE i.schemaname = current_schema() AND i.tablename <> 'schema_migrations'
+    UNION ALL
+    SELECT 'constraint ' || r.relname || '.' || con.conname || ': ' || pg_get_constraintdef(con.oid)
+    FROM pg_constraint con
+    JOIN pg_class r ON r.oid = con.conrelid
+    JOIN pg_namespace n ON n.oid = r.relnamespace
+    WHERE n.nspname = current_schema() AND r.relname <> 'schema_migrations'
+    UNION ALL
+    SELECT 'trigger ' || t.tgname || ': ' || pg_get_triggerdef(t.oid)
+    FROM pg_trigger t
+    JOIN pg_class r ON r.oid = t.tgrelid
+    JOIN pg_namespace n ON n.oid = r.relnamespace
+    WHERE n.nspname = current_schema() AND NOT t.tgisinternal
+    UNION ALL
+    SELECT 'function ' || p.proname || ': ' || pg_get_functiondef(p.oid)
+    FROM pg_proc p
+    JOIN pg_namespace n ON n.oid = p.pronamespace
+    WHERE n.nspname = current_schema() AND p.prokind IN ('f', 'p')
+      AND NOT EXISTS (
+          SELECT 1 FROM pg_depend d
+          WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+      )
+    UNION ALL
+    SELECT 'policy ' || p.tablename || '.' || p.policyname || ': ' || p.cmd
+           || COALESCE(' using ' || p.qual, '') || COALESCE(' check ' || p.with_check, '')
+    FROM pg_policies p
+    WHERE p.schemaname = current_schema()
+    UNION ALL
+    SELECT 'row security ' || r.relname || CASE WHEN r.relforcerowsecurity THEN ' forced' ELSE '' END
+    FROM pg_class r
+    JOIN pg_namespace n ON n.oid = r.relnamespace
+    WHERE n.nspname = current_schema() AND r.relrowsecurity
+    ORDER BY 1`
+
+// Schema возвращает описание текущей схемы базы данных для сравнения схем: по строке на каждую
+// колонку, индекс, ограничение, триггер, функцию, политику и таблицу с защитой на уровне строк.
+func (db *Database) Schema(ctx context.Context) ([]string, error) {
+	rows, err := db.pool.Query(ctx, schemaQuery)
+	if err != nil {
+		logger.Error(ctx, "failed to read database schema", zap.Error(err))
+		return nil, fmt.Errorf("failed to read database schema: %w", err)
+	}
+	objects, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		logger.Error(ctx, "failed to read database schema", zap.Error(err))
+		return nil, fmt.Errorf("failed to read database schema: %w", err)
+	}
+	return objects, nil
+}
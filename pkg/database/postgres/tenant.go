@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// TenantSetting - параметр сессии с идентификатором арендатора, по которому политики защиты строк
+// отбирают строки таблиц. Пул устанавливает его из контекста при каждой выдаче соединения,
+// поэтому запросы и транзакции видят только строки арендатора из своего контекста.
+const TenantSetting = "app.tenant_id"
+
+// tenantSessions запоминает арендатора, установленного в сессии каждого соединения пула,
+// чтобы не устанавливать параметр заново, пока соединение выдается запросам того же арендатора.
+type tenantSessions struct {
+	mu      sync.Mutex
+	tenants map[*pgx.Conn]string
+}
+
+// bindTenants настраивает пул на установку арендатора из контекста в сессии выдаваемых соединений.
+func bindTenants(poolCfg *pgxpool.Config) {
+	sessions := &tenantSessions{tenants: make(map[*pgx.Conn]string)}
+	poolCfg.BeforeAcquire = sessions.beforeAcquire
+	poolCfg.BeforeClose = sessions.forget
+}
+
+// beforeAcquire устанавливает в сессии соединения арендатора из контекста запроса. Без арендатора
+// в контексте параметр очищается, и политики защиты строк не пропускают ни чтения, ни записи.
+// Соединение, в котором параметр установить не удалось, пул закрывает и выдает другое.
+func (s *tenantSessions) beforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	id, _ := tenant.ID(ctx)
+
+	s.mu.Lock()
+	current, ok := s.tenants[conn]
+	s.mu.Unlock()
+	if ok && current == id {
+		return true
+	}
+
+	if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", TenantSetting, id); err != nil {
+		logger.Warn(ctx, "failed to set connection tenant", zap.String("tenant", id), zap.Error(err))
+		s.forget(conn)
+		return false
+	}
+
+	s.mu.Lock()
+	s.tenants[conn] = id
+	s.mu.Unlock()
+	return true
+}
+
+// forget удаляет сведения о закрываемом соединении.
+func (s *tenantSessions) forget(conn *pgx.Conn) {
+	s.mu.Lock()
+	delete(s.tenants, conn)
+	s.mu.Unlock()
+}
+
+// ForTenant возвращает q, если в контексте задан арендатор. Без арендатора возвращается Querier,
+// каждый запрос которого завершается ошибкой tenant.ErrMissing: политики защиты строк вернули бы
+// пустой результат, и забытый в контексте арендатор остался бы незамеченным.
+func ForTenant(ctx context.Context, q Querier) Querier {
+	if _, ok := tenant.ID(ctx); ok {
+		return q
+	}
+	return missingTenant{}
+}
+
+// RequireTenant возвращает tenant.ErrMissing, если в контексте не задан арендатор.
+func RequireTenant(ctx context.Context) error {
+	if _, ok := tenant.ID(ctx); !ok {
+		return tenant.ErrMissing
+	}
+	return nil
+}
+
+// missingTenant реализует Querier, отклоняющий все запросы без арендатора.
+type missingTenant struct{}
+
+// Exec возвращает tenant.ErrMissing.
+func (missingTenant) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, tenant.ErrMissing
+}
+
+// Query возвращает tenant.ErrMissing.
+func (missingTenant) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, tenant.ErrMissing
+}
+
+// QueryRow возвращает строку, сканирование которой завершается ошибкой tenant.ErrMissing.
+func (missingTenant) QueryRow(context.Context, string, ...any) pgx.Row {
+	return missingTenantRow{}
+}
+
+// SendBatch возвращает результаты пакета, каждый из которых завершается ошибкой tenant.ErrMissing.
+func (missingTenant) SendBatch(context.Context, *pgx.Batch) pgx.BatchResults {
+	return missingTenantBatch{}
+}
+
+// Begin возвращает tenant.ErrMissing.
+func (missingTenant) Begin(context.Context) (pgx.Tx, error) {
+	return nil, tenant.ErrMissing
+}
+
+// missingTenantRow реализует pgx.Row для запроса без арендатора.
+type missingTenantRow struct{}
+
+// Scan возвращает tenant.ErrMissing.
+func (missingTenantRow) Scan(...any) error {
+	return tenant.ErrMissing
+}
+
+// missingTenantBatch реализует pgx.BatchResults для пакета без арендатора.
+type missingTenantBatch struct{}
+
+// Exec возвращает tenant.ErrMissing.
+func (missingTenantBatch) Exec() (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, tenant.ErrMissing
+}
+
+// Query возвращает tenant.ErrMissing.
+func (missingTenantBatch) Query() (pgx.Rows, error) {
+	return nil, tenant.ErrMissing
+}
+
+// QueryRow возвращает строку, сканирование которой завершается ошибкой tenant.ErrMissing.
+func (missingTenantBatch) QueryRow() pgx.Row {
+	return missingTenantRow{}
+}
+
+// Close возвращает tenant.ErrMissing.
+func (missingTenantBatch) Close() error {
+	return tenant.ErrMissing
+}
+
+// BypassesRowSecurity сообщает, обходит ли роль подключения политики защиты строк.
+// Суперпользователь и роль с атрибутом BYPASSRLS видят строки всех арендаторов.
+func (db *Database) BypassesRowSecurity(ctx context.Context) (bool, error) {
+	var bypass bool
+	err := db.pool.QueryRow(ctx,
+		`SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypass)
+	if err != nil {
+		return false, fmt.Errorf("failed to check database role: %w", err)
+	}
+	return bypass, nil
+}
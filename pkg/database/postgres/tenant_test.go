@@ -0,0 +1,35 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForTenant(t *testing.T) {
+	t.Run("rejects queries without tenant", func(t *testing.T) {
+		ctx := context.Background()
+		querier := postgres.ForTenant(ctx, nil)
+
+		_, err := querier.Exec(ctx, "DELETE FROM persons")
+		require.ErrorIs(t, err, tenant.ErrMissing)
+		_, err = querier.Query(ctx, "SELECT id FROM persons")
+		require.ErrorIs(t, err, tenant.ErrMissing)
+		require.ErrorIs(t, querier.QueryRow(ctx, "SELECT id FROM persons").Scan(), tenant.ErrMissing)
+		require.ErrorIs(t, querier.SendBatch(ctx, &pgx.Batch{}).Close(), tenant.ErrMissing)
+		_, err = querier.Begin(ctx)
+		require.ErrorIs(t, err, tenant.ErrMissing)
+		require.ErrorIs(t, postgres.RequireTenant(ctx), tenant.ErrMissing)
+	})
+
+	t.Run("passes queries with tenant", func(t *testing.T) {
+		ctx := tenant.WithID(context.Background(), "acme")
+		assert.Nil(t, postgres.ForTenant(ctx, nil), "the querier is returned unchanged")
+		assert.NoError(t, postgres.RequireTenant(ctx))
+	})
+}
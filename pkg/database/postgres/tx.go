@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/consistency"
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// Параметры повторов транзакций, прерванных конфликтом сериализации.
+const (
+	// MaxTxAttempts - максимальное число попыток выполнения транзакции.
+	MaxTxAttempts = 5
+	// txRetryBaseDelay - пауза перед второй попыткой; каждая следующая пауза вдвое длиннее.
+	txRetryBaseDelay = 10 * time.Millisecond
+)
+
+// Коды ошибок PostgreSQL, после которых транзакцию можно безопасно повторить.
+const (
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// Querier определяет методы выполнения запросов, общие для пула соединений и транзакции.
+// Репозитории выполняют запросы через Querier, поэтому одинаково работают и вне транзакции, и внутри нее.
+type Querier interface {
+	// Exec выполняет запрос без результата.
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	// Query выполняет запрос и возвращает строки результата.
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	// QueryRow выполняет запрос, возвращающий не более одной строки.
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	// SendBatch отправляет пакет запросов.
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	// Begin начинает транзакцию, а внутри транзакции - вложенную транзакцию на точке сохранения.
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Проверка реализации интерфейса.
+var _ Querier = pgx.Tx(nil)
+
+// TxBeginner начинает транзакции с заданными параметрами; его реализует *pgxpool.Pool.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// IsRetryable сообщает, прервана ли транзакция конфликтом сериализации или взаимной блокировкой,
+// после которых ее можно выполнить повторно.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == codeSerializationFailure || pgErr.Code == codeDeadlockDetected
+}
+
+// RunInTx выполняет fn в транзакции и фиксирует ее, если fn завершилась без ошибки.
+// Транзакция, прерванная конфликтом сериализации или взаимной блокировкой, повторяется целиком
+// до MaxTxAttempts раз с растущей паузой, поэтому fn не должна иметь побочных эффектов вне транзакции.
+func RunInTx(ctx context.Context, db TxBeginner, options pgx.TxOptions, fn func(tx pgx.Tx) error) error {
+	delay := txRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := runInTxOnce(ctx, db, options, fn)
+		if err == nil || !IsRetryable(err) || attempt == MaxTxAttempts {
+			return err
+		}
+
+		logger.Debug(ctx, "retrying transaction after conflict",
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction retry canceled: %w", errors.Join(ctx.Err(), err))
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// runInTxOnce выполняет одну попытку транзакции RunInTx.
+func runInTxOnce(ctx context.Context, db TxBeginner, options pgx.TxOptions, fn func(tx pgx.Tx) error) error {
+	tx, err := db.BeginTx(ctx, options)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// После успешной фиксации Rollback возвращает pgx.ErrTxClosed, который не является ошибкой.
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn(ctx, "failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if options.AccessMode != pgx.ReadOnly {
+		consistency.MarkWritten(ctx)
+	}
+	return nil
+}
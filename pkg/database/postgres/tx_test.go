@@ -0,0 +1,142 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTx фиксирует вызовы Commit и Rollback; остальные методы pgx.Tx в тестах не используются.
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Commit(_ context.Context) error {
+	if t.commitErr != nil {
+		return t.commitErr
+	}
+	t.committed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback(_ context.Context) error {
+	if t.committed {
+		return pgx.ErrTxClosed
+	}
+	t.rolledBack = true
+	return nil
+}
+
+// fakeBeginner выдает транзакции по очереди и запоминает параметры последней.
+type fakeBeginner struct {
+	txs     []*fakeTx
+	begun   int
+	options pgx.TxOptions
+}
+
+func (b *fakeBeginner) BeginTx(_ context.Context, options pgx.TxOptions) (pgx.Tx, error) {
+	b.options = options
+	tx := &fakeTx{}
+	if b.begun < len(b.txs) {
+		tx = b.txs[b.begun]
+	}
+	b.begun++
+	return tx, nil
+}
+
+func serializationFailure() error {
+	return fmt.Errorf("failed to update person: %w", &pgconn.PgError{Code: "40001"})
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, postgres.IsRetryable(serializationFailure()))
+	assert.True(t, postgres.IsRetryable(&pgconn.PgError{Code: "40P01"}))
+	assert.False(t, postgres.IsRetryable(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, postgres.IsRetryable(errors.New("serialization failure")))
+	assert.False(t, postgres.IsRetryable(nil))
+}
+
+func TestRunInTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commits on success", func(t *testing.T) {
+		tx := &fakeTx{}
+		db := &fakeBeginner{txs: []*fakeTx{tx}}
+		options := pgx.TxOptions{IsoLevel: pgx.RepeatableRead}
+
+		err := postgres.RunInTx(ctx, db, options, func(pgx.Tx) error { return nil })
+
+		require.NoError(t, err)
+		assert.True(t, tx.committed)
+		assert.Equal(t, options, db.options)
+	})
+
+	t.Run("rolls back and returns error of fn", func(t *testing.T) {
+		tx := &fakeTx{}
+		db := &fakeBeginner{txs: []*fakeTx{tx}}
+		errFn := errors.New("fn failed")
+
+		err := postgres.RunInTx(ctx, db, pgx.TxOptions{}, func(pgx.Tx) error { return errFn })
+
+		require.ErrorIs(t, err, errFn)
+		assert.True(t, tx.rolledBack)
+		assert.Equal(t, 1, db.begun)
+	})
+
+	t.Run("retries serialization failures", func(t *testing.T) {
+		db := &fakeBeginner{}
+		calls := 0
+
+		err := postgres.RunInTx(ctx, db, pgx.TxOptions{}, func(pgx.Tx) error {
+			calls++
+			if calls < 3 {
+				return serializationFailure()
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, 3, db.begun)
+	})
+
+	t.Run("retries failed commit", func(t *testing.T) {
+		db := &fakeBeginner{txs: []*fakeTx{{commitErr: &pgconn.PgError{Code: "40001"}}}}
+
+		err := postgres.RunInTx(ctx, db, pgx.TxOptions{}, func(pgx.Tx) error { return nil })
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, db.begun)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		db := &fakeBeginner{}
+
+		err := postgres.RunInTx(ctx, db, pgx.TxOptions{}, func(pgx.Tx) error { return serializationFailure() })
+
+		require.Error(t, err)
+		assert.True(t, postgres.IsRetryable(err))
+		assert.Equal(t, postgres.MaxTxAttempts, db.begun)
+	})
+
+	t.Run("stops retrying when context is canceled", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		db := &fakeBeginner{}
+
+		err := postgres.RunInTx(canceled, db, pgx.TxOptions{}, func(pgx.Tx) error { return serializationFailure() })
+
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, db.begun)
+	})
+}
@@ -0,0 +1,18 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Provider определяет интерфейс для провайдера базы данных SQLite.
+type Provider interface {
+	// DB возвращает пул соединений с базой данных.
+	DB() *sql.DB
+	// Close закрывает соединение с базой данных.
+	Close(ctx context.Context)
+	// Ping проверяет доступность базы данных.
+	Ping(ctx context.Context) error
+	// GetDSN возвращает строку подключения для миграций.
+	GetDSN() string
+}
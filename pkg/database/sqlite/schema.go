@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Schema возвращает описание схемы базы данных для сравнения схем: по строке с SQL-определением
+// на каждую таблицу, индекс, представление и триггер. Служебные объекты SQLite и таблица
+// версий golang-migrate не включаются.
+func (d *Database) Schema(ctx context.Context) ([]string, error) {
+	rows, err := d.db.QueryContext(ctx, `
+        SELECT type || ' ' || name || ': ' || COALESCE(sql, '')
+        FROM sqlite_master
+        WHERE name NOT LIKE 'sqlite_%' AND tbl_name <> 'schema_migrations'
+        ORDER BY type, name`)
+	if err != nil {
+		logger.Error(ctx, "failed to read database schema", zap.Error(err))
+		return nil, fmt.Errorf("failed to read database schema: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn(ctx, "failed to close rows", zap.Error(err))
+		}
+	}()
+
+	objects := make([]string, 0)
+	for rows.Next() {
+		var object string
+		if err := rows.Scan(&object); err != nil {
+			return nil, fmt.Errorf("failed to scan database schema: %w", err)
+		}
+		objects = append(objects, object)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read database schema: %w", rows.Err())
+	}
+	return objects, nil
+}
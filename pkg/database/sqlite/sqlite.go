@@ -0,0 +1,146 @@
+// Package sqlite предоставляет функциональность для работы с SQLite через драйвер
+// modernc.org/sqlite, написанный на чистом Go и не требующий CGO.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// DriverName - имя драйвера database/sql, под которым зарегистрирован modernc.org/sqlite.
+const DriverName = "sqlite"
+
+// ErrInvalidConfiguration ошибка, возникающая при неверной конфигурации базы данных.
+var (
+	ErrInvalidConfiguration = errors.New("invalid sqlite configuration: database path is required")
+)
+
+// Config содержит настройки для подключения к базе данных SQLite.
+type Config struct {
+	// Path путь к файлу базы данных; файл создается при первом подключении.
+	Path string
+	// BusyTimeout время ожидания блокировки базы другим соединением.
+	BusyTimeout time.Duration
+}
+
+// Validate проверяет конфигурацию на валидность.
+func (c Config) Validate() error {
+	// База в памяти существует только в одном соединении, поэтому миграции, выполняемые
+	// отдельным соединением, ее не увидят.
+	if c.Path == "" || c.Path == ":memory:" {
+		return ErrInvalidConfiguration
+	}
+	return nil
+}
+
+// DSN возвращает строку подключения к базе данных для драйвера modernc.org/sqlite.
+// Включаются внешние ключи и журнал WAL, а транзакции сразу берут блокировку записи
+// (BEGIN IMMEDIATE), чтобы параллельные транзакции ожидали друг друга в пределах BusyTimeout,
+// а не завершались ошибкой SQLITE_BUSY при повышении блокировки.
+func (c Config) DSN() string {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", c.BusyTimeout.Milliseconds()))
+	query.Set("_txlock", "immediate")
+	return c.Path + "?" + query.Encode()
+}
+
+// MigrateURL возвращает строку подключения для golang-migrate, который выбирает драйвер по схеме.
+func (c Config) MigrateURL() string {
+	return "sqlite://" + c.DSN()
+}
+
+// Database представляет соединение с SQLite.
+type Database struct {
+	db     *sql.DB
+	config Config
+}
+
+// New открывает базу данных SQLite и проверяет подключение.
+func New(ctx context.Context, config Config) (*Database, error) {
+	if err := config.Validate(); err != nil {
+		logger.Error(ctx, "invalid database configuration", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info(ctx, "opening sqlite database", zap.String("path", config.Path))
+
+	// SQLite создает файл базы, но не каталог, в котором он лежит.
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0o750); err != nil {
+		logger.Error(ctx, "failed to create sqlite database directory", zap.Error(err))
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := sql.Open(DriverName, config.DSN())
+	if err != nil {
+		logger.Error(ctx, "failed to open sqlite database", zap.Error(err))
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			logger.Warn(ctx, "failed to close sqlite database", zap.Error(closeErr))
+		}
+		logger.Error(ctx, "failed to ping database", zap.Error(err))
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	logger.Info(ctx, "opened sqlite database", zap.String("path", config.Path))
+
+	return &Database{
+		db:     db,
+		config: config,
+	}, nil
+}
+
+// DB возвращает пул соединений с базой данных.
+func (d *Database) DB() *sql.DB {
+	return d.db
+}
+
+// Close закрывает соединение с базой данных.
+func (d *Database) Close(ctx context.Context) {
+	logger.Info(ctx, "closing sqlite database connection")
+	if err := d.db.Close(); err != nil {
+		logger.Warn(ctx, "failed to close sqlite database", zap.Error(err))
+	}
+}
+
+// Ping проверяет доступность базы данных.
+func (d *Database) Ping(ctx context.Context) error {
+	if err := d.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// Config возвращает конфигурацию базы данных.
+func (d *Database) Config() Config {
+	return d.config
+}
+
+// GetDSN возвращает строку подключения для миграций.
+func (d *Database) GetDSN() string {
+	return d.config.MigrateURL()
+}
+
+// IsUniqueViolation сообщает, нарушено ли ограничение первичного ключа или уникальности.
+func IsUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
@@ -0,0 +1,79 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/database/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	require.ErrorIs(t, sqlite.Config{}.Validate(), sqlite.ErrInvalidConfiguration)
+	require.ErrorIs(t, sqlite.Config{Path: ":memory:"}.Validate(), sqlite.ErrInvalidConfiguration)
+	require.NoError(t, sqlite.Config{Path: "persons.db"}.Validate())
+}
+
+func TestConfigDSN(t *testing.T) {
+	config := sqlite.Config{Path: "data/persons.db", BusyTimeout: 2 * time.Second}
+
+	dsn := config.DSN()
+	assert.True(t, strings.HasPrefix(dsn, "data/persons.db?"))
+	assert.Contains(t, dsn, "busy_timeout%282000%29")
+	assert.Contains(t, dsn, "foreign_keys%281%29")
+	assert.Contains(t, dsn, "_txlock=immediate")
+	assert.Equal(t, "sqlite://"+dsn, config.MigrateURL())
+}
+
+func TestNewCreatesDirectory(t *testing.T) {
+	ctx := context.Background()
+	config := sqlite.Config{Path: filepath.Join(t.TempDir(), "nested", "persons.db"), BusyTimeout: time.Second}
+
+	db, err := sqlite.New(ctx, config)
+	require.NoError(t, err)
+	defer db.Close(ctx)
+
+	require.NoError(t, db.Ping(ctx))
+	assert.Equal(t, config.MigrateURL(), db.GetDSN())
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.New(ctx, sqlite.Config{Path: filepath.Join(t.TempDir(), "persons.db"), BusyTimeout: time.Second})
+	require.NoError(t, err)
+	defer db.Close(ctx)
+
+	_, err = db.DB().ExecContext(ctx, `CREATE TABLE items (id TEXT PRIMARY KEY)`)
+	require.NoError(t, err)
+	_, err = db.DB().ExecContext(ctx, `INSERT INTO items (id) VALUES ('a')`)
+	require.NoError(t, err)
+
+	_, err = db.DB().ExecContext(ctx, `INSERT INTO items (id) VALUES ('a')`)
+	require.Error(t, err)
+	assert.True(t, sqlite.IsUniqueViolation(err))
+	assert.False(t, sqlite.IsUniqueViolation(context.Canceled))
+}
+
+func TestSchema(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.New(ctx, sqlite.Config{Path: filepath.Join(t.TempDir(), "persons.db"), BusyTimeout: time.Second})
+	require.NoError(t, err)
+	defer db.Close(ctx)
+
+	_, err = db.DB().ExecContext(ctx, `
+        CREATE TABLE schema_migrations (version INTEGER);
+        CREATE TABLE items (id TEXT PRIMARY KEY, name TEXT);
+        CREATE INDEX idx_items_name ON items(name);`)
+	require.NoError(t, err)
+
+	schema, err := db.Schema(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"index idx_items_name: CREATE INDEX idx_items_name ON items(name)",
+		"table items: CREATE TABLE items (id TEXT PRIMARY KEY, name TEXT)",
+	}, schema)
+}
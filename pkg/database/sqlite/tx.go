@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Querier определяет методы выполнения запросов, общие для пула соединений и транзакции.
+// Репозитории выполняют запросы через Querier, поэтому одинаково работают и вне транзакции, и внутри нее.
+type Querier interface {
+	// ExecContext выполняет запрос без результата.
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	// QueryContext выполняет запрос и возвращает строки результата.
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	// QueryRowContext выполняет запрос, возвращающий не более одной строки.
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Проверка реализации интерфейса.
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+)
+
+// savepointName - имя точки сохранения RunInSavepoint. Вложенные точки с одним именем допустимы:
+// RELEASE и ROLLBACK TO относятся к последней из них.
+const savepointName = "nested"
+
+// RunInTx выполняет fn в транзакции и фиксирует ее, если fn завершилась без ошибки, иначе откатывает.
+// SQLite допускает одну пишущую транзакцию, поэтому конфликтов сериализации, требующих повтора, нет.
+func RunInTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// После успешной фиксации Rollback возвращает sql.ErrTxDone, который не является ошибкой.
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.Warn(ctx, "failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RunInSavepoint выполняет fn внутри транзакции tx на точке сохранения:
+// ошибка fn откатывает только изменения fn, а транзакция tx продолжается.
+func RunInSavepoint(ctx context.Context, tx *sql.Tx, fn func(tx *sql.Tx) error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepointName); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO "+savepointName); rollbackErr != nil {
+			logger.Warn(ctx, "failed to rollback to savepoint", zap.Error(rollbackErr))
+		}
+		if _, releaseErr := tx.ExecContext(ctx, "RELEASE "+savepointName); releaseErr != nil {
+			logger.Warn(ctx, "failed to release savepoint", zap.Error(releaseErr))
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE "+savepointName); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,454 @@
+// Package jsonpatch реализует применение JSON Patch (RFC 6902) и JSON Merge Patch (RFC 7396)
+// к JSON-документам.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Типы содержимого запросов с патчами.
+const (
+	ContentTypeJSONPatch  = "application/json-patch+json"
+	ContentTypeMergePatch = "application/merge-patch+json"
+)
+
+// Ошибки, возникающие при разборе и применении патчей.
+var (
+	ErrInvalidPatch     = errors.New("invalid patch document")
+	ErrInvalidOperation = errors.New("invalid patch operation")
+	ErrInvalidPointer   = errors.New("invalid JSON pointer")
+	ErrPathNotFound     = errors.New("path not found")
+	ErrTestFailed       = errors.New("test operation failed")
+)
+
+// Operation представляет одну операцию JSON Patch.
+type Operation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from,omitempty"`
+	Value *json.RawMessage `json:"value,omitempty"`
+}
+
+// UnmarshalJSON разбирает операцию, сохраняя явное значение null в поле value.
+func (op *Operation) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to decode operation: %w", err)
+	}
+
+	for key, target := range map[string]*string{"op": &op.Op, "path": &op.Path, "from": &op.From} {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			return fmt.Errorf("failed to decode operation field %q: %w", key, err)
+		}
+	}
+
+	if raw, ok := fields["value"]; ok {
+		value := raw
+		op.Value = &value
+	}
+	return nil
+}
+
+// Patch представляет документ JSON Patch - упорядоченный список операций.
+type Patch []Operation
+
+// DecodePatch разбирает документ JSON Patch и проверяет корректность операций.
+func DecodePatch(data []byte) (Patch, error) {
+	var patch Patch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	for i, op := range patch {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("%w: operation %d (%s) requires value", ErrInvalidOperation, i, op.Op)
+			}
+		case "move", "copy":
+			if _, err := parsePointer(op.From); err != nil {
+				return nil, fmt.Errorf("%w: operation %d (%s): %w", ErrInvalidOperation, i, op.Op, err)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("%w: operation %d has unknown op %q", ErrInvalidOperation, i, op.Op)
+		}
+
+		if _, err := parsePointer(op.Path); err != nil {
+			return nil, fmt.Errorf("%w: operation %d (%s): %w", ErrInvalidOperation, i, op.Op, err)
+		}
+	}
+
+	return patch, nil
+}
+
+// Apply применяет JSON Patch к документу и возвращает новый документ.
+// Операции применяются атомарно: при ошибке исходный документ не изменяется.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	var root any
+	if err := decode(doc, &root); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	for i, op := range p {
+		var err error
+		root, err = applyOperation(root, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	result, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patched document: %w", err)
+	}
+	return result, nil
+}
+
+// applyOperation применяет одну операцию к дереву документа.
+func applyOperation(root any, op Operation) (any, error) {
+	path, _ := parsePointer(op.Path)
+
+	switch op.Op {
+	case "add":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	case "remove":
+		root, _, err := remove(root, path)
+		return root, err
+	case "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		root, _, err = remove(root, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	case "move":
+		from, _ := parsePointer(op.From)
+		if isPrefix(from, path) && len(from) < len(path) {
+			return nil, fmt.Errorf("%w: cannot move a value into one of its children", ErrInvalidOperation)
+		}
+		root, value, err := remove(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	case "copy":
+		from, _ := parsePointer(op.From)
+		value, err := get(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, deepCopy(value))
+	case "test":
+		expected, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := get(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !Equal(actual, expected) {
+			return nil, ErrTestFailed
+		}
+		return root, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidOperation, op.Op)
+	}
+}
+
+// value декодирует значение операции.
+func (op Operation) value() (any, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("%w: missing value", ErrInvalidOperation)
+	}
+	var value any
+	if err := decode(*op.Value, &value); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOperation, err)
+	}
+	return value, nil
+}
+
+// MergePatch применяет JSON Merge Patch к документу и возвращает новый документ.
+// Значение null в патче удаляет соответствующий ключ документа.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target any
+	if err := decode(doc, &target); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	var patchValue any
+	if err := decode(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	result, err := json.Marshal(mergeValue(target, patchValue))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patched document: %w", err)
+	}
+	return result, nil
+}
+
+// mergeValue реализует алгоритм MergePatch из RFC 7396.
+func mergeValue(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeValue(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// Equal сравнивает два декодированных JSON-значения.
+func Equal(a, b any) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// normalize приводит числа к единому представлению для сравнения.
+func normalize(value any) any {
+	switch typed := value.(type) {
+	case json.Number:
+		if f, err := typed.Float64(); err == nil {
+			return f
+		}
+		return typed.String()
+	case map[string]any:
+		result := make(map[string]any, len(typed))
+		for key, item := range typed {
+			result[key] = normalize(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(typed))
+		for i, item := range typed {
+			result[i] = normalize(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// decode разбирает JSON, сохраняя числа в виде json.Number.
+func decode(data []byte, value *any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("failed to decode JSON: %w", ErrInvalidPatch)
+	}
+	return nil
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901) в список токенов.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: %q must start with '/'", ErrInvalidPointer, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// isPrefix проверяет, является ли путь prefix началом пути path.
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// get возвращает значение по указанному пути.
+func get(root any, path []string) (any, error) {
+	current := root
+	for _, token := range path {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrPathNotFound, token)
+			}
+			current = value
+		case []any:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrPathNotFound, token)
+		}
+	}
+	return current, nil
+}
+
+// add добавляет значение по указанному пути и возвращает новый корень документа.
+func add(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+		return root, nil
+	case []any:
+		index := len(node)
+		if last != "-" {
+			index, err = arrayIndex(last, len(node))
+			if err != nil {
+				return nil, err
+			}
+		}
+		updated := make([]any, 0, len(node)+1)
+		updated = append(updated, node[:index]...)
+		updated = append(updated, value)
+		updated = append(updated, node[index:]...)
+		return replaceParent(root, path[:len(path)-1], updated)
+	default:
+		return nil, fmt.Errorf("%w: parent of %q is not a container", ErrPathNotFound, last)
+	}
+}
+
+// remove удаляет значение по указанному пути и возвращает новый корень и удаленное значение.
+func remove(root any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, root, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		value, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %q", ErrPathNotFound, last)
+		}
+		delete(node, last)
+		return root, value, nil
+	case []any:
+		index, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[index]
+		updated := make([]any, 0, len(node)-1)
+		updated = append(updated, node[:index]...)
+		updated = append(updated, node[index+1:]...)
+		root, err = replaceParent(root, path[:len(path)-1], updated)
+		return root, value, err
+	default:
+		return nil, nil, fmt.Errorf("%w: parent of %q is not a container", ErrPathNotFound, last)
+	}
+}
+
+// replaceParent заменяет массив по указанному пути новым значением.
+func replaceParent(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+	case []any:
+		index, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = value
+	}
+	return root, nil
+}
+
+// arrayIndex разбирает индекс массива и проверяет его границы.
+func arrayIndex(token string, maxIndex int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPointer, token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > maxIndex {
+		return 0, fmt.Errorf("%w: array index %q out of range", ErrPathNotFound, token)
+	}
+	return index, nil
+}
+
+// deepCopy создает глубокую копию декодированного JSON-значения.
+func deepCopy(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(typed))
+		for key, item := range typed {
+			result[key] = deepCopy(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(typed))
+		for i, item := range typed {
+			result[i] = deepCopy(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
@@ -0,0 +1,209 @@
+package jsonpatch_test
+
+import (
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/jsonpatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePatch(t *testing.T) {
+	t.Run("accepts all RFC 6902 operations", func(t *testing.T) {
+		patch, err := jsonpatch.DecodePatch([]byte(`[
+			{"op": "add", "path": "/a", "value": 1},
+			{"op": "remove", "path": "/b"},
+			{"op": "replace", "path": "/c", "value": null},
+			{"op": "move", "from": "/d", "path": "/e"},
+			{"op": "copy", "from": "/e", "path": "/f"},
+			{"op": "test", "path": "/a", "value": 1}
+		]`))
+		require.NoError(t, err)
+		assert.Len(t, patch, 6)
+		require.NotNil(t, patch[2].Value, "explicit null value must be preserved")
+		assert.JSONEq(t, "null", string(*patch[2].Value))
+	})
+
+	t.Run("rejects unknown operation", func(t *testing.T) {
+		_, err := jsonpatch.DecodePatch([]byte(`[{"op": "merge", "path": "/a"}]`))
+		assert.ErrorIs(t, err, jsonpatch.ErrInvalidOperation)
+	})
+
+	t.Run("rejects add without value", func(t *testing.T) {
+		_, err := jsonpatch.DecodePatch([]byte(`[{"op": "add", "path": "/a"}]`))
+		assert.ErrorIs(t, err, jsonpatch.ErrInvalidOperation)
+	})
+
+	t.Run("rejects pointer without leading slash", func(t *testing.T) {
+		_, err := jsonpatch.DecodePatch([]byte(`[{"op": "remove", "path": "a"}]`))
+		assert.ErrorIs(t, err, jsonpatch.ErrInvalidOperation)
+	})
+
+	t.Run("rejects non-array document", func(t *testing.T) {
+		_, err := jsonpatch.DecodePatch([]byte(`{"op": "remove", "path": "/a"}`))
+		assert.ErrorIs(t, err, jsonpatch.ErrInvalidPatch)
+	})
+}
+
+func TestPatchApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "add member",
+			doc:      `{"name": "Ivan"}`,
+			patch:    `[{"op": "add", "path": "/age", "value": 30}]`,
+			expected: `{"name": "Ivan", "age": 30}`,
+		},
+		{
+			name:     "replace member",
+			doc:      `{"name": "Ivan", "age": 25}`,
+			patch:    `[{"op": "replace", "path": "/age", "value": 26}]`,
+			expected: `{"name": "Ivan", "age": 26}`,
+		},
+		{
+			name:  "replace missing member fails",
+			doc:   `{"name": "Ivan"}`,
+			patch: `[{"op": "replace", "path": "/age", "value": 26}]`,
+			err:   jsonpatch.ErrPathNotFound,
+		},
+		{
+			name:     "remove member",
+			doc:      `{"name": "Ivan", "age": 25}`,
+			patch:    `[{"op": "remove", "path": "/age"}]`,
+			expected: `{"name": "Ivan"}`,
+		},
+		{
+			name:  "remove missing member fails",
+			doc:   `{"name": "Ivan"}`,
+			patch: `[{"op": "remove", "path": "/age"}]`,
+			err:   jsonpatch.ErrPathNotFound,
+		},
+		{
+			name:     "move member",
+			doc:      `{"a": 1}`,
+			patch:    `[{"op": "move", "from": "/a", "path": "/b"}]`,
+			expected: `{"b": 1}`,
+		},
+		{
+			name:     "copy member",
+			doc:      `{"a": {"x": 1}}`,
+			patch:    `[{"op": "copy", "from": "/a", "path": "/b"}]`,
+			expected: `{"a": {"x": 1}, "b": {"x": 1}}`,
+		},
+		{
+			name:     "array insert and append",
+			doc:      `{"list": [1, 3]}`,
+			patch:    `[{"op": "add", "path": "/list/1", "value": 2}, {"op": "add", "path": "/list/-", "value": 4}]`,
+			expected: `{"list": [1, 2, 3, 4]}`,
+		},
+		{
+			name:     "array remove",
+			doc:      `{"list": [1, 2, 3]}`,
+			patch:    `[{"op": "remove", "path": "/list/0"}]`,
+			expected: `{"list": [2, 3]}`,
+		},
+		{
+			name:     "escaped pointer",
+			doc:      `{"a/b": 1, "c~d": 2}`,
+			patch:    `[{"op": "replace", "path": "/a~1b", "value": 3}, {"op": "remove", "path": "/c~0d"}]`,
+			expected: `{"a/b": 3}`,
+		},
+		{
+			name:     "successful test operation",
+			doc:      `{"age": 30}`,
+			patch:    `[{"op": "test", "path": "/age", "value": 30.0}, {"op": "replace", "path": "/age", "value": 31}]`,
+			expected: `{"age": 31}`,
+		},
+		{
+			name:  "failed test operation",
+			doc:   `{"age": 30}`,
+			patch: `[{"op": "test", "path": "/age", "value": 29}]`,
+			err:   jsonpatch.ErrTestFailed,
+		},
+		{
+			name:  "move into own child fails",
+			doc:   `{"a": {"b": 1}}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a/c"}]`,
+			err:   jsonpatch.ErrInvalidOperation,
+		},
+		{
+			name:  "array index out of range fails",
+			doc:   `{"list": [1]}`,
+			patch: `[{"op": "add", "path": "/list/5", "value": 2}]`,
+			err:   jsonpatch.ErrPathNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := jsonpatch.DecodePatch([]byte(tt.patch))
+			require.NoError(t, err)
+
+			result, err := patch.Apply([]byte(tt.doc))
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "replace and add members",
+			doc:      `{"name": "Ivan", "age": 25}`,
+			patch:    `{"age": 30, "gender": "male"}`,
+			expected: `{"name": "Ivan", "age": 30, "gender": "male"}`,
+		},
+		{
+			name:     "null removes member",
+			doc:      `{"name": "Ivan", "gender": "male"}`,
+			patch:    `{"gender": null}`,
+			expected: `{"name": "Ivan"}`,
+		},
+		{
+			name:     "nested objects are merged recursively",
+			doc:      `{"a": {"b": 1, "c": 2}}`,
+			patch:    `{"a": {"c": null, "d": 3}}`,
+			expected: `{"a": {"b": 1, "d": 3}}`,
+		},
+		{
+			name:     "non-object patch replaces document",
+			doc:      `{"a": 1}`,
+			patch:    `[1, 2]`,
+			expected: `[1, 2]`,
+		},
+		{
+			name:     "empty patch keeps document",
+			doc:      `{"a": 1}`,
+			patch:    `{}`,
+			expected: `{"a": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonpatch.MergePatch([]byte(tt.doc), []byte(tt.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+
+	t.Run("invalid patch document", func(t *testing.T) {
+		_, err := jsonpatch.MergePatch([]byte(`{}`), []byte(`{`))
+		assert.ErrorIs(t, err, jsonpatch.ErrInvalidPatch)
+	})
+}
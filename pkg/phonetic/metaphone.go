@@ -0,0 +1,533 @@
+package phonetic
+
+import "strings"
+
+// metaphoneLength определяет длину кодов Double Metaphone.
+const metaphoneLength = 4
+
+// DoubleMetaphone возвращает основной и альтернативный коды Double Metaphone
+// (Lawrence Philips). Альтернативный код учитывает иное произношение, например
+// славянское или германское; если он совпадает с основным, возвращается пустая строка.
+// Кириллица предварительно транслитерируется.
+func DoubleMetaphone(value string) (primary, alternate string) {
+	m := &metaphone{value: latin(value)}
+	if m.value == "" {
+		return "", ""
+	}
+	m.encode()
+
+	primary, alternate = m.primary.String(), m.alternate.String()
+	if alternate == primary {
+		alternate = ""
+	}
+	return primary, alternate
+}
+
+// metaphone хранит состояние кодирования одного слова.
+type metaphone struct {
+	value         string
+	slavoGermanic bool
+	primary       strings.Builder
+	alternate     strings.Builder
+}
+
+func (m *metaphone) encode() {
+	m.slavoGermanic = strings.Contains(m.value, "W") || strings.Contains(m.value, "K") ||
+		strings.Contains(m.value, "CZ") || strings.Contains(m.value, "WITZ")
+
+	index := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		index = 1
+	}
+
+	for !m.complete() && index < len(m.value) {
+		switch m.at(index) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if index == 0 {
+				m.add("A")
+			}
+			index++
+		case 'B':
+			m.add("P")
+			index = m.skip(index, "B")
+		case 'C':
+			index = m.handleC(index)
+		case 'D':
+			index = m.handleD(index)
+		case 'F':
+			m.add("F")
+			index = m.skip(index, "F")
+		case 'G':
+			index = m.handleG(index)
+		case 'H':
+			index = m.handleH(index)
+		case 'J':
+			index = m.handleJ(index)
+		case 'K':
+			m.add("K")
+			index = m.skip(index, "K")
+		case 'L':
+			index = m.handleL(index)
+		case 'M':
+			m.add("M")
+			if m.at(index+1) == 'M' || (m.contains(index-1, 3, "UMB") &&
+				(index+1 == len(m.value)-1 || m.contains(index+2, 2, "ER"))) {
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.add("N")
+			index = m.skip(index, "N")
+		case 'P':
+			if m.at(index+1) == 'H' {
+				m.add("F")
+				index += 2
+			} else {
+				m.add("P")
+				index = m.skip(index, "P", "B")
+			}
+		case 'Q':
+			m.add("K")
+			index = m.skip(index, "Q")
+		case 'R':
+			index = m.handleR(index)
+		case 'S':
+			index = m.handleS(index)
+		case 'T':
+			index = m.handleT(index)
+		case 'V':
+			m.add("F")
+			index = m.skip(index, "V")
+		case 'W':
+			index = m.handleW(index)
+		case 'X':
+			index = m.handleX(index)
+		case 'Z':
+			index = m.handleZ(index)
+		default:
+			index++
+		}
+	}
+}
+
+func (m *metaphone) handleC(index int) int {
+	switch {
+	case m.germanicC(index):
+		m.add("K")
+		return index + 2
+	case index == 0 && m.contains(index, 6, "CAESAR"):
+		m.add("S")
+		return index + 2
+	case m.contains(index, 2, "CH"):
+		return m.handleCH(index)
+	case m.contains(index, 2, "CZ") && !m.contains(index-2, 4, "WICZ"):
+		m.addPair("S", "X")
+		return index + 2
+	case m.contains(index+1, 3, "CIA"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "CC") && !(index == 1 && m.at(0) == 'M'):
+		return m.handleCC(index)
+	case m.contains(index, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return index + 2
+	case m.contains(index, 2, "CI", "CE", "CY"):
+		if m.contains(index, 3, "CIO", "CIE", "CIA") {
+			m.addPair("S", "X")
+		} else {
+			m.add("S")
+		}
+		return index + 2
+	}
+
+	m.add("K")
+	switch {
+	case m.contains(index+1, 2, " C", " Q", " G"):
+		return index + 3
+	case m.contains(index+1, 1, "C", "K", "Q") && !m.contains(index+1, 2, "CE", "CI"):
+		return index + 2
+	default:
+		return index + 1
+	}
+}
+
+// germanicC проверяет германское «ACH», произносимое как K (Bacher, Macher).
+func (m *metaphone) germanicC(index int) bool {
+	switch {
+	case m.contains(index, 4, "CHIA"):
+		return true
+	case index <= 1, isVowel(m.at(index - 2)), !m.contains(index-1, 3, "ACH"):
+		return false
+	}
+	c := m.at(index + 2)
+	return (c != 'I' && c != 'E') || m.contains(index-2, 6, "BACHER", "MACHER")
+}
+
+func (m *metaphone) handleCC(index int) int {
+	if m.contains(index+2, 1, "I", "E", "H") && !m.contains(index+2, 2, "HU") {
+		if (index == 1 && m.at(index-1) == 'A') || m.contains(index-1, 5, "UCCEE", "UCCES") {
+			m.add("KS")
+		} else {
+			m.add("X")
+		}
+		return index + 3
+	}
+	m.add("K")
+	return index + 2
+}
+
+func (m *metaphone) handleCH(index int) int {
+	switch {
+	case index > 0 && m.contains(index, 4, "CHAE"):
+		m.addPair("K", "X")
+	case m.greekCH(index), m.germanicCH(index):
+		m.add("K")
+	case index == 0:
+		m.add("X")
+	case m.contains(0, 2, "MC"):
+		m.add("K")
+	default:
+		m.addPair("X", "K")
+	}
+	return index + 2
+}
+
+// greekCH проверяет греческие корни в начале слова (Character, Chorus).
+func (m *metaphone) greekCH(index int) bool {
+	return index == 0 &&
+		(m.contains(index+1, 5, "HARAC", "HARIS") || m.contains(index+1, 3, "HOR", "HYM", "HIA", "HEM")) &&
+		!m.contains(0, 5, "CHORE")
+}
+
+// germanicCH проверяет германские и греческие сочетания, где CH звучит как K.
+func (m *metaphone) germanicCH(index int) bool {
+	return m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") ||
+		m.contains(index-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.contains(index+2, 1, "T", "S") ||
+		((m.contains(index-1, 1, "A", "O", "U", "E") || index == 0) &&
+			(m.contains(index+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || index+1 == len(m.value)-1))
+}
+
+func (m *metaphone) handleD(index int) int {
+	switch {
+	case m.contains(index, 2, "DG"):
+		if m.contains(index+2, 1, "I", "E", "Y") {
+			m.add("J")
+			return index + 3
+		}
+		m.add("TK")
+		return index + 2
+	case m.contains(index, 2, "DT", "DD"):
+		m.add("T")
+		return index + 2
+	default:
+		m.add("T")
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleG(index int) int {
+	switch {
+	case m.at(index+1) == 'H':
+		return m.handleGH(index)
+	case m.at(index+1) == 'N':
+		switch {
+		case index == 1 && isVowel(m.at(0)) && !m.slavoGermanic:
+			m.addPair("KN", "N")
+		case !m.contains(index+2, 2, "EY") && m.at(index+1) != 'Y' && !m.slavoGermanic:
+			m.addPair("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return index + 2
+	case m.contains(index+1, 2, "LI") && !m.slavoGermanic:
+		m.addPair("KL", "L")
+		return index + 2
+	case index == 0 && (m.at(index+1) == 'Y' ||
+		m.contains(index+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.addPair("K", "J")
+		return index + 2
+	case (m.contains(index+1, 2, "ER") || m.at(index+1) == 'Y') &&
+		!m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(index-1, 1, "E", "I") && !m.contains(index-1, 3, "RGY", "OGY"):
+		m.addPair("K", "J")
+		return index + 2
+	case m.contains(index+1, 1, "E", "I", "Y") || m.contains(index-1, 4, "AGGI", "OGGI"):
+		switch {
+		case m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") || m.contains(index+1, 2, "ET"):
+			m.add("K")
+		case m.contains(index+1, 3, "IER"):
+			m.add("J")
+		default:
+			m.addPair("J", "K")
+		}
+		return index + 2
+	default:
+		m.add("K")
+		return m.skip(index, "G")
+	}
+}
+
+func (m *metaphone) handleGH(index int) int {
+	switch {
+	case index > 0 && !isVowel(m.at(index-1)):
+		m.add("K")
+	case index == 0:
+		if m.at(index+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case (index > 1 && m.contains(index-2, 1, "B", "H", "D")) ||
+		(index > 2 && m.contains(index-3, 1, "B", "H", "D")) ||
+		(index > 3 && m.contains(index-4, 1, "B", "H")):
+		// Немое GH (Hugh, bough).
+	case index > 2 && m.at(index-1) == 'U' && m.contains(index-3, 1, "C", "G", "L", "R", "T"):
+		m.add("F")
+	case m.at(index-1) != 'I':
+		m.add("K")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleH(index int) int {
+	if (index == 0 || isVowel(m.at(index-1))) && isVowel(m.at(index+1)) {
+		m.add("H")
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleJ(index int) int {
+	if m.contains(index, 4, "JOSE") || m.contains(0, 4, "SAN ") {
+		if (index == 0 && m.at(index+4) == ' ') || len(m.value) == 4 || m.contains(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.addPair("J", "H")
+		}
+		return index + 1
+	}
+
+	switch {
+	case index == 0:
+		m.addPair("J", "A")
+	case isVowel(m.at(index-1)) && !m.slavoGermanic && (m.at(index+1) == 'A' || m.at(index+1) == 'O'):
+		m.addPair("J", "H")
+	case index == len(m.value)-1:
+		m.addPair("J", "")
+	case !m.contains(index+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(index-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(index, "J")
+}
+
+func (m *metaphone) handleL(index int) int {
+	if m.at(index+1) != 'L' {
+		m.add("L")
+		return index + 1
+	}
+
+	last := len(m.value) - 1
+	spanish := (index == last-2 && m.contains(index-1, 4, "ILLO", "ILLA", "ALLE")) ||
+		((m.contains(last-1, 2, "AS", "OS") || m.contains(last, 1, "A", "O")) && m.contains(index-1, 4, "ALLE"))
+	if spanish {
+		m.addPair("L", "")
+	} else {
+		m.add("L")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleR(index int) int {
+	// Французское немое R в конце слова (Rogier).
+	if index == len(m.value)-1 && !m.slavoGermanic &&
+		m.contains(index-2, 2, "IE") && !m.contains(index-4, 2, "ME", "MA") {
+		m.addPair("", "R")
+	} else {
+		m.add("R")
+	}
+	return m.skip(index, "R")
+}
+
+func (m *metaphone) handleS(index int) int {
+	switch {
+	case m.contains(index-1, 3, "ISL", "YSL"):
+		return index + 1
+	case index == 0 && m.contains(index, 5, "SUGAR"):
+		m.addPair("X", "S")
+		return index + 1
+	case m.contains(index, 2, "SH"):
+		if m.contains(index+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return index + 2
+	case m.contains(index, 3, "SIO", "SIA") || m.contains(index, 4, "SIAN"):
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.addPair("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.contains(index+1, 1, "M", "N", "L", "W")) || m.contains(index+1, 1, "Z"):
+		m.addPair("S", "X")
+		return m.skip(index, "Z")
+	case m.contains(index, 2, "SC"):
+		return m.handleSC(index)
+	}
+
+	if index == len(m.value)-1 && m.contains(index-2, 2, "AI", "OI") {
+		m.addPair("", "S")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, "S", "Z")
+}
+
+func (m *metaphone) handleSC(index int) int {
+	switch {
+	case m.at(index+2) == 'H':
+		switch {
+		case m.contains(index+3, 2, "ER", "EN"):
+			m.addPair("X", "SK")
+		case m.contains(index+3, 2, "OO", "UY", "ED", "EM"):
+			m.add("SK")
+		case index == 0 && !isVowel(m.at(3)) && m.at(3) != 'W':
+			m.addPair("X", "S")
+		default:
+			m.add("X")
+		}
+	case m.contains(index+2, 1, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return index + 3
+}
+
+func (m *metaphone) handleT(index int) int {
+	switch {
+	case m.contains(index, 4, "TION"), m.contains(index, 3, "TIA", "TCH"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "TH") || m.contains(index, 3, "TTH"):
+		if m.contains(index+2, 2, "OM", "AM") || m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") {
+			m.add("T")
+		} else {
+			m.addPair("0", "T")
+		}
+		return index + 2
+	default:
+		m.add("T")
+		return m.skip(index, "T", "D")
+	}
+}
+
+func (m *metaphone) handleW(index int) int {
+	switch {
+	case m.contains(index, 2, "WR"):
+		m.add("R")
+		return index + 2
+	case index == 0 && (isVowel(m.at(index+1)) || m.contains(index, 2, "WH")):
+		if isVowel(m.at(index + 1)) {
+			m.addPair("A", "F")
+		} else {
+			m.add("A")
+		}
+		return index + 1
+	case (index == len(m.value)-1 && isVowel(m.at(index-1))) ||
+		m.contains(index-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.contains(0, 3, "SCH"):
+		m.addPair("", "F")
+		return index + 1
+	case m.contains(index, 4, "WICZ", "WITZ"):
+		m.addPair("TS", "FX")
+		return index + 4
+	default:
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleX(index int) int {
+	if index == 0 {
+		m.add("S")
+		return index + 1
+	}
+	// Французское немое X в конце слова (Breaux).
+	if !(index == len(m.value)-1 && (m.contains(index-3, 3, "IAU", "EAU") || m.contains(index-2, 2, "AU", "OU"))) {
+		m.add("KS")
+	}
+	return m.skip(index, "C", "X")
+}
+
+func (m *metaphone) handleZ(index int) int {
+	if m.at(index+1) == 'H' {
+		m.add("J")
+		return index + 2
+	}
+	if m.contains(index+1, 2, "ZO", "ZI", "ZA") || (m.slavoGermanic && index > 0 && m.at(index-1) != 'T') {
+		m.addPair("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, "Z")
+}
+
+// at возвращает символ по индексу или 0 за пределами слова.
+func (m *metaphone) at(index int) byte {
+	if index < 0 || index >= len(m.value) {
+		return 0
+	}
+	return m.value[index]
+}
+
+// contains проверяет, совпадает ли подстрока длины length с позиции start с одним из вариантов.
+func (m *metaphone) contains(start, length int, variants ...string) bool {
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+	target := m.value[start : start+length]
+	for _, variant := range variants {
+		if target == variant {
+			return true
+		}
+	}
+	return false
+}
+
+// skip возвращает индекс следующего символа, пропуская один из перечисленных символов после текущего.
+func (m *metaphone) skip(index int, next ...string) int {
+	if m.contains(index+1, 1, next...) {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) add(code string) {
+	m.addPair(code, code)
+}
+
+func (m *metaphone) addPair(primary, alternate string) {
+	appendLimited(&m.primary, primary)
+	appendLimited(&m.alternate, alternate)
+}
+
+func (m *metaphone) complete() bool {
+	return m.primary.Len() >= metaphoneLength && m.alternate.Len() >= metaphoneLength
+}
+
+func appendLimited(b *strings.Builder, code string) {
+	if room := metaphoneLength - b.Len(); room < len(code) {
+		code = code[:max(room, 0)]
+	}
+	b.WriteString(code)
+}
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'A', 'E', 'I', 'O', 'U', 'Y':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+// Package phonetic реализует фонетические алгоритмы для сопоставления имен:
+// Soundex, Double Metaphone и русский метафон, устойчивый к транслитерации.
+package phonetic
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Префиксы ключей, по которым различаются алгоритмы в общем наборе ключей.
+const (
+	PrefixSoundex   = "s:"
+	PrefixMetaphone = "m:"
+	PrefixRussian   = "r:"
+)
+
+// Keys возвращает отсортированный набор фонетических ключей всех слов строки.
+// Каждый ключ снабжен префиксом алгоритма, поэтому наборы двух строк можно
+// сравнивать пересечением: совпадение хотя бы одного ключа означает созвучие.
+func Keys(value string) []string {
+	seen := make(map[string]bool)
+	for _, word := range words(value) {
+		if code := Soundex(word); code != "" {
+			seen[PrefixSoundex+code] = true
+		}
+		primary, alternate := DoubleMetaphone(word)
+		if primary != "" {
+			seen[PrefixMetaphone+primary] = true
+		}
+		if alternate != "" {
+			seen[PrefixMetaphone+alternate] = true
+		}
+		if code := Russian(word); code != "" {
+			seen[PrefixRussian+code] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// words разбивает строку на слова из букв; составные имена («Анна-Мария») дают несколько слов.
+func words(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// cyrillicToLatin содержит транслитерацию кириллицы для алгоритмов, работающих с латиницей.
+var cyrillicToLatin = map[rune]string{
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ё': "E", 'Ж': "ZH",
+	'З': "Z", 'И': "I", 'Й': "Y", 'К': "K", 'Л': "L", 'М': "M", 'Н': "N", 'О': "O",
+	'П': "P", 'Р': "R", 'С': "S", 'Т': "T", 'У': "U", 'Ф': "F", 'Х': "KH", 'Ц': "TS",
+	'Ч': "CH", 'Ш': "SH", 'Щ': "SHCH", 'Ъ': "", 'Ы': "Y", 'Ь': "", 'Э': "E", 'Ю': "YU",
+	'Я': "YA", 'І': "I", 'Ї': "YI", 'Є': "YE", 'Ґ': "G",
+}
+
+// latin приводит слово к верхнему регистру латиницы: кириллица транслитерируется,
+// прочие символы вне диапазона A-Z отбрасываются.
+func latin(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(value) {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		default:
+			if s, ok := cyrillicToLatin[r]; ok {
+				b.WriteString(s)
+			}
+		}
+	}
+	return b.String()
+}
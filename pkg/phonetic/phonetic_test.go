@@ -0,0 +1,119 @@
+package phonetic_test
+
+import (
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/phonetic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoundex(t *testing.T) {
+	cases := map[string]string{
+		"Robert":    "R163",
+		"Rupert":    "R163",
+		"Rubin":     "R150",
+		"Ashcraft":  "A261",
+		"Tymczak":   "T522",
+		"Pfister":   "P236",
+		"Lee":       "L000",
+		"":          "",
+		"123":       "",
+		"Иванов":    "I151",
+		"Ivanov":    "I151",
+		"o'connor":  "O256",
+		"Dmitriy":   "D536",
+		"Dmitry":    "D536",
+		"Gutierrez": "G362",
+	}
+	for input, want := range cases {
+		t.Run(input, func(t *testing.T) {
+			assert.Equal(t, want, phonetic.Soundex(input))
+		})
+	}
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	cases := []struct {
+		input     string
+		primary   string
+		alternate string
+	}{
+		{"Smith", "SM0", "XMT"},
+		{"Schmidt", "XMT", "SMT"},
+		{"Thompson", "TMPS", ""},
+		{"Knight", "NT", ""},
+		{"Jose", "HS", ""},
+		{"Catherine", "K0RN", "KTRN"},
+		{"Katherine", "K0RN", "KTRN"},
+		{"Philip", "FLP", ""},
+		{"Dmitriy", "TMTR", ""},
+		{"Dmitry", "TMTR", ""},
+		{"Дмитрий", "TMTR", ""},
+		{"Alexander", "ALKS", ""},
+		{"Wasserman", "ASRM", "FSRM"},
+		{"", "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			primary, alternate := phonetic.DoubleMetaphone(tc.input)
+			assert.Equal(t, tc.primary, primary)
+			assert.Equal(t, tc.alternate, alternate)
+		})
+	}
+}
+
+func TestRussian(t *testing.T) {
+	t.Run("transliteration variants share a key", func(t *testing.T) {
+		groups := [][]string{
+			{"Дмитрий", "Dmitriy", "Dmitry", "Dmitrii", "Dmitrij"},
+			{"Андрей", "Andrey", "Andrei"},
+			{"Мария", "Maria", "Mariya"},
+			{"Щукин", "Shchukin", "Schukin"},
+			{"Чайковский", "Chaikovskiy", "Chaykovsky"},
+			{"Юрий", "Yuriy", "Yury", "Iurii"},
+		}
+		for _, group := range groups {
+			want := phonetic.Russian(group[0])
+			assert.NotEmpty(t, want, group[0])
+			for _, variant := range group[1:] {
+				assert.Equal(t, want, phonetic.Russian(variant), "%s vs %s", group[0], variant)
+			}
+		}
+	})
+
+	t.Run("vowel reduction and devoicing", func(t *testing.T) {
+		assert.Equal(t, phonetic.Russian("Петров"), phonetic.Russian("Питров"))
+		assert.Equal(t, phonetic.Russian("Глеб"), phonetic.Russian("Глеп"))
+	})
+
+	t.Run("different names differ", func(t *testing.T) {
+		assert.NotEqual(t, phonetic.Russian("Иван"), phonetic.Russian("Олег"))
+		assert.NotEqual(t, phonetic.Russian("Петров"), phonetic.Russian("Сидоров"))
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		assert.Empty(t, phonetic.Russian(""))
+		assert.Empty(t, phonetic.Russian("ЪЬ-1"))
+	})
+}
+
+func TestKeys(t *testing.T) {
+	t.Run("keys are prefixed, sorted and unique", func(t *testing.T) {
+		keys := phonetic.Keys("Dmitry")
+		assert.Equal(t, []string{"m:TMTR", "r:ДМИТР7", "s:D536"}, keys)
+	})
+
+	t.Run("spelling variants overlap", func(t *testing.T) {
+		assert.Equal(t, phonetic.Keys("Дмитрий"), phonetic.Keys("Dmitriy"))
+	})
+
+	t.Run("compound names produce keys for every part", func(t *testing.T) {
+		keys := phonetic.Keys("Anna-Maria")
+		assert.Subset(t, keys, phonetic.Keys("Anna"))
+		assert.Subset(t, keys, phonetic.Keys("Maria"))
+	})
+
+	t.Run("no letters", func(t *testing.T) {
+		assert.Empty(t, phonetic.Keys(" 42 "))
+	})
+}
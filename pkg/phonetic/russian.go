@@ -0,0 +1,161 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// russianEndings сжимает типичные окончания фамилий и имен в один символ,
+// чтобы их написание не влияло на ключ. Более длинные окончания проверяются первыми.
+var russianEndings = []struct {
+	ending string
+	code   string
+}{
+	{"ОВСКИЙ", "@"}, {"ЕВСКИЙ", "#"}, {"ОВСКАЯ", "$"}, {"ЕВСКАЯ", "%"},
+	{"ИЕВА", "9"}, {"ЕЕВА", "9"}, {"ОВА", "9"}, {"ЕВА", "9"},
+	{"ИЕВ", "4"}, {"ЕЕВ", "4"}, {"НКО", "3"}, {"ИНА", "1"},
+	{"ОВ", "4"}, {"ЕВ", "4"}, {"АЯ", "6"}, {"ИЙ", "7"}, {"ЫЙ", "7"},
+	{"ЫХ", "5"}, {"ИХ", "5"}, {"ИН", "8"}, {"ИК", "2"}, {"ЕК", "2"}, {"УК", "0"}, {"ЮК", "0"},
+}
+
+// russianVowels сводит безударные и йотированные гласные к опорным.
+var russianVowels = map[rune]rune{
+	'О': 'А', 'Ы': 'А', 'Я': 'А',
+	'Е': 'И', 'Ё': 'И', 'Э': 'И', 'Й': 'И',
+	'Ю': 'У',
+}
+
+// russianDevoicing содержит пары звонких и глухих согласных, оглушаемых
+// перед глухими согласными и на конце слова.
+var russianDevoicing = map[rune]rune{
+	'Б': 'П', 'З': 'С', 'Д': 'Т', 'В': 'Ф', 'Г': 'К', 'Ж': 'Ш',
+}
+
+// russianVoiceless перечисляет согласные, перед которыми звонкие оглушаются.
+const russianVoiceless = "ПСТКФХЦЧШЩ"
+
+// Russian возвращает ключ русского метафона (по алгоритму П. Каминского).
+// Латиница предварительно транслитерируется в кириллицу, поэтому «Dmitriy», «Dmitry»
+// и «Дмитрий» получают одинаковый ключ.
+func Russian(value string) string {
+	word := cyrillic(value)
+	if word == "" {
+		return ""
+	}
+
+	for _, e := range russianEndings {
+		if strings.HasSuffix(word, e.ending) && utf8.RuneCountInString(word) > utf8.RuneCountInString(e.ending) {
+			word = strings.TrimSuffix(word, e.ending) + e.code
+			break
+		}
+	}
+	word = strings.NewReplacer("ТС", "Ц", "ДС", "Ц").Replace(word)
+
+	letters := []rune(word)
+	var key []rune
+	for i, r := range letters {
+		switch {
+		case (r == 'О' || r == 'Е') && len(key) > 0 && i > 0 && (letters[i-1] == 'Й' || letters[i-1] == 'И'):
+			// ЙО, ИО, ЙЕ и ИЕ звучат как И.
+			key[len(key)-1] = 'И'
+			continue
+		case russianVowels[r] != 0:
+			r = russianVowels[r]
+		case russianDevoicing[r] != 0:
+			if i == len(letters)-1 || strings.ContainsRune(russianVoiceless, letters[i+1]) {
+				r = russianDevoicing[r]
+			}
+		}
+		if len(key) > 0 && key[len(key)-1] == r {
+			continue
+		}
+		key = append(key, r)
+	}
+	return string(key)
+}
+
+// latinToCyrillic содержит сочетания латиницы для обратной транслитерации;
+// более длинные сочетания должны проверяться раньше коротких.
+var latinToCyrillic = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"SHCH", "Щ"}, {"SCH", "Щ"},
+	{"ZH", "Ж"}, {"KH", "Х"}, {"CH", "Ч"}, {"SH", "Ш"}, {"TS", "Ц"}, {"TZ", "Ц"},
+	{"YA", "Я"}, {"YU", "Ю"}, {"IU", "Ю"}, {"YO", "Ё"}, {"YE", "Е"}, {"PH", "Ф"},
+	{"A", "А"}, {"B", "Б"}, {"C", "К"}, {"D", "Д"}, {"E", "Е"}, {"F", "Ф"}, {"G", "Г"},
+	{"H", "Х"}, {"I", "И"}, {"J", "Й"}, {"K", "К"}, {"L", "Л"}, {"M", "М"}, {"N", "Н"},
+	{"O", "О"}, {"P", "П"}, {"Q", "К"}, {"R", "Р"}, {"S", "С"}, {"T", "Т"}, {"U", "У"},
+	{"V", "В"}, {"W", "В"}, {"X", "КС"}, {"Y", "Ы"}, {"Z", "З"},
+}
+
+// latinEndings приводит варианты транслитерации окончаний -ий и -ей к одному написанию.
+var latinEndings = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"IY", "ИЙ"}, {"IJ", "ИЙ"}, {"II", "ИЙ"}, {"YY", "ЫЙ"}, {"YI", "ЫЙ"},
+	{"EY", "ЕЙ"}, {"EI", "ЕЙ"}, {"AY", "АЙ"}, {"OY", "ОЙ"},
+}
+
+// cyrillic приводит слово к верхнему регистру кириллицы, транслитерируя латиницу;
+// прочие символы, а также Ъ и Ь, отбрасываются.
+func cyrillic(value string) string {
+	upper := strings.ToUpper(value)
+
+	var b strings.Builder
+	var latinRun strings.Builder
+	flush := func() {
+		if latinRun.Len() > 0 {
+			b.WriteString(transliterate(latinRun.String()))
+			latinRun.Reset()
+		}
+	}
+	for _, r := range upper {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			latinRun.WriteRune(r)
+		case r >= 'А' && r <= 'Я', r == 'Ё':
+			flush()
+			if r != 'Ъ' && r != 'Ь' {
+				b.WriteRune(r)
+			}
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// transliterate переводит латинское слово в кириллицу.
+func transliterate(word string) string {
+	var ending string
+	for _, e := range latinEndings {
+		if strings.HasSuffix(word, e.latin) && len(word) > len(e.latin) {
+			word, ending = strings.TrimSuffix(word, e.latin), e.cyrillic
+			break
+		}
+	}
+	// Конечная Y после согласной соответствует -ий (Dmitry, Vasily).
+	if ending == "" && len(word) > 1 && word[len(word)-1] == 'Y' && !isVowel(word[len(word)-2]) {
+		word, ending = word[:len(word)-1], "ИЙ"
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(word); {
+		// Y между гласной и согласной обозначает Й (Chaykovsky).
+		if word[i] == 'Y' && i > 0 && isVowel(word[i-1]) && (i == len(word)-1 || !isVowel(word[i+1])) {
+			b.WriteString("Й")
+			i++
+			continue
+		}
+		for _, t := range latinToCyrillic {
+			if strings.HasPrefix(word[i:], t.latin) {
+				b.WriteString(t.cyrillic)
+				i += len(t.latin)
+				break
+			}
+		}
+	}
+	b.WriteString(ending)
+	return b.String()
+}
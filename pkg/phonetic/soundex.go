@@ -0,0 +1,45 @@
+package phonetic
+
+// soundexLength определяет длину кода Soundex.
+const soundexLength = 4
+
+// soundexCodes сопоставляет согласные с цифрами американского Soundex.
+var soundexCodes = map[byte]byte{
+	'B': '1', 'F': '1', 'P': '1', 'V': '1',
+	'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
+	'D': '3', 'T': '3',
+	'L': '4',
+	'M': '5', 'N': '5',
+	'R': '6',
+}
+
+// Soundex возвращает код American Soundex (например, «Robert» → R163).
+// Кириллица предварительно транслитерируется; для строки без букв возвращается пустая строка.
+func Soundex(value string) string {
+	word := latin(value)
+	if word == "" {
+		return ""
+	}
+
+	code := []byte{word[0]}
+	last := soundexCodes[word[0]]
+	for i := 1; i < len(word) && len(code) < soundexLength; i++ {
+		c := word[i]
+		digit, ok := soundexCodes[c]
+		switch {
+		case ok && digit != last:
+			code = append(code, digit)
+			last = digit
+		case ok:
+		case c == 'H' || c == 'W':
+			// H и W не разделяют одинаковые коды соседних согласных.
+		default:
+			last = 0
+		}
+	}
+
+	for len(code) < soundexLength {
+		code = append(code, '0')
+	}
+	return string(code)
+}
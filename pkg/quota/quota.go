@@ -0,0 +1,65 @@
+// Package quota предоставляет ограничение числа операций за интервал времени.
+package quota
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter ограничивает число операций по каждому ключу в пределах фиксированного окна.
+// Счетчик ключа сбрасывается, когда с начала его окна прошла длительность окна.
+type Limiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	counters map[string]*counter
+}
+
+// counter хранит число операций ключа в текущем окне.
+type counter struct {
+	start time.Time
+	count int
+}
+
+// NewLimiter создает ограничитель на limit операций за window.
+// Неположительный limit снимает ограничение.
+func NewLimiter(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:    limit,
+		window:   window,
+		now:      time.Now,
+		counters: make(map[string]*counter),
+	}
+}
+
+// WithClock возвращает ограничитель, получающий текущее время из now; используется в тестах.
+func (l *Limiter) WithClock(now func() time.Time) *Limiter {
+	l.now = now
+	return l
+}
+
+// Allow учитывает операцию по ключу, если квота окна не исчерпана.
+// Возвращает: разрешена ли операция, время до начала следующего окна, если не разрешена.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || l.limit <= 0 {
+		return true, 0
+	}
+
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c, ok := l.counters[key]
+	if !ok || now.Sub(c.start) >= l.window {
+		l.counters[key] = &counter{start: now, count: 1}
+		return true, 0
+	}
+	if c.count >= l.limit {
+		return false, c.start.Add(l.window).Sub(now)
+	}
+	c.count++
+	return true, 0
+}
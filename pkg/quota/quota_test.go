@@ -0,0 +1,40 @@
+package quota_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/quota"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := quota.NewLimiter(2, time.Minute).WithClock(func() time.Time { return now })
+
+	allowed, _ := limiter.Allow("acme")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("acme")
+	assert.True(t, allowed)
+
+	now = now.Add(20 * time.Second)
+	allowed, retryAfter := limiter.Allow("acme")
+	assert.False(t, allowed)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	allowed, _ = limiter.Allow("globex")
+	assert.True(t, allowed, "keys have separate quotas")
+
+	now = now.Add(40 * time.Second)
+	allowed, _ = limiter.Allow("acme")
+	assert.True(t, allowed, "quota resets with the next window")
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	for _, limiter := range []*quota.Limiter{nil, quota.NewLimiter(0, time.Minute)} {
+		for range 10 {
+			allowed, _ := limiter.Allow("acme")
+			assert.True(t, allowed)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Package tenant предоставляет передачу идентификатора арендатора через контекст.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Default - идентификатор арендатора, к которому относятся все запросы сервиса без настроенных
+// арендаторов и все данные, созданные до разделения по арендаторам.
+const Default = "default"
+
+// Ошибки определения арендатора.
+var (
+	ErrInvalidID = errors.New("invalid tenant id")
+	ErrMissing   = errors.New("tenant is not set in context")
+)
+
+// idPattern ограничивает идентификатор строчными латинскими буквами, цифрами, дефисом и подчеркиванием.
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Тип для ключа контекста, использующий строгую типизацию.
+type ctxKey struct{}
+
+// WithID возвращает контекст с идентификатором арендатора.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// ID возвращает идентификатор арендатора из контекста и признак того, что он задан.
+// Арендатор по умолчанию не подставляется: его выбирает вызывающий код, если арендаторы не настроены.
+func ID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Validate проверяет формат идентификатора арендатора.
+func Validate(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+	return nil
+}
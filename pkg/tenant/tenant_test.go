@@ -0,0 +1,29 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestID(t *testing.T) {
+	_, ok := tenant.ID(context.Background())
+	assert.False(t, ok, "a context without tenant must not fall back to the default tenant")
+	_, ok = tenant.ID(tenant.WithID(context.Background(), ""))
+	assert.False(t, ok)
+
+	id, ok := tenant.ID(tenant.WithID(context.Background(), "acme"))
+	assert.True(t, ok)
+	assert.Equal(t, "acme", id)
+}
+
+func TestValidate(t *testing.T) {
+	for _, id := range []string{"default", "acme", "acme-corp_2", "0"} {
+		assert.NoError(t, tenant.Validate(id), id)
+	}
+	for _, id := range []string{"", "Acme", "-acme", "acme corp", "acme'; --", string(make([]byte, 64))} {
+		assert.ErrorIs(t, tenant.Validate(id), tenant.ErrInvalidID, id)
+	}
+}
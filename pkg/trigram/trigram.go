@@ -0,0 +1,46 @@
+// Package trigram реализует триграммное сходство строк по правилам расширения pg_trgm PostgreSQL,
+// чтобы хранилища без pg_trgm оценивали нечеткие совпадения так же, как PostgreSQL.
+package trigram
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Threshold соответствует порогу pg_trgm.similarity_threshold по умолчанию,
+// при котором строки считаются похожими (оператор %).
+const Threshold = 0.3
+
+// Similarity вычисляет сходство строк как функция similarity расширения pg_trgm:
+// строки разбиваются на слова из букв и цифр в нижнем регистре, каждое слово дополняется
+// двумя пробелами в начале и одним в конце, а сходство равно отношению числа общих триграмм
+// к числу триграмм в объединении. Результат округляется до real, как в PostgreSQL.
+func Similarity(a, b string) float64 {
+	trigramsA, trigramsB := Trigrams(a), Trigrams(b)
+	if len(trigramsA) == 0 || len(trigramsB) == 0 {
+		return 0
+	}
+
+	common := 0
+	for trigram := range trigramsA {
+		if trigramsB[trigram] {
+			common++
+		}
+	}
+	return float64(float32(common) / float32(len(trigramsA)+len(trigramsB)-common))
+}
+
+// Trigrams возвращает набор триграмм строки по правилам pg_trgm.
+func Trigrams(value string) map[string]bool {
+	result := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result[string(padded[i:i+3])] = true
+		}
+	}
+	return result
+}
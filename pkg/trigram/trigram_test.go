@@ -0,0 +1,32 @@
+package trigram_test
+
+import (
+	"testing"
+
+	"github.com/flexer2006/case-person-enrichment-go/pkg/trigram"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigrams(t *testing.T) {
+	assert.Equal(t, map[string]bool{"  c": true, " ca": true, "cat": true, "at ": true}, trigram.Trigrams("Cat"))
+	assert.Len(t, trigram.Trigrams("cat, cat!"), 4)
+	assert.Empty(t, trigram.Trigrams(" -- "))
+}
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want float64
+	}{
+		{"Ivanov", "ivanov", 1},
+		{"Иванов", "ИВАНОВ", 1},
+		{"Ivanov", "Ivanof", 5.0 / 9.0},
+		{"Ivanov", "Smith", 0},
+		{"", "Ivanov", 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.a+"/"+tc.b, func(t *testing.T) {
+			assert.InDelta(t, tc.want, trigram.Similarity(tc.a, tc.b), 1e-6)
+		})
+	}
+}